@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Coderovshik/film-library/internal/config"
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/user"
+)
+
+const usage = `usage: filmlib-admin <command> [flags]
+
+commands:
+  create-admin  create the initial admin, the password is read from
+                -password-file or ADMIN_PASSWORD_FILE, or generated and printed
+
+flags:
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	username := flag.String("username", "", "admin username, defaults to ADMIN_USERNAME")
+	passwordFile := flag.String("password-file", "", "file holding the admin password")
+	force := flag.Bool("force", false, "create the admin even if one exists, resetting the password of an existing user")
+
+	if len(os.Args) < 2 || os.Args[1] != "create-admin" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	flag.CommandLine.Parse(os.Args[2:])
+
+	cfg := config.New()
+	if cfg.Storage != config.StorageDatabase {
+		log.Fatalf("create-admin requires %s storage", config.StorageDatabase)
+	}
+	if len(*username) != 0 {
+		cfg.AdminUsername = *username
+	}
+	if len(*passwordFile) != 0 {
+		cfg.AdminPasswordFile = *passwordFile
+	}
+
+	password, err := cfg.AdminPassword()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database, err := db.NewDatabase(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer database.Close()
+
+	repo := user.NewRepository(database.GetDB(), database.GetDialect())
+	s := user.NewService(repo, cfg)
+
+	res, err := s.CreateAdmin(context.Background(), &user.CreateAdminRequest{
+		Username: cfg.AdminUsername,
+		Password: password,
+		Force:    *force,
+	})
+	if errors.Is(err, user.ErrAdminExist) {
+		log.Fatal("admin already exists, use -force to create another one")
+	}
+	if errors.Is(err, user.ErrUserExist) {
+		log.Fatalf("user %s already exists, use -force to make it an admin", cfg.AdminUsername)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	action := "created"
+	if res.Updated {
+		action = "updated"
+	}
+	log.Printf("%s admin %s id=%d", action, res.Username, res.ID)
+
+	// print to stdout only, so the password can be piped into a secret store
+	if len(res.GeneratedPassword) != 0 {
+		fmt.Println(res.GeneratedPassword)
+	}
+}
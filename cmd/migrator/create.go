@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var (
+	validName     = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+	migrationFile = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
+)
+
+// nextVersion returns the version following the highest one found
+// in any of the dirs, so that dialects stay in step.
+func nextVersion(dirs []string) (uint64, error) {
+	var last uint64
+	for _, d := range dirs {
+		entries, err := os.ReadDir(d)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, e := range entries {
+			m := migrationFile.FindStringSubmatch(e.Name())
+			if m == nil {
+				continue
+			}
+			v, err := strconv.ParseUint(m[1], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			last = max(last, v)
+		}
+	}
+
+	return last + 1, nil
+}
+
+func create(base, name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("create: invalid name %q, expected letters, digits and underscores", name)
+	}
+
+	dirs := make([]string, 0, len(db.MigrationDirs))
+	for _, v := range db.MigrationDirs {
+		dirs = append(dirs, filepath.Join(base, v))
+	}
+
+	v, err := nextVersion(dirs)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	for _, d := range dirs {
+		for _, direction := range []string{db.DirectionUp, db.DirectionDown} {
+			path := filepath.Join(d, fmt.Sprintf("%06d_%s.%s.sql", v, name, direction))
+
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+			if err != nil {
+				if errors.Is(err, os.ErrExist) {
+					return fmt.Errorf("create: %s already exists", path)
+				}
+				return fmt.Errorf("create: %w", err)
+			}
+			f.Close()
+
+			log.Printf("created %s", path)
+		}
+	}
+
+	return nil
+}
@@ -1,18 +1,332 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
 
 	"github.com/Coderovshik/film-library/internal/config"
 	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
 )
 
+const usage = `usage: migrator [flags] <command> [args]
+
+commands:
+  up [N]       apply all or N up migrations
+  down [N]     apply N down migrations, all of them with -all
+  goto V       migrate up or down to version V
+  version      print the current version
+  force V      set version V without running migrations, clears the dirty flag
+  status       list migrations and whether they are applied
+  create NAME  create a new pair of up and down migrations for every dialect
+
+flags:
+`
+
+var (
+	dryRun  = flag.Bool("dry-run", false, "print pending migrations or the forced version instead of applying them")
+	downAll = flag.Bool("all", false, "allow down to roll back all migrations")
+	dir     = flag.String("dir", "internal/db", "directory holding the migration directories, used by create")
+)
+
+type logger struct{}
+
+func (logger) Printf(format string, v ...any) {
+	log.Printf(format, v...)
+}
+
+func (logger) Verbose() bool {
+	return false
+}
+
 func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cmd, args := args[0], parseArgs(args[1:])
+
+	// create works on the source tree and needs no database
+	if cmd == "create" {
+		if len(args) != 1 {
+			log.Fatal("create: expected migration name")
+		}
+		if err := create(*dir, args[0]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	cfg := config.New()
+	if cfg.Storage != config.StorageDatabase {
+		log.Fatalf("migrations require %s storage", config.StorageDatabase)
+	}
+
+	src, err := db.NewMigrationSource(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer src.Close()
+
 	m := db.NewMigrator(cfg)
+	defer m.Close()
+	m.Log = logger{}
+
+	mg := &migrator{m: m, src: src}
+
+	switch cmd {
+	case "up":
+		err = mg.up(optionalN(args))
+	case "down":
+		err = mg.down(optionalN(args))
+	case "goto":
+		err = mg.gotoVersion(requiredN(args))
+	case "version":
+		err = mg.version()
+	case "force":
+		err = mg.force(requiredN(args))
+	case "status":
+		err = mg.status()
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
 
-	log.Println("migrating")
-	if err := m.Up(); err != nil {
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Println("no change")
+		return
+	}
+	var dErr migrate.ErrDirty
+	if errors.As(err, &dErr) {
+		log.Fatalf("%s, fix the failed migration and run force %d", err, dErr.Version)
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
+
+// parseArgs lets flags go anywhere after the command
+// and returns the positional arguments.
+func parseArgs(args []string) []string {
+	var positional []string
+	for {
+		flag.CommandLine.Parse(args)
+		args = flag.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional, args = append(positional, args[0]), args[1:]
+	}
+}
+
+func optionalN(args []string) int {
+	if len(args) == 0 {
+		return 0
+	}
+
+	return requiredN(args)
+}
+
+func requiredN(args []string) int {
+	if len(args) != 1 {
+		log.Fatal("expected a single numeric argument")
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		log.Fatalf("invalid number %q", args[0])
+	}
+
+	return n
+}
+
+type migrator struct {
+	m   *migrate.Migrate
+	src source.Driver
+}
+
+func (mg *migrator) current() (db.Version, bool, error) {
+	v, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return db.Version{}, false, nil
+	}
+	if err != nil {
+		return db.Version{}, false, err
+	}
+
+	return db.Version{Version: v, Applied: true}, dirty, nil
+}
+
+// plan prints the steps in dry-run mode and reports whether
+// the migrator should go on running them. Asking for n > 0 steps
+// fails up front when fewer are available.
+func (mg *migrator) plan(n int, f func(cur db.Version) ([]db.Step, error)) (bool, error) {
+	cur, _, err := mg.current()
+	if err != nil {
+		return false, err
+	}
+
+	steps, err := f(cur)
+	if err != nil {
+		return false, err
+	}
+	if len(steps) == 0 {
+		return false, migrate.ErrNoChange
+	}
+	if n > 0 && len(steps) < n {
+		return false, fmt.Errorf("only %d migrations available, %d requested", len(steps), n)
+	}
+	if !*dryRun {
+		return true, nil
+	}
+
+	log.Printf("dry run, %d pending migrations:", len(steps))
+	for _, v := range steps {
+		fmt.Println(v)
+	}
+
+	return false, nil
+}
+
+func (mg *migrator) up(n int) error {
+	if n < 0 {
+		return fmt.Errorf("up: expected positive number of migrations, got %d", n)
+	}
+
+	ok, err := mg.plan(n, func(cur db.Version) ([]db.Step, error) {
+		return db.PlanUp(mg.src, cur, n)
+	})
+	if !ok {
+		return err
+	}
+
+	if n == 0 {
+		return mg.m.Up()
+	}
+	return mg.m.Steps(n)
+}
+
+func (mg *migrator) down(n int) error {
+	if n < 0 {
+		return fmt.Errorf("down: expected positive number of migrations, got %d", n)
+	}
+	if n == 0 && !*downAll && !*dryRun {
+		return errors.New("down: rolling back all migrations requires -all")
+	}
+
+	ok, err := mg.plan(n, func(cur db.Version) ([]db.Step, error) {
+		return db.PlanDown(mg.src, cur, n)
+	})
+	if !ok {
+		return err
+	}
+
+	if n == 0 {
+		return mg.m.Down()
+	}
+	return mg.m.Steps(-n)
+}
+
+func (mg *migrator) gotoVersion(v int) error {
+	if v < 0 {
+		return fmt.Errorf("goto: invalid version %d", v)
+	}
+
+	ok, err := mg.plan(0, func(cur db.Version) ([]db.Step, error) {
+		return db.PlanGoto(mg.src, cur, uint(v))
+	})
+	if !ok {
+		return err
+	}
+
+	return mg.m.Migrate(uint(v))
+}
+
+// force sets the version without running migrations, in dry-run mode
+// it only prints the version it would replace.
+func (mg *migrator) force(v int) error {
+	if v < 0 {
+		return fmt.Errorf("force: invalid version %d", v)
+	}
+	if !*dryRun {
+		return mg.m.Force(v)
+	}
+
+	cur, dirty, err := mg.current()
+	if err != nil {
+		return err
+	}
+
+	from := "no version"
+	if cur.Applied {
+		from = strconv.FormatUint(uint64(cur.Version), 10)
+	}
+	if dirty {
+		from += " (dirty)"
+	}
+	log.Printf("dry run, would force version %d over %s", v, from)
+
+	return nil
+}
+
+func (mg *migrator) version() error {
+	cur, dirty, err := mg.current()
+	if err != nil {
+		return err
+	}
+
+	if !cur.Applied {
+		fmt.Println("no migrations applied")
+		return nil
+	}
+	if dirty {
+		fmt.Printf("%d (dirty)\n", cur.Version)
+		return nil
+	}
+	fmt.Println(cur.Version)
+
+	return nil
+}
+
+func (mg *migrator) status() error {
+	cur, dirty, err := mg.current()
+	if err != nil {
+		return err
+	}
+
+	versions, err := db.Versions(mg.src)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		r, identifier, err := mg.src.ReadUp(v)
+		if err != nil {
+			return err
+		}
+		r.Close()
+
+		state := "pending"
+		switch {
+		case cur.Applied && v == cur.Version && dirty:
+			state = "dirty"
+		case cur.Applied && v <= cur.Version:
+			state = "applied"
+		}
+		fmt.Printf("%-8s %06d %s\n", state, v, identifier)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/Coderovshik/film-library/internal/config"
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/seed"
+)
+
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func main() {
+	var files fileList
+	flag.Var(&files, "file", "fixture file (.json, .yaml or .yml), can be repeated")
+	films := flag.Int("films", 0, "number of synthetic films to generate")
+	actors := flag.Int("actors", 0, "number of synthetic actors to generate")
+	bindings := flag.Int("bindings", 0, "number of synthetic film-actor bindings to generate")
+	randSeed := flag.Int64("rand-seed", 1, "seed of the synthetic data generator")
+	flag.Parse()
+
+	cfg := config.New()
+	if cfg.Storage != config.StorageDatabase {
+		log.Fatalf("seeding requires %s storage", config.StorageDatabase)
+	}
+
+	fixture := &seed.Fixture{}
+	for _, v := range files {
+		f, err := seed.Load(v)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fixture.Merge(f)
+	}
+	fixture.Merge(seed.Generate(seed.GenerateOptions{
+		Films:    *films,
+		Actors:   *actors,
+		Bindings: *bindings,
+		Seed:     *randSeed,
+	}))
+
+	database, err := db.NewDatabase(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+	tx, err := database.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("seeding")
+	rep, err := seed.NewSeeder(tx, database.GetDialect()).Seed(ctx, fixture)
+	if err != nil {
+		tx.Rollback()
+		log.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("seeded %s", rep)
+}
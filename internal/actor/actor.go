@@ -4,22 +4,54 @@ import (
 	"context"
 	"net/http"
 	"time"
+
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/util"
 )
 
 type Actor struct {
-	ID       int32     `json:"id"`
-	Name     string    `json:"name"`
-	Sex      string    `json:"sex"`
-	Birthday time.Time `json:"birthday"`
-	Films    []string  `json:"films"`
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	Sex       string    `json:"sex"`
+	Birthday  time.Time `json:"birthday"`
+	Films     []string  `json:"films"`
+	Version   int32     `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	CreatedBy *int32    `json:"createdBy"`
+	UpdatedBy *int32    `json:"updatedBy"`
+	// DeletedAt is set for actors in the trash.
+	DeletedAt *time.Time `json:"deletedAt"`
+	DeletedBy *int32     `json:"deletedBy"`
+}
+
+// ActorUpdate lists the columns to change, nil fields are left as they are.
+// Version is the expected row version, zero skips the check.
+type ActorUpdate struct {
+	ID       int32
+	Version  int32
+	Name     *string
+	Sex      *string
+	Birthday *time.Time
 }
 
 type ActorRepository interface {
+	// Atomic runs fn on a repository and an audit recorder bound to a
+	// single transaction, committed if fn returns nil.
+	Atomic(ctx context.Context, fn func(ar ActorRepository, rec audit.Recorder) error) error
 	GetActor(ctx context.Context, id int32) (*Actor, error)
 	AddActor(ctx context.Context, a *Actor) (*Actor, error)
-	DeleteActor(ctx context.Context, id int32) error
-	UpdateActor(ctx context.Context, a *Actor) error
+	DeleteActor(ctx context.Context, id int32, version int32) error
+	UpdateActor(ctx context.Context, au *ActorUpdate) error
 	GetActors(ctx context.Context) ([]*Actor, error)
+	RestoreActor(ctx context.Context, id int32) error
+	GetDeletedActors(ctx context.Context) ([]*Actor, error)
+	// PurgeActors deletes the actors in the trash since before for good
+	// and returns their ids.
+	PurgeActors(ctx context.Context, before time.Time) ([]int32, error)
+	// ExportActors calls fn for every selected actor with the films ordered
+	// by film id, the actors are streamed without being loaded all at once.
+	ExportActors(ctx context.Context, q *ExportQuery, fn func(a *Actor, films []*FilmShort) error) error
 }
 
 type ActorService interface {
@@ -27,7 +59,9 @@ type ActorService interface {
 	AddActor(ctx context.Context, req *ActorInfo) (*ActorResponse, error)
 	GetActor(ctx context.Context, req *ActorIdRequest) (*ActorResponse, error)
 	UpdateActor(ctx context.Context, req *ActorIdInfoRequest) (*ActorResponse, error)
+	PatchActor(ctx context.Context, req *ActorPatchRequest) (*ActorResponse, error)
 	DeleteActor(ctx context.Context, req *ActorIdRequest) (*ActorResponse, error)
+	RestoreActor(ctx context.Context, req *ActorIdRequest) (*ActorResponse, error)
 }
 
 type ActorHandler interface {
@@ -35,26 +69,69 @@ type ActorHandler interface {
 	AddActor(w http.ResponseWriter, r *http.Request)
 	GetActor(w http.ResponseWriter, r *http.Request)
 	UpdateActor(w http.ResponseWriter, r *http.Request)
+	PatchActor(w http.ResponseWriter, r *http.Request)
 	DeleteActor(w http.ResponseWriter, r *http.Request)
+	RestoreActor(w http.ResponseWriter, r *http.Request)
+}
+
+// ExportQuery selects actors in id order starting after the cursor,
+// zero limit selects all of them.
+type ExportQuery struct {
+	After int32
+	Limit int
+}
+
+type FilmShort struct {
+	ID   int32
+	Name string
 }
 
 type ActorInfo struct {
-	Name     string `json:"name"`
-	Sex      string `json:"sex"`
-	Birthday string `json:"birthday"`
+	Name     string `json:"name" xml:"name" yaml:"name"`
+	Sex      string `json:"sex" xml:"sex" yaml:"sex"`
+	Birthday string `json:"birthday" xml:"birthday" yaml:"birthday"`
 }
 
 type ActorResponse struct {
-	ID    int       `json:"id"`
+	ID        int       `json:"id" xml:"id" yaml:"id"`
+	Info      ActorInfo `json:"info" xml:"info" yaml:"info"`
+	Films     []string  `json:"films,omitempty" xml:"films>film,omitempty" yaml:"films,omitempty"`
+	CreatedAt string    `json:"createdAt" xml:"createdAt" yaml:"createdAt"`
+	UpdatedAt string    `json:"updatedAt" xml:"updatedAt" yaml:"updatedAt"`
+	CreatedBy *int32    `json:"createdBy,omitempty" xml:"createdBy,omitempty" yaml:"createdBy,omitempty"`
+	UpdatedBy *int32    `json:"updatedBy,omitempty" xml:"updatedBy,omitempty" yaml:"updatedBy,omitempty"`
+	DeletedAt string    `json:"deletedAt,omitempty" xml:"deletedAt,omitempty" yaml:"deletedAt,omitempty"`
+	DeletedBy *int32    `json:"deletedBy,omitempty" xml:"deletedBy,omitempty" yaml:"deletedBy,omitempty"`
+	ETag      string    `json:"-" xml:"-" yaml:"-"`
+}
+
+// ActorState is the actor as recorded in the audit log.
+type ActorState struct {
 	Info  ActorInfo `json:"info"`
-	Films []string  `json:"films,omitempty"`
+	Films []string  `json:"films"`
 }
 
+// IfMatch holds the If-Match header, it is ignored by reads.
 type ActorIdRequest struct {
-	ID string
+	ID      string
+	IfMatch string
 }
 
 type ActorIdInfoRequest struct {
-	ID   string
-	Info ActorInfo
+	ID      string
+	IfMatch string
+	Info    ActorInfo
+}
+
+// ActorPatch is a JSON Merge Patch of ActorInfo, none of the fields can be null.
+type ActorPatch struct {
+	Name     util.PatchField[string] `json:"name"`
+	Sex      util.PatchField[string] `json:"sex"`
+	Birthday util.PatchField[string] `json:"birthday"`
+}
+
+type ActorPatchRequest struct {
+	ID      string
+	IfMatch string
+	Patch   ActorPatch
 }
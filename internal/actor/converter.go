@@ -6,33 +6,84 @@ import (
 	"github.com/Coderovshik/film-library/internal/util"
 )
 
-func ToQueryableObject(a *Actor) *util.QueryableObject {
+func ToQueryableObject(au *ActorUpdate) *util.QueryableObject {
 	qo := util.NewQueryableObject()
 
-	if len(a.Name) != 0 {
-		qo.Add("actor_name", a.Name)
+	if au.Name != nil {
+		qo.Add("actor_name", *au.Name)
 	}
 
-	if len(a.Sex) != 0 {
-		qo.Add("sex", a.Sex)
+	if au.Sex != nil {
+		qo.Add("sex", *au.Sex)
 	}
 
-	if !a.Birthday.IsZero() {
-		qo.Add("birthday", a.Birthday)
+	if au.Birthday != nil {
+		qo.Add("birthday", *au.Birthday)
 	}
 
 	return qo
 }
 
+// ToActorUpdate replaces every column of the actor.
+func ToActorUpdate(a *Actor) *ActorUpdate {
+	return &ActorUpdate{
+		ID:       a.ID,
+		Version:  a.Version,
+		Name:     &a.Name,
+		Sex:      &a.Sex,
+		Birthday: &a.Birthday,
+	}
+}
+
+// PatchToActorUpdate expects a validated patch.
+func PatchToActorUpdate(id, version int32, ap *ActorPatch) *ActorUpdate {
+	au := &ActorUpdate{
+		ID:      id,
+		Version: version,
+		Name:    ap.Name.Ptr(),
+		Sex:     ap.Sex.Ptr(),
+	}
+
+	if ap.Birthday.Set {
+		birthday, _ := time.Parse(time.DateOnly, ap.Birthday.Value)
+		au.Birthday = &birthday
+	}
+
+	return au
+}
+
 func ToActorResponse(a *Actor) *ActorResponse {
-	return &ActorResponse{
+	res := &ActorResponse{
 		ID: int(a.ID),
 		Info: ActorInfo{
 			Name:     a.Name,
 			Sex:      a.Sex,
 			Birthday: a.Birthday.Format(time.DateOnly),
 		},
-		Films: a.Films,
+		Films:     a.Films,
+		CreatedAt: a.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt: a.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		CreatedBy: a.CreatedBy,
+		UpdatedBy: a.UpdatedBy,
+		DeletedBy: a.DeletedBy,
+	}
+	if a.DeletedAt != nil {
+		res.DeletedAt = a.DeletedAt.UTC().Format(time.RFC3339Nano)
+	}
+	res.ETag = util.ETag(a.Version, res)
+
+	return res
+}
+
+func ToActorState(a *Actor) *ActorState {
+	res := ToActorResponse(a)
+	if res.Films == nil {
+		res.Films = []string{}
+	}
+
+	return &ActorState{
+		Info:  res.Info,
+		Films: res.Films,
 	}
 }
 
@@ -1,6 +1,7 @@
 package actor
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -13,18 +14,31 @@ func TestToQueryableObject(t *testing.T) {
 		Birthday: bd,
 	}
 
-	qo := ToQueryableObject(a)
+	qo := ToQueryableObject(ToActorUpdate(a))
 	args, values := qo.Args(1), qo.Values()
 	if len(values) != 3 || args != "actor_name = $1, sex = $2, birthday = $3" {
 		t.Fatalf("Expected %d values with args '%s', got %d values with args '%s'",
 			3, "actor_name = $1, sex = $2, birthday = $3", len(values), args)
 	}
 
-	a = &Actor{}
-	qo = ToQueryableObject(a)
+	qo = ToQueryableObject(&ActorUpdate{ID: 1})
 	args, values = qo.Args(1), qo.Values()
 	if !qo.IsEmpty() {
 		t.Fatalf("Expected empty object, got %d values with args '%s'",
 			len(values), args)
 	}
 }
+
+func TestPatchToActorUpdate(t *testing.T) {
+	var ap ActorPatch
+	if err := json.Unmarshal([]byte(`{"sex": "female", "birthday": "1995-05-03"}`), &ap); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	qo := ToQueryableObject(PatchToActorUpdate(1, 1, &ap))
+	args, values := qo.Args(1), qo.Values()
+	if len(values) != 2 || args != "sex = $1, birthday = $2" {
+		t.Fatalf("Expected %d values with args '%s', got %d values with args '%s'",
+			2, "sex = $1, birthday = $2", len(values), args)
+	}
+}
@@ -26,7 +26,7 @@ func (h *Handler) GetActors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.JSON(w, r, http.StatusOK, res)
+	util.Render(w, r, http.StatusOK, ActorList(res))
 }
 
 func (h *Handler) AddActor(w http.ResponseWriter, r *http.Request) {
@@ -71,6 +71,9 @@ func (h *Handler) GetActor(w http.ResponseWriter, r *http.Request) {
 		util.InternalServerError(w, r)
 		return
 	}
+	if util.CheckNotModified(w, r, res.ETag) {
+		return
+	}
 
 	util.JSON(w, r, http.StatusOK, res)
 }
@@ -79,6 +82,10 @@ func (h *Handler) UpdateActor(w http.ResponseWriter, r *http.Request) {
 	req := ActorIdInfoRequest{
 		ID: r.PathValue("id"),
 	}
+	var ok bool
+	if req.IfMatch, ok = util.RequireIfMatch(w, r); !ok {
+		return
+	}
 	if ok := util.BindJSON(w, r, &req.Info); !ok {
 		return
 	}
@@ -86,26 +93,87 @@ func (h *Handler) UpdateActor(w http.ResponseWriter, r *http.Request) {
 	res, err := h.service.UpdateActor(r.Context(), &req)
 	if err != nil {
 		log.Printf("ERROR: failed to update actor err=%s\n", err.Error())
+		updateError(w, r, err)
+		return
+	}
+
+	w.Header().Set("etag", res.ETag)
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) PatchActor(w http.ResponseWriter, r *http.Request) {
+	req := ActorPatchRequest{
+		ID: r.PathValue("id"),
+	}
+	var ok bool
+	if req.IfMatch, ok = util.RequireIfMatch(w, r); !ok {
+		return
+	}
+	if ok := util.BindMergePatch(w, r, &req.Patch); !ok {
+		return
+	}
+
+	res, err := h.service.PatchActor(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to patch actor err=%s\n", err.Error())
+		updateError(w, r, err)
+		return
+	}
+
+	w.Header().Set("etag", res.ETag)
+	util.JSON(w, r, http.StatusOK, res)
+}
 
+func updateError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrActorNotExist) {
+		util.NotFound(w, r)
+		return
+	}
+
+	var ve *util.ValidationError
+	if errors.As(err, &ve) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, ErrEmptyUpdate) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      "empty update",
+		})
+		return
+	}
+
+	if errors.Is(err, ErrVersionMismatch) {
+		util.PreconditionFailed(w, r)
+		return
+	}
+
+	util.InternalServerError(w, r)
+}
+
+func (h *Handler) DeleteActor(w http.ResponseWriter, r *http.Request) {
+	req := ActorIdRequest{
+		ID: r.PathValue("id"),
+	}
+	var ok bool
+	if req.IfMatch, ok = util.RequireIfMatch(w, r); !ok {
+		return
+	}
+
+	res, err := h.service.DeleteActor(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to get actor err=%s\n", err.Error())
 		if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrActorNotExist) {
 			util.NotFound(w, r)
 			return
 		}
 
-		var ve *util.ValidationError
-		if errors.As(err, &ve) {
-			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
-				ErrorType: util.ErrorTypeValidation,
-				Body:      ve.Error(),
-			})
-			return
-		}
-
-		if errors.Is(err, ErrEmptyUpdate) {
-			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
-				ErrorType: util.ErrorTypeValidation,
-				Body:      "empty update",
-			})
+		if errors.Is(err, ErrVersionMismatch) {
+			util.PreconditionFailed(w, r)
 			return
 		}
 
@@ -116,14 +184,14 @@ func (h *Handler) UpdateActor(w http.ResponseWriter, r *http.Request) {
 	util.JSON(w, r, http.StatusOK, res)
 }
 
-func (h *Handler) DeleteActor(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) RestoreActor(w http.ResponseWriter, r *http.Request) {
 	req := ActorIdRequest{
 		ID: r.PathValue("id"),
 	}
 
-	res, err := h.service.DeleteActor(r.Context(), &req)
+	res, err := h.service.RestoreActor(r.Context(), &req)
 	if err != nil {
-		log.Printf("ERROR: failed to get actor err=%s\n", err.Error())
+		log.Printf("ERROR: failed to restore actor err=%s\n", err.Error())
 		if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrActorNotExist) {
 			util.NotFound(w, r)
 			return
@@ -133,5 +201,6 @@ func (h *Handler) DeleteActor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("etag", res.ETag)
 	util.JSON(w, r, http.StatusOK, res)
 }
@@ -0,0 +1,44 @@
+package actor
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ util.Table = ActorList(nil)
+
+// ActorList is the actor list response, an array in JSON and YAML and
+// an actors element in XML.
+type ActorList []*ActorResponse
+
+func (l ActorList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Actors []*ActorResponse `xml:"actor"`
+	}{l}, xml.StartElement{Name: xml.Name{Local: "actors"}})
+}
+
+func (l ActorList) CSVHeader() []string {
+	return []string{"id", "name", "sex", "birthday", "films", "createdAt", "updatedAt", "createdBy", "updatedBy"}
+}
+
+func (l ActorList) CSVRecords() [][]string {
+	records := make([][]string, 0, len(l))
+	for _, v := range l {
+		records = append(records, []string{
+			strconv.Itoa(v.ID),
+			v.Info.Name,
+			v.Info.Sex,
+			v.Info.Birthday,
+			strings.Join(v.Films, ";"),
+			v.CreatedAt,
+			v.UpdatedAt,
+			util.FormatID(v.CreatedBy),
+			util.FormatID(v.UpdatedBy),
+		})
+	}
+
+	return records
+}
@@ -13,7 +13,9 @@ import (
 	context "context"
 	http "net/http"
 	reflect "reflect"
+	time "time"
 
+	audit "github.com/Coderovshik/film-library/internal/audit"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -55,18 +57,46 @@ func (mr *MockActorRepositoryMockRecorder) AddActor(ctx, a any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActor", reflect.TypeOf((*MockActorRepository)(nil).AddActor), ctx, a)
 }
 
+// Atomic mocks base method.
+func (m *MockActorRepository) Atomic(ctx context.Context, fn func(ActorRepository, audit.Recorder) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Atomic", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Atomic indicates an expected call of Atomic.
+func (mr *MockActorRepositoryMockRecorder) Atomic(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Atomic", reflect.TypeOf((*MockActorRepository)(nil).Atomic), ctx, fn)
+}
+
 // DeleteActor mocks base method.
-func (m *MockActorRepository) DeleteActor(ctx context.Context, id int32) error {
+func (m *MockActorRepository) DeleteActor(ctx context.Context, id, version int32) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteActor", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteActor", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteActor indicates an expected call of DeleteActor.
-func (mr *MockActorRepositoryMockRecorder) DeleteActor(ctx, id any) *gomock.Call {
+func (mr *MockActorRepositoryMockRecorder) DeleteActor(ctx, id, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockActorRepository)(nil).DeleteActor), ctx, id, version)
+}
+
+// ExportActors mocks base method.
+func (m *MockActorRepository) ExportActors(ctx context.Context, q *ExportQuery, fn func(*Actor, []*FilmShort) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportActors", ctx, q, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportActors indicates an expected call of ExportActors.
+func (mr *MockActorRepositoryMockRecorder) ExportActors(ctx, q, fn any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockActorRepository)(nil).DeleteActor), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportActors", reflect.TypeOf((*MockActorRepository)(nil).ExportActors), ctx, q, fn)
 }
 
 // GetActor mocks base method.
@@ -99,18 +129,62 @@ func (mr *MockActorRepositoryMockRecorder) GetActors(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockActorRepository)(nil).GetActors), ctx)
 }
 
+// GetDeletedActors mocks base method.
+func (m *MockActorRepository) GetDeletedActors(ctx context.Context) ([]*Actor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedActors", ctx)
+	ret0, _ := ret[0].([]*Actor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedActors indicates an expected call of GetDeletedActors.
+func (mr *MockActorRepositoryMockRecorder) GetDeletedActors(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedActors", reflect.TypeOf((*MockActorRepository)(nil).GetDeletedActors), ctx)
+}
+
+// PurgeActors mocks base method.
+func (m *MockActorRepository) PurgeActors(ctx context.Context, before time.Time) ([]int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeActors", ctx, before)
+	ret0, _ := ret[0].([]int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeActors indicates an expected call of PurgeActors.
+func (mr *MockActorRepositoryMockRecorder) PurgeActors(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeActors", reflect.TypeOf((*MockActorRepository)(nil).PurgeActors), ctx, before)
+}
+
+// RestoreActor mocks base method.
+func (m *MockActorRepository) RestoreActor(ctx context.Context, id int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreActor", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreActor indicates an expected call of RestoreActor.
+func (mr *MockActorRepositoryMockRecorder) RestoreActor(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreActor", reflect.TypeOf((*MockActorRepository)(nil).RestoreActor), ctx, id)
+}
+
 // UpdateActor mocks base method.
-func (m *MockActorRepository) UpdateActor(ctx context.Context, a *Actor) error {
+func (m *MockActorRepository) UpdateActor(ctx context.Context, au *ActorUpdate) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateActor", ctx, a)
+	ret := m.ctrl.Call(m, "UpdateActor", ctx, au)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateActor indicates an expected call of UpdateActor.
-func (mr *MockActorRepositoryMockRecorder) UpdateActor(ctx, a any) *gomock.Call {
+func (mr *MockActorRepositoryMockRecorder) UpdateActor(ctx, au any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockActorRepository)(nil).UpdateActor), ctx, a)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockActorRepository)(nil).UpdateActor), ctx, au)
 }
 
 // MockActorService is a mock of ActorService interface.
@@ -196,6 +270,36 @@ func (mr *MockActorServiceMockRecorder) GetActors(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockActorService)(nil).GetActors), ctx)
 }
 
+// PatchActor mocks base method.
+func (m *MockActorService) PatchActor(ctx context.Context, req *ActorPatchRequest) (*ActorResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchActor", ctx, req)
+	ret0, _ := ret[0].(*ActorResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchActor indicates an expected call of PatchActor.
+func (mr *MockActorServiceMockRecorder) PatchActor(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchActor", reflect.TypeOf((*MockActorService)(nil).PatchActor), ctx, req)
+}
+
+// RestoreActor mocks base method.
+func (m *MockActorService) RestoreActor(ctx context.Context, req *ActorIdRequest) (*ActorResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreActor", ctx, req)
+	ret0, _ := ret[0].(*ActorResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreActor indicates an expected call of RestoreActor.
+func (mr *MockActorServiceMockRecorder) RestoreActor(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreActor", reflect.TypeOf((*MockActorService)(nil).RestoreActor), ctx, req)
+}
+
 // UpdateActor mocks base method.
 func (m *MockActorService) UpdateActor(ctx context.Context, req *ActorIdInfoRequest) (*ActorResponse, error) {
 	m.ctrl.T.Helper()
@@ -282,6 +386,30 @@ func (mr *MockActorHandlerMockRecorder) GetActors(w, r any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockActorHandler)(nil).GetActors), w, r)
 }
 
+// PatchActor mocks base method.
+func (m *MockActorHandler) PatchActor(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PatchActor", w, r)
+}
+
+// PatchActor indicates an expected call of PatchActor.
+func (mr *MockActorHandlerMockRecorder) PatchActor(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchActor", reflect.TypeOf((*MockActorHandler)(nil).PatchActor), w, r)
+}
+
+// RestoreActor mocks base method.
+func (m *MockActorHandler) RestoreActor(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RestoreActor", w, r)
+}
+
+// RestoreActor indicates an expected call of RestoreActor.
+func (mr *MockActorHandlerMockRecorder) RestoreActor(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreActor", reflect.TypeOf((*MockActorHandler)(nil).RestoreActor), w, r)
+}
+
 // UpdateActor mocks base method.
 func (m *MockActorHandler) UpdateActor(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
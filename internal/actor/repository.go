@@ -7,37 +7,52 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	"github.com/Coderovshik/film-library/internal/audit"
 	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/util"
 )
 
 var (
-	ErrActorNotExist = errors.New("actor does not exist")
-	ErrEmptyUpdate   = errors.New("no updates to apply")
+	ErrActorNotExist   = errors.New("actor does not exist")
+	ErrEmptyUpdate     = errors.New("no updates to apply")
+	ErrVersionMismatch = errors.New("actor was modified since it was read")
 )
 
 var _ ActorRepository = (*Repository)(nil)
 
 type Repository struct {
-	db db.DBTX
+	db      db.DBTX
+	dialect db.Dialect
 }
 
-func NewRepository(db db.DBTX) *Repository {
+func NewRepository(db db.DBTX, d db.Dialect) *Repository {
 	return &Repository{
-		db: db,
+		db:      db,
+		dialect: d,
 	}
 }
 
+// Atomic runs fn in a transaction of its own, or in the one the
+// repository is already bound to.
+func (r *Repository) Atomic(ctx context.Context, fn func(ar ActorRepository, rec audit.Recorder) error) error {
+	return db.InTx(ctx, r.db, func(tx db.DBTX) error {
+		return fn(NewRepository(tx, r.dialect), audit.NewService(audit.NewRepository(tx, r.dialect)))
+	})
+}
+
 func (r *Repository) GetActor(ctx context.Context, id int32) (*Actor, error) {
 	const op = "actor.Repository.GetActor"
 
-	const query = `
-		SELECT a.actor_id, a.actor_name, a.sex, a.birthday,
-			STRING_AGG (m.movie_name, ';') movie_list
-		FROM actor a 
+	query := `
+		SELECT a.actor_id, a.actor_name, a.sex, a.birthday, a.version,
+			a.created_at, a.updated_at, a.created_by, a.updated_by,
+			` + r.dialect.StringAgg("m.movie_name", ";") + ` movie_list
+		FROM actor a
 		LEFT JOIN actor_in_movie am USING (actor_id)
-		LEFT JOIN movie m USING (movie_id)
-		WHERE a.actor_id = $1
+		LEFT JOIN movie m ON m.movie_id = am.movie_id AND m.deleted_at IS NULL
+		WHERE a.actor_id = $1 AND a.deleted_at IS NULL
 		GROUP BY a.actor_id`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
@@ -48,7 +63,8 @@ func (r *Repository) GetActor(ctx context.Context, id int32) (*Actor, error) {
 
 	var a Actor
 	var filmString sql.NullString
-	err = stmt.QueryRowContext(ctx, id).Scan(&a.ID, &a.Name, &a.Sex, &a.Birthday, &filmString)
+	err = stmt.QueryRowContext(ctx, id).Scan(&a.ID, &a.Name, &a.Sex, &a.Birthday, &a.Version,
+		&a.CreatedAt, &a.UpdatedAt, &a.CreatedBy, &a.UpdatedBy, &filmString)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("ERROR: actor with id=%d does not exist\n", id)
@@ -68,29 +84,32 @@ func (r *Repository) GetActor(ctx context.Context, id int32) (*Actor, error) {
 func (r *Repository) AddActor(ctx context.Context, a *Actor) (*Actor, error) {
 	const op = "actor.Repository.AddActor"
 
-	const query = `
-		INSERT INTO actor(actor_name, sex, birthday)
-		VALUES ($1, $2, $3) RETURNING actor_id`
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		log.Printf("ERROR: failed to prepare query\n")
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-	defer stmt.Close()
+	a.CreatedAt, a.CreatedBy = db.Now(), util.AuthorFromContext(ctx)
+	a.UpdatedAt, a.UpdatedBy = a.CreatedAt, a.CreatedBy
 
-	err = stmt.QueryRowContext(ctx, a.Name, a.Sex, a.Birthday).Scan(&a.ID)
+	const query = `
+		INSERT INTO actor(actor_name, sex, birthday,
+			created_at, updated_at, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, "actor_id", a.Name, a.Sex, a.Birthday,
+		a.CreatedAt, a.UpdatedAt, a.CreatedBy, a.UpdatedBy)
 	if err != nil {
 		log.Printf("ERROR: failed to execute query\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	a.ID = int32(id)
+	a.Version = 1
 
 	return a, nil
 }
 
-func (r *Repository) DeleteActor(ctx context.Context, id int32) error {
+func (r *Repository) DeleteActor(ctx context.Context, id int32, version int32) error {
 	const op = "actor.Repository.DeleteActor"
 
-	const query = `DELETE FROM actor WHERE actor_id = $1`
+	// the actor goes to the trash, its bindings stay for a restore
+	const query = `
+		UPDATE actor SET deleted_at = $3, deleted_by = $4
+		WHERE actor_id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		log.Printf("ERROR: failed to prepare query\n")
@@ -98,7 +117,7 @@ func (r *Repository) DeleteActor(ctx context.Context, id int32) error {
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, id)
+	res, err := stmt.ExecContext(ctx, id, version, db.Now(), util.AuthorFromContext(ctx))
 	if err != nil {
 		log.Printf("ERROR: failed to execute query\n")
 		return fmt.Errorf("%s: %w", op, err)
@@ -111,23 +130,27 @@ func (r *Repository) DeleteActor(ctx context.Context, id int32) error {
 	}
 	if count == 0 {
 		log.Printf("ERROR: zero rows addected by deletion\n")
-		return fmt.Errorf("%s: %w", op, ErrActorNotExist)
+		return fmt.Errorf("%s: %w", op, r.missError(ctx, id, version))
 	}
 
 	return nil
 }
 
-func (r *Repository) UpdateActor(ctx context.Context, a *Actor) error {
+func (r *Repository) UpdateActor(ctx context.Context, au *ActorUpdate) error {
 	const op = "actor.Repository.UpdateActor"
 
-	qo := ToQueryableObject(a)
+	qo := ToQueryableObject(au)
 	if qo.IsEmpty() {
 		log.Print("ERROR: no updates to apply\n")
 		return fmt.Errorf("%s: %w", op, ErrEmptyUpdate)
 	}
 
-	query := `UPDATE actor SET ` + qo.Args(1) +
-		` WHERE actor_id = ` + fmt.Sprintf("$%d", qo.Len()+1)
+	qo.Add("updated_at", db.Now())
+	qo.Add("updated_by", util.AuthorFromContext(ctx))
+
+	n := qo.Len()
+	query := `UPDATE actor SET ` + qo.Args(1) + `, version = version + 1` +
+		fmt.Sprintf(` WHERE actor_id = $%d AND deleted_at IS NULL AND ($%d = 0 OR version = $%d)`, n+1, n+2, n+2)
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		log.Printf("ERROR: failed to prepare query\n")
@@ -136,7 +159,7 @@ func (r *Repository) UpdateActor(ctx context.Context, a *Actor) error {
 	defer stmt.Close()
 
 	values := qo.Values()
-	values = append(values, a.ID)
+	values = append(values, au.ID, au.Version)
 	res, err := stmt.ExecContext(ctx, values...)
 	if err != nil {
 		log.Printf("ERROR: failed to execute query\n")
@@ -150,21 +173,44 @@ func (r *Repository) UpdateActor(ctx context.Context, a *Actor) error {
 	}
 	if count == 0 {
 		log.Printf("ERROR: zero rows affected by update\n")
-		return fmt.Errorf("%s: %w", op, ErrActorNotExist)
+		return fmt.Errorf("%s: %w", op, r.missError(ctx, au.ID, au.Version))
 	}
 
 	return nil
 }
 
+// missError tells why a conditional statement affected no rows.
+func (r *Repository) missError(ctx context.Context, id int32, version int32) error {
+	if version == 0 {
+		return ErrActorNotExist
+	}
+
+	const query = `SELECT EXISTS (SELECT 1 FROM actor WHERE actor_id = $1 AND deleted_at IS NULL)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+	if !exists {
+		return ErrActorNotExist
+	}
+
+	log.Printf("ERROR: actor with id=%d is not of version %d\n", id, version)
+	return ErrVersionMismatch
+}
+
 func (r *Repository) GetActors(ctx context.Context) ([]*Actor, error) {
 	const op = "actor.Repository.GetActors"
 
-	const query = `
-		SELECT a.actor_id, a.actor_name, a.sex, a.birthday,
-    		STRING_AGG (m.movie_name, ';') movie_list
+	query := `
+		SELECT a.actor_id, a.actor_name, a.sex, a.birthday, a.version,
+			a.created_at, a.updated_at, a.created_by, a.updated_by,
+    		` + r.dialect.StringAgg("m.movie_name", ";") + ` movie_list
 		FROM actor a
 		LEFT JOIN actor_in_movie am USING (actor_id)
-		LEFT JOIN movie m USING (movie_id)
+		LEFT JOIN movie m ON m.movie_id = am.movie_id AND m.deleted_at IS NULL
+		WHERE a.deleted_at IS NULL
 		GROUP BY a.actor_id`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
@@ -184,7 +230,8 @@ func (r *Repository) GetActors(ctx context.Context) ([]*Actor, error) {
 	for rows.Next() {
 		var a Actor
 		var filmString sql.NullString
-		err := rows.Scan(&a.ID, &a.Name, &a.Sex, &a.Birthday, &filmString)
+		err := rows.Scan(&a.ID, &a.Name, &a.Sex, &a.Birthday, &a.Version,
+			&a.CreatedAt, &a.UpdatedAt, &a.CreatedBy, &a.UpdatedBy, &filmString)
 		if err != nil {
 			log.Printf("ERROR: failed to execute query\n")
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -202,3 +249,192 @@ func (r *Repository) GetActors(ctx context.Context) ([]*Actor, error) {
 
 	return actors, nil
 }
+
+func (r *Repository) RestoreActor(ctx context.Context, id int32) error {
+	const op = "actor.Repository.RestoreActor"
+
+	const query = `
+		UPDATE actor SET deleted_at = NULL, deleted_by = NULL, updated_at = $2, updated_by = $3
+		WHERE actor_id = $1 AND deleted_at IS NOT NULL`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to prepare query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, db.Now(), util.AuthorFromContext(ctx))
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: actor with id=%d is not in the trash\n", id)
+		return fmt.Errorf("%s: %w", op, ErrActorNotExist)
+	}
+
+	return nil
+}
+
+// GetDeletedActors lists the trash, most recently deleted first.
+func (r *Repository) GetDeletedActors(ctx context.Context) ([]*Actor, error) {
+	const op = "actor.Repository.GetDeletedActors"
+
+	query := `
+		SELECT a.actor_id, a.actor_name, a.sex, a.birthday, a.version,
+			a.created_at, a.updated_at, a.created_by, a.updated_by,
+			a.deleted_at, a.deleted_by,
+			` + r.dialect.StringAgg("m.movie_name", ";") + ` movie_list
+		FROM actor a
+		LEFT JOIN actor_in_movie am USING (actor_id)
+		LEFT JOIN movie m ON m.movie_id = am.movie_id AND m.deleted_at IS NULL
+		WHERE a.deleted_at IS NOT NULL
+		GROUP BY a.actor_id
+		ORDER BY a.deleted_at DESC, a.actor_id`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to prepare query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	actors := make([]*Actor, 0)
+	for rows.Next() {
+		var a Actor
+		var filmString sql.NullString
+		err := rows.Scan(&a.ID, &a.Name, &a.Sex, &a.Birthday, &a.Version,
+			&a.CreatedAt, &a.UpdatedAt, &a.CreatedBy, &a.UpdatedBy, &a.DeletedAt, &a.DeletedBy, &filmString)
+		if err != nil {
+			log.Printf("ERROR: failed to execute query\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if len(filmString.String) != 0 {
+			a.Films = strings.Split(filmString.String, ";")
+		}
+
+		actors = append(actors, &a)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return actors, nil
+}
+
+// PurgeActors removes actors deleted before the given time for good,
+// their bindings go with them.
+func (r *Repository) PurgeActors(ctx context.Context, before time.Time) ([]int32, error) {
+	const op = "actor.Repository.PurgeActors"
+
+	const query = `DELETE FROM actor WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING actor_id`
+	rows, err := r.db.QueryContext(ctx, query, before.UTC())
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int32, 0)
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+// ExportActors joins the actors with their films, so that each actor is
+// streamed as soon as the rows of its films are read.
+func (r *Repository) ExportActors(ctx context.Context, q *ExportQuery, fn func(a *Actor, films []*FilmShort) error) error {
+	const op = "actor.Repository.ExportActors"
+
+	values := []any{q.After}
+	limit := ""
+	if q.Limit != 0 {
+		values = append(values, q.Limit)
+		limit = "LIMIT $2"
+	}
+	query := `
+		SELECT a.actor_id, a.actor_name, a.sex, a.birthday, a.version,
+			a.created_at, a.updated_at, a.created_by, a.updated_by,
+			m.movie_id, m.movie_name
+		FROM (SELECT * FROM actor WHERE deleted_at IS NULL AND actor_id > $1
+			ORDER BY actor_id ` + limit + `) a
+		LEFT JOIN actor_in_movie am USING (actor_id)
+		LEFT JOIN movie m ON m.movie_id = am.movie_id AND m.deleted_at IS NULL
+		ORDER BY a.actor_id, m.movie_id`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to prepare query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, values...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var cur *Actor
+	var films []*FilmShort
+	for rows.Next() {
+		var a Actor
+		var filmID sql.NullInt32
+		var filmName sql.NullString
+		err := rows.Scan(&a.ID, &a.Name, &a.Sex, &a.Birthday, &a.Version,
+			&a.CreatedAt, &a.UpdatedAt, &a.CreatedBy, &a.UpdatedBy, &filmID, &filmName)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if cur == nil || cur.ID != a.ID {
+			if cur != nil {
+				if err := fn(cur, films); err != nil {
+					return fmt.Errorf("%s: %w", op, err)
+				}
+			}
+			cur, films = &a, nil
+		}
+		if filmID.Valid {
+			cur.Films = append(cur.Films, filmName.String)
+			films = append(films, &FilmShort{ID: filmID.Int32, Name: filmName.String})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if cur != nil {
+		if err := fn(cur, films); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/util"
 )
 
 var (
@@ -15,7 +18,8 @@ var (
 var _ ActorService = (*Service)(nil)
 
 type Service struct {
-	repo ActorRepository
+	repo  ActorRepository
+	audit audit.Recorder
 }
 
 func NewService(ar ActorRepository) *Service {
@@ -24,6 +28,33 @@ func NewService(ar ActorRepository) *Service {
 	}
 }
 
+// atomic runs fn on a service bound to a single transaction, so that a
+// change and its audit entry are made together or not at all.
+func (s *Service) atomic(ctx context.Context, fn func(tx *Service) error) error {
+	return s.repo.Atomic(ctx, func(ar ActorRepository, rec audit.Recorder) error {
+		return fn(&Service{repo: ar, audit: rec})
+	})
+}
+
+// record appends the change to the audit log, it is called on a service
+// bound to the transaction of the change.
+func (s *Service) record(ctx context.Context, id int32, action string, before, after *Actor) error {
+	var from, to any
+	if before != nil {
+		from = ToActorState(before)
+	}
+	if after != nil {
+		to = ToActorState(after)
+	}
+
+	if err := s.audit.Record(ctx, audit.EntityActor, id, action, from, to); err != nil {
+		log.Printf("ERROR: failed to record actor %s\n", action)
+		return err
+	}
+
+	return nil
+}
+
 func (s *Service) GetActors(ctx context.Context) ([]*ActorResponse, error) {
 	const op = "actor.Service.GetActors"
 
@@ -56,9 +87,17 @@ func (s *Service) AddActor(ctx context.Context, req *ActorInfo) (*ActorResponse,
 	}
 	actor := ToActor(req)
 
-	actor, err := s.repo.AddActor(ctx, actor)
+	err := s.atomic(ctx, func(tx *Service) error {
+		var err error
+		actor, err = tx.repo.AddActor(ctx, actor)
+		if err != nil {
+			log.Printf("ERROR: failed to create actor record in repository")
+			return err
+		}
+
+		return tx.record(ctx, actor.ID, audit.ActionCreate, nil, actor)
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to create actor record in repository")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -87,8 +126,9 @@ func (s *Service) GetActor(ctx context.Context, req *ActorIdRequest) (*ActorResp
 	return res, nil
 }
 
+// UpdateActor replaces all the actor fields.
 func (s *Service) UpdateActor(ctx context.Context, req *ActorIdInfoRequest) (*ActorResponse, error) {
-	const op = "actor.Service.GetActors"
+	const op = "actor.Service.UpdateActor"
 
 	id, err := strconv.ParseUint(req.ID, 10, 32)
 	if err != nil {
@@ -96,24 +136,66 @@ func (s *Service) UpdateActor(ctx context.Context, req *ActorIdInfoRequest) (*Ac
 		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
 	}
 
-	vErr := ValidateFormatActorInfo(&req.Info)
+	vErr := ValidateEmptyActorInfo(&req.Info)
+	if vErr != nil {
+		log.Printf("ERROR: failed request empty validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+	vErr = ValidateFormatActorInfo(&req.Info)
 	if vErr != nil {
 		log.Printf("ERROR: failed request format validation\n")
 		return nil, fmt.Errorf("%s: %w", op, vErr)
 	}
 
-	actor := ToActor(&req.Info)
-	actor.ID = int32(id)
+	var actor *Actor
+	err = s.atomic(ctx, func(tx *Service) error {
+		cur, err := tx.current(ctx, int32(id), req.IfMatch)
+		if err != nil {
+			return err
+		}
 
-	err = s.repo.UpdateActor(ctx, actor)
+		au := ToActor(&req.Info)
+		au.ID = cur.ID
+		au.Version = cur.Version
+
+		actor, err = tx.updateActor(ctx, cur, ToActorUpdate(au))
+		return err
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to update actor record in repository")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	actor, err = s.repo.GetActor(ctx, int32(id))
+	res := ToActorResponse(actor)
+
+	return res, nil
+}
+
+func (s *Service) PatchActor(ctx context.Context, req *ActorPatchRequest) (*ActorResponse, error) {
+	const op = "actor.Service.PatchActor"
+
+	id, err := strconv.ParseUint(req.ID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+
+	vErr := ValidateActorPatch(&req.Patch)
+	if vErr != nil {
+		log.Printf("ERROR: failed patch validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	var actor *Actor
+	err = s.atomic(ctx, func(tx *Service) error {
+		cur, err := tx.current(ctx, int32(id), req.IfMatch)
+		if err != nil {
+			return err
+		}
+
+		actor, err = tx.updateActor(ctx, cur, PatchToActorUpdate(cur.ID, cur.Version, &req.Patch))
+		return err
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to get actor record from repository\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -122,6 +204,44 @@ func (s *Service) UpdateActor(ctx context.Context, req *ActorIdInfoRequest) (*Ac
 	return res, nil
 }
 
+// current returns the actor checked against the If-Match header,
+// an empty header matches any version.
+func (s *Service) current(ctx context.Context, id int32, ifMatch string) (*Actor, error) {
+	actor, err := s.repo.GetActor(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to get actor record from repository\n")
+		return nil, err
+	}
+
+	if len(ifMatch) == 0 {
+		actor.Version = 0
+		return actor, nil
+	}
+
+	if !util.MatchETag(ifMatch, ToActorResponse(actor).ETag, false) {
+		log.Printf("ERROR: actor with id=%d does not match %s\n", id, ifMatch)
+		return nil, ErrVersionMismatch
+	}
+
+	return actor, nil
+}
+
+func (s *Service) updateActor(ctx context.Context, cur *Actor, au *ActorUpdate) (*Actor, error) {
+	err := s.repo.UpdateActor(ctx, au)
+	if err != nil {
+		log.Printf("ERROR: failed to update actor record in repository")
+		return nil, err
+	}
+
+	actor, err := s.repo.GetActor(ctx, au.ID)
+	if err != nil {
+		log.Printf("ERROR: failed to get actor record from repository\n")
+		return nil, err
+	}
+
+	return actor, s.record(ctx, actor.ID, audit.ActionUpdate, cur, actor)
+}
+
 func (s *Service) DeleteActor(ctx context.Context, req *ActorIdRequest) (*ActorResponse, error) {
 	const op = "actor.Service.GetActors"
 
@@ -131,15 +251,57 @@ func (s *Service) DeleteActor(ctx context.Context, req *ActorIdRequest) (*ActorR
 		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
 	}
 
-	actor, err := s.repo.GetActor(ctx, int32(id))
+	var actor *Actor
+	err = s.atomic(ctx, func(tx *Service) error {
+		actor, err = tx.current(ctx, int32(id), req.IfMatch)
+		if err != nil {
+			return err
+		}
+
+		err = tx.repo.DeleteActor(ctx, actor.ID, actor.Version)
+		if err != nil {
+			log.Printf("ERROR: failed to delete actor record in repository")
+			return err
+		}
+
+		return tx.record(ctx, actor.ID, audit.ActionDelete, actor, nil)
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to get actor record from repository\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	err = s.repo.DeleteActor(ctx, int32(id))
+	res := ToActorResponse(actor)
+
+	return res, nil
+}
+
+// RestoreActor takes the actor out of the trash together with its bindings.
+func (s *Service) RestoreActor(ctx context.Context, req *ActorIdRequest) (*ActorResponse, error) {
+	const op = "actor.Service.RestoreActor"
+
+	id, err := strconv.ParseUint(req.ID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+
+	var actor *Actor
+	err = s.atomic(ctx, func(tx *Service) error {
+		err := tx.repo.RestoreActor(ctx, int32(id))
+		if err != nil {
+			log.Printf("ERROR: failed to restore actor record in repository\n")
+			return err
+		}
+
+		actor, err = tx.repo.GetActor(ctx, int32(id))
+		if err != nil {
+			log.Printf("ERROR: failed to get actor record from repository\n")
+			return err
+		}
+
+		return tx.record(ctx, actor.ID, audit.ActionRestore, nil, actor)
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to delete actor record in repository")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
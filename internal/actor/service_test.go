@@ -2,20 +2,34 @@ package actor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/Coderovshik/film-library/internal/audit"
 	"github.com/Coderovshik/film-library/internal/util"
 	gomock "go.uber.org/mock/gomock"
 )
 
+// newService returns a service whose transactions run on m and rec.
+func newService(m *MockActorRepository, rec audit.Recorder) *Service {
+	m.EXPECT().
+		Atomic(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ar ActorRepository, rec audit.Recorder) error) error {
+			return fn(m, rec)
+		}).AnyTimes()
+
+	return NewService(m)
+}
+
 func TestService_GetActors(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	m := NewMockActorRepository(ctrl)
+	rec := audit.NewMockRecorder(ctrl)
 
-	s := NewService(m)
+	s := newService(m, rec)
 
 	// valid request
 	bd1, _ := time.Parse(time.DateOnly, "1995-05-03")
@@ -63,8 +77,9 @@ func TestService_GetActors(t *testing.T) {
 func TestService_AddActor(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	m := NewMockActorRepository(ctrl)
+	rec := audit.NewMockRecorder(ctrl)
 
-	s := NewService(m)
+	s := newService(m, rec)
 
 	// valid request
 	bd, _ := time.Parse(time.DateOnly, "1995-05-03")
@@ -82,6 +97,9 @@ func TestService_AddActor(t *testing.T) {
 	m.EXPECT().
 		AddActor(gomock.Any(), gomock.Eq(in)).
 		Return(out, nil).Times(1)
+	rec.EXPECT().
+		Record(gomock.Any(), audit.EntityActor, out.ID, audit.ActionCreate, nil, ToActorState(out)).
+		Return(nil).Times(1)
 
 	req := &ActorInfo{
 		Name:     "actor1",
@@ -129,8 +147,9 @@ func TestService_AddActor(t *testing.T) {
 func TestService_GetActor(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	m := NewMockActorRepository(ctrl)
+	rec := audit.NewMockRecorder(ctrl)
 
-	s := NewService(m)
+	s := newService(m, rec)
 
 	// valid requset
 	in := int32(1)
@@ -191,14 +210,26 @@ func TestService_GetActor(t *testing.T) {
 func TestService_UpdateActor(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	m := NewMockActorRepository(ctrl)
+	rec := audit.NewMockRecorder(ctrl)
 
-	s := NewService(m)
+	s := newService(m, rec)
 
-	//valid request
+	//valid request replaces every field
 	bd, _ := time.Parse(time.DateOnly, "1995-05-03")
+	cur := &Actor{
+		ID:       1,
+		Name:     "old name",
+		Sex:      "female",
+		Birthday: bd,
+		Films:    []string{"film1", "film2"},
+		Version:  1,
+	}
 	in := &Actor{
 		ID:       1,
+		Name:     "actor1",
+		Sex:      "male",
 		Birthday: bd,
+		Version:  1,
 	}
 	out := &Actor{
 		ID:       1,
@@ -206,16 +237,24 @@ func TestService_UpdateActor(t *testing.T) {
 		Sex:      "male",
 		Birthday: bd,
 		Films:    []string{"film1", "film2"},
+		Version:  2,
 	}
 
 	gomock.InOrder(
-		m.EXPECT().UpdateActor(gomock.Any(), gomock.Eq(in)).Return(nil).Times(1),
+		m.EXPECT().GetActor(gomock.Any(), gomock.Eq(in.ID)).Return(cur, nil).Times(1),
+		m.EXPECT().UpdateActor(gomock.Any(), gomock.Eq(ToActorUpdate(in))).Return(nil).Times(1),
 		m.EXPECT().GetActor(gomock.Any(), gomock.Eq(in.ID)).Return(out, nil).Times(1),
+		rec.EXPECT().
+			Record(gomock.Any(), audit.EntityActor, in.ID, audit.ActionUpdate, ToActorState(cur), ToActorState(out)).
+			Return(nil).Times(1),
 	)
 
 	req := &ActorIdInfoRequest{
-		ID: "1",
+		ID:      "1",
+		IfMatch: ToActorResponse(cur).ETag,
 		Info: ActorInfo{
+			Name:     "actor1",
+			Sex:      "male",
 			Birthday: bd.Format(time.DateOnly),
 		},
 	}
@@ -230,38 +269,17 @@ func TestService_UpdateActor(t *testing.T) {
 		t.Errorf("Expected %+v, got %+v", expRes, res)
 	}
 
-	// empty update
-	in = &Actor{
-		ID: 1,
-	}
-
-	m.EXPECT().
-		UpdateActor(gomock.Any(), gomock.Eq(in)).
-		Return(ErrEmptyUpdate).Times(1)
-
-	req = &ActorIdInfoRequest{
-		ID: "1",
-	}
-
-	_, err = s.UpdateActor(context.TODO(), req)
-	if !errors.Is(err, ErrEmptyUpdate) {
-		t.Fatalf("Expected %s, got %s", ErrEmptyUpdate.Error(), err.Error())
-	}
-
 	// actor does not exist
-	in = &Actor{
-		ID:   6969,
-		Name: "new name",
-	}
-
 	m.EXPECT().
-		UpdateActor(gomock.Any(), gomock.Eq(in)).
-		Return(ErrActorNotExist).Times(1)
+		GetActor(gomock.Any(), gomock.Eq(int32(6969))).
+		Return(nil, ErrActorNotExist).Times(1)
 
 	req = &ActorIdInfoRequest{
 		ID: "6969",
 		Info: ActorInfo{
-			Name: "new name",
+			Name:     "new name",
+			Sex:      "male",
+			Birthday: bd.Format(time.DateOnly),
 		},
 	}
 
@@ -284,26 +302,140 @@ func TestService_UpdateActor(t *testing.T) {
 		t.Fatalf("Expected %s, got %s", ErrIdInvalid.Error(), err.Error())
 	}
 
+	// partial (empty fields) request
+	req = &ActorIdInfoRequest{
+		ID: "1",
+		Info: ActorInfo{
+			Birthday: bd.Format(time.DateOnly),
+		},
+	}
+
+	_, err = s.UpdateActor(context.TODO(), req)
+	vErr := &util.ValidationError{}
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Expected %s, got %v", vErr.Error(), err)
+	}
+
 	// invalid (format) request
 	req = &ActorIdInfoRequest{
 		ID: "1",
 		Info: ActorInfo{
+			Name:     "actor1",
+			Sex:      "male",
 			Birthday: "apple",
 		},
 	}
 
 	_, err = s.UpdateActor(context.TODO(), req)
-	vErr := &util.ValidationError{}
 	if !errors.As(err, &vErr) {
-		t.Fatalf("Expected %s, got %s", vErr.Error(), err.Error())
+		t.Fatalf("Expected %s, got %v", vErr.Error(), err)
+	}
+}
+
+func TestService_PatchActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockActorRepository(ctrl)
+	rec := audit.NewMockRecorder(ctrl)
+
+	s := newService(m, rec)
+
+	// valid patch changes only the present fields
+	bd, _ := time.Parse(time.DateOnly, "1995-05-03")
+	cur := &Actor{
+		ID:      1,
+		Name:    "actor1",
+		Sex:     "male",
+		Version: 4,
+	}
+	in := &ActorUpdate{
+		ID:       1,
+		Version:  4,
+		Birthday: &bd,
+	}
+	out := &Actor{
+		ID:       1,
+		Name:     "actor1",
+		Sex:      "male",
+		Birthday: bd,
+		Version:  5,
+	}
+
+	gomock.InOrder(
+		m.EXPECT().GetActor(gomock.Any(), gomock.Eq(in.ID)).Return(cur, nil).Times(1),
+		m.EXPECT().UpdateActor(gomock.Any(), gomock.Eq(in)).Return(nil).Times(1),
+		m.EXPECT().GetActor(gomock.Any(), gomock.Eq(in.ID)).Return(out, nil).Times(1),
+		rec.EXPECT().
+			Record(gomock.Any(), audit.EntityActor, in.ID, audit.ActionUpdate, ToActorState(cur), ToActorState(out)).
+			Return(nil).Times(1),
+	)
+
+	req := &ActorPatchRequest{ID: "1", IfMatch: ToActorResponse(cur).ETag}
+	if err := json.Unmarshal([]byte(`{"birthday": "1995-05-03"}`), &req.Patch); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	expRes := ToActorResponse(out)
+
+	res, err := s.PatchActor(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(expRes, res) {
+		t.Errorf("Expected %+v, got %+v", expRes, res)
+	}
+
+	// a failing audit log fails the change, which is rolled back with it
+	auditErr := errors.New("audit log unavailable")
+	gomock.InOrder(
+		m.EXPECT().GetActor(gomock.Any(), gomock.Eq(in.ID)).Return(cur, nil).Times(1),
+		m.EXPECT().UpdateActor(gomock.Any(), gomock.Eq(in)).Return(nil).Times(1),
+		m.EXPECT().GetActor(gomock.Any(), gomock.Eq(in.ID)).Return(out, nil).Times(1),
+		rec.EXPECT().
+			Record(gomock.Any(), audit.EntityActor, in.ID, audit.ActionUpdate, ToActorState(cur), ToActorState(out)).
+			Return(auditErr).Times(1),
+	)
+
+	_, err = s.PatchActor(context.TODO(), req)
+	if !errors.Is(err, auditErr) {
+		t.Fatalf("Expected %s, got %v", auditErr.Error(), err)
+	}
+
+	// empty patch without If-Match
+	gomock.InOrder(
+		m.EXPECT().GetActor(gomock.Any(), gomock.Eq(int32(1))).Return(&Actor{ID: 1}, nil).Times(1),
+		m.EXPECT().
+			UpdateActor(gomock.Any(), gomock.Eq(&ActorUpdate{ID: 1})).
+			Return(ErrEmptyUpdate).Times(1),
+	)
+
+	req = &ActorPatchRequest{ID: "1"}
+
+	_, err = s.PatchActor(context.TODO(), req)
+	if !errors.Is(err, ErrEmptyUpdate) {
+		t.Fatalf("Expected %s, got %v", ErrEmptyUpdate.Error(), err)
+	}
+
+	// null and empty fields
+	for _, patch := range []string{`{"name": null}`, `{"sex": ""}`, `{"birthday": "apple"}`} {
+		req = &ActorPatchRequest{ID: "1"}
+		if err := json.Unmarshal([]byte(patch), &req.Patch); err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+
+		_, err = s.PatchActor(context.TODO(), req)
+		vErr := &util.ValidationError{}
+		if !errors.As(err, &vErr) {
+			t.Errorf("Expected validation error for %s, got %v", patch, err)
+		}
 	}
 }
 
 func TestService_DeleteActor(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	m := NewMockActorRepository(ctrl)
+	rec := audit.NewMockRecorder(ctrl)
 
-	s := NewService(m)
+	s := newService(m, rec)
 
 	//valid request
 	in := int32(1)
@@ -314,17 +446,22 @@ func TestService_DeleteActor(t *testing.T) {
 		Sex:      "male",
 		Birthday: bd,
 		Films:    []string{"film1", "film2"},
+		Version:  3,
 	}
+	expRes := ToActorResponse(out)
 
 	gomock.InOrder(
 		m.EXPECT().GetActor(gomock.Any(), gomock.Eq(in)).Return(out, nil).Times(1),
-		m.EXPECT().DeleteActor(gomock.Any(), gomock.Eq(in)).Return(nil).Times(1),
+		m.EXPECT().DeleteActor(gomock.Any(), gomock.Eq(in), gomock.Eq(out.Version)).Return(nil).Times(1),
+		rec.EXPECT().
+			Record(gomock.Any(), audit.EntityActor, in, audit.ActionDelete, ToActorState(out), nil).
+			Return(nil).Times(1),
 	)
 
 	req := &ActorIdRequest{
-		ID: "1",
+		ID:      "1",
+		IfMatch: expRes.ETag,
 	}
-	expRes := ToActorResponse(out)
 
 	res, err := s.DeleteActor(context.TODO(), req)
 	if err != nil {
@@ -335,6 +472,19 @@ func TestService_DeleteActor(t *testing.T) {
 		t.Errorf("Expected %+v, got %+v", expRes, res)
 	}
 
+	// stale If-Match
+	m.EXPECT().GetActor(gomock.Any(), gomock.Eq(in)).Return(out, nil).Times(1)
+
+	req = &ActorIdRequest{
+		ID:      "1",
+		IfMatch: `"2-0000000000000000"`,
+	}
+
+	_, err = s.DeleteActor(context.TODO(), req)
+	if !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("Expected %s, got %v", ErrVersionMismatch.Error(), err)
+	}
+
 	// actor does not exist
 	in = int32(1)
 
@@ -361,3 +511,49 @@ func TestService_DeleteActor(t *testing.T) {
 		t.Fatalf("Expected %s, got %s", ErrIdInvalid.Error(), err.Error())
 	}
 }
+
+func TestService_RestoreActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockActorRepository(ctrl)
+	rec := audit.NewMockRecorder(ctrl)
+
+	s := newService(m, rec)
+
+	// valid request
+	in := int32(1)
+	bd, _ := time.Parse(time.DateOnly, "1995-05-03")
+	out := &Actor{
+		ID:       1,
+		Name:     "actor1",
+		Sex:      "male",
+		Birthday: bd,
+		Films:    []string{"film1"},
+		Version:  2,
+	}
+
+	gomock.InOrder(
+		m.EXPECT().RestoreActor(gomock.Any(), gomock.Eq(in)).Return(nil).Times(1),
+		m.EXPECT().GetActor(gomock.Any(), gomock.Eq(in)).Return(out, nil).Times(1),
+		rec.EXPECT().
+			Record(gomock.Any(), audit.EntityActor, in, audit.ActionRestore, nil, ToActorState(out)).
+			Return(nil).Times(1),
+	)
+	expRes := ToActorResponse(out)
+
+	res, err := s.RestoreActor(context.TODO(), &ActorIdRequest{ID: "1"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(expRes, res) {
+		t.Errorf("Expected %+v, got %+v", expRes, res)
+	}
+
+	// actor is not in the trash
+	m.EXPECT().RestoreActor(gomock.Any(), gomock.Eq(in)).Return(ErrActorNotExist).Times(1)
+
+	_, err = s.RestoreActor(context.TODO(), &ActorIdRequest{ID: "1"})
+	if !errors.Is(err, ErrActorNotExist) {
+		t.Fatalf("Expected %s, got %v", ErrActorNotExist.Error(), err)
+	}
+}
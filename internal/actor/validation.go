@@ -51,3 +51,30 @@ func ValidateEmptyActorInfo(ai *ActorInfo) *util.ValidationError {
 
 	return ve
 }
+
+func ValidateActorPatch(ap *ActorPatch) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if ap.Name.Null || (ap.Name.Set && len(ap.Name.Value) == 0) {
+		ve.AddViolation("name empty")
+	}
+
+	if ap.Sex.Null || (ap.Sex.Set && len(ap.Sex.Value) == 0) {
+		ve.AddViolation("sex empty (expected one of [male, female])")
+	}
+
+	if ap.Birthday.Null || (ap.Birthday.Set && len(ap.Birthday.Value) == 0) {
+		ve.AddViolation("date empty (expected format: 2006-01-02)")
+	}
+
+	if !ve.NoViolations() {
+		return ve
+	}
+
+	// absent fields hold zero values which pass the format checks
+	return ValidateFormatActorInfo(&ActorInfo{
+		Name:     ap.Name.Value,
+		Sex:      ap.Sex.Value,
+		Birthday: ap.Birthday.Value,
+	})
+}
@@ -1,48 +1,249 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 
 	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/autocomplete"
+	"github.com/Coderovshik/film-library/internal/batch"
+	"github.com/Coderovshik/film-library/internal/collection"
 	"github.com/Coderovshik/film-library/internal/config"
 	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/export"
 	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/follow"
+	"github.com/Coderovshik/film-library/internal/graph"
+	"github.com/Coderovshik/film-library/internal/history"
+	"github.com/Coderovshik/film-library/internal/idempotency"
+	"github.com/Coderovshik/film-library/internal/importer"
+	"github.com/Coderovshik/film-library/internal/lending"
+	"github.com/Coderovshik/film-library/internal/memory"
+	"github.com/Coderovshik/film-library/internal/recommend"
 	"github.com/Coderovshik/film-library/internal/router"
+	"github.com/Coderovshik/film-library/internal/search"
+	"github.com/Coderovshik/film-library/internal/trash"
 	"github.com/Coderovshik/film-library/internal/user"
 )
 
 type App struct {
 	Router *router.Router
 	Config *config.Config
+
+	trash       *trash.Service
+	idempotency *idempotency.Service
+	// dispatcher is nil when no notify driver is configured
+	dispatcher *follow.Dispatcher
 }
 
-func NewApp(cfg *config.Config) *App {
+type repositories struct {
+	users  user.UserRepository
+	actors actor.ActorRepository
+	films  film.FilmRepository
+	audit  audit.AuditRepository
+	tx     importer.Transactor
+
+	idempotency idempotency.IdempotencyRepository
+	search      search.SearchRepository
+
+	autocomplete autocomplete.AutocompleteRepository
+	graph        graph.GraphRepository
+	views        recommend.ViewRepository
+	collections  collection.CollectionRepository
+	history      history.HistoryRepository
+	lending      lending.LendingRepository
+	follow       follow.FollowRepository
+}
+
+func newRepositories(cfg *config.Config) *repositories {
+	if cfg.Storage == config.StorageMemory {
+		log.Printf("using in-memory storage, data will be lost on shutdown")
+		store := memory.NewStore()
+
+		return &repositories{
+			users:  memory.NewUserRepository(store),
+			actors: memory.NewActorRepository(store),
+			films:  memory.NewFilmRepository(store),
+			audit:  memory.NewAuditRepository(store),
+			tx:     store,
+
+			idempotency: memory.NewIdempotencyRepository(store),
+			search:      memory.NewSearchRepository(store),
+
+			autocomplete: memory.NewAutocompleteRepository(store),
+			graph:        memory.NewGraphRepository(store),
+			views:        memory.NewViewRepository(store),
+			collections:  memory.NewCollectionRepository(store),
+			history:      memory.NewHistoryRepository(store),
+			lending:      memory.NewLendingRepository(store),
+			follow:       memory.NewFollowRepository(store),
+		}
+	}
+
 	database, err := db.NewDatabase(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	userRepo := user.NewRepository(database.GetDB())
-	userService := user.NewService(userRepo, cfg)
+	log.Printf("using %s storage", database.GetDialect().Name())
+
+	return &repositories{
+		users:  user.NewRepository(database.GetDB(), database.GetDialect()),
+		actors: actor.NewRepository(database.GetDB(), database.GetDialect()),
+		films:  film.NewRepository(database.GetDB(), database.GetDialect()),
+		audit:  audit.NewRepository(database.GetDB(), database.GetDialect()),
+		tx:     importer.NewDatabaseTransactor(database.GetDB(), database.GetDialect()),
+
+		idempotency: idempotency.NewRepository(database.GetDB(), database.GetDialect()),
+		search:      search.NewRepository(database.GetDB(), database.GetDialect()),
+
+		autocomplete: autocomplete.NewRepository(database.GetDB(), database.GetDialect()),
+		graph:        graph.NewRepository(database.GetDB(), database.GetDialect()),
+		views:        recommend.NewRepository(database.GetDB(), database.GetDialect()),
+		collections:  collection.NewRepository(database.GetDB(), database.GetDialect()),
+		history:      history.NewRepository(database.GetDB(), database.GetDialect()),
+		lending:      lending.NewRepository(database.GetDB(), database.GetDialect()),
+		follow:       follow.NewRepository(database.GetDB(), database.GetDialect()),
+	}
+}
+
+// bootstrapAdmin creates the configured admin on the first run,
+// it does nothing once any admin exists.
+func bootstrapAdmin(cfg *config.Config, s *user.Service) {
+	password, err := cfg.AdminPassword()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	res, err := s.CreateAdmin(context.Background(), &user.CreateAdminRequest{
+		Username: cfg.AdminUsername,
+		Password: password,
+	})
+	if errors.Is(err, user.ErrAdminExist) {
+		log.Printf("admin already exists, skipping bootstrap")
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("created admin %s", res.Username)
+
+	// print to stdout only, the log is often shipped and kept
+	if len(res.GeneratedPassword) != 0 {
+		fmt.Printf("generated password of admin %s, it is not shown again: %s\n", res.Username, res.GeneratedPassword)
+	}
+}
+
+// newNotifier returns the notifier of the configured driver, nil if
+// there is none.
+func newNotifier(cfg *config.Config) follow.Notifier {
+	switch cfg.NotifyDriver {
+	case config.NotifySMTP:
+		return follow.NewSMTPNotifier(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword, cfg.NotifyTimeout)
+	case config.NotifyWebhook:
+		return follow.NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookSecret, cfg.NotifyTimeout)
+	}
+
+	return nil
+}
+
+func NewApp(cfg *config.Config) *App {
+	repos := newRepositories(cfg)
+
+	userService := user.NewService(repos.users, cfg)
+	if len(cfg.AdminUsername) != 0 {
+		bootstrapAdmin(cfg, userService)
+	}
 	userHandler := user.NewHandler(userService)
 
-	actorRepo := actor.NewRepository(database.GetDB())
-	actorService := actor.NewService(actorRepo)
+	auditService := audit.NewService(repos.audit)
+	auditHandler := audit.NewHandler(auditService)
+
+	// film changes are published to the followers of actors
+	followService := follow.NewService(repos.follow)
+	followHandler := follow.NewHandler(followService)
+
+	actorService := actor.NewService(repos.actors)
 	actorHandler := actor.NewHandler(actorService)
 
-	filmRepo := film.NewRepository(database.GetDB())
-	filmService := film.NewService(filmRepo)
+	filmService := film.NewService(repos.films, followService)
 	filmHandler := film.NewHandler(filmService)
 
-	router := router.NewRouter(cfg, userHandler, actorHandler, filmHandler)
+	trashService := trash.NewService(repos.films, repos.actors)
+	trashHandler := trash.NewHandler(trashService)
+
+	importService := importer.NewService(repos.tx)
+	importHandler := importer.NewHandler(importService)
+
+	exportService := export.NewService(repos.films, repos.actors)
+	exportHandler := export.NewHandler(exportService)
+
+	batchService := batch.NewService(repos.tx, followService)
+	batchHandler := batch.NewHandler(batchService)
+
+	searchService := search.NewService(repos.search)
+	searchHandler := search.NewHandler(searchService)
 
-	return &App{
-		Router: router,
-		Config: cfg,
+	autocompleteService := autocomplete.NewService(repos.autocomplete, cfg.AutocompleteTimeout)
+	autocompleteHandler := autocomplete.NewHandler(autocompleteService)
+
+	graphService := graph.NewService(repos.graph)
+	graphHandler := graph.NewHandler(graphService)
+
+	recommendService := recommend.NewService(repos.films, repos.views, recommend.Weights{
+		Cast:     cfg.SimilarCastWeight,
+		Era:      cfg.SimilarEraWeight,
+		Rating:   cfg.SimilarRatingWeight,
+		EraYears: cfg.SimilarEraYears,
+	})
+	recommendHandler := recommend.NewHandler(recommendService)
+
+	collectionService := collection.NewService(repos.collections)
+	collectionHandler := collection.NewHandler(collectionService)
+
+	historyService := history.NewService(repos.history)
+	historyHandler := history.NewHandler(historyService)
+
+	lendingService := lending.NewService(repos.lending, lending.Policy{
+		LoanPeriod:  cfg.LoanPeriod,
+		MaxRenewals: cfg.LoanMaxRenewals,
+	})
+	lendingHandler := lending.NewHandler(lendingService)
+
+	idempotencyService := idempotency.NewService(repos.idempotency, cfg.IdempotencyTTL, cfg.IdempotencyLockTimeout)
+
+	router := router.NewRouter(cfg, userHandler, actorHandler, filmHandler, auditHandler, trashHandler, importHandler, exportHandler, batchHandler, searchHandler, autocompleteHandler, graphHandler, recommendHandler, collectionHandler, historyHandler, lendingHandler, followHandler, idempotencyService, recommendService)
+
+	app := &App{
+		Router:      router,
+		Config:      cfg,
+		trash:       trashService,
+		idempotency: idempotencyService,
+	}
+	if notifier := newNotifier(cfg); notifier != nil {
+		app.dispatcher = follow.NewDispatcher(repos.follow, notifier, cfg.NotifyMaxAttempts, cfg.NotifyInterval, cfg.NotifyClaimTimeout)
 	}
+
+	return app
 }
 
 func (a *App) Run() {
+	if a.Config.TrashPurgeInterval > 0 {
+		go a.trash.RunPurge(context.Background(), a.Config.TrashRetention, a.Config.TrashPurgeInterval)
+	}
+	if a.Config.IdempotencyPurgeInterval > 0 {
+		go a.idempotency.RunPurge(context.Background(), a.Config.IdempotencyPurgeInterval)
+	}
+	if a.dispatcher != nil {
+		go a.dispatcher.Run(context.Background(), a.Config.NotifyInterval)
+	} else {
+		log.Printf("no notify driver, notifications are recorded but not delivered")
+	}
+
 	log.Printf("server running %s", a.Config.Addr())
 	if err := a.Router.Run(a.Config.Addr()); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const (
+	EntityFilm  = "film"
+	EntityActor = "actor"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionBind    = "bind"
+	ActionUnbind  = "unbind"
+	ActionRestore = "restore"
+	ActionPurge   = "purge"
+)
+
+// Entry is an append-only record of a change. Before is null for
+// creations and After is null for deletions and purges.
+type Entry struct {
+	ID         int64
+	OccurredAt time.Time
+	UserID     *int32
+	Entity     string
+	EntityID   int32
+	Action     string
+	Before     json.RawMessage
+	After      json.RawMessage
+}
+
+// Query selects entries newest first, zero fields match anything.
+// BeforeID continues a listing from the smallest id seen.
+type Query struct {
+	Entity   string
+	EntityID int32
+	UserID   int32
+	Action   string
+	Since    time.Time
+	Until    time.Time
+	BeforeID int64
+	Limit    int
+}
+
+// Recorder appends changes to the audit log. Before and after are the
+// states of the entity, nil if it does not exist.
+type Recorder interface {
+	Record(ctx context.Context, entity string, id int32, action string, before, after any) error
+}
+
+type AuditRepository interface {
+	AddEntry(ctx context.Context, e *Entry) (*Entry, error)
+	GetEntries(ctx context.Context, q *Query) ([]*Entry, error)
+}
+
+type AuditService interface {
+	Recorder
+	GetHistory(ctx context.Context, req *HistoryRequest) ([]*EntryResponse, error)
+	GetEntries(ctx context.Context, req *GetEntriesRequest) ([]*EntryResponse, error)
+}
+
+type AuditHandler interface {
+	GetFilmHistory(w http.ResponseWriter, r *http.Request)
+	GetActorHistory(w http.ResponseWriter, r *http.Request)
+	GetEntries(w http.ResponseWriter, r *http.Request)
+}
+
+type HistoryRequest struct {
+	Entity   string
+	ID       string
+	BeforeID string
+	Limit    string
+}
+
+type GetEntriesRequest struct {
+	Entity   string
+	EntityID string
+	UserID   string
+	Action   string
+	Since    string
+	Until    string
+	BeforeID string
+	Limit    string
+}
+
+// Change holds the old and new value of a field, a missing value is null.
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+type EntryResponse struct {
+	ID         int64             `json:"id"`
+	OccurredAt string            `json:"occurredAt"`
+	UserID     *int32            `json:"userId,omitempty"`
+	Entity     string            `json:"entity"`
+	EntityID   int32             `json:"entityId"`
+	Action     string            `json:"action"`
+	Before     json.RawMessage   `json:"before"`
+	After      json.RawMessage   `json:"after"`
+	Changes    map[string]Change `json:"changes"`
+}
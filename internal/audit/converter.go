@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// Diff compares two JSON states field by field, nested objects are
+// flattened to dotted keys and arrays are compared as a whole.
+func Diff(before, after json.RawMessage) map[string]Change {
+	from, to := flatten(before), flatten(after)
+
+	changes := make(map[string]Change)
+	for k, v := range from {
+		if w, ok := to[k]; !ok || !reflect.DeepEqual(v, w) {
+			changes[k] = Change{From: v, To: w}
+		}
+	}
+	for k, w := range to {
+		if _, ok := from[k]; !ok {
+			changes[k] = Change{To: w}
+		}
+	}
+
+	return changes
+}
+
+func flatten(state json.RawMessage) map[string]any {
+	fields := make(map[string]any)
+	if len(state) == 0 {
+		return fields
+	}
+
+	var v any
+	if err := json.Unmarshal(state, &v); err != nil {
+		return fields
+	}
+	flattenInto(fields, "", v)
+
+	return fields
+}
+
+func flattenInto(fields map[string]any, prefix string, v any) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		fields[prefix] = v
+		return
+	}
+
+	for k, w := range obj {
+		if len(prefix) != 0 {
+			k = prefix + "." + k
+		}
+		flattenInto(fields, k, w)
+	}
+}
+
+// ToState marshals an entity state, nil gives no state.
+func ToState(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
+
+func ToEntryResponse(e *Entry) *EntryResponse {
+	return &EntryResponse{
+		ID:         e.ID,
+		OccurredAt: e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		UserID:     e.UserID,
+		Entity:     e.Entity,
+		EntityID:   e.EntityID,
+		Action:     e.Action,
+		Before:     nullState(e.Before),
+		After:      nullState(e.After),
+		Changes:    Diff(e.Before, e.After),
+	}
+}
+
+func nullState(state json.RawMessage) json.RawMessage {
+	if len(state) == 0 {
+		return json.RawMessage("null")
+	}
+
+	return state
+}
+
+// ToQuery expects a validated request.
+func ToQuery(req *GetEntriesRequest) *Query {
+	entityID, _ := strconv.ParseInt(req.EntityID, 10, 32)
+	userID, _ := strconv.ParseInt(req.UserID, 10, 32)
+	since, _ := time.Parse(time.RFC3339Nano, req.Since)
+	until, _ := time.Parse(time.RFC3339Nano, req.Until)
+
+	return &Query{
+		Entity:   req.Entity,
+		EntityID: int32(entityID),
+		UserID:   int32(userID),
+		Action:   req.Action,
+		Since:    since,
+		Until:    until,
+		BeforeID: toBeforeID(req.BeforeID),
+		Limit:    toLimit(req.Limit),
+	}
+}
+
+func toBeforeID(s string) int64 {
+	id, _ := strconv.ParseInt(s, 10, 64)
+	return id
+}
+
+func toLimit(s string) int {
+	limit, err := strconv.Atoi(s)
+	if err != nil {
+		return defaultLimit
+	}
+
+	return limit
+}
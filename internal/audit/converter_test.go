@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := json.RawMessage(`{"info": {"name": "film", "rating": 5}, "actors": ["a", "b"]}`)
+	after := json.RawMessage(`{"info": {"name": "film", "rating": 7}, "actors": ["a"]}`)
+
+	exp := map[string]Change{
+		"info.rating": {From: 5.0, To: 7.0},
+		"actors":      {From: []any{"a", "b"}, To: []any{"a"}},
+	}
+	if got := Diff(before, after); !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+
+	// creation and deletion list every field
+	exp = map[string]Change{
+		"info.name":   {To: "film"},
+		"info.rating": {To: 7.0},
+		"actors":      {To: []any{"a"}},
+	}
+	if got := Diff(nil, after); !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+
+	exp = map[string]Change{
+		"info.name":   {From: "film"},
+		"info.rating": {From: 7.0},
+		"actors":      {From: []any{"a"}},
+	}
+	if got := Diff(after, nil); !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+
+	if got := Diff(after, after); len(got) != 0 {
+		t.Errorf("Expected no changes, got %+v", got)
+	}
+}
+
+func TestToQuery(t *testing.T) {
+	q := ToQuery(&GetEntriesRequest{
+		Entity:   EntityFilm,
+		EntityID: "3",
+		Since:    "2024-03-01T00:00:00Z",
+		BeforeID: "42",
+	})
+	if q.Entity != EntityFilm || q.EntityID != 3 || q.UserID != 0 || q.Since.IsZero() || !q.Until.IsZero() ||
+		q.BeforeID != 42 || q.Limit != defaultLimit {
+		t.Errorf("Unexpected query %+v", q)
+	}
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ AuditHandler = (*Handler)(nil)
+
+type Handler struct {
+	service AuditService
+}
+
+func NewHandler(as AuditService) *Handler {
+	return &Handler{
+		service: as,
+	}
+}
+
+func (h *Handler) GetFilmHistory(w http.ResponseWriter, r *http.Request) {
+	h.getHistory(w, r, EntityFilm)
+}
+
+func (h *Handler) GetActorHistory(w http.ResponseWriter, r *http.Request) {
+	h.getHistory(w, r, EntityActor)
+}
+
+func (h *Handler) getHistory(w http.ResponseWriter, r *http.Request, entity string) {
+	res, err := h.service.GetHistory(r.Context(), &HistoryRequest{
+		Entity:   entity,
+		ID:       r.PathValue("id"),
+		BeforeID: r.URL.Query().Get("before"),
+		Limit:    r.URL.Query().Get("limit"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get %s history err=%s\n", entity, err.Error())
+		if errors.Is(err, ErrIdInvalid) {
+			util.NotFound(w, r)
+			return
+		}
+
+		entriesError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetEntries(r.Context(), &GetEntriesRequest{
+		Entity:   r.URL.Query().Get("entity"),
+		EntityID: r.URL.Query().Get("entityId"),
+		UserID:   r.URL.Query().Get("userId"),
+		Action:   r.URL.Query().Get("action"),
+		Since:    r.URL.Query().Get("since"),
+		Until:    r.URL.Query().Get("until"),
+		BeforeID: r.URL.Query().Get("before"),
+		Limit:    r.URL.Query().Get("limit"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get audit entries err=%s\n", err.Error())
+		entriesError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func entriesError(w http.ResponseWriter, r *http.Request, err error) {
+	var ve *util.ValidationError
+	if errors.As(err, &ve) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		})
+		return
+	}
+
+	util.InternalServerError(w, r)
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ AuditRepository = (*Repository)(nil)
+
+type Repository struct {
+	db      db.DBTX
+	dialect db.Dialect
+}
+
+func NewRepository(db db.DBTX, d db.Dialect) *Repository {
+	return &Repository{
+		db:      db,
+		dialect: d,
+	}
+}
+
+func (r *Repository) AddEntry(ctx context.Context, e *Entry) (*Entry, error) {
+	const op = "audit.Repository.AddEntry"
+
+	const query = `INSERT INTO audit_log(occurred_at, user_id, entity, entity_id, action, before_state, after_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, "audit_id",
+		e.OccurredAt, e.UserID, e.Entity, e.EntityID, e.Action, toNullString(e.Before), toNullString(e.After))
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	e.ID = id
+
+	return e, nil
+}
+
+func (r *Repository) GetEntries(ctx context.Context, q *Query) ([]*Entry, error) {
+	const op = "audit.Repository.GetEntries"
+
+	where, values := toQueryConditions(q)
+	values = append(values, q.Limit)
+	query := fmt.Sprintf(`SELECT audit_id, occurred_at, user_id, entity, entity_id, action, before_state, after_state
+		FROM audit_log %s ORDER BY audit_id DESC LIMIT $%d`, where, len(values))
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to prepare query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, values...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	for rows.Next() {
+		var e Entry
+		var before, after sql.NullString
+		err := rows.Scan(&e.ID, &e.OccurredAt, &e.UserID, &e.Entity, &e.EntityID, &e.Action, &before, &after)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if before.Valid {
+			e.Before = []byte(before.String)
+		}
+		if after.Valid {
+			e.After = []byte(after.String)
+		}
+
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
+
+func toQueryConditions(q *Query) (string, []any) {
+	var where []string
+	var values []any
+	add := func(cond string, v any) {
+		values = append(values, v)
+		where = append(where, fmt.Sprintf(cond, len(values)))
+	}
+
+	if len(q.Entity) != 0 {
+		add("entity = $%d", q.Entity)
+	}
+	if q.EntityID != 0 {
+		add("entity_id = $%d", q.EntityID)
+	}
+	if q.UserID != 0 {
+		add("user_id = $%d", q.UserID)
+	}
+	if len(q.Action) != 0 {
+		add("action = $%d", q.Action)
+	}
+	if !q.Since.IsZero() {
+		add("occurred_at >= $%d", q.Since.UTC())
+	}
+	if !q.Until.IsZero() {
+		add("occurred_at < $%d", q.Until.UTC())
+	}
+	if q.BeforeID != 0 {
+		add("audit_id < $%d", q.BeforeID)
+	}
+
+	if len(where) == 0 {
+		return "", values
+	}
+
+	return "WHERE " + strings.Join(where, " AND "), values
+}
+
+func toNullString(state []byte) sql.NullString {
+	return sql.NullString{
+		String: string(state),
+		Valid:  len(state) != 0,
+	}
+}
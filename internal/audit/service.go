@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var (
+	ErrIdInvalid = errors.New("invalid id")
+)
+
+var _ AuditService = (*Service)(nil)
+
+type Service struct {
+	repo AuditRepository
+}
+
+func NewService(ar AuditRepository) *Service {
+	return &Service{
+		repo: ar,
+	}
+}
+
+// Record stamps the entry with the current time and the user of the request.
+func (s *Service) Record(ctx context.Context, entity string, id int32, action string, before, after any) error {
+	const op = "audit.Service.Record"
+
+	e := &Entry{
+		OccurredAt: db.Now(),
+		UserID:     util.AuthorFromContext(ctx),
+		Entity:     entity,
+		EntityID:   id,
+		Action:     action,
+	}
+
+	var err error
+	if e.Before, err = ToState(before); err != nil {
+		log.Printf("ERROR: failed to marshal state before %s\n", action)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if e.After, err = ToState(after); err != nil {
+		log.Printf("ERROR: failed to marshal state after %s\n", action)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.repo.AddEntry(ctx, e)
+	if err != nil {
+		log.Printf("ERROR: failed to add audit entry to repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetHistory lists changes of a single film or actor, including deleted ones.
+func (s *Service) GetHistory(ctx context.Context, req *HistoryRequest) ([]*EntryResponse, error) {
+	const op = "audit.Service.GetHistory"
+
+	id, err := strconv.ParseUint(req.ID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+
+	vErr := ValidateHistoryRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	return s.getEntries(ctx, &Query{
+		Entity:   req.Entity,
+		EntityID: int32(id),
+		BeforeID: toBeforeID(req.BeforeID),
+		Limit:    toLimit(req.Limit),
+	}, op)
+}
+
+func (s *Service) GetEntries(ctx context.Context, req *GetEntriesRequest) ([]*EntryResponse, error) {
+	const op = "audit.Service.GetEntries"
+
+	vErr := ValidateGetEntriesRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	return s.getEntries(ctx, ToQuery(req), op)
+}
+
+func (s *Service) getEntries(ctx context.Context, q *Query, op string) ([]*EntryResponse, error) {
+	entries, err := s.repo.GetEntries(ctx, q)
+	if err != nil {
+		log.Printf("ERROR: failed to get audit entries from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := make([]*EntryResponse, 0, len(entries))
+	for _, v := range entries {
+		res = append(res, ToEntryResponse(v))
+	}
+
+	return res, nil
+}
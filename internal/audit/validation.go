@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var (
+	validEntities = map[string]bool{EntityFilm: true, EntityActor: true}
+	validActions  = map[string]bool{
+		ActionCreate:  true,
+		ActionUpdate:  true,
+		ActionDelete:  true,
+		ActionBind:    true,
+		ActionUnbind:  true,
+		ActionRestore: true,
+		ActionPurge:   true,
+	}
+)
+
+func ValidateGetEntriesRequest(req *GetEntriesRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(req.Entity) != 0 && !validEntities[req.Entity] {
+		ve.AddViolation("incorrect entity, expected one of: film, actor")
+	}
+
+	if _, err := strconv.ParseUint(req.EntityID, 10, 31); err != nil && len(req.EntityID) != 0 {
+		ve.AddViolation("incorrect entityId, expected positive integer")
+	}
+
+	if _, err := strconv.ParseUint(req.UserID, 10, 31); err != nil && len(req.UserID) != 0 {
+		ve.AddViolation("incorrect userId, expected positive integer")
+	}
+
+	if len(req.Action) != 0 && !validActions[req.Action] {
+		ve.AddViolation("incorrect action, expected one of: create, update, delete, bind, unbind, restore, purge")
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, req.Since); err != nil && len(req.Since) != 0 {
+		ve.AddViolation("incorrect since format (expected RFC 3339 timestamp: 2006-01-02T15:04:05Z)")
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, req.Until); err != nil && len(req.Until) != 0 {
+		ve.AddViolation("incorrect until format (expected RFC 3339 timestamp: 2006-01-02T15:04:05Z)")
+	}
+
+	validatePage(ve, req.BeforeID, req.Limit)
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateHistoryRequest(req *HistoryRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	validatePage(ve, req.BeforeID, req.Limit)
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func validatePage(ve *util.ValidationError, beforeID, limit string) {
+	if _, err := strconv.ParseUint(beforeID, 10, 63); err != nil && len(beforeID) != 0 {
+		ve.AddViolation("incorrect before, expected positive integer")
+	}
+
+	if n, err := strconv.Atoi(limit); len(limit) != 0 && (err != nil || n < 1 || n > maxLimit) {
+		ve.AddViolation(fmt.Sprintf("incorrect limit, expected: 1 <= limit <= %d", maxLimit))
+	}
+}
@@ -0,0 +1,79 @@
+// Package autocomplete suggests film and actor names as the user types,
+// tolerating typos.
+package autocomplete
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+const (
+	KindFilm  = "film"
+	KindActor = "actor"
+
+	maxQueryLength = 64
+	defaultLimit   = 8
+	maxLimit       = 20
+)
+
+// ErrTimeout is returned when the suggestions are not found within the
+// latency budget.
+var ErrTimeout = errors.New("autocomplete timed out")
+
+// Suggestion is a name matching the query, prefix matches score 1.
+type Suggestion struct {
+	ID    int32
+	Name  string
+	Score float64
+}
+
+type Query struct {
+	Text  string
+	Kind  string
+	Limit int
+}
+
+type AutocompleteRepository interface {
+	// Suggest returns the prefix matches followed by the fuzzy ones, best
+	// scored first.
+	Suggest(ctx context.Context, q *Query) ([]*Suggestion, error)
+}
+
+// NameSource gives the names indexed by the Go fallback.
+type NameSource interface {
+	// Stamp changes whenever the names of the kind do, so that the index
+	// built from them is known to be stale.
+	Stamp(ctx context.Context, kind string) (string, error)
+	Names(ctx context.Context, kind string) ([]*Entry, error)
+}
+
+type AutocompleteService interface {
+	Suggest(ctx context.Context, req *SuggestRequest) (*SuggestResponse, error)
+}
+
+type AutocompleteHandler interface {
+	Suggest(w http.ResponseWriter, r *http.Request)
+}
+
+type SuggestRequest struct {
+	Query string
+	Type  string
+	Limit string
+}
+
+type SuggestionResponse struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type SuggestResponse struct {
+	Suggestions []*SuggestionResponse `json:"suggestions"`
+}
+
+func ToSuggestionResponse(s *Suggestion) *SuggestionResponse {
+	return &SuggestionResponse{
+		ID:   s.ID,
+		Name: s.Name,
+	}
+}
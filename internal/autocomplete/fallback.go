@@ -0,0 +1,68 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+)
+
+var _ AutocompleteRepository = (*Fallback)(nil)
+
+type cachedIndex struct {
+	stamp string
+	index *Index
+}
+
+// Fallback suggests names from an Index kept in memory, it is rebuilt from
+// the source once the stamp of the names changes.
+type Fallback struct {
+	source NameSource
+
+	mu      sync.Mutex
+	indexes map[string]*cachedIndex
+}
+
+func NewFallback(ns NameSource) *Fallback {
+	return &Fallback{
+		source:  ns,
+		indexes: make(map[string]*cachedIndex),
+	}
+}
+
+func (f *Fallback) Suggest(ctx context.Context, q *Query) ([]*Suggestion, error) {
+	const op = "autocomplete.Fallback.Suggest"
+
+	ix, err := f.index(ctx, q.Kind)
+	if err != nil {
+		log.Printf("ERROR: failed to get %s index\n", q.Kind)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ix.Match(q.Text, q.Limit), nil
+}
+
+func (f *Fallback) index(ctx context.Context, kind string) (*Index, error) {
+	stamp, err := f.source.Stamp(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// one rebuild at a time, the others wait for it instead of repeating it
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if c, ok := f.indexes[kind]; ok && c.stamp == stamp {
+		return c.index, nil
+	}
+
+	entries, err := f.source.Names(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	ix := NewIndex(entries)
+	f.indexes[kind] = &cachedIndex{stamp: stamp, index: ix}
+	log.Printf("built %s autocomplete index of %d names", kind, ix.Len())
+
+	return ix, nil
+}
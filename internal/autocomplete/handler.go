@@ -0,0 +1,53 @@
+package autocomplete
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ AutocompleteHandler = (*Handler)(nil)
+
+type Handler struct {
+	service AutocompleteService
+}
+
+func NewHandler(as AutocompleteService) *Handler {
+	return &Handler{
+		service: as,
+	}
+}
+
+func (h *Handler) Suggest(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.Suggest(r.Context(), &SuggestRequest{
+		Query: r.URL.Query().Get("q"),
+		Type:  r.URL.Query().Get("type"),
+		Limit: r.URL.Query().Get("limit"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get suggestions err=%s\n", err.Error())
+
+		var ve *util.ValidationError
+		if errors.As(err, &ve) {
+			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeValidation,
+				Body:      ve.Error(),
+			})
+			return
+		}
+		if errors.Is(err, ErrTimeout) {
+			util.ServiceUnavailable(w, r)
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	// suggestions change as films and actors do, a short cache spares
+	// repeated keystrokes
+	w.Header().Set("cache-control", "private, max-age=30")
+	util.JSON(w, r, http.StatusOK, res)
+}
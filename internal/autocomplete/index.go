@@ -0,0 +1,169 @@
+package autocomplete
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minSimilarity is the least similarity of a fuzzy match, the default of
+// strict_word_similarity_threshold in pg_trgm.
+const minSimilarity = 0.5
+
+type Entry struct {
+	ID   int32
+	Name string
+}
+
+type trigrams map[string]struct{}
+
+type indexed struct {
+	entry *Entry
+	// norm is the lower-cased name words joined by single spaces
+	norm  string
+	words []trigrams
+}
+
+// Index matches names by prefix and by trigram similarity the way pg_trgm
+// does, for the backends that lack it.
+type Index struct {
+	entries  []*indexed
+	postings map[string][]int
+}
+
+func NewIndex(entries []*Entry) *Index {
+	ix := &Index{
+		entries:  make([]*indexed, 0, len(entries)),
+		postings: make(map[string][]int),
+	}
+
+	for _, v := range entries {
+		words := splitWords(v.Name)
+		e := &indexed{
+			entry: v,
+			norm:  strings.Join(words, " "),
+			words: make([]trigrams, 0, len(words)),
+		}
+
+		seen := make(trigrams)
+		for _, w := range words {
+			t := wordTrigrams(w)
+			e.words = append(e.words, t)
+			for g := range t {
+				if _, ok := seen[g]; !ok {
+					seen[g] = struct{}{}
+					ix.postings[g] = append(ix.postings[g], len(ix.entries))
+				}
+			}
+		}
+		ix.entries = append(ix.entries, e)
+	}
+
+	return ix
+}
+
+func (ix *Index) Len() int {
+	return len(ix.entries)
+}
+
+// Match returns up to limit names starting with the text, or with a word
+// starting with it, followed by the names most similar to it.
+func (ix *Index) Match(text string, limit int) []*Suggestion {
+	words := splitWords(text)
+	res := make([]*Suggestion, 0, limit)
+	if len(words) == 0 {
+		return res
+	}
+	norm := strings.Join(words, " ")
+
+	query := make(trigrams)
+	candidates := make(map[int]struct{})
+	for _, w := range words {
+		for g := range wordTrigrams(w) {
+			query[g] = struct{}{}
+			for _, i := range ix.postings[g] {
+				candidates[i] = struct{}{}
+			}
+		}
+	}
+
+	for i := range candidates {
+		e := ix.entries[i]
+
+		score := 1.0
+		if !strings.HasPrefix(e.norm, norm) && !strings.Contains(e.norm, " "+norm) {
+			score = e.similarity(query, len(words))
+			if score < minSimilarity {
+				continue
+			}
+		}
+		res = append(res, &Suggestion{ID: e.entry.ID, Name: e.entry.Name, Score: score})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].ID < res[j].ID
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res
+}
+
+// similarity compares the query with every run of as many consecutive
+// words of the name and returns the best one.
+func (e *indexed) similarity(query trigrams, n int) float64 {
+	n = min(n, len(e.words))
+
+	best := 0.0
+	for i := 0; i+n <= len(e.words); i++ {
+		window := make(trigrams)
+		for _, w := range e.words[i : i+n] {
+			for g := range w {
+				window[g] = struct{}{}
+			}
+		}
+		best = max(best, jaccard(query, window))
+	}
+
+	return best
+}
+
+func jaccard(a, b trigrams) float64 {
+	common := 0
+	for g := range a {
+		if _, ok := b[g]; ok {
+			common++
+		}
+	}
+	if common == 0 {
+		return 0
+	}
+
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// wordTrigrams pads the word with two spaces in front and one behind,
+// as pg_trgm does.
+func wordTrigrams(word string) trigrams {
+	r := []rune("  " + word + " ")
+
+	t := make(trigrams, len(r)-2)
+	for i := 0; i+3 <= len(r); i++ {
+		t[string(r[i:i+3])] = struct{}{}
+	}
+
+	return t
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+}
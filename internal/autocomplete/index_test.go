@@ -0,0 +1,45 @@
+package autocomplete
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIndex_Match(t *testing.T) {
+	ix := NewIndex([]*Entry{
+		{ID: 1, Name: "Quentin Tarantino"},
+		{ID: 2, Name: "Квентин Тарантино"},
+		{ID: 3, Name: "Spider-Man"},
+		{ID: 4, Name: "Taran Killam"},
+	})
+
+	tests := []struct {
+		text string
+		exp  []string
+	}{
+		{"tara", []string{"Quentin Tarantino", "Taran Killam"}},
+		{"Tarantno", []string{"Quentin Tarantino", "Taran Killam"}},
+		{"тарантио", []string{"Квентин Тарантино"}},
+		{"spider man", []string{"Spider-Man"}},
+		{"man", []string{"Spider-Man"}},
+		{"xyz", []string{}},
+		{"  ", []string{}},
+	}
+	for _, v := range tests {
+		res := ix.Match(v.text, 10)
+		got := make([]string, 0, len(res))
+		for _, s := range res {
+			got = append(got, s.Name)
+		}
+		if !reflect.DeepEqual(v.exp, got) {
+			t.Errorf("Expected %+v for %q, got %+v", v.exp, v.text, got)
+		}
+	}
+
+	if res := ix.Match("tara", 10); res[0].Score != 1 {
+		t.Errorf("Expected prefix match to score 1, got %v", res[0].Score)
+	}
+	if res := ix.Match("Tarantno", 10); res[0].Score >= 1 || res[0].Score < minSimilarity {
+		t.Errorf("Expected fuzzy score in [%v, 1), got %v", minSimilarity, res[0].Score)
+	}
+}
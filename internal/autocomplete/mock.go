@@ -0,0 +1,182 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/autocomplete/autocomplete.go
+//
+// Generated by this command:
+//
+//	mockgen -self_package=github.com/Coderovshik/film-library/internal/autocomplete -package=autocomplete -source=internal/autocomplete/autocomplete.go -destination=internal/autocomplete/mock.go
+//
+
+// Package autocomplete is a generated GoMock package.
+package autocomplete
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAutocompleteRepository is a mock of AutocompleteRepository interface.
+type MockAutocompleteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAutocompleteRepositoryMockRecorder
+}
+
+// MockAutocompleteRepositoryMockRecorder is the mock recorder for MockAutocompleteRepository.
+type MockAutocompleteRepositoryMockRecorder struct {
+	mock *MockAutocompleteRepository
+}
+
+// NewMockAutocompleteRepository creates a new mock instance.
+func NewMockAutocompleteRepository(ctrl *gomock.Controller) *MockAutocompleteRepository {
+	mock := &MockAutocompleteRepository{ctrl: ctrl}
+	mock.recorder = &MockAutocompleteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAutocompleteRepository) EXPECT() *MockAutocompleteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Suggest mocks base method.
+func (m *MockAutocompleteRepository) Suggest(ctx context.Context, q *Query) ([]*Suggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suggest", ctx, q)
+	ret0, _ := ret[0].([]*Suggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suggest indicates an expected call of Suggest.
+func (mr *MockAutocompleteRepositoryMockRecorder) Suggest(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suggest", reflect.TypeOf((*MockAutocompleteRepository)(nil).Suggest), ctx, q)
+}
+
+// MockNameSource is a mock of NameSource interface.
+type MockNameSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockNameSourceMockRecorder
+}
+
+// MockNameSourceMockRecorder is the mock recorder for MockNameSource.
+type MockNameSourceMockRecorder struct {
+	mock *MockNameSource
+}
+
+// NewMockNameSource creates a new mock instance.
+func NewMockNameSource(ctrl *gomock.Controller) *MockNameSource {
+	mock := &MockNameSource{ctrl: ctrl}
+	mock.recorder = &MockNameSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNameSource) EXPECT() *MockNameSourceMockRecorder {
+	return m.recorder
+}
+
+// Names mocks base method.
+func (m *MockNameSource) Names(ctx context.Context, kind string) ([]*Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Names", ctx, kind)
+	ret0, _ := ret[0].([]*Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Names indicates an expected call of Names.
+func (mr *MockNameSourceMockRecorder) Names(ctx, kind any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Names", reflect.TypeOf((*MockNameSource)(nil).Names), ctx, kind)
+}
+
+// Stamp mocks base method.
+func (m *MockNameSource) Stamp(ctx context.Context, kind string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stamp", ctx, kind)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stamp indicates an expected call of Stamp.
+func (mr *MockNameSourceMockRecorder) Stamp(ctx, kind any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stamp", reflect.TypeOf((*MockNameSource)(nil).Stamp), ctx, kind)
+}
+
+// MockAutocompleteService is a mock of AutocompleteService interface.
+type MockAutocompleteService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAutocompleteServiceMockRecorder
+}
+
+// MockAutocompleteServiceMockRecorder is the mock recorder for MockAutocompleteService.
+type MockAutocompleteServiceMockRecorder struct {
+	mock *MockAutocompleteService
+}
+
+// NewMockAutocompleteService creates a new mock instance.
+func NewMockAutocompleteService(ctrl *gomock.Controller) *MockAutocompleteService {
+	mock := &MockAutocompleteService{ctrl: ctrl}
+	mock.recorder = &MockAutocompleteServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAutocompleteService) EXPECT() *MockAutocompleteServiceMockRecorder {
+	return m.recorder
+}
+
+// Suggest mocks base method.
+func (m *MockAutocompleteService) Suggest(ctx context.Context, req *SuggestRequest) (*SuggestResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suggest", ctx, req)
+	ret0, _ := ret[0].(*SuggestResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suggest indicates an expected call of Suggest.
+func (mr *MockAutocompleteServiceMockRecorder) Suggest(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suggest", reflect.TypeOf((*MockAutocompleteService)(nil).Suggest), ctx, req)
+}
+
+// MockAutocompleteHandler is a mock of AutocompleteHandler interface.
+type MockAutocompleteHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockAutocompleteHandlerMockRecorder
+}
+
+// MockAutocompleteHandlerMockRecorder is the mock recorder for MockAutocompleteHandler.
+type MockAutocompleteHandlerMockRecorder struct {
+	mock *MockAutocompleteHandler
+}
+
+// NewMockAutocompleteHandler creates a new mock instance.
+func NewMockAutocompleteHandler(ctrl *gomock.Controller) *MockAutocompleteHandler {
+	mock := &MockAutocompleteHandler{ctrl: ctrl}
+	mock.recorder = &MockAutocompleteHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAutocompleteHandler) EXPECT() *MockAutocompleteHandlerMockRecorder {
+	return m.recorder
+}
+
+// Suggest mocks base method.
+func (m *MockAutocompleteHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Suggest", w, r)
+}
+
+// Suggest indicates an expected call of Suggest.
+func (mr *MockAutocompleteHandlerMockRecorder) Suggest(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suggest", reflect.TypeOf((*MockAutocompleteHandler)(nil).Suggest), w, r)
+}
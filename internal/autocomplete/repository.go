@@ -0,0 +1,147 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var (
+	_ AutocompleteRepository = (*Repository)(nil)
+	_ NameSource             = (*Repository)(nil)
+)
+
+type table struct {
+	name     string
+	idColumn string
+	column   string
+}
+
+var tables = map[string]table{
+	KindFilm:  {name: "movie", idColumn: "movie_id", column: "movie_name"},
+	KindActor: {name: "actor", idColumn: "actor_id", column: "actor_name"},
+}
+
+// Repository matches names with pg_trgm on PostgreSQL, other databases
+// get the Go fallback.
+type Repository struct {
+	db       db.DBTX
+	dialect  db.Dialect
+	fallback *Fallback
+}
+
+func NewRepository(conn db.DBTX, d db.Dialect) *Repository {
+	r := &Repository{
+		db:      conn,
+		dialect: d,
+	}
+	if d != db.Postgres {
+		r.fallback = NewFallback(r)
+	}
+
+	return r
+}
+
+func (r *Repository) Suggest(ctx context.Context, q *Query) ([]*Suggestion, error) {
+	const op = "autocomplete.Repository.Suggest"
+
+	if r.fallback != nil {
+		return r.fallback.Suggest(ctx, q)
+	}
+
+	t, ok := tables[q.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown kind %q", op, q.Kind)
+	}
+
+	// a name or one of its words starting with the text scores 1, others
+	// are matched by pg_trgm strict word similarity
+	query := fmt.Sprintf(`SELECT %[2]s, %[3]s,
+			CASE WHEN %[3]s ILIKE $2 OR %[3]s ILIKE '%% ' || $2 THEN 1 ELSE strict_word_similarity($1, %[3]s) END AS score
+		FROM %[1]s
+		WHERE deleted_at IS NULL AND (%[3]s ILIKE $2 OR %[3]s ILIKE '%% ' || $2 OR $1 <<%% %[3]s)
+		ORDER BY score DESC, %[3]s, %[2]s
+		LIMIT $3`, t.name, t.idColumn, t.column)
+
+	rows, err := r.db.QueryContext(ctx, query, q.Text, util.EscapeLike(q.Text)+"%", q.Limit)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := make([]*Suggestion, 0, q.Limit)
+	for rows.Next() {
+		var s Suggestion
+		if err := rows.Scan(&s.ID, &s.Name, &s.Score); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, &s)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+// Stamp counts the names and takes the latest id and change, which moves
+// on any insert, rename, deletion or restore.
+func (r *Repository) Stamp(ctx context.Context, kind string) (string, error) {
+	const op = "autocomplete.Repository.Stamp"
+
+	t, ok := tables[kind]
+	if !ok {
+		return "", fmt.Errorf("%s: unknown kind %q", op, kind)
+	}
+
+	query := fmt.Sprintf(`SELECT COUNT(*), COALESCE(MAX(%[2]s), 0), COALESCE(CAST(MAX(updated_at) AS TEXT), '')
+		FROM %[1]s WHERE deleted_at IS NULL`, t.name, t.idColumn)
+
+	var count, maxID int64
+	var updatedAt string
+	if err := r.db.QueryRowContext(ctx, query).Scan(&count, &maxID, &updatedAt); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fmt.Sprintf("%d/%d/%s", count, maxID, updatedAt), nil
+}
+
+func (r *Repository) Names(ctx context.Context, kind string) ([]*Entry, error) {
+	const op = "autocomplete.Repository.Names"
+
+	t, ok := tables[kind]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown kind %q", op, kind)
+	}
+
+	query := fmt.Sprintf(`SELECT %[2]s, %[3]s FROM %[1]s WHERE deleted_at IS NULL`, t.name, t.idColumn, t.column)
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.ID, &e.Name); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,71 @@
+package autocomplete
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ AutocompleteService = (*Service)(nil)
+
+type Service struct {
+	repo    AutocompleteRepository
+	timeout time.Duration
+}
+
+// NewService bounds every lookup by timeout, zero disables the bound.
+func NewService(ar AutocompleteRepository, timeout time.Duration) *Service {
+	return &Service{
+		repo:    ar,
+		timeout: timeout,
+	}
+}
+
+func (s *Service) Suggest(ctx context.Context, req *SuggestRequest) (*SuggestResponse, error) {
+	const op = "autocomplete.Service.Suggest"
+
+	vErr := ValidateSuggestRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	// validated by ValidateSuggestRequest
+	limit := defaultLimit
+	if len(req.Limit) != 0 {
+		limit, _ = strconv.Atoi(req.Limit)
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	suggestions, err := s.repo.Suggest(ctx, &Query{
+		Text:  strings.TrimSpace(req.Query),
+		Kind:  req.Type,
+		Limit: limit,
+	})
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		log.Printf("ERROR: suggestions not found within %s\n", s.timeout)
+		return nil, fmt.Errorf("%s: %w", op, ErrTimeout)
+	}
+	if err != nil {
+		log.Printf("ERROR: failed to get suggestions\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := &SuggestResponse{
+		Suggestions: make([]*SuggestionResponse, 0, len(suggestions)),
+	}
+	for _, v := range suggestions {
+		res.Suggestions = append(res.Suggestions, ToSuggestionResponse(v))
+	}
+
+	return res, nil
+}
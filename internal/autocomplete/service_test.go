@@ -0,0 +1,119 @@
+package autocomplete
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestService_Suggest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockAutocompleteRepository(ctrl)
+
+	s := NewService(m, time.Second)
+
+	suggestions := []*Suggestion{{ID: 1, Name: "Quentin Tarantino", Score: 0.6}}
+	m.EXPECT().
+		Suggest(gomock.Any(), &Query{Text: "Tarantno", Kind: KindActor, Limit: defaultLimit}).
+		DoAndReturn(func(ctx context.Context, q *Query) ([]*Suggestion, error) {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Errorf("Expected lookup bound by a deadline")
+			}
+			return suggestions, nil
+		}).
+		Times(1)
+
+	exp := &SuggestResponse{Suggestions: []*SuggestionResponse{{ID: 1, Name: "Quentin Tarantino"}}}
+	res, err := s.Suggest(context.TODO(), &SuggestRequest{Query: " Tarantno", Type: KindActor})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+}
+
+func TestService_SuggestTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockAutocompleteRepository(ctrl)
+
+	s := NewService(m, time.Millisecond)
+
+	m.EXPECT().
+		Suggest(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, q *Query) ([]*Suggestion, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}).
+		Times(1)
+
+	_, err := s.Suggest(context.TODO(), &SuggestRequest{Query: "pulp", Type: KindFilm, Limit: "3"})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected %v, got %v", ErrTimeout, err)
+	}
+}
+
+func TestService_SuggestInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockAutocompleteRepository(ctrl)
+
+	s := NewService(m, time.Second)
+
+	tests := []*SuggestRequest{
+		{Query: "", Type: KindFilm},
+		{Query: "pulp"},
+		{Query: "pulp", Type: "director"},
+		{Query: "pulp", Type: KindFilm, Limit: "21"},
+		{Query: strings.Repeat("a", maxQueryLength+1), Type: KindFilm},
+	}
+	for _, v := range tests {
+		_, err := s.Suggest(context.TODO(), v)
+		var ve *util.ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("Expected validation error for %+v, got %v", v, err)
+		}
+	}
+}
+
+func TestFallback_Suggest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockNameSource(ctrl)
+
+	f := NewFallback(m)
+	q := &Query{Text: "pulp", Kind: KindFilm, Limit: 5}
+
+	// the index is built once per stamp
+	gomock.InOrder(
+		m.EXPECT().Stamp(gomock.Any(), KindFilm).Return("1", nil).Times(2),
+		m.EXPECT().Stamp(gomock.Any(), KindFilm).Return("2", nil).Times(1),
+	)
+	gomock.InOrder(
+		m.EXPECT().Names(gomock.Any(), KindFilm).Return([]*Entry{{ID: 1, Name: "Pulp Fiction"}}, nil).Times(1),
+		m.EXPECT().Names(gomock.Any(), KindFilm).Return([]*Entry{}, nil).Times(1),
+	)
+
+	exp := []*Suggestion{{ID: 1, Name: "Pulp Fiction", Score: 1}}
+	for range 2 {
+		res, err := f.Suggest(context.TODO(), q)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		if !reflect.DeepEqual(exp, res) {
+			t.Errorf("Expected %+v, got %+v", exp, res)
+		}
+	}
+
+	res, err := f.Suggest(context.TODO(), q)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(res) != 0 {
+		t.Errorf("Expected no suggestions from the rebuilt index, got %+v", res)
+	}
+}
@@ -0,0 +1,35 @@
+package autocomplete
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+func ValidateSuggestRequest(req *SuggestRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(strings.TrimSpace(req.Query)) == 0 {
+		ve.AddViolation("empty query")
+	}
+	if utf8.RuneCountInString(req.Query) > maxQueryLength {
+		ve.AddViolation(fmt.Sprintf("query is too long, expected at most %d characters", maxQueryLength))
+	}
+
+	if _, ok := tables[req.Type]; !ok {
+		ve.AddViolation(fmt.Sprintf("incorrect type, expected one of: %s, %s", KindFilm, KindActor))
+	}
+
+	if n, err := strconv.Atoi(req.Limit); len(req.Limit) != 0 && (err != nil || n < 1 || n > maxLimit) {
+		ve.AddViolation(fmt.Sprintf("incorrect limit, expected integer from 1 to %d", maxLimit))
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
@@ -0,0 +1,108 @@
+// Package batch runs an ordered list of film and actor operations in a
+// single request. An operation can use the results of earlier ones with
+// {"$ref": "<operation id>.<path>"} anywhere in its body.
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+const maxOperations = 100
+
+const (
+	OpCreateActor  = "createActor"
+	OpUpdateActor  = "updateActor"
+	OpDeleteActor  = "deleteActor"
+	OpCreateFilm   = "createFilm"
+	OpUpdateFilm   = "updateFilm"
+	OpDeleteFilm   = "deleteFilm"
+	OpBindActors   = "bindActors"
+	OpUnbindActors = "unbindActors"
+)
+
+var ops = []string{
+	OpCreateActor, OpUpdateActor, OpDeleteActor,
+	OpCreateFilm, OpUpdateFilm, OpDeleteFilm,
+	OpBindActors, OpUnbindActors,
+}
+
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	// StatusRolledBack is an operation that succeeded, but was discarded
+	// because a later one failed.
+	StatusRolledBack = "rolledBack"
+	// StatusSkipped is an operation that was not run.
+	StatusSkipped = "skipped"
+)
+
+type BatchService interface {
+	Execute(ctx context.Context, req *BatchRequest) (*BatchResponse, error)
+}
+
+type BatchHandler interface {
+	Execute(w http.ResponseWriter, r *http.Request)
+}
+
+// BatchRequest runs in one transaction, unless ContinueOnError is set.
+// Then every operation is committed on its own and a failed one only
+// fails the operations referencing it.
+type BatchRequest struct {
+	Operations      []*Operation `json:"operations"`
+	ContinueOnError bool         `json:"continueOnError"`
+}
+
+// Operation is identified by ID, which defaults to op<N> for the N-th
+// operation starting from 1.
+type Operation struct {
+	ID   string          `json:"id"`
+	Op   string          `json:"op"`
+	Body json.RawMessage `json:"body"`
+}
+
+// TargetBody selects the entity to delete. IfMatch is required as the
+// If-Match header of the endpoint is, "*" matches any version, so that
+// entities created by the batch can be changed.
+type TargetBody struct {
+	ID      int    `json:"id"`
+	IfMatch string `json:"ifMatch"`
+}
+
+type ActorUpdateBody struct {
+	ID      int             `json:"id"`
+	IfMatch string          `json:"ifMatch"`
+	Info    actor.ActorInfo `json:"info"`
+}
+
+type FilmUpdateBody struct {
+	ID      int           `json:"id"`
+	IfMatch string        `json:"ifMatch"`
+	Info    film.FilmInfo `json:"info"`
+}
+
+type FilmActorsBody struct {
+	ID       int   `json:"id"`
+	ActorIDs []int `json:"actorIds"`
+}
+
+type BatchResponse struct {
+	Committed bool               `json:"committed"`
+	Results   []*OperationResult `json:"results"`
+}
+
+// OperationResult holds the response the operation would get from its
+// own endpoint, Code is its status code.
+type OperationResult struct {
+	ID     string             `json:"id"`
+	Op     string             `json:"op"`
+	Status string             `json:"status"`
+	Code   int                `json:"code,omitempty"`
+	Result any                `json:"result,omitempty"`
+	Error  *util.ErrorMessage `json:"error,omitempty"`
+}
@@ -0,0 +1,30 @@
+package batch
+
+import (
+	"context"
+	"log"
+
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+var _ film.EventPublisher = (*pending)(nil)
+
+// pending holds the film events of a transaction, they are published
+// only once it is committed.
+type pending struct {
+	events []*film.Event
+}
+
+func (p *pending) Publish(ctx context.Context, e *film.Event) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+func (p *pending) flush(ctx context.Context, ep film.EventPublisher) {
+	for _, v := range p.events {
+		if err := ep.Publish(ctx, v); err != nil {
+			log.Printf("ERROR: failed to publish film %s err=%s\n", v.Type, err.Error())
+		}
+	}
+	p.events = nil
+}
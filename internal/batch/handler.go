@@ -0,0 +1,47 @@
+package batch
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ BatchHandler = (*Handler)(nil)
+
+type Handler struct {
+	service BatchService
+}
+
+func NewHandler(bs BatchService) *Handler {
+	return &Handler{
+		service: bs,
+	}
+}
+
+func (h *Handler) Execute(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if ok := util.BindJSON(w, r, &req); !ok {
+		return
+	}
+
+	res, err := h.service.Execute(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to execute batch err=%s\n", err.Error())
+
+		var ve *util.ValidationError
+		if errors.As(err, &ve) {
+			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeValidation,
+				Body:      ve.Error(),
+			})
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,162 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const refKey = "$ref"
+
+// lookupError is a reference to a value missing from the result.
+type lookupError struct {
+	ref string
+	msg string
+}
+
+func (e *lookupError) Error() string {
+	return fmt.Sprintf("reference %q: %s", e.ref, e.msg)
+}
+
+// decode reads a JSON document keeping numbers exact.
+func decode(data []byte) (any, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// toValue gives the result as decode would read it from the response.
+func toValue(result any) (any, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(data)
+}
+
+// asRef reports whether v is a reference, an object with $ref only.
+func asRef(v any) (string, bool) {
+	obj, ok := v.(map[string]any)
+	if !ok || len(obj) != 1 {
+		return "", false
+	}
+
+	ref, ok := obj[refKey].(string)
+
+	return ref, ok
+}
+
+// splitRef splits a reference into the operation id and the path within
+// its result, an empty path refers to the whole result.
+func splitRef(ref string) (string, []string) {
+	id, path, found := strings.Cut(ref, ".")
+	if !found {
+		return id, nil
+	}
+
+	return id, strings.Split(path, ".")
+}
+
+// refs returns the operation ids referenced by the body.
+func refs(body any) []string {
+	var ids []string
+
+	var walk func(v any)
+	walk = func(v any) {
+		if ref, ok := asRef(v); ok {
+			id, _ := splitRef(ref)
+			ids = append(ids, id)
+			return
+		}
+
+		switch v := v.(type) {
+		case map[string]any:
+			for _, e := range v {
+				walk(e)
+			}
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(body)
+
+	return ids
+}
+
+// resolve replaces the references in the body with the values they point
+// to in the results of earlier operations.
+func resolve(body any, results map[string]any) (any, error) {
+	if ref, ok := asRef(body); ok {
+		id, path := splitRef(ref)
+		res, ok := results[id]
+		if !ok {
+			return nil, fmt.Errorf("reference %q: %w", ref, errNoResult)
+		}
+
+		return lookup(ref, res, path)
+	}
+
+	switch v := body.(type) {
+	case map[string]any:
+		obj := make(map[string]any, len(v))
+		for k, e := range v {
+			r, err := resolve(e, results)
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = r
+		}
+		return obj, nil
+	case []any:
+		arr := make([]any, 0, len(v))
+		for _, e := range v {
+			r, err := resolve(e, results)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, r)
+		}
+		return arr, nil
+	}
+
+	return body, nil
+}
+
+// lookup follows the path through objects by key and arrays by index.
+func lookup(ref string, v any, path []string) (any, error) {
+	for _, p := range path {
+		switch e := v.(type) {
+		case map[string]any:
+			next, ok := e[p]
+			if !ok {
+				return nil, &lookupError{ref, fmt.Sprintf("no field %q", p)}
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(p)
+			if err != nil || i < 0 || i >= len(e) {
+				return nil, &lookupError{ref, fmt.Sprintf("no element %q", p)}
+			}
+			v = e[i]
+		default:
+			return nil, &lookupError{ref, fmt.Sprintf("no %q in a scalar", p)}
+		}
+	}
+
+	return v, nil
+}
@@ -0,0 +1,50 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	results := map[string]any{}
+	var err error
+	results["op1"], err = toValue(map[string]any{"id": 7, "actors": []map[string]any{{"id": 3}}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	body, _ := decode([]byte(`{"id": {"$ref": "op1.id"}, "actorIds": [1, {"$ref": "op1.actors.0.id"}], "all": {"$ref": "op1"}}`))
+
+	if ids := refs(body); !slices.Equal(ids, []string{"op1", "op1", "op1"}) {
+		t.Errorf("Expected %v, got %v", []string{"op1", "op1", "op1"}, ids)
+	}
+
+	got, err := resolve(body, results)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := map[string]any{
+		"id":       json.Number("7"),
+		"actorIds": []any{json.Number("1"), json.Number("3")},
+		"all":      results["op1"],
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+
+	for _, v := range []string{`{"$ref": "op1.name"}`, `{"$ref": "op1.actors.1"}`, `{"$ref": "op1.id.x"}`} {
+		body, _ := decode([]byte(v))
+		var le *lookupError
+		if _, err := resolve(body, results); !errors.As(err, &le) {
+			t.Errorf("Expected lookup error for %s, got %v", v, err)
+		}
+	}
+
+	body, _ = decode([]byte(`{"$ref": "op2.id"}`))
+	if _, err := resolve(body, results); !errors.Is(err, errNoResult) {
+		t.Errorf("Expected %v, got %v", errNoResult, err)
+	}
+}
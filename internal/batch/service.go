@@ -0,0 +1,322 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/importer"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var (
+	// errRollback discards the operations run so far.
+	errRollback = errors.New("rollback")
+	// errNoResult is a reference to an operation that failed.
+	errNoResult = errors.New("referenced operation did not succeed")
+	// errIfMatchRequired is an update or a delete without ifMatch.
+	errIfMatchRequired = errors.New("ifMatch is required")
+)
+
+var _ BatchService = (*Service)(nil)
+
+type Service struct {
+	tx     importer.Transactor
+	events film.EventPublisher
+}
+
+func NewService(t importer.Transactor, ep film.EventPublisher) *Service {
+	return &Service{
+		tx:     t,
+		events: ep,
+	}
+}
+
+func (s *Service) Execute(ctx context.Context, req *BatchRequest) (*BatchResponse, error) {
+	const op = "batch.Service.Execute"
+
+	for i, v := range req.Operations {
+		if v != nil && len(v.ID) == 0 {
+			v.ID = fmt.Sprintf("op%d", i+1)
+		}
+	}
+
+	vErr := ValidateBatchRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	res := &BatchResponse{
+		Results: make([]*OperationResult, 0, len(req.Operations)),
+	}
+	for _, v := range req.Operations {
+		res.Results = append(res.Results, &OperationResult{ID: v.ID, Op: v.Op, Status: StatusSkipped})
+	}
+
+	var err error
+	if req.ContinueOnError {
+		err = s.executeEach(ctx, req.Operations, res)
+	} else {
+		err = s.executeAll(ctx, req.Operations, res)
+	}
+	if err != nil {
+		log.Printf("ERROR: failed to execute batch\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+// executeAll runs the operations in one transaction, which is rolled back
+// on the first failure.
+func (s *Service) executeAll(ctx context.Context, operations []*Operation, res *BatchResponse) error {
+	events := &pending{}
+	results := make(map[string]any, len(operations))
+
+	err := s.tx.Atomic(ctx, func(fr film.FilmRepository, ar actor.ActorRepository, _ audit.Recorder) error {
+		e := newExecutor(fr, ar, events)
+		for i, v := range operations {
+			if !e.execute(ctx, v, res.Results[i], results) {
+				return errRollback
+			}
+		}
+
+		return nil
+	})
+	if errors.Is(err, errRollback) {
+		for _, v := range res.Results {
+			if v.Status == StatusSucceeded {
+				v.Status, v.Result = StatusRolledBack, nil
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	res.Committed = true
+	events.flush(ctx, s.events)
+
+	return nil
+}
+
+// executeEach commits every operation on its own.
+func (s *Service) executeEach(ctx context.Context, operations []*Operation, res *BatchResponse) error {
+	results := make(map[string]any, len(operations))
+
+	for i, v := range operations {
+		events := &pending{}
+		r := res.Results[i]
+
+		err := s.tx.Atomic(ctx, func(fr film.FilmRepository, ar actor.ActorRepository, _ audit.Recorder) error {
+			if !newExecutor(fr, ar, events).execute(ctx, v, r, results) {
+				return errRollback
+			}
+
+			return nil
+		})
+		if errors.Is(err, errRollback) {
+			continue
+		}
+		if err != nil {
+			log.Printf("ERROR: failed to commit operation %q err=%s\n", v.ID, err.Error())
+			r.Status, r.Code, r.Result = StatusFailed, http.StatusInternalServerError, nil
+			delete(results, v.ID)
+			continue
+		}
+
+		res.Committed = true
+		events.flush(ctx, s.events)
+	}
+
+	return nil
+}
+
+// executor runs operations with services bound to a transaction, their
+// audit entries are recorded in it as well.
+type executor struct {
+	films  film.FilmService
+	actors actor.ActorService
+}
+
+func newExecutor(fr film.FilmRepository, ar actor.ActorRepository, events *pending) *executor {
+	return &executor{
+		films:  film.NewService(fr, events),
+		actors: actor.NewService(ar),
+	}
+}
+
+// execute sets the outcome of the operation and reports whether it
+// succeeded, results gets the result of a successful one.
+func (e *executor) execute(ctx context.Context, op *Operation, r *OperationResult, results map[string]any) bool {
+	out, err := e.run(ctx, op, results)
+	if err != nil {
+		log.Printf("ERROR: failed to execute operation %q err=%s\n", op.ID, err.Error())
+		r.Status = StatusFailed
+		r.Code, r.Error = failure(err)
+		return false
+	}
+
+	value, err := toValue(out)
+	if err != nil {
+		log.Printf("ERROR: failed to convert result of operation %q err=%s\n", op.ID, err.Error())
+		r.Status, r.Code = StatusFailed, http.StatusInternalServerError
+		return false
+	}
+	results[op.ID] = value
+	r.Status, r.Code, r.Result = StatusSucceeded, http.StatusOK, out
+
+	return true
+}
+
+func (e *executor) run(ctx context.Context, op *Operation, results map[string]any) (any, error) {
+	// validated by ValidateBatchRequest
+	body, _ := decode(op.Body)
+
+	body, err := resolve(body, results)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case OpCreateActor:
+		var b actor.ActorInfo
+		if err := bindBody(data, &b); err != nil {
+			return nil, err
+		}
+		return e.actors.AddActor(ctx, &b)
+	case OpUpdateActor:
+		var b ActorUpdateBody
+		if err := bindBody(data, &b); err != nil {
+			return nil, err
+		}
+		if len(b.IfMatch) == 0 {
+			return nil, errIfMatchRequired
+		}
+		return e.actors.UpdateActor(ctx, &actor.ActorIdInfoRequest{ID: strconv.Itoa(b.ID), IfMatch: b.IfMatch, Info: b.Info})
+	case OpDeleteActor:
+		var b TargetBody
+		if err := bindBody(data, &b); err != nil {
+			return nil, err
+		}
+		if len(b.IfMatch) == 0 {
+			return nil, errIfMatchRequired
+		}
+		return e.actors.DeleteActor(ctx, &actor.ActorIdRequest{ID: strconv.Itoa(b.ID), IfMatch: b.IfMatch})
+	case OpCreateFilm:
+		var b film.AddFilmRequest
+		if err := bindBody(data, &b); err != nil {
+			return nil, err
+		}
+		return e.films.AddFilm(ctx, &b)
+	case OpUpdateFilm:
+		var b FilmUpdateBody
+		if err := bindBody(data, &b); err != nil {
+			return nil, err
+		}
+		if len(b.IfMatch) == 0 {
+			return nil, errIfMatchRequired
+		}
+		return e.films.UpdateFilm(ctx, &film.FilmIdInfoRequest{ID: strconv.Itoa(b.ID), IfMatch: b.IfMatch, Info: b.Info})
+	case OpDeleteFilm:
+		var b TargetBody
+		if err := bindBody(data, &b); err != nil {
+			return nil, err
+		}
+		if len(b.IfMatch) == 0 {
+			return nil, errIfMatchRequired
+		}
+		return e.films.DeleteFilm(ctx, &film.FilmIdRequest{ID: strconv.Itoa(b.ID), IfMatch: b.IfMatch})
+	case OpBindActors:
+		var b FilmActorsBody
+		if err := bindBody(data, &b); err != nil {
+			return nil, err
+		}
+		return e.films.AddFilmActors(ctx, &film.FilmActorsRequest{ID: strconv.Itoa(b.ID), ActorIDs: b.ActorIDs})
+	case OpUnbindActors:
+		var b FilmActorsBody
+		if err := bindBody(data, &b); err != nil {
+			return nil, err
+		}
+		return e.films.DeleteFilmActors(ctx, &film.FilmActorsRequest{ID: strconv.Itoa(b.ID), ActorIDs: b.ActorIDs})
+	}
+
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// bindBody decodes the resolved body, unknown fields are rejected.
+func bindBody(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		ve := &util.ValidationError{}
+		ve.AddViolation(fmt.Sprintf("invalid body: %s", err.Error()))
+		return ve
+	}
+
+	return nil
+}
+
+// failure gives the status code and message the endpoint of the
+// operation would respond with.
+func failure(err error) (int, *util.ErrorMessage) {
+	var ve *util.ValidationError
+	var le *lookupError
+
+	switch {
+	case errors.As(err, &ve):
+		return http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		}
+	case errors.As(err, &le):
+		return http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      le.Error(),
+		}
+	case errors.Is(err, errNoResult):
+		return http.StatusFailedDependency, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeConflict,
+			Body:      err.Error(),
+		}
+	case errors.Is(err, film.ErrEmptyUpdate), errors.Is(err, actor.ErrEmptyUpdate):
+		return http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      "empty update",
+		}
+	case errors.Is(err, film.ErrFilmActorExist):
+		return http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeConflict,
+			Body:      "one of the provided actors is already bound to the film",
+		}
+	case errors.Is(err, film.ErrActorNotExist):
+		return http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeConflict,
+			Body:      "one of the provided actors is non-existent",
+		}
+	case errors.Is(err, film.ErrIdInvalid), errors.Is(err, film.ErrFilmNotExist), errors.Is(err, film.ErrZeroActors),
+		errors.Is(err, actor.ErrIdInvalid), errors.Is(err, actor.ErrActorNotExist):
+		return http.StatusNotFound, nil
+	case errors.Is(err, errIfMatchRequired):
+		return http.StatusPreconditionRequired, nil
+	case errors.Is(err, film.ErrVersionMismatch), errors.Is(err, actor.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, nil
+	}
+
+	return http.StatusInternalServerError, nil
+}
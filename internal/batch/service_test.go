@@ -0,0 +1,261 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/memory"
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func operation(id, op, body string) *Operation {
+	return &Operation{ID: id, Op: op, Body: json.RawMessage(body)}
+}
+
+func statuses(res *BatchResponse) []string {
+	s := make([]string, 0, len(res.Results))
+	for _, v := range res.Results {
+		s = append(s, v.Status)
+	}
+
+	return s
+}
+
+func getFilms(t *testing.T, s *memory.Store) []*film.Film {
+	t.Helper()
+
+	films, err := memory.NewFilmRepository(s).GetFilms(context.TODO(), &film.Query{})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	return films
+}
+
+// getChanges returns the audit entries of the store as entity:action,
+// oldest first.
+func getChanges(t *testing.T, s *memory.Store) []string {
+	t.Helper()
+
+	entries, err := memory.NewAuditRepository(s).GetEntries(context.TODO(), &audit.Query{Limit: 100})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	changes := make([]string, 0, len(entries))
+	for _, v := range entries {
+		changes = append(changes, v.Entity+":"+v.Action)
+	}
+	slices.Reverse(changes)
+
+	return changes
+}
+
+func TestService_Execute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ep := film.NewMockEventPublisher(ctrl)
+	store := memory.NewStore()
+
+	s := NewService(store, ep)
+
+	// the film is added with actor1 and bound to actor2 later
+	var events []*film.Event
+	ep.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, e *film.Event) error {
+		events = append(events, e)
+		return nil
+	}).Times(2)
+
+	res, err := s.Execute(context.TODO(), &BatchRequest{
+		Operations: []*Operation{
+			operation("", OpCreateActor, `{"name": "actor1", "sex": "male", "birthday": "1980-01-01"}`),
+			operation("film", OpCreateFilm, `{"info": {"name": "film1", "description": "about", "releasedate": "2000-01-12", "rating": 5}, "actorIds": [{"$ref": "op1.id"}]}`),
+			operation("", OpCreateActor, `{"name": "actor2", "sex": "female", "birthday": "1985-01-01"}`),
+			operation("", OpBindActors, `{"id": {"$ref": "film.id"}, "actorIds": [{"$ref": "op3.id"}]}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	exp := []string{StatusSucceeded, StatusSucceeded, StatusSucceeded, StatusSucceeded}
+	if !res.Committed || !slices.Equal(statuses(res), exp) {
+		t.Errorf("Expected committed %v, got %+v", exp, res)
+	}
+	if res.Results[3].ID != "op4" || res.Results[3].Code != http.StatusOK {
+		t.Errorf("Unexpected result %+v", res.Results[3])
+	}
+
+	films := getFilms(t, store)
+	if len(films) != 1 || !slices.Equal(films[0].Actors, []string{"actor1", "actor2"}) {
+		t.Errorf("Expected film1 with actor1 and actor2, got %+v", films)
+	}
+	for i, v := range events {
+		if v.Type != film.EventActorsBound || v.FilmID != films[0].ID || !slices.Equal(v.ActorIDs, []int32{int32(i + 1)}) {
+			t.Errorf("Expected actor%d bound to film1, got %+v", i+1, v)
+		}
+	}
+	expChanges := []string{"actor:create", "film:create", "actor:create", "film:bind"}
+	if changes := getChanges(t, store); !slices.Equal(changes, expChanges) {
+		t.Errorf("Expected %v, got %v", expChanges, changes)
+	}
+}
+
+func TestService_Execute_Rollback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ep := film.NewMockEventPublisher(ctrl)
+	store := memory.NewStore()
+
+	s := NewService(store, ep)
+
+	res, err := s.Execute(context.TODO(), &BatchRequest{
+		Operations: []*Operation{
+			operation("", OpCreateActor, `{"name": "actor1", "sex": "male", "birthday": "1980-01-01"}`),
+			operation("", OpCreateFilm, `{"info": {"name": "film1", "description": "about", "releasedate": "2000-01-12", "rating": 5}, "actorIds": [{"$ref": "op1.id"}, 999]}`),
+			operation("", OpDeleteActor, `{"id": {"$ref": "op1.id"}}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	exp := []string{StatusRolledBack, StatusFailed, StatusSkipped}
+	if res.Committed || !slices.Equal(statuses(res), exp) {
+		t.Errorf("Expected %v, got %+v", exp, res)
+	}
+	if r := res.Results[1]; r.Code != http.StatusBadRequest || r.Error == nil || r.Error.ErrorType != util.ErrorTypeConflict {
+		t.Errorf("Expected conflict, got %+v", r)
+	}
+	if res.Results[0].Result != nil {
+		t.Errorf("Expected no result of rolled back operation, got %+v", res.Results[0].Result)
+	}
+
+	actors, _ := memory.NewActorRepository(store).GetActors(context.TODO())
+	if len(actors) != 0 || len(getFilms(t, store)) != 0 {
+		t.Errorf("Expected empty store, got %+v", actors)
+	}
+	if changes := getChanges(t, store); len(changes) != 0 {
+		t.Errorf("Expected no audit entries, got %v", changes)
+	}
+}
+
+func TestService_Execute_ContinueOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ep := film.NewMockEventPublisher(ctrl)
+	store := memory.NewStore()
+
+	s := NewService(store, ep)
+
+	res, err := s.Execute(context.TODO(), &BatchRequest{
+		ContinueOnError: true,
+		Operations: []*Operation{
+			operation("", OpCreateActor, `{"name": "", "sex": "male", "birthday": "1980-01-01"}`),
+			operation("", OpCreateFilm, `{"info": {"name": "film1", "description": "about", "releasedate": "2000-01-12", "rating": 5}, "actorIds": [{"$ref": "op1.id"}]}`),
+			operation("", OpCreateActor, `{"name": "actor2", "sex": "female", "birthday": "1985-01-01"}`),
+			operation("", OpUpdateFilm, `{"id": {"$ref": "op3.name"}}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	exp := []string{StatusFailed, StatusFailed, StatusSucceeded, StatusFailed}
+	if !res.Committed || !slices.Equal(statuses(res), exp) {
+		t.Errorf("Expected committed %v, got %+v", exp, res)
+	}
+	codes := []int{res.Results[0].Code, res.Results[1].Code, res.Results[2].Code, res.Results[3].Code}
+	if expCodes := []int{http.StatusBadRequest, http.StatusFailedDependency, http.StatusOK, http.StatusBadRequest}; !slices.Equal(codes, expCodes) {
+		t.Errorf("Expected %v, got %v", expCodes, codes)
+	}
+
+	actors, _ := memory.NewActorRepository(store).GetActors(context.TODO())
+	if len(actors) != 1 || actors[0].Name != "actor2" {
+		t.Errorf("Expected actor2, got %+v", actors)
+	}
+	if changes := getChanges(t, store); !slices.Equal(changes, []string{"actor:create"}) {
+		t.Errorf("Expected the entry of actor2, got %v", changes)
+	}
+}
+
+func TestService_Execute_IfMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ep := film.NewMockEventPublisher(ctrl)
+	store := memory.NewStore()
+
+	s := NewService(store, ep)
+
+	birthday, _ := time.Parse(time.DateOnly, "1980-01-01")
+	a, err := memory.NewActorRepository(store).AddActor(context.TODO(), &actor.Actor{Name: "actor1", Sex: "male", Birthday: birthday})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	etag, _ := json.Marshal(actor.ToActorResponse(a).ETag)
+
+	// updates and deletes fail without ifMatch or with a stale one, "*"
+	// matches the actors created by the batch
+	res, err := s.Execute(context.TODO(), &BatchRequest{
+		ContinueOnError: true,
+		Operations: []*Operation{
+			operation("", OpUpdateActor, `{"id": 1, "info": {"name": "renamed", "sex": "male", "birthday": "1980-01-01"}}`),
+			operation("", OpDeleteActor, `{"id": 1}`),
+			operation("", OpDeleteActor, `{"id": 1, "ifMatch": "\"9-0000000000000000\""}`),
+			operation("", OpUpdateActor, `{"id": 1, "ifMatch": `+string(etag)+`, "info": {"name": "renamed", "sex": "male", "birthday": "1980-01-01"}}`),
+			operation("", OpCreateActor, `{"name": "actor2", "sex": "female", "birthday": "1985-01-01"}`),
+			operation("", OpDeleteActor, `{"id": {"$ref": "op5.id"}, "ifMatch": "*"}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	codes := make([]int, 0, len(res.Results))
+	for _, v := range res.Results {
+		codes = append(codes, v.Code)
+	}
+	exp := []int{http.StatusPreconditionRequired, http.StatusPreconditionRequired, http.StatusPreconditionFailed, http.StatusOK, http.StatusOK, http.StatusOK}
+	if !slices.Equal(codes, exp) {
+		t.Errorf("Expected %v, got %v", exp, codes)
+	}
+
+	actors, _ := memory.NewActorRepository(store).GetActors(context.TODO())
+	if len(actors) != 1 || actors[0].Name != "renamed" {
+		t.Errorf("Expected renamed actor1 only, got %+v", actors)
+	}
+}
+
+func TestService_Execute_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ep := film.NewMockEventPublisher(ctrl)
+
+	s := NewService(memory.NewStore(), ep)
+
+	tests := []*BatchRequest{
+		{},
+		{Operations: []*Operation{operation("", "createUser", `{}`)}},
+		{Operations: []*Operation{
+			operation("a", OpCreateActor, `{}`),
+			operation("a", OpCreateActor, `{}`),
+		}},
+		{Operations: []*Operation{
+			operation("", OpDeleteActor, `{"id": {"$ref": "op2.id"}}`),
+			operation("", OpCreateActor, `{}`),
+		}},
+		{Operations: []*Operation{operation("", OpCreateActor, `{`)}},
+	}
+
+	for i, v := range tests {
+		_, err := s.Execute(context.TODO(), v)
+		var ve *util.ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("Test %d: expected validation error, got %v", i, err)
+		}
+	}
+}
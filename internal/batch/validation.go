@@ -0,0 +1,59 @@
+package batch
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+// ValidateBatchRequest checks the operations before any of them is run,
+// a reference must name an earlier operation.
+func ValidateBatchRequest(req *BatchRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(req.Operations) == 0 {
+		ve.AddViolation("no operations provided")
+	}
+	if len(req.Operations) > maxOperations {
+		ve.AddViolation(fmt.Sprintf("too many operations, at most %d are allowed", maxOperations))
+	}
+
+	seen := make(map[string]bool, len(req.Operations))
+	for i, v := range req.Operations {
+		if v == nil {
+			ve.AddViolation(fmt.Sprintf("operations[%d]: operation empty", i))
+			continue
+		}
+
+		if strings.Contains(v.ID, ".") {
+			ve.AddViolation(fmt.Sprintf("operations[%d]: id %q contains a dot", i, v.ID))
+		}
+		if seen[v.ID] {
+			ve.AddViolation(fmt.Sprintf("operations[%d]: duplicate id %q", i, v.ID))
+		}
+
+		if !slices.Contains(ops, v.Op) {
+			ve.AddViolation(fmt.Sprintf("operations[%d]: incorrect op %q, expected one of: %s", i, v.Op, strings.Join(ops, ", ")))
+		}
+
+		body, err := decode(v.Body)
+		if err != nil {
+			ve.AddViolation(fmt.Sprintf("operations[%d]: invalid body", i))
+		}
+		for _, ref := range refs(body) {
+			if !seen[ref] {
+				ve.AddViolation(fmt.Sprintf("operations[%d]: reference to %q, which is not an earlier operation", i, ref))
+			}
+		}
+
+		seen[v.ID] = true
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
@@ -0,0 +1,211 @@
+// Package collection manages the film lists of users: the watchlist,
+// the favorites and custom lists, with ordered entries and notes. Lists
+// are private, unlisted or public and can be shared read-only by token.
+package collection
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+const (
+	KindWatchlist = "watchlist"
+	KindFavorites = "favorites"
+	KindCustom    = "custom"
+
+	VisibilityPrivate  = "private"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPublic   = "public"
+
+	maxNameLength        = 100
+	maxDescriptionLength = 1000
+	maxNoteLength        = 500
+	shareTokenBytes      = 16
+)
+
+var (
+	ErrIdInvalid          = errors.New("invalid id")
+	ErrCollectionNotExist = errors.New("list does not exist")
+	ErrNameTaken          = errors.New("list with the same name already exists")
+	ErrKindTaken          = errors.New("list of the same kind already exists")
+	ErrFilmNotExist       = errors.New("film does not exist")
+	ErrEntryNotExist      = errors.New("film is not in the list")
+	ErrEmptyUpdate        = errors.New("no updates to apply")
+)
+
+type Collection struct {
+	ID          int32
+	UserID      int32
+	Name        string
+	Description string
+	Kind        string
+	Visibility  string
+	ShareToken  string
+	FilmCount   int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Entry is a film of a list, Position counts from 1. Films in the trash
+// stay in lists and are not Available until restored.
+type Entry struct {
+	CollectionID int32
+	FilmID       int32
+	Position     int
+	Note         string
+	AddedAt      time.Time
+	FilmName     string
+	ReleaseDate  time.Time
+	Rating       int32
+	Available    bool
+}
+
+type CollectionUpdate struct {
+	ID          int32
+	Name        *string
+	Description *string
+	Visibility  *string
+}
+
+// Query selects lists, zero fields match any.
+type Query struct {
+	UserID     int32
+	Visibility string
+}
+
+type CollectionRepository interface {
+	// GetCollections returns the lists oldest first.
+	GetCollections(ctx context.Context, q *Query) ([]*Collection, error)
+	GetCollection(ctx context.Context, id int32) (*Collection, error)
+	GetCollectionByToken(ctx context.Context, token string) (*Collection, error)
+	AddCollection(ctx context.Context, c *Collection) (*Collection, error)
+	UpdateCollection(ctx context.Context, cu *CollectionUpdate) error
+	DeleteCollection(ctx context.Context, id int32) error
+	// GetEntries returns the films of the list in order.
+	GetEntries(ctx context.Context, id int32) ([]*Entry, error)
+	// PutEntry adds the film at the position or moves it there and sets
+	// its note. Position 0 appends a new film and keeps an existing one
+	// in place, positions past the end append.
+	PutEntry(ctx context.Context, e *Entry) error
+	DeleteEntry(ctx context.Context, id int32, filmID int32) error
+}
+
+type CollectionService interface {
+	GetCollections(ctx context.Context, req *UserRequest) ([]*CollectionResponse, error)
+	GetPublicCollections(ctx context.Context) ([]*CollectionResponse, error)
+	GetSharedCollection(ctx context.Context, req *SharedRequest) (*CollectionEntriesResponse, error)
+	AddCollection(ctx context.Context, req *AddCollectionRequest) (*CollectionResponse, error)
+	GetCollection(ctx context.Context, req *CollectionIdRequest) (*CollectionEntriesResponse, error)
+	PatchCollection(ctx context.Context, req *CollectionPatchRequest) (*CollectionResponse, error)
+	DeleteCollection(ctx context.Context, req *CollectionIdRequest) (*CollectionResponse, error)
+	PutEntry(ctx context.Context, req *PutEntryRequest) (*CollectionEntriesResponse, error)
+	DeleteEntry(ctx context.Context, req *EntryIdRequest) (*CollectionEntriesResponse, error)
+}
+
+type CollectionHandler interface {
+	GetCollections(w http.ResponseWriter, r *http.Request)
+	GetPublicCollections(w http.ResponseWriter, r *http.Request)
+	GetSharedCollection(w http.ResponseWriter, r *http.Request)
+	AddCollection(w http.ResponseWriter, r *http.Request)
+	GetCollection(w http.ResponseWriter, r *http.Request)
+	PatchCollection(w http.ResponseWriter, r *http.Request)
+	DeleteCollection(w http.ResponseWriter, r *http.Request)
+	PutEntry(w http.ResponseWriter, r *http.Request)
+	DeleteEntry(w http.ResponseWriter, r *http.Request)
+}
+
+type UserRequest struct {
+	UserID int32
+}
+
+type SharedRequest struct {
+	Token string
+}
+
+type CollectionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"`
+	Visibility  string `json:"visibility"`
+}
+
+type AddCollectionRequest struct {
+	UserID int32
+	Info   CollectionInfo
+}
+
+type CollectionIdRequest struct {
+	UserID int32
+	ID     string
+}
+
+// CollectionPatch is a JSON Merge Patch of CollectionInfo, the kind can
+// not be changed and none of the fields can be null.
+type CollectionPatch struct {
+	Name        util.PatchField[string] `json:"name"`
+	Description util.PatchField[string] `json:"description"`
+	Visibility  util.PatchField[string] `json:"visibility"`
+}
+
+type CollectionPatchRequest struct {
+	UserID int32
+	ID     string
+	Patch  CollectionPatch
+}
+
+type EntryInfo struct {
+	Note     string `json:"note"`
+	Position int    `json:"position"`
+}
+
+type PutEntryRequest struct {
+	UserID int32
+	ID     string
+	FilmID string
+	Info   EntryInfo
+}
+
+type EntryIdRequest struct {
+	UserID int32
+	ID     string
+	FilmID string
+}
+
+type EntryFilmResponse struct {
+	ID          int32  `json:"id"`
+	Name        string `json:"name"`
+	ReleaseDate string `json:"releaseDate"`
+	Rating      int32  `json:"rating"`
+}
+
+type EntryResponse struct {
+	Position  int               `json:"position"`
+	Film      EntryFilmResponse `json:"film"`
+	Note      string            `json:"note"`
+	AddedAt   string            `json:"addedAt"`
+	Available bool              `json:"available"`
+}
+
+// CollectionResponse is a list without its films, ShareURL is set for
+// lists that are not private.
+type CollectionResponse struct {
+	ID          int32  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"`
+	Visibility  string `json:"visibility"`
+	ShareURL    string `json:"shareUrl,omitempty"`
+	FilmCount   int    `json:"filmCount"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+}
+
+// CollectionEntriesResponse is a single list with its films in order.
+type CollectionEntriesResponse struct {
+	CollectionResponse
+	Entries []*EntryResponse `json:"entries"`
+}
@@ -0,0 +1,123 @@
+package collection
+
+import (
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+// WithDefaults fills in the kind and the visibility when absent, new
+// lists are custom and private.
+func WithDefaults(ci *CollectionInfo) *CollectionInfo {
+	res := *ci
+	if len(res.Kind) == 0 {
+		res.Kind = KindCustom
+	}
+	if len(res.Visibility) == 0 {
+		res.Visibility = VisibilityPrivate
+	}
+
+	return &res
+}
+
+func ToCollection(userID int32, ci *CollectionInfo, token string) *Collection {
+	return &Collection{
+		UserID:      userID,
+		Name:        ci.Name,
+		Description: ci.Description,
+		Kind:        ci.Kind,
+		Visibility:  ci.Visibility,
+		ShareToken:  token,
+	}
+}
+
+// PatchToCollectionUpdate expects a validated patch.
+func PatchToCollectionUpdate(id int32, cp *CollectionPatch) *CollectionUpdate {
+	return &CollectionUpdate{
+		ID:          id,
+		Name:        cp.Name.Ptr(),
+		Description: cp.Description.Ptr(),
+		Visibility:  cp.Visibility.Ptr(),
+	}
+}
+
+func ToQueryableObject(cu *CollectionUpdate) *util.QueryableObject {
+	qo := util.NewQueryableObject()
+
+	if cu.Name != nil {
+		qo.Add("list_name", *cu.Name)
+	}
+
+	if cu.Description != nil {
+		qo.Add("list_description", *cu.Description)
+	}
+
+	if cu.Visibility != nil {
+		qo.Add("visibility", *cu.Visibility)
+	}
+
+	return qo
+}
+
+// ShareURL is the path of the read-only view of the list, private lists
+// have none.
+func ShareURL(c *Collection) string {
+	if c.Visibility == VisibilityPrivate {
+		return ""
+	}
+
+	return "/lists/" + c.ShareToken
+}
+
+func ToCollectionResponse(c *Collection) *CollectionResponse {
+	return &CollectionResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		Kind:        c.Kind,
+		Visibility:  c.Visibility,
+		ShareURL:    ShareURL(c),
+		FilmCount:   c.FilmCount,
+		CreatedAt:   c.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt:   c.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func ToCollectionResponses(collections []*Collection) []*CollectionResponse {
+	res := make([]*CollectionResponse, 0, len(collections))
+	for _, v := range collections {
+		res = append(res, ToCollectionResponse(v))
+	}
+
+	return res
+}
+
+func ToEntryResponse(e *Entry) *EntryResponse {
+	return &EntryResponse{
+		Position: e.Position,
+		Film: EntryFilmResponse{
+			ID:          e.FilmID,
+			Name:        e.FilmName,
+			ReleaseDate: e.ReleaseDate.Format("2006-01-02"),
+			Rating:      e.Rating,
+		},
+		Note:      e.Note,
+		AddedAt:   e.AddedAt.UTC().Format(time.RFC3339Nano),
+		Available: e.Available,
+	}
+}
+
+// ToCollectionEntriesResponse counts the films from the entries, they
+// are read after the list.
+func ToCollectionEntriesResponse(c *Collection, entries []*Entry) *CollectionEntriesResponse {
+	res := &CollectionEntriesResponse{
+		CollectionResponse: *ToCollectionResponse(c),
+		Entries:            make([]*EntryResponse, 0, len(entries)),
+	}
+	res.FilmCount = len(entries)
+	for _, v := range entries {
+		res.Entries = append(res.Entries, ToEntryResponse(v))
+	}
+
+	return res
+}
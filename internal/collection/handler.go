@@ -0,0 +1,258 @@
+package collection
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ CollectionHandler = (*Handler)(nil)
+
+type Handler struct {
+	service CollectionService
+}
+
+func NewHandler(cs CollectionService) *Handler {
+	return &Handler{
+		service: cs,
+	}
+}
+
+// userID returns the id of the authenticated user, the routes of the
+// user's own lists are behind the authentication middleware.
+func userID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	uc, ok := util.UserClaimsFromContext(r.Context())
+	if !ok {
+		log.Printf("ERROR: list request without authenticated user\n")
+		util.InternalServerError(w, r)
+		return 0, false
+	}
+
+	return int32(uc.ID), true
+}
+
+func serviceError(w http.ResponseWriter, r *http.Request, err error) {
+	var ve *util.ValidationError
+	if errors.As(err, &ve) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, ErrEmptyUpdate) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      "empty update",
+		})
+		return
+	}
+
+	for _, v := range []error{ErrNameTaken, ErrKindTaken} {
+		if errors.Is(err, v) {
+			util.JSON(w, r, http.StatusConflict, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeConflict,
+				Body:      v.Error(),
+			})
+			return
+		}
+	}
+
+	if errors.Is(err, ErrIdInvalid) {
+		util.NotFound(w, r)
+		return
+	}
+
+	// the body tells a missing list from a film missing from the catalog
+	// or from the list
+	for _, v := range []error{ErrCollectionNotExist, ErrFilmNotExist, ErrEntryNotExist} {
+		if errors.Is(err, v) {
+			util.JSON(w, r, http.StatusNotFound, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeNotFound,
+				Body:      v.Error(),
+			})
+			return
+		}
+	}
+
+	util.InternalServerError(w, r)
+}
+
+func (h *Handler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetCollections(r.Context(), &UserRequest{UserID: id})
+	if err != nil {
+		log.Printf("ERROR: failed to get lists err=%s\n", err.Error())
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetPublicCollections(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetPublicCollections(r.Context())
+	if err != nil {
+		log.Printf("ERROR: failed to get public lists err=%s\n", err.Error())
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetSharedCollection(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetSharedCollection(r.Context(), &SharedRequest{
+		Token: r.PathValue("token"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get shared list err=%s\n", err.Error())
+		if errors.Is(err, ErrCollectionNotExist) {
+			util.NotFound(w, r)
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) AddCollection(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	req := AddCollectionRequest{UserID: id}
+	if ok := util.BindJSON(w, r, &req.Info); !ok {
+		return
+	}
+
+	res, err := h.service.AddCollection(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to add list err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetCollection(r.Context(), &CollectionIdRequest{
+		UserID: id,
+		ID:     r.PathValue("id"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get list err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) PatchCollection(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	req := CollectionPatchRequest{
+		UserID: id,
+		ID:     r.PathValue("id"),
+	}
+	if ok := util.BindMergePatch(w, r, &req.Patch); !ok {
+		return
+	}
+
+	res, err := h.service.PatchCollection(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to patch list err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.DeleteCollection(r.Context(), &CollectionIdRequest{
+		UserID: id,
+		ID:     r.PathValue("id"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to delete list err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) PutEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	req := PutEntryRequest{
+		UserID: id,
+		ID:     r.PathValue("id"),
+		FilmID: r.PathValue("filmId"),
+	}
+	// the body is optional, a film is appended without a note by default
+	if r.ContentLength != 0 {
+		if ok := util.BindJSON(w, r, &req.Info); !ok {
+			return
+		}
+	}
+
+	res, err := h.service.PutEntry(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to put list entry err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.DeleteEntry(r.Context(), &EntryIdRequest{
+		UserID: id,
+		ID:     r.PathValue("id"),
+		FilmID: r.PathValue("filmId"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to delete list entry err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
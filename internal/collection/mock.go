@@ -0,0 +1,461 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/collection/collection.go
+//
+// Generated by this command:
+//
+//	mockgen -self_package=github.com/Coderovshik/film-library/internal/collection -package=collection -source=internal/collection/collection.go -destination=internal/collection/mock.go
+//
+
+// Package collection is a generated GoMock package.
+package collection
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCollectionRepository is a mock of CollectionRepository interface.
+type MockCollectionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionRepositoryMockRecorder
+}
+
+// MockCollectionRepositoryMockRecorder is the mock recorder for MockCollectionRepository.
+type MockCollectionRepositoryMockRecorder struct {
+	mock *MockCollectionRepository
+}
+
+// NewMockCollectionRepository creates a new mock instance.
+func NewMockCollectionRepository(ctrl *gomock.Controller) *MockCollectionRepository {
+	mock := &MockCollectionRepository{ctrl: ctrl}
+	mock.recorder = &MockCollectionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollectionRepository) EXPECT() *MockCollectionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddCollection mocks base method.
+func (m *MockCollectionRepository) AddCollection(ctx context.Context, c *Collection) (*Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCollection", ctx, c)
+	ret0, _ := ret[0].(*Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCollection indicates an expected call of AddCollection.
+func (mr *MockCollectionRepositoryMockRecorder) AddCollection(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCollection", reflect.TypeOf((*MockCollectionRepository)(nil).AddCollection), ctx, c)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockCollectionRepository) DeleteCollection(ctx context.Context, id int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockCollectionRepositoryMockRecorder) DeleteCollection(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockCollectionRepository)(nil).DeleteCollection), ctx, id)
+}
+
+// DeleteEntry mocks base method.
+func (m *MockCollectionRepository) DeleteEntry(ctx context.Context, id, filmID int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEntry", ctx, id, filmID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEntry indicates an expected call of DeleteEntry.
+func (mr *MockCollectionRepositoryMockRecorder) DeleteEntry(ctx, id, filmID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntry", reflect.TypeOf((*MockCollectionRepository)(nil).DeleteEntry), ctx, id, filmID)
+}
+
+// GetCollection mocks base method.
+func (m *MockCollectionRepository) GetCollection(ctx context.Context, id int32) (*Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollection", ctx, id)
+	ret0, _ := ret[0].(*Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollection indicates an expected call of GetCollection.
+func (mr *MockCollectionRepositoryMockRecorder) GetCollection(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockCollectionRepository)(nil).GetCollection), ctx, id)
+}
+
+// GetCollectionByToken mocks base method.
+func (m *MockCollectionRepository) GetCollectionByToken(ctx context.Context, token string) (*Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollectionByToken", ctx, token)
+	ret0, _ := ret[0].(*Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollectionByToken indicates an expected call of GetCollectionByToken.
+func (mr *MockCollectionRepositoryMockRecorder) GetCollectionByToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollectionByToken", reflect.TypeOf((*MockCollectionRepository)(nil).GetCollectionByToken), ctx, token)
+}
+
+// GetCollections mocks base method.
+func (m *MockCollectionRepository) GetCollections(ctx context.Context, q *Query) ([]*Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollections", ctx, q)
+	ret0, _ := ret[0].([]*Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockCollectionRepositoryMockRecorder) GetCollections(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockCollectionRepository)(nil).GetCollections), ctx, q)
+}
+
+// GetEntries mocks base method.
+func (m *MockCollectionRepository) GetEntries(ctx context.Context, id int32) ([]*Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntries", ctx, id)
+	ret0, _ := ret[0].([]*Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntries indicates an expected call of GetEntries.
+func (mr *MockCollectionRepositoryMockRecorder) GetEntries(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntries", reflect.TypeOf((*MockCollectionRepository)(nil).GetEntries), ctx, id)
+}
+
+// PutEntry mocks base method.
+func (m *MockCollectionRepository) PutEntry(ctx context.Context, e *Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutEntry", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutEntry indicates an expected call of PutEntry.
+func (mr *MockCollectionRepositoryMockRecorder) PutEntry(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutEntry", reflect.TypeOf((*MockCollectionRepository)(nil).PutEntry), ctx, e)
+}
+
+// UpdateCollection mocks base method.
+func (m *MockCollectionRepository) UpdateCollection(ctx context.Context, cu *CollectionUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCollection", ctx, cu)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCollection indicates an expected call of UpdateCollection.
+func (mr *MockCollectionRepositoryMockRecorder) UpdateCollection(ctx, cu any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCollection", reflect.TypeOf((*MockCollectionRepository)(nil).UpdateCollection), ctx, cu)
+}
+
+// MockCollectionService is a mock of CollectionService interface.
+type MockCollectionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionServiceMockRecorder
+}
+
+// MockCollectionServiceMockRecorder is the mock recorder for MockCollectionService.
+type MockCollectionServiceMockRecorder struct {
+	mock *MockCollectionService
+}
+
+// NewMockCollectionService creates a new mock instance.
+func NewMockCollectionService(ctrl *gomock.Controller) *MockCollectionService {
+	mock := &MockCollectionService{ctrl: ctrl}
+	mock.recorder = &MockCollectionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollectionService) EXPECT() *MockCollectionServiceMockRecorder {
+	return m.recorder
+}
+
+// AddCollection mocks base method.
+func (m *MockCollectionService) AddCollection(ctx context.Context, req *AddCollectionRequest) (*CollectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCollection", ctx, req)
+	ret0, _ := ret[0].(*CollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCollection indicates an expected call of AddCollection.
+func (mr *MockCollectionServiceMockRecorder) AddCollection(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCollection", reflect.TypeOf((*MockCollectionService)(nil).AddCollection), ctx, req)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockCollectionService) DeleteCollection(ctx context.Context, req *CollectionIdRequest) (*CollectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", ctx, req)
+	ret0, _ := ret[0].(*CollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockCollectionServiceMockRecorder) DeleteCollection(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockCollectionService)(nil).DeleteCollection), ctx, req)
+}
+
+// DeleteEntry mocks base method.
+func (m *MockCollectionService) DeleteEntry(ctx context.Context, req *EntryIdRequest) (*CollectionEntriesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEntry", ctx, req)
+	ret0, _ := ret[0].(*CollectionEntriesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteEntry indicates an expected call of DeleteEntry.
+func (mr *MockCollectionServiceMockRecorder) DeleteEntry(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntry", reflect.TypeOf((*MockCollectionService)(nil).DeleteEntry), ctx, req)
+}
+
+// GetCollection mocks base method.
+func (m *MockCollectionService) GetCollection(ctx context.Context, req *CollectionIdRequest) (*CollectionEntriesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollection", ctx, req)
+	ret0, _ := ret[0].(*CollectionEntriesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollection indicates an expected call of GetCollection.
+func (mr *MockCollectionServiceMockRecorder) GetCollection(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockCollectionService)(nil).GetCollection), ctx, req)
+}
+
+// GetCollections mocks base method.
+func (m *MockCollectionService) GetCollections(ctx context.Context, req *UserRequest) ([]*CollectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollections", ctx, req)
+	ret0, _ := ret[0].([]*CollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockCollectionServiceMockRecorder) GetCollections(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockCollectionService)(nil).GetCollections), ctx, req)
+}
+
+// GetPublicCollections mocks base method.
+func (m *MockCollectionService) GetPublicCollections(ctx context.Context) ([]*CollectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicCollections", ctx)
+	ret0, _ := ret[0].([]*CollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicCollections indicates an expected call of GetPublicCollections.
+func (mr *MockCollectionServiceMockRecorder) GetPublicCollections(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicCollections", reflect.TypeOf((*MockCollectionService)(nil).GetPublicCollections), ctx)
+}
+
+// GetSharedCollection mocks base method.
+func (m *MockCollectionService) GetSharedCollection(ctx context.Context, req *SharedRequest) (*CollectionEntriesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedCollection", ctx, req)
+	ret0, _ := ret[0].(*CollectionEntriesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharedCollection indicates an expected call of GetSharedCollection.
+func (mr *MockCollectionServiceMockRecorder) GetSharedCollection(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedCollection", reflect.TypeOf((*MockCollectionService)(nil).GetSharedCollection), ctx, req)
+}
+
+// PatchCollection mocks base method.
+func (m *MockCollectionService) PatchCollection(ctx context.Context, req *CollectionPatchRequest) (*CollectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchCollection", ctx, req)
+	ret0, _ := ret[0].(*CollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchCollection indicates an expected call of PatchCollection.
+func (mr *MockCollectionServiceMockRecorder) PatchCollection(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCollection", reflect.TypeOf((*MockCollectionService)(nil).PatchCollection), ctx, req)
+}
+
+// PutEntry mocks base method.
+func (m *MockCollectionService) PutEntry(ctx context.Context, req *PutEntryRequest) (*CollectionEntriesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutEntry", ctx, req)
+	ret0, _ := ret[0].(*CollectionEntriesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutEntry indicates an expected call of PutEntry.
+func (mr *MockCollectionServiceMockRecorder) PutEntry(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutEntry", reflect.TypeOf((*MockCollectionService)(nil).PutEntry), ctx, req)
+}
+
+// MockCollectionHandler is a mock of CollectionHandler interface.
+type MockCollectionHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionHandlerMockRecorder
+}
+
+// MockCollectionHandlerMockRecorder is the mock recorder for MockCollectionHandler.
+type MockCollectionHandlerMockRecorder struct {
+	mock *MockCollectionHandler
+}
+
+// NewMockCollectionHandler creates a new mock instance.
+func NewMockCollectionHandler(ctrl *gomock.Controller) *MockCollectionHandler {
+	mock := &MockCollectionHandler{ctrl: ctrl}
+	mock.recorder = &MockCollectionHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollectionHandler) EXPECT() *MockCollectionHandlerMockRecorder {
+	return m.recorder
+}
+
+// AddCollection mocks base method.
+func (m *MockCollectionHandler) AddCollection(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddCollection", w, r)
+}
+
+// AddCollection indicates an expected call of AddCollection.
+func (mr *MockCollectionHandlerMockRecorder) AddCollection(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCollection", reflect.TypeOf((*MockCollectionHandler)(nil).AddCollection), w, r)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockCollectionHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteCollection", w, r)
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockCollectionHandlerMockRecorder) DeleteCollection(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockCollectionHandler)(nil).DeleteCollection), w, r)
+}
+
+// DeleteEntry mocks base method.
+func (m *MockCollectionHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteEntry", w, r)
+}
+
+// DeleteEntry indicates an expected call of DeleteEntry.
+func (mr *MockCollectionHandlerMockRecorder) DeleteEntry(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntry", reflect.TypeOf((*MockCollectionHandler)(nil).DeleteEntry), w, r)
+}
+
+// GetCollection mocks base method.
+func (m *MockCollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetCollection", w, r)
+}
+
+// GetCollection indicates an expected call of GetCollection.
+func (mr *MockCollectionHandlerMockRecorder) GetCollection(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockCollectionHandler)(nil).GetCollection), w, r)
+}
+
+// GetCollections mocks base method.
+func (m *MockCollectionHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetCollections", w, r)
+}
+
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockCollectionHandlerMockRecorder) GetCollections(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockCollectionHandler)(nil).GetCollections), w, r)
+}
+
+// GetPublicCollections mocks base method.
+func (m *MockCollectionHandler) GetPublicCollections(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetPublicCollections", w, r)
+}
+
+// GetPublicCollections indicates an expected call of GetPublicCollections.
+func (mr *MockCollectionHandlerMockRecorder) GetPublicCollections(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicCollections", reflect.TypeOf((*MockCollectionHandler)(nil).GetPublicCollections), w, r)
+}
+
+// GetSharedCollection mocks base method.
+func (m *MockCollectionHandler) GetSharedCollection(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetSharedCollection", w, r)
+}
+
+// GetSharedCollection indicates an expected call of GetSharedCollection.
+func (mr *MockCollectionHandlerMockRecorder) GetSharedCollection(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedCollection", reflect.TypeOf((*MockCollectionHandler)(nil).GetSharedCollection), w, r)
+}
+
+// PatchCollection mocks base method.
+func (m *MockCollectionHandler) PatchCollection(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PatchCollection", w, r)
+}
+
+// PatchCollection indicates an expected call of PatchCollection.
+func (mr *MockCollectionHandlerMockRecorder) PatchCollection(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCollection", reflect.TypeOf((*MockCollectionHandler)(nil).PatchCollection), w, r)
+}
+
+// PutEntry mocks base method.
+func (m *MockCollectionHandler) PutEntry(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PutEntry", w, r)
+}
+
+// PutEntry indicates an expected call of PutEntry.
+func (mr *MockCollectionHandlerMockRecorder) PutEntry(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutEntry", reflect.TypeOf((*MockCollectionHandler)(nil).PutEntry), w, r)
+}
@@ -0,0 +1,401 @@
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ CollectionRepository = (*Repository)(nil)
+
+type Repository struct {
+	db      db.DBTX
+	dialect db.Dialect
+}
+
+func NewRepository(conn db.DBTX, d db.Dialect) *Repository {
+	return &Repository{
+		db:      conn,
+		dialect: d,
+	}
+}
+
+const selectCollections = `
+	SELECT l.list_id, l.user_id, l.list_name, l.list_description, l.kind,
+		l.visibility, l.share_token, l.created_at, l.updated_at, COUNT(e.movie_id)
+	FROM lists l
+	LEFT JOIN list_entries e ON e.list_id = l.list_id`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCollection(s scanner) (*Collection, error) {
+	var c Collection
+	err := s.Scan(&c.ID, &c.UserID, &c.Name, &c.Description, &c.Kind,
+		&c.Visibility, &c.ShareToken, &c.CreatedAt, &c.UpdatedAt, &c.FilmCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *Repository) GetCollections(ctx context.Context, q *Query) ([]*Collection, error) {
+	const op = "collection.Repository.GetCollections"
+
+	const query = selectCollections + `
+		WHERE ($1 = 0 OR l.user_id = $1) AND ($2 = '' OR l.visibility = $2)
+		GROUP BY l.list_id
+		ORDER BY l.created_at, l.list_id`
+	rows, err := r.db.QueryContext(ctx, query, q.UserID, q.Visibility)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	collections := make([]*Collection, 0)
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		collections = append(collections, c)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return collections, nil
+}
+
+func (r *Repository) getCollection(ctx context.Context, where string, arg any) (*Collection, error) {
+	query := selectCollections + `
+		WHERE ` + where + `
+		GROUP BY l.list_id`
+	c, err := scanCollection(r.db.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("ERROR: list with %s does not exist\n", where)
+			return nil, ErrCollectionNotExist
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r *Repository) GetCollection(ctx context.Context, id int32) (*Collection, error) {
+	const op = "collection.Repository.GetCollection"
+
+	c, err := r.getCollection(ctx, "l.list_id = $1", id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return c, nil
+}
+
+func (r *Repository) GetCollectionByToken(ctx context.Context, token string) (*Collection, error) {
+	const op = "collection.Repository.GetCollectionByToken"
+
+	c, err := r.getCollection(ctx, "l.share_token = $1", token)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return c, nil
+}
+
+func (r *Repository) AddCollection(ctx context.Context, c *Collection) (*Collection, error) {
+	const op = "collection.Repository.AddCollection"
+
+	c.CreatedAt = db.Now()
+	c.UpdatedAt = c.CreatedAt
+
+	const query = `
+		INSERT INTO lists(user_id, list_name, list_description, kind,
+			visibility, share_token, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, "list_id", c.UserID, c.Name, c.Description,
+		c.Kind, c.Visibility, c.ShareToken, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("%s: %w", op, r.takenError(ctx, c))
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	c.ID = int32(id)
+	c.FilmCount = 0
+
+	return c, nil
+}
+
+// takenError tells which unique constraint the new list violates, the
+// name or the kind, tokens are too long to collide.
+func (r *Repository) takenError(ctx context.Context, c *Collection) error {
+	const query = `SELECT EXISTS (SELECT 1 FROM lists WHERE user_id = $1 AND list_name = $2)`
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, c.UserID, c.Name).Scan(&exists); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+	if exists {
+		log.Printf("ERROR: list %s of user with id=%d already exists\n", c.Name, c.UserID)
+		return ErrNameTaken
+	}
+
+	log.Printf("ERROR: %s list of user with id=%d already exists\n", c.Kind, c.UserID)
+	return ErrKindTaken
+}
+
+func (r *Repository) UpdateCollection(ctx context.Context, cu *CollectionUpdate) error {
+	const op = "collection.Repository.UpdateCollection"
+
+	qo := ToQueryableObject(cu)
+	if qo.IsEmpty() {
+		log.Print("ERROR: no updates to apply\n")
+		return fmt.Errorf("%s: %w", op, ErrEmptyUpdate)
+	}
+
+	qo.Add("updated_at", db.Now())
+
+	query := `UPDATE lists SET ` + qo.Args(1) + fmt.Sprintf(` WHERE list_id = $%d`, qo.Len()+1)
+	res, err := r.db.ExecContext(ctx, query, append(qo.Values(), cu.ID)...)
+	if err != nil {
+		// the name is the only unique column that can be updated
+		if r.dialect.IsUniqueViolation(err) {
+			log.Printf("ERROR: list %s already exists\n", *cu.Name)
+			return fmt.Errorf("%s: %w", op, ErrNameTaken)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, ErrCollectionNotExist)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteCollection(ctx context.Context, id int32) error {
+	const op = "collection.Repository.DeleteCollection"
+
+	// the entries go with the list
+	const query = `DELETE FROM lists WHERE list_id = $1`
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by deletion\n")
+		return fmt.Errorf("%s: %w", op, ErrCollectionNotExist)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetEntries(ctx context.Context, id int32) ([]*Entry, error) {
+	const op = "collection.Repository.GetEntries"
+
+	// films in the trash are kept, purged ones are gone with their entries
+	const query = `
+		SELECT e.movie_id, e.note, e.added_at, m.movie_name, m.releasedate,
+			m.rating, m.deleted_at IS NULL
+		FROM list_entries e
+		INNER JOIN movie m ON m.movie_id = e.movie_id
+		WHERE e.list_id = $1
+		ORDER BY e.position, e.added_at, e.movie_id`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	for rows.Next() {
+		e := Entry{CollectionID: id}
+		err := rows.Scan(&e.FilmID, &e.Note, &e.AddedAt, &e.FilmName, &e.ReleaseDate,
+			&e.Rating, &e.Available)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		// stored positions may have gaps, the ones reported do not
+		e.Position = len(entries) + 1
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
+
+// position returns the stored position of the film in the list and the
+// number of films in it, ok is false if the film is not in the list.
+func (r *Repository) position(ctx context.Context, id int32, filmID int32) (pos int, n int, ok bool, err error) {
+	const query = `
+		SELECT COUNT(*), COALESCE(MAX(CASE WHEN movie_id = $2 THEN position END), 0)
+		FROM list_entries WHERE list_id = $1`
+	if err := r.db.QueryRowContext(ctx, query, id, filmID).Scan(&n, &pos); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return 0, 0, false, err
+	}
+
+	return pos, n, pos != 0, nil
+}
+
+func (r *Repository) PutEntry(ctx context.Context, e *Entry) error {
+	const op = "collection.Repository.PutEntry"
+
+	cur, n, ok, err := r.position(ctx, e.CollectionID, e.FilmID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if ok {
+		err = r.moveEntry(ctx, e, cur, n)
+	} else {
+		err = r.insertEntry(ctx, e, n)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return r.touch(ctx, e.CollectionID, op)
+}
+
+func (r *Repository) insertEntry(ctx context.Context, e *Entry, n int) error {
+	const exists = `SELECT EXISTS (SELECT 1 FROM movie WHERE movie_id = $1 AND deleted_at IS NULL)`
+	var ok bool
+	if err := r.db.QueryRowContext(ctx, exists, e.FilmID).Scan(&ok); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+	if !ok {
+		log.Printf("ERROR: film with id=%d does not exist\n", e.FilmID)
+		return ErrFilmNotExist
+	}
+
+	pos := e.Position
+	if pos == 0 || pos > n {
+		pos = n + 1
+	} else {
+		const shift = `UPDATE list_entries SET position = position + 1 WHERE list_id = $1 AND position >= $2`
+		if _, err := r.db.ExecContext(ctx, shift, e.CollectionID, pos); err != nil {
+			log.Printf("ERROR: failed to execute query\n")
+			return err
+		}
+	}
+
+	const query = `
+		INSERT INTO list_entries(list_id, movie_id, position, note, added_at)
+		VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.ExecContext(ctx, query, e.CollectionID, e.FilmID, pos, e.Note, db.Now())
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) moveEntry(ctx context.Context, e *Entry, cur int, n int) error {
+	pos := e.Position
+	if pos == 0 {
+		pos = cur
+	}
+	pos = min(pos, n)
+
+	var shift string
+	var from, to int
+	switch {
+	case pos < cur:
+		shift = `UPDATE list_entries SET position = position + 1 WHERE list_id = $1 AND position >= $2 AND position < $3`
+		from, to = pos, cur
+	case pos > cur:
+		shift = `UPDATE list_entries SET position = position - 1 WHERE list_id = $1 AND position > $2 AND position <= $3`
+		from, to = cur, pos
+	}
+	if len(shift) != 0 {
+		if _, err := r.db.ExecContext(ctx, shift, e.CollectionID, from, to); err != nil {
+			log.Printf("ERROR: failed to execute query\n")
+			return err
+		}
+	}
+
+	const query = `UPDATE list_entries SET position = $3, note = $4 WHERE list_id = $1 AND movie_id = $2`
+	if _, err := r.db.ExecContext(ctx, query, e.CollectionID, e.FilmID, pos, e.Note); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteEntry(ctx context.Context, id int32, filmID int32) error {
+	const op = "collection.Repository.DeleteEntry"
+
+	cur, _, ok, err := r.position(ctx, id, filmID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		log.Printf("ERROR: film with id=%d is not in list with id=%d\n", filmID, id)
+		return fmt.Errorf("%s: %w", op, ErrEntryNotExist)
+	}
+
+	const query = `DELETE FROM list_entries WHERE list_id = $1 AND movie_id = $2`
+	if _, err := r.db.ExecContext(ctx, query, id, filmID); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	const shift = `UPDATE list_entries SET position = position - 1 WHERE list_id = $1 AND position > $2`
+	if _, err := r.db.ExecContext(ctx, shift, id, cur); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return r.touch(ctx, id, op)
+}
+
+// touch marks the list as changed after a change of its entries.
+func (r *Repository) touch(ctx context.Context, id int32, op string) error {
+	const query = `UPDATE lists SET updated_at = $2 WHERE list_id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id, db.Now()); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package collection
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+var _ CollectionService = (*Service)(nil)
+
+type Service struct {
+	repo CollectionRepository
+}
+
+func NewService(cr CollectionRepository) *Service {
+	return &Service{
+		repo: cr,
+	}
+}
+
+func generateShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// owned returns the list of the user, the lists of other users do not
+// exist for them.
+func (s *Service) owned(ctx context.Context, userID int32, idString string) (*Collection, error) {
+	id, err := strconv.ParseUint(idString, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return nil, ErrIdInvalid
+	}
+
+	c, err := s.repo.GetCollection(ctx, int32(id))
+	if err != nil {
+		log.Printf("ERROR: failed to get list from repository\n")
+		return nil, err
+	}
+	if c.UserID != userID {
+		log.Printf("ERROR: list with id=%d is not owned by user with id=%d\n", id, userID)
+		return nil, ErrCollectionNotExist
+	}
+
+	return c, nil
+}
+
+func (s *Service) withEntries(ctx context.Context, c *Collection) (*CollectionEntriesResponse, error) {
+	entries, err := s.repo.GetEntries(ctx, c.ID)
+	if err != nil {
+		log.Printf("ERROR: failed to get list entries from repository\n")
+		return nil, err
+	}
+
+	return ToCollectionEntriesResponse(c, entries), nil
+}
+
+func (s *Service) GetCollections(ctx context.Context, req *UserRequest) ([]*CollectionResponse, error) {
+	const op = "collection.Service.GetCollections"
+
+	collections, err := s.repo.GetCollections(ctx, &Query{UserID: req.UserID})
+	if err != nil {
+		log.Printf("ERROR: failed to get lists from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToCollectionResponses(collections), nil
+}
+
+func (s *Service) GetPublicCollections(ctx context.Context) ([]*CollectionResponse, error) {
+	const op = "collection.Service.GetPublicCollections"
+
+	collections, err := s.repo.GetCollections(ctx, &Query{Visibility: VisibilityPublic})
+	if err != nil {
+		log.Printf("ERROR: failed to get lists from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToCollectionResponses(collections), nil
+}
+
+// GetSharedCollection reads an unlisted or public list by its token.
+func (s *Service) GetSharedCollection(ctx context.Context, req *SharedRequest) (*CollectionEntriesResponse, error) {
+	const op = "collection.Service.GetSharedCollection"
+
+	c, err := s.repo.GetCollectionByToken(ctx, req.Token)
+	if err != nil {
+		log.Printf("ERROR: failed to get list from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if c.Visibility == VisibilityPrivate {
+		log.Printf("ERROR: list with id=%d is private\n", c.ID)
+		return nil, fmt.Errorf("%s: %w", op, ErrCollectionNotExist)
+	}
+
+	res, err := s.withEntries(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+func (s *Service) AddCollection(ctx context.Context, req *AddCollectionRequest) (*CollectionResponse, error) {
+	const op = "collection.Service.AddCollection"
+
+	ci := WithDefaults(&req.Info)
+	vErr := ValidateCollectionInfo(ci)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		log.Printf("ERROR: failed to generate share token\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	c, err := s.repo.AddCollection(ctx, ToCollection(req.UserID, ci, token))
+	if err != nil {
+		log.Printf("ERROR: failed to add list to repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToCollectionResponse(c), nil
+}
+
+func (s *Service) GetCollection(ctx context.Context, req *CollectionIdRequest) (*CollectionEntriesResponse, error) {
+	const op = "collection.Service.GetCollection"
+
+	c, err := s.owned(ctx, req.UserID, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.withEntries(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+func (s *Service) PatchCollection(ctx context.Context, req *CollectionPatchRequest) (*CollectionResponse, error) {
+	const op = "collection.Service.PatchCollection"
+
+	c, err := s.owned(ctx, req.UserID, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	vErr := ValidateCollectionPatch(&req.Patch)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	err = s.repo.UpdateCollection(ctx, PatchToCollectionUpdate(c.ID, &req.Patch))
+	if err != nil {
+		log.Printf("ERROR: failed to update list in repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	c, err = s.repo.GetCollection(ctx, c.ID)
+	if err != nil {
+		log.Printf("ERROR: failed to get list from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToCollectionResponse(c), nil
+}
+
+func (s *Service) DeleteCollection(ctx context.Context, req *CollectionIdRequest) (*CollectionResponse, error) {
+	const op = "collection.Service.DeleteCollection"
+
+	c, err := s.owned(ctx, req.UserID, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.repo.DeleteCollection(ctx, c.ID)
+	if err != nil {
+		log.Printf("ERROR: failed to delete list from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToCollectionResponse(c), nil
+}
+
+func (s *Service) PutEntry(ctx context.Context, req *PutEntryRequest) (*CollectionEntriesResponse, error) {
+	const op = "collection.Service.PutEntry"
+
+	c, err := s.owned(ctx, req.UserID, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	filmID, err := strconv.ParseUint(req.FilmID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed film id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+	}
+
+	vErr := ValidateEntryInfo(&req.Info)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	err = s.repo.PutEntry(ctx, &Entry{
+		CollectionID: c.ID,
+		FilmID:       int32(filmID),
+		Position:     req.Info.Position,
+		Note:         req.Info.Note,
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to put list entry to repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.reread(ctx, c.ID, op)
+}
+
+func (s *Service) DeleteEntry(ctx context.Context, req *EntryIdRequest) (*CollectionEntriesResponse, error) {
+	const op = "collection.Service.DeleteEntry"
+
+	c, err := s.owned(ctx, req.UserID, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	filmID, err := strconv.ParseUint(req.FilmID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed film id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrEntryNotExist)
+	}
+
+	err = s.repo.DeleteEntry(ctx, c.ID, int32(filmID))
+	if err != nil {
+		log.Printf("ERROR: failed to delete list entry from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.reread(ctx, c.ID, op)
+}
+
+// reread returns the list after a change of its entries, which touches it.
+func (s *Service) reread(ctx context.Context, id int32, op string) (*CollectionEntriesResponse, error) {
+	c, err := s.repo.GetCollection(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to get list from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.withEntries(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
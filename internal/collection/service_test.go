@@ -0,0 +1,218 @@
+package collection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+var testTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func testCollection(visibility string) *Collection {
+	return &Collection{
+		ID:         1,
+		UserID:     7,
+		Name:       "Watchlist",
+		Kind:       KindWatchlist,
+		Visibility: visibility,
+		ShareToken: "token",
+		FilmCount:  1,
+		CreatedAt:  testTime,
+		UpdatedAt:  testTime,
+	}
+}
+
+func TestService_AddCollection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cm := NewMockCollectionRepository(ctrl)
+
+	s := NewService(cm)
+
+	cm.EXPECT().AddCollection(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, c *Collection) (*Collection, error) {
+		if c.UserID != 7 || c.Kind != KindCustom || c.Visibility != VisibilityPrivate || len(c.ShareToken) == 0 {
+			t.Errorf("Expected private custom list of user 7 with a token, got %+v", c)
+		}
+		c.ID, c.CreatedAt, c.UpdatedAt = 2, testTime, testTime
+		return c, nil
+	}).Times(1)
+
+	res, err := s.AddCollection(context.TODO(), &AddCollectionRequest{UserID: 7, Info: CollectionInfo{Name: "Noir"}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := &CollectionResponse{
+		ID:         2,
+		Name:       "Noir",
+		Kind:       KindCustom,
+		Visibility: VisibilityPrivate,
+		CreatedAt:  "2024-03-01T12:00:00Z",
+		UpdatedAt:  "2024-03-01T12:00:00Z",
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	var ve *util.ValidationError
+	_, err = s.AddCollection(context.TODO(), &AddCollectionRequest{UserID: 7, Info: CollectionInfo{Kind: "later"}})
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestService_GetCollection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cm := NewMockCollectionRepository(ctrl)
+
+	s := NewService(cm)
+
+	cm.EXPECT().GetCollection(gomock.Any(), int32(1)).Return(testCollection(VisibilityPublic), nil).Times(2)
+	cm.EXPECT().GetEntries(gomock.Any(), int32(1)).Return([]*Entry{{
+		CollectionID: 1,
+		FilmID:       3,
+		Position:     1,
+		Note:         "with friends",
+		AddedAt:      testTime,
+		FilmName:     "Speed",
+		ReleaseDate:  time.Date(1994, 6, 10, 0, 0, 0, 0, time.UTC),
+		Rating:       7,
+	}}, nil).Times(1)
+
+	res, err := s.GetCollection(context.TODO(), &CollectionIdRequest{UserID: 7, ID: "1"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := &CollectionEntriesResponse{
+		CollectionResponse: CollectionResponse{
+			ID:         1,
+			Name:       "Watchlist",
+			Kind:       KindWatchlist,
+			Visibility: VisibilityPublic,
+			ShareURL:   "/lists/token",
+			FilmCount:  1,
+			CreatedAt:  "2024-03-01T12:00:00Z",
+			UpdatedAt:  "2024-03-01T12:00:00Z",
+		},
+		Entries: []*EntryResponse{{
+			Position:  1,
+			Film:      EntryFilmResponse{ID: 3, Name: "Speed", ReleaseDate: "1994-06-10", Rating: 7},
+			Note:      "with friends",
+			AddedAt:   "2024-03-01T12:00:00Z",
+			Available: false,
+		}},
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	// lists of other users do not exist for the user
+	_, err = s.GetCollection(context.TODO(), &CollectionIdRequest{UserID: 8, ID: "1"})
+	if !errors.Is(err, ErrCollectionNotExist) {
+		t.Errorf("Expected %v, got %v", ErrCollectionNotExist, err)
+	}
+
+	_, err = s.GetCollection(context.TODO(), &CollectionIdRequest{UserID: 7, ID: "one"})
+	if !errors.Is(err, ErrIdInvalid) {
+		t.Errorf("Expected %v, got %v", ErrIdInvalid, err)
+	}
+}
+
+func TestService_GetSharedCollection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cm := NewMockCollectionRepository(ctrl)
+
+	s := NewService(cm)
+
+	cm.EXPECT().GetCollectionByToken(gomock.Any(), "token").Return(testCollection(VisibilityUnlisted), nil).Times(1)
+	cm.EXPECT().GetEntries(gomock.Any(), int32(1)).Return([]*Entry{}, nil).Times(1)
+
+	res, err := s.GetSharedCollection(context.TODO(), &SharedRequest{Token: "token"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if res.ID != 1 || res.FilmCount != 0 || res.ShareURL != "/lists/token" {
+		t.Errorf("Expected unlisted list 1 without films, got %+v", res)
+	}
+
+	// private lists are not shared even with a known token
+	cm.EXPECT().GetCollectionByToken(gomock.Any(), "token").Return(testCollection(VisibilityPrivate), nil).Times(1)
+	_, err = s.GetSharedCollection(context.TODO(), &SharedRequest{Token: "token"})
+	if !errors.Is(err, ErrCollectionNotExist) {
+		t.Errorf("Expected %v, got %v", ErrCollectionNotExist, err)
+	}
+}
+
+func TestService_PatchCollection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cm := NewMockCollectionRepository(ctrl)
+
+	s := NewService(cm)
+
+	visibility := VisibilityUnlisted
+	cm.EXPECT().GetCollection(gomock.Any(), int32(1)).Return(testCollection(VisibilityPrivate), nil).Times(1)
+	cm.EXPECT().UpdateCollection(gomock.Any(), &CollectionUpdate{ID: 1, Visibility: &visibility}).Return(nil).Times(1)
+	cm.EXPECT().GetCollection(gomock.Any(), int32(1)).Return(testCollection(VisibilityUnlisted), nil).Times(1)
+
+	req := &CollectionPatchRequest{UserID: 7, ID: "1"}
+	if err := json.Unmarshal([]byte(`{"visibility": "unlisted"}`), &req.Patch); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	res, err := s.PatchCollection(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if res.Visibility != VisibilityUnlisted || res.ShareURL != "/lists/token" {
+		t.Errorf("Expected unlisted list with share url, got %+v", res)
+	}
+
+	for _, patch := range []string{`{"name": ""}`, `{"name": null}`, `{"visibility": "friends"}`, `{"description": null}`} {
+		cm.EXPECT().GetCollection(gomock.Any(), int32(1)).Return(testCollection(VisibilityPrivate), nil).Times(1)
+
+		req = &CollectionPatchRequest{UserID: 7, ID: "1"}
+		if err := json.Unmarshal([]byte(patch), &req.Patch); err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		var ve *util.ValidationError
+		if _, err := s.PatchCollection(context.TODO(), req); !errors.As(err, &ve) {
+			t.Errorf("Expected validation error for %s, got %v", patch, err)
+		}
+	}
+}
+
+func TestService_PutEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cm := NewMockCollectionRepository(ctrl)
+
+	s := NewService(cm)
+
+	cm.EXPECT().GetCollection(gomock.Any(), int32(1)).Return(testCollection(VisibilityPrivate), nil).Times(5)
+	cm.EXPECT().PutEntry(gomock.Any(), &Entry{CollectionID: 1, FilmID: 3, Position: 2, Note: "later"}).Return(nil).Times(1)
+	cm.EXPECT().GetEntries(gomock.Any(), int32(1)).Return([]*Entry{}, nil).Times(1)
+
+	_, err := s.PutEntry(context.TODO(), &PutEntryRequest{UserID: 7, ID: "1", FilmID: "3", Info: EntryInfo{Note: "later", Position: 2}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	cm.EXPECT().PutEntry(gomock.Any(), &Entry{CollectionID: 1, FilmID: 4}).Return(ErrFilmNotExist).Times(1)
+	_, err = s.PutEntry(context.TODO(), &PutEntryRequest{UserID: 7, ID: "1", FilmID: "4"})
+	if !errors.Is(err, ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", ErrFilmNotExist, err)
+	}
+
+	var ve *util.ValidationError
+	_, err = s.PutEntry(context.TODO(), &PutEntryRequest{UserID: 7, ID: "1", FilmID: "3", Info: EntryInfo{Position: -1}})
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+
+	_, err = s.PutEntry(context.TODO(), &PutEntryRequest{UserID: 7, ID: "1", FilmID: "film"})
+	if !errors.Is(err, ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", ErrFilmNotExist, err)
+	}
+}
@@ -0,0 +1,100 @@
+package collection
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var (
+	kinds        = []string{KindWatchlist, KindFavorites, KindCustom}
+	visibilities = []string{VisibilityPrivate, VisibilityUnlisted, VisibilityPublic}
+)
+
+// ValidateCollectionInfo expects the defaults applied, see WithDefaults.
+func ValidateCollectionInfo(ci *CollectionInfo) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	validateName(ve, ci.Name)
+	validateDescription(ve, ci.Description)
+
+	if !slices.Contains(kinds, ci.Kind) {
+		ve.AddViolation("incorrect kind (expected one of [watchlist, favorites, custom])")
+	}
+
+	validateVisibility(ve, ci.Visibility)
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateCollectionPatch(cp *CollectionPatch) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if cp.Name.Null {
+		ve.AddViolation("name empty")
+	} else if cp.Name.Set {
+		validateName(ve, cp.Name.Value)
+	}
+
+	if cp.Description.Null {
+		ve.AddViolation("description null (expected string, possibly empty)")
+	} else {
+		validateDescription(ve, cp.Description.Value)
+	}
+
+	if cp.Visibility.Null {
+		ve.AddViolation("visibility empty (expected one of [private, unlisted, public])")
+	} else if cp.Visibility.Set {
+		validateVisibility(ve, cp.Visibility.Value)
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateEntryInfo(ei *EntryInfo) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if utf8.RuneCountInString(ei.Note) > maxNoteLength {
+		ve.AddViolation(fmt.Sprintf("note too long (expected at most %d characters)", maxNoteLength))
+	}
+
+	if ei.Position < 0 {
+		ve.AddViolation("incorrect position (expected positive integer, or 0 to append)")
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func validateName(ve *util.ValidationError, name string) {
+	if len(name) == 0 {
+		ve.AddViolation("name empty")
+	} else if utf8.RuneCountInString(name) > maxNameLength {
+		ve.AddViolation(fmt.Sprintf("name too long (expected at most %d characters)", maxNameLength))
+	}
+}
+
+func validateDescription(ve *util.ValidationError, description string) {
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		ve.AddViolation(fmt.Sprintf("description too long (expected at most %d characters)", maxDescriptionLength))
+	}
+}
+
+func validateVisibility(ve *util.ValidationError, visibility string) {
+	if !slices.Contains(visibilities, visibility) {
+		ve.AddViolation("incorrect visibility (expected one of [private, unlisted, public])")
+	}
+}
@@ -3,23 +3,107 @@ package config
 import (
 	"log"
 	"net"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+const (
+	// StorageDatabase keeps data in the SQL database given by DATABASE_URI,
+	// either postgres:// or sqlite://.
+	StorageDatabase = "database"
+	StorageMemory   = "memory"
+	// StoragePostgres is what STORAGE called database storage before
+	// SQLite was supported, it is still accepted for it.
+	StoragePostgres = "postgres"
+
+	NotifySMTP    = "smtp"
+	NotifyWebhook = "webhook"
+)
+
 type Config struct {
 	Host        string `env:"SERVER_HOST" env-default:"localhost"`
 	Port        string `env:"SERVER_PORT" env-default:"8080"`
 	SigningKey  string `env:"SIGNING_KEY" env-required:"true"`
-	DatabaseURI string `env:"DATABASE_URI" env-required:"true"`
+	Storage     string `env:"STORAGE" env-default:"database"`
+	DatabaseURI string `env:"DATABASE_URI"`
 	DocsHTML    string `env:"DOCS_HTML" env-required:"true"`
 	DocsYAML    string `env:"DOCS_YAML" env-required:"true"`
+
+	// AdminUsername enables first-run bootstrap of an admin account,
+	// its password is read from AdminPasswordFile or generated.
+	AdminUsername     string `env:"ADMIN_USERNAME"`
+	AdminPasswordFile string `env:"ADMIN_PASSWORD_FILE"`
+
+	// Deleted films and actors are purged for good once they spent
+	// TrashRetention in the trash, a zero TrashPurgeInterval disables it.
+	TrashRetention     time.Duration `env:"TRASH_RETENTION" env-default:"720h"`
+	TrashPurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+
+	// Responses to requests with an Idempotency-Key are replayed for
+	// IdempotencyTTL, a zero IdempotencyPurgeInterval keeps expired ones.
+	// A request in progress for longer than IdempotencyLockTimeout is
+	// taken to have died, a retry then handles the key again.
+	IdempotencyTTL           time.Duration `env:"IDEMPOTENCY_TTL" env-default:"24h"`
+	IdempotencyPurgeInterval time.Duration `env:"IDEMPOTENCY_PURGE_INTERVAL" env-default:"1h"`
+	IdempotencyLockTimeout   time.Duration `env:"IDEMPOTENCY_LOCK_TIMEOUT" env-default:"30s"`
+
+	// AutocompleteTimeout is the latency budget of a suggestion lookup,
+	// zero lifts it.
+	AutocompleteTimeout time.Duration `env:"AUTOCOMPLETE_TIMEOUT" env-default:"200ms"`
+
+	// Similar films are scored by shared cast, release era and rating
+	// closeness with the given weights, films released SimilarEraYears
+	// apart are no longer of the same era.
+	SimilarCastWeight   float64 `env:"SIMILAR_CAST_WEIGHT" env-default:"0.6"`
+	SimilarEraWeight    float64 `env:"SIMILAR_ERA_WEIGHT" env-default:"0.25"`
+	SimilarRatingWeight float64 `env:"SIMILAR_RATING_WEIGHT" env-default:"0.15"`
+	SimilarEraYears     float64 `env:"SIMILAR_ERA_YEARS" env-default:"20"`
+
+	// Copies are lent for LoanPeriod, a loan can be renewed
+	// LoanMaxRenewals times while nobody holds the film.
+	LoanPeriod      time.Duration `env:"LOAN_PERIOD" env-default:"336h"`
+	LoanMaxRenewals int           `env:"LOAN_MAX_RENEWALS" env-default:"2"`
+
+	// Notifications about followed actors are delivered every
+	// NotifyInterval through NotifyDriver, smtp or webhook, and are only
+	// recorded without one. A delivery is given up after NotifyMaxAttempts,
+	// each one limited by NotifyTimeout. A notification left sending longer
+	// than NotifyClaimTimeout by a stopped dispatcher is failed.
+	NotifyDriver       string        `env:"NOTIFY_DRIVER"`
+	NotifyInterval     time.Duration `env:"NOTIFY_INTERVAL" env-default:"1m"`
+	NotifyMaxAttempts  int           `env:"NOTIFY_MAX_ATTEMPTS" env-default:"5"`
+	NotifyTimeout      time.Duration `env:"NOTIFY_TIMEOUT" env-default:"10s"`
+	NotifyClaimTimeout time.Duration `env:"NOTIFY_CLAIM_TIMEOUT" env-default:"10m"`
+	SMTPAddr           string        `env:"SMTP_ADDR"`
+	SMTPFrom           string        `env:"SMTP_FROM"`
+	SMTPUsername       string        `env:"SMTP_USERNAME"`
+	SMTPPassword       string        `env:"SMTP_PASSWORD"`
+	WebhookURL         string        `env:"WEBHOOK_URL"`
+	WebhookSecret      string        `env:"WEBHOOK_SECRET"`
 }
 
 func (c *Config) Addr() string {
 	return net.JoinHostPort(c.Host, c.Port)
 }
 
+// AdminPassword reads the admin password from AdminPasswordFile,
+// an empty password means it has to be generated.
+func (c *Config) AdminPassword() (string, error) {
+	if len(c.AdminPasswordFile) == 0 {
+		return "", nil
+	}
+
+	b, err := os.ReadFile(c.AdminPasswordFile)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
 func New() *Config {
 	var cfg Config
 	err := cleanenv.ReadEnv(&cfg)
@@ -27,5 +111,44 @@ func New() *Config {
 		log.Fatal(err)
 	}
 
+	switch cfg.Storage {
+	case StoragePostgres:
+		cfg.Storage = StorageDatabase
+		fallthrough
+	case StorageDatabase:
+		if len(cfg.DatabaseURI) == 0 {
+			log.Fatal("DATABASE_URI is required for database storage")
+		}
+	case StorageMemory:
+	default:
+		log.Fatalf("unknown storage %q, expected one of [database, memory]", cfg.Storage)
+	}
+
+	if cfg.IdempotencyLockTimeout <= 0 {
+		log.Fatal("IDEMPOTENCY_LOCK_TIMEOUT must be positive")
+	}
+
+	if cfg.SimilarCastWeight < 0 || cfg.SimilarEraWeight < 0 || cfg.SimilarRatingWeight < 0 ||
+		cfg.SimilarCastWeight+cfg.SimilarEraWeight+cfg.SimilarRatingWeight == 0 {
+		log.Fatal("similarity weights must not be negative and must not all be zero")
+	}
+
+	switch cfg.NotifyDriver {
+	case "":
+	case NotifySMTP:
+		if len(cfg.SMTPAddr) == 0 || len(cfg.SMTPFrom) == 0 {
+			log.Fatal("SMTP_ADDR and SMTP_FROM are required for smtp notifications")
+		}
+	case NotifyWebhook:
+		if len(cfg.WebhookURL) == 0 {
+			log.Fatal("WEBHOOK_URL is required for webhook notifications")
+		}
+	default:
+		log.Fatalf("unknown notify driver %q, expected one of [smtp, webhook]", cfg.NotifyDriver)
+	}
+	if cfg.NotifyDriver != "" && (cfg.NotifyInterval <= 0 || cfg.NotifyMaxAttempts <= 0 || cfg.NotifyClaimTimeout <= 0) {
+		log.Fatal("NOTIFY_INTERVAL, NOTIFY_MAX_ATTEMPTS and NOTIFY_CLAIM_TIMEOUT must be positive")
+	}
+
 	return &cfg
 }
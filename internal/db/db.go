@@ -3,11 +3,29 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/Coderovshik/film-library/internal/config"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
+var (
+	ErrUnsupportedScheme = errors.New("unsupported database uri scheme")
+)
+
+// sqlitePragmas make SQLite behave like the Postgres schema expects:
+// enforced foreign keys and case-sensitive LIKE.
+var sqlitePragmas = []string{
+	"foreign_keys(1)",
+	"case_sensitive_like(1)",
+	"busy_timeout(5000)",
+}
+
 type DBTX interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	PrepareContext(context.Context, string) (*sql.Stmt, error)
@@ -15,17 +33,86 @@ type DBTX interface {
 	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
 }
 
+// Now returns the current time the way timestamp columns store it: in UTC
+// and truncated to microseconds, the finest precision Postgres keeps.
+func Now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// InTx runs fn over a transaction begun on conn and commits it if fn
+// succeeds. A conn that is a transaction already is used as it is, the
+// caller owning it commits or rolls back.
+func InTx(ctx context.Context, conn DBTX, fn func(tx DBTX) error) error {
+	sdb, ok := conn.(*sql.DB)
+	if !ok {
+		return fn(conn)
+	}
+
+	tx, err := sdb.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 type Database struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect Dialect
+}
+
+// DialectOf returns the dialect matching the scheme of the database uri.
+func DialectOf(uri string) (Dialect, error) {
+	scheme, _, _ := strings.Cut(uri, "://")
+
+	switch scheme {
+	case "postgres", "postgresql":
+		return Postgres, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedScheme, scheme)
+}
+
+func sqliteDSN(uri string) string {
+	_, dsn, _ := strings.Cut(uri, "://")
+
+	params := make([]string, 0, len(sqlitePragmas))
+	for _, v := range sqlitePragmas {
+		params = append(params, "_pragma="+url.QueryEscape(v))
+	}
+
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+
+	return dsn + sep + strings.Join(params, "&")
 }
 
 func NewDatabase(cfg *config.Config) (*Database, error) {
-	db, err := sql.Open("postgres", cfg.DatabaseURI)
+	dialect, err := DialectOf(cfg.DatabaseURI)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Database{db: db}, nil
+	driver, dsn := "postgres", cfg.DatabaseURI
+	if dialect == SQLite {
+		driver, dsn = "sqlite", sqliteDSN(cfg.DatabaseURI)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Database{db: db, dialect: dialect}, nil
 }
 
 func (d *Database) Close() {
@@ -35,3 +122,7 @@ func (d *Database) Close() {
 func (d *Database) GetDB() *sql.DB {
 	return d.db
 }
+
+func (d *Database) GetDialect() Dialect {
+	return d.dialect
+}
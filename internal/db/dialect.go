@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect hides the differences between the SQL databases the repositories
+// run on. Queries use $N placeholders, which both dialects understand.
+type Dialect interface {
+	Name() string
+	// StringAgg returns an aggregate expression concatenating expr values
+	// separated by sep.
+	StringAgg(expr, sep string) string
+	// Year returns an integer expression extracting the year of the date
+	// expression expr.
+	Year(expr string) string
+	// InsertReturningID executes an INSERT statement and returns the value
+	// generated for idColumn.
+	InsertReturningID(ctx context.Context, db DBTX, query, idColumn string, args ...any) (int64, error)
+	IsUniqueViolation(err error) bool
+	// ForeignKeyViolation reports whether err is a foreign key violation and,
+	// if the database tells, the name of the offending column.
+	ForeignKeyViolation(err error) (column string, ok bool)
+}
+
+var (
+	Postgres Dialect = postgresDialect{}
+	SQLite   Dialect = sqliteDialect{}
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) StringAgg(expr, sep string) string {
+	return fmt.Sprintf("STRING_AGG (%s, '%s')", expr, sep)
+}
+
+func (postgresDialect) Year(expr string) string {
+	return fmt.Sprintf("CAST(EXTRACT(YEAR FROM %s) AS INTEGER)", expr)
+}
+
+func (postgresDialect) InsertReturningID(ctx context.Context, db DBTX, query, idColumn string, args ...any) (int64, error) {
+	stmt, err := db.PrepareContext(ctx, query+" RETURNING "+idColumn)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var id int64
+	err = stmt.QueryRowContext(ctx, args...).Scan(&id)
+
+	return id, err
+}
+
+func (postgresDialect) IsUniqueViolation(err error) bool {
+	var pgErr *pq.Error
+	return errors.As(err, &pgErr) && pgErr.Code.Name() == "unique_violation"
+}
+
+// pgKeyDetail matches details like "Key (movie_id)=(42) is not present in table "movie"."
+var pgKeyDetail = regexp.MustCompile(`^Key \((\w+)\)`)
+
+func (postgresDialect) ForeignKeyViolation(err error) (string, bool) {
+	var pgErr *pq.Error
+	if !errors.As(err, &pgErr) || pgErr.Code.Name() != "foreign_key_violation" {
+		return "", false
+	}
+
+	var column string
+	if m := pgKeyDetail.FindStringSubmatch(pgErr.Detail); m != nil {
+		column = m[1]
+	}
+
+	return column, true
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) StringAgg(expr, sep string) string {
+	return fmt.Sprintf("GROUP_CONCAT (%s, '%s')", expr, sep)
+}
+
+// SQLite keeps dates as text starting with the year, in a layout its date
+// functions do not parse.
+func (sqliteDialect) Year(expr string) string {
+	return fmt.Sprintf("CAST(substr(%s, 1, 4) AS INTEGER)", expr)
+}
+
+func (sqliteDialect) InsertReturningID(ctx context.Context, db DBTX, query, idColumn string, args ...any) (int64, error) {
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func sqliteCode(err error) int {
+	var sqlErr *sqlite.Error
+	if !errors.As(err, &sqlErr) {
+		return 0
+	}
+
+	return sqlErr.Code()
+}
+
+func (sqliteDialect) IsUniqueViolation(err error) bool {
+	code := sqliteCode(err)
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// SQLite does not report which foreign key failed.
+func (sqliteDialect) ForeignKeyViolation(err error) (string, bool) {
+	return "", sqliteCode(err) == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
@@ -1,2 +1 @@
-DELETE FROM users
-WHERE user_name = 'admin_user';
\ No newline at end of file
+-- seed data lives in fixtures/, see cmd/seed
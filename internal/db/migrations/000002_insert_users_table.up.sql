@@ -1,3 +1 @@
-INSERT INTO users(user_name, passhash, is_admin)
-VALUES
-    ('admin_user', '$2y$10$9NGc0czSPk8mFDXnNvBCJeYsonhtv8u9tsfS2UgBjPd3IsiDC4m3O', TRUE);
\ No newline at end of file
+-- seed data lives in fixtures/, see cmd/seed
@@ -1,7 +1 @@
-INSERT INTO actor(actor_name, sex, birthday)
-VALUES
-    ('actor1', 'male', '1995-05-03'),
-    ('actor2', 'female', '1994-05-30'),
-    ('actor3', 'male', '1993-05-26'),
-    ('actor4', 'female', '1996-05-17'),
-    ('actor5', 'male', '1997-05-21');
\ No newline at end of file
+-- seed data lives in fixtures/, see cmd/seed
@@ -1,5 +1 @@
-INSERT INTO movie(movie_name, movie_description, releasedate, rating)
-VALUES
-    ('movie1', 'about movie1', '2000-01-12', 5),
-    ('movie2', 'about movie2', '2001-02-12', 7),
-    ('movie3', 'about movie3', '2002-03-12', 10);
\ No newline at end of file
+-- seed data lives in fixtures/, see cmd/seed
@@ -1,9 +1 @@
-INSERT INTO actor_in_movie(actor_id, movie_id)
-VALUES
-    (1, 1),
-    (1, 2),
-    (2, 3),
-    (3, 1),
-    (4, 2),
-    (4, 3),
-    (5, 1);
\ No newline at end of file
+-- seed data lives in fixtures/, see cmd/seed
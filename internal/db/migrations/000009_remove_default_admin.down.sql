@@ -0,0 +1 @@
+-- the default admin is not restored, use filmlib-admin create-admin
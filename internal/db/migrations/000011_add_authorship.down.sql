@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS movie_updated_at_idx;
+ALTER TABLE actor_in_movie
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at,
+    DROP COLUMN created_by,
+    DROP COLUMN updated_by;
+ALTER TABLE actor
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at,
+    DROP COLUMN created_by,
+    DROP COLUMN updated_by;
+ALTER TABLE movie
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at,
+    DROP COLUMN created_by,
+    DROP COLUMN updated_by;
@@ -0,0 +1,16 @@
+ALTER TABLE movie
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    ADD COLUMN updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE actor
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    ADD COLUMN updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE actor_in_movie
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    ADD COLUMN updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS movie_updated_at_idx ON movie(updated_at);
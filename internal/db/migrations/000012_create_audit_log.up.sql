@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS audit_log(
+    audit_id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    entity VARCHAR(16) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    before_state JSONB,
+    after_state JSONB
+);
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log(entity, entity_id);
+CREATE INDEX IF NOT EXISTS audit_log_occurred_at_idx ON audit_log(occurred_at);
@@ -0,0 +1,10 @@
+DELETE FROM movie WHERE deleted_at IS NOT NULL;
+DELETE FROM actor WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS movie_deleted_at_idx;
+DROP INDEX IF EXISTS actor_deleted_at_idx;
+ALTER TABLE movie
+    DROP COLUMN deleted_at,
+    DROP COLUMN deleted_by;
+ALTER TABLE actor
+    DROP COLUMN deleted_at,
+    DROP COLUMN deleted_by;
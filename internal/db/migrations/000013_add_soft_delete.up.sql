@@ -0,0 +1,8 @@
+ALTER TABLE movie
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE actor
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS movie_deleted_at_idx ON movie(deleted_at);
+CREATE INDEX IF NOT EXISTS actor_deleted_at_idx ON actor(deleted_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS movie_search_idx;
+DROP INDEX IF EXISTS actor_search_idx;
+ALTER TABLE movie DROP COLUMN search_vector;
+ALTER TABLE actor DROP COLUMN search_vector;
@@ -0,0 +1,11 @@
+-- the russian configuration stems cyrillic words with the russian stemmer
+-- and latin ones with the english stemmer
+ALTER TABLE movie ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', movie_name), 'A') ||
+    setweight(to_tsvector('russian', movie_description), 'B')
+) STORED;
+ALTER TABLE actor ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', actor_name), 'A')
+) STORED;
+CREATE INDEX IF NOT EXISTS movie_search_idx ON movie USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS actor_search_idx ON actor USING GIN (search_vector);
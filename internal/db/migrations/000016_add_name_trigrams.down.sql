@@ -0,0 +1,3 @@
+-- pg_trgm is kept, it may be used outside of the film library
+DROP INDEX IF EXISTS movie_name_trgm_idx;
+DROP INDEX IF EXISTS actor_name_trgm_idx;
@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS movie_name_trgm_idx ON movie USING GIN (movie_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS actor_name_trgm_idx ON actor USING GIN (actor_name gin_trgm_ops);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS film_views(
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    movie_id INTEGER NOT NULL REFERENCES movie(movie_id) ON DELETE CASCADE,
+    viewed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, movie_id)
+);
+CREATE INDEX IF NOT EXISTS film_views_user_viewed_at_idx ON film_views(user_id, viewed_at);
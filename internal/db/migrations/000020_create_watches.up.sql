@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS watches(
+    watch_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    movie_id INTEGER NOT NULL REFERENCES movie(movie_id) ON DELETE CASCADE,
+    watched_on DATE NOT NULL,
+    rating INTEGER CHECK (rating BETWEEN 1 AND 10),
+    rewatch BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS watches_user_watched_on_idx ON watches(user_id, watched_on);
+CREATE INDEX IF NOT EXISTS watches_user_movie_idx ON watches(user_id, movie_id);
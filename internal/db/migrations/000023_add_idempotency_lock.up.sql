@@ -0,0 +1,3 @@
+ALTER TABLE idempotency_keys ADD COLUMN locked_at TIMESTAMPTZ;
+UPDATE idempotency_keys SET locked_at = created_at;
+ALTER TABLE idempotency_keys ALTER COLUMN locked_at SET NOT NULL;
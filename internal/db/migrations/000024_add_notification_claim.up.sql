@@ -0,0 +1 @@
+ALTER TABLE notifications ADD COLUMN claimed_at TIMESTAMPTZ;
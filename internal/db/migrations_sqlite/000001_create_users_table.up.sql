@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS users(
+    user_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_name VARCHAR UNIQUE NOT NULL,
+    passhash VARCHAR NOT NULL,
+    is_admin BOOLEAN NOT NULL
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS actor(
+    actor_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_name VARCHAR NOT NULL,
+    sex VARCHAR NOT NULL,
+    birthday DATE NOT NULL
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS movie;
\ No newline at end of file
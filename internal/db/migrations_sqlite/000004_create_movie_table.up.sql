@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS movie(
+    movie_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    movie_name VARCHAR NOT NULL,
+    movie_description VARCHAR NOT NULL,
+    releasedate DATE NOT NULL,
+    rating INTEGER NOT NULL
+);
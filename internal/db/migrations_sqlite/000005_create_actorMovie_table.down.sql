@@ -0,0 +1 @@
+DROP TABLE IF EXISTS actor_in_movie;
\ No newline at end of file
@@ -0,0 +1 @@
+-- seed data lives in fixtures/, see cmd/seed
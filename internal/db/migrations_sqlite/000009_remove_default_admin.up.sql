@@ -0,0 +1,3 @@
+DELETE FROM users
+WHERE user_name = 'admin_user'
+    AND passhash = '$2y$10$9NGc0czSPk8mFDXnNvBCJeYsonhtv8u9tsfS2UgBjPd3IsiDC4m3O';
@@ -0,0 +1,2 @@
+ALTER TABLE actor DROP COLUMN version;
+ALTER TABLE movie DROP COLUMN version;
@@ -0,0 +1,2 @@
+ALTER TABLE movie ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE actor ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS movie_updated_at_idx;
+ALTER TABLE actor_in_movie DROP COLUMN created_at;
+ALTER TABLE actor_in_movie DROP COLUMN updated_at;
+ALTER TABLE actor_in_movie DROP COLUMN created_by;
+ALTER TABLE actor_in_movie DROP COLUMN updated_by;
+ALTER TABLE actor DROP COLUMN created_at;
+ALTER TABLE actor DROP COLUMN updated_at;
+ALTER TABLE actor DROP COLUMN created_by;
+ALTER TABLE actor DROP COLUMN updated_by;
+ALTER TABLE movie DROP COLUMN created_at;
+ALTER TABLE movie DROP COLUMN updated_at;
+ALTER TABLE movie DROP COLUMN created_by;
+ALTER TABLE movie DROP COLUMN updated_by;
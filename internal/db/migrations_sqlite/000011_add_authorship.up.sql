@@ -0,0 +1,18 @@
+-- SQLite cannot add columns with a non-constant default, existing rows
+-- are stamped with the migration time instead
+ALTER TABLE movie ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE movie ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE movie ADD COLUMN created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE movie ADD COLUMN updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+UPDATE movie SET created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'), updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+ALTER TABLE actor ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE actor ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE actor ADD COLUMN created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE actor ADD COLUMN updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+UPDATE actor SET created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'), updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+ALTER TABLE actor_in_movie ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE actor_in_movie ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE actor_in_movie ADD COLUMN created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE actor_in_movie ADD COLUMN updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+UPDATE actor_in_movie SET created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'), updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+CREATE INDEX IF NOT EXISTS movie_updated_at_idx ON movie(updated_at);
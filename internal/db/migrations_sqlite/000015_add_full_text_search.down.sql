@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS movie_search_insert;
+DROP TRIGGER IF EXISTS movie_search_delete;
+DROP TRIGGER IF EXISTS movie_search_update;
+DROP TRIGGER IF EXISTS actor_search_insert;
+DROP TRIGGER IF EXISTS actor_search_delete;
+DROP TRIGGER IF EXISTS actor_search_update;
+DROP TABLE IF EXISTS movie_search;
+DROP TABLE IF EXISTS actor_search;
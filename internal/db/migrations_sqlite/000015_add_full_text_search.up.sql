@@ -0,0 +1,36 @@
+-- FTS5 indexes kept in sync with the tables by triggers, the porter
+-- tokenizer stems english words only
+CREATE VIRTUAL TABLE IF NOT EXISTS movie_search USING fts5(
+    movie_name, movie_description,
+    content='movie', content_rowid='movie_id',
+    tokenize='porter unicode61 remove_diacritics 2'
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS actor_search USING fts5(
+    actor_name,
+    content='actor', content_rowid='actor_id',
+    tokenize='porter unicode61 remove_diacritics 2'
+);
+INSERT INTO movie_search(movie_search) VALUES('rebuild');
+INSERT INTO actor_search(actor_search) VALUES('rebuild');
+
+CREATE TRIGGER IF NOT EXISTS movie_search_insert AFTER INSERT ON movie BEGIN
+    INSERT INTO movie_search(rowid, movie_name, movie_description) VALUES (new.movie_id, new.movie_name, new.movie_description);
+END;
+CREATE TRIGGER IF NOT EXISTS movie_search_delete AFTER DELETE ON movie BEGIN
+    INSERT INTO movie_search(movie_search, rowid, movie_name, movie_description) VALUES ('delete', old.movie_id, old.movie_name, old.movie_description);
+END;
+CREATE TRIGGER IF NOT EXISTS movie_search_update AFTER UPDATE OF movie_name, movie_description ON movie BEGIN
+    INSERT INTO movie_search(movie_search, rowid, movie_name, movie_description) VALUES ('delete', old.movie_id, old.movie_name, old.movie_description);
+    INSERT INTO movie_search(rowid, movie_name, movie_description) VALUES (new.movie_id, new.movie_name, new.movie_description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS actor_search_insert AFTER INSERT ON actor BEGIN
+    INSERT INTO actor_search(rowid, actor_name) VALUES (new.actor_id, new.actor_name);
+END;
+CREATE TRIGGER IF NOT EXISTS actor_search_delete AFTER DELETE ON actor BEGIN
+    INSERT INTO actor_search(actor_search, rowid, actor_name) VALUES ('delete', old.actor_id, old.actor_name);
+END;
+CREATE TRIGGER IF NOT EXISTS actor_search_update AFTER UPDATE OF actor_name ON actor BEGIN
+    INSERT INTO actor_search(actor_search, rowid, actor_name) VALUES ('delete', old.actor_id, old.actor_name);
+    INSERT INTO actor_search(rowid, actor_name) VALUES (new.actor_id, new.actor_name);
+END;
@@ -0,0 +1 @@
+-- autocomplete on sqlite uses a trigram index kept by the application
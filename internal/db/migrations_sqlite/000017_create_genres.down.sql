@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS genre_in_movie;
+DROP TABLE IF EXISTS genre;
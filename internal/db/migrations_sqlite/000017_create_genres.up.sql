@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS genre(
+    genre_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    genre_name VARCHAR(50) UNIQUE NOT NULL
+);
+CREATE TABLE IF NOT EXISTS genre_in_movie(
+    genre_id INTEGER NOT NULL REFERENCES genre(genre_id) ON DELETE CASCADE,
+    movie_id INTEGER NOT NULL REFERENCES movie(movie_id) ON DELETE CASCADE,
+    PRIMARY KEY (movie_id, genre_id)
+);
+CREATE INDEX IF NOT EXISTS genre_in_movie_genre_idx ON genre_in_movie(genre_id);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS lists(
+    list_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    list_name VARCHAR(100) NOT NULL,
+    list_description VARCHAR(1000) NOT NULL DEFAULT '',
+    kind VARCHAR(16) NOT NULL,
+    visibility VARCHAR(16) NOT NULL,
+    share_token VARCHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, list_name)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS lists_user_kind_idx ON lists(user_id, kind) WHERE kind <> 'custom';
+CREATE INDEX IF NOT EXISTS lists_visibility_idx ON lists(visibility);
+CREATE TABLE IF NOT EXISTS list_entries(
+    list_id INTEGER NOT NULL REFERENCES lists(list_id) ON DELETE CASCADE,
+    movie_id INTEGER NOT NULL REFERENCES movie(movie_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    note VARCHAR(500) NOT NULL DEFAULT '',
+    added_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (list_id, movie_id)
+);
+CREATE INDEX IF NOT EXISTS list_entries_movie_idx ON list_entries(movie_id);
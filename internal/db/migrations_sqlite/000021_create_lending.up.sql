@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS copies(
+    copy_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    movie_id INTEGER NOT NULL REFERENCES movie(movie_id) ON DELETE CASCADE,
+    format VARCHAR(16) NOT NULL,
+    barcode VARCHAR(64) UNIQUE NOT NULL,
+    condition VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS copies_movie_idx ON copies(movie_id);
+CREATE TABLE IF NOT EXISTS loans(
+    loan_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    copy_id INTEGER NOT NULL REFERENCES copies(copy_id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    checked_out_at TIMESTAMP NOT NULL,
+    due_at TIMESTAMP NOT NULL,
+    returned_at TIMESTAMP,
+    renewals INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS loans_active_copy_idx ON loans(copy_id) WHERE returned_at IS NULL;
+CREATE INDEX IF NOT EXISTS loans_user_idx ON loans(user_id);
+CREATE TABLE IF NOT EXISTS holds(
+    hold_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    movie_id INTEGER NOT NULL REFERENCES movie(movie_id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (movie_id, user_id)
+);
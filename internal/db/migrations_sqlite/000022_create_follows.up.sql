@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS follows(
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    actor_id INTEGER NOT NULL REFERENCES actor(actor_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, actor_id)
+);
+CREATE INDEX IF NOT EXISTS follows_actor_idx ON follows(actor_id);
+CREATE TABLE IF NOT EXISTS notification_emails(
+    user_id INTEGER PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    email VARCHAR(254) NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS notifications(
+    notification_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    actor_id INTEGER NOT NULL REFERENCES actor(actor_id) ON DELETE CASCADE,
+    movie_id INTEGER NOT NULL REFERENCES movie(movie_id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    next_attempt_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP,
+    UNIQUE (user_id, actor_id, movie_id)
+);
+CREATE INDEX IF NOT EXISTS notifications_due_idx ON notifications(status, next_attempt_at);
@@ -0,0 +1,2 @@
+ALTER TABLE idempotency_keys ADD COLUMN locked_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+UPDATE idempotency_keys SET locked_at = created_at;
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+var (
+	ErrUnknownVersion = errors.New("no migration with given version")
+)
+
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+)
+
+// Step is a single migration the migrator would run.
+type Step struct {
+	Version    uint
+	Identifier string
+	Direction  string
+}
+
+func (s Step) String() string {
+	return fmt.Sprintf("%d/%s %s", s.Version, s.Direction, s.Identifier)
+}
+
+// Version is the state of a database schema, Applied is false when
+// no migration has been run yet.
+type Version struct {
+	Version uint
+	Applied bool
+}
+
+func isEnd(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// Versions returns all migration versions of the source in ascending order.
+func Versions(src source.Driver) ([]uint, error) {
+	var versions []uint
+
+	v, err := src.First()
+	for err == nil {
+		versions = append(versions, v)
+		v, err = src.Next(v)
+	}
+	if !isEnd(err) {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func step(src source.Driver, v uint, direction string) (Step, error) {
+	read := src.ReadUp
+	if direction == DirectionDown {
+		read = src.ReadDown
+	}
+
+	r, identifier, err := read(v)
+	if err != nil && !isEnd(err) {
+		return Step{}, err
+	}
+	if r != nil {
+		r.Close()
+	}
+
+	return Step{Version: v, Identifier: identifier, Direction: direction}, nil
+}
+
+// PlanUp returns the up migrations pending after cur, at most n of them
+// when n > 0.
+func PlanUp(src source.Driver, cur Version, n int) ([]Step, error) {
+	versions, err := Versions(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []Step
+	for _, v := range versions {
+		if cur.Applied && v <= cur.Version {
+			continue
+		}
+		if n > 0 && len(steps) == n {
+			break
+		}
+
+		s, err := step(src, v, DirectionUp)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s)
+	}
+
+	return steps, nil
+}
+
+// PlanDown returns the down migrations rolling back cur, at most n of them
+// when n > 0.
+func PlanDown(src source.Driver, cur Version, n int) ([]Step, error) {
+	if !cur.Applied {
+		return nil, nil
+	}
+
+	versions, err := Versions(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []Step
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		if v > cur.Version {
+			continue
+		}
+		if n > 0 && len(steps) == n {
+			break
+		}
+
+		s, err := step(src, v, DirectionDown)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s)
+	}
+
+	return steps, nil
+}
+
+// PlanGoto returns the migrations moving the schema from cur to target.
+func PlanGoto(src source.Driver, cur Version, target uint) ([]Step, error) {
+	versions, err := Versions(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(versions, target) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+	}
+
+	var up, down int
+	for _, v := range versions {
+		switch {
+		case v <= target && (!cur.Applied || v > cur.Version):
+			up++
+		case v > target && cur.Applied && v <= cur.Version:
+			down++
+		}
+	}
+
+	if down > 0 {
+		return PlanDown(src, cur, down)
+	}
+	if up > 0 {
+		return PlanUp(src, cur, up)
+	}
+
+	return nil, nil
+}
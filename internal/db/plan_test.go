@@ -0,0 +1,71 @@
+package db
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Coderovshik/film-library/internal/config"
+)
+
+func versionsOf(steps []Step) []uint {
+	v := make([]uint, 0, len(steps))
+	for _, s := range steps {
+		v = append(v, s.Version)
+	}
+
+	return v
+}
+
+func TestPlan(t *testing.T) {
+	src, err := NewMigrationSource(&config.Config{DatabaseURI: "sqlite://filmlib.db"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	defer src.Close()
+
+	all, err := Versions(src)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(all) < 5 || all[0] != 1 {
+		t.Fatalf("Unexpected versions %v", all)
+	}
+	last := all[len(all)-1]
+
+	tests := []struct {
+		name string
+		plan func() ([]Step, error)
+		exp  []uint
+	}{
+		{"up all from scratch", func() ([]Step, error) { return PlanUp(src, Version{}, 0) }, all},
+		{"up 2 from 3", func() ([]Step, error) { return PlanUp(src, Version{3, true}, 2) }, []uint{4, 5}},
+		{"up at last", func() ([]Step, error) { return PlanUp(src, Version{last, true}, 0) }, []uint{}},
+		{"down 2 from 3", func() ([]Step, error) { return PlanDown(src, Version{3, true}, 2) }, []uint{3, 2}},
+		{"down all from 3", func() ([]Step, error) { return PlanDown(src, Version{3, true}, 0) }, []uint{3, 2, 1}},
+		{"down from scratch", func() ([]Step, error) { return PlanDown(src, Version{}, 0) }, []uint{}},
+		{"goto up", func() ([]Step, error) { return PlanGoto(src, Version{1, true}, 3) }, []uint{2, 3}},
+		{"goto down", func() ([]Step, error) { return PlanGoto(src, Version{4, true}, 2) }, []uint{4, 3}},
+		{"goto same", func() ([]Step, error) { return PlanGoto(src, Version{2, true}, 2) }, []uint{}},
+	}
+
+	for _, tt := range tests {
+		steps, err := tt.plan()
+		if err != nil {
+			t.Fatalf("%s: no error expected, got %s", tt.name, err.Error())
+		}
+		if v := versionsOf(steps); !slices.Equal(v, tt.exp) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.exp, v)
+		}
+	}
+
+	steps, _ := PlanDown(src, Version{1, true}, 0)
+	if len(steps) != 1 || steps[0].Direction != DirectionDown || steps[0].Identifier != "create_users_table" {
+		t.Errorf("Unexpected steps %+v", steps)
+	}
+
+	_, err = PlanGoto(src, Version{}, last+1)
+	if !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Expected %v, got %v", ErrUnknownVersion, err)
+	}
+}
@@ -0,0 +1,73 @@
+package db_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/autocomplete"
+	"github.com/Coderovshik/film-library/internal/collection"
+	"github.com/Coderovshik/film-library/internal/config"
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/follow"
+	"github.com/Coderovshik/film-library/internal/graph"
+	"github.com/Coderovshik/film-library/internal/history"
+	"github.com/Coderovshik/film-library/internal/idempotency"
+	"github.com/Coderovshik/film-library/internal/importer"
+	"github.com/Coderovshik/film-library/internal/lending"
+	"github.com/Coderovshik/film-library/internal/recommend"
+	"github.com/Coderovshik/film-library/internal/search"
+	"github.com/Coderovshik/film-library/internal/storagetest"
+	"github.com/Coderovshik/film-library/internal/user"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// TestPostgresConformance runs against the database given in
+// TEST_DATABASE_URI and wipes all of its data.
+func TestPostgresConformance(t *testing.T) {
+	uri := os.Getenv("TEST_DATABASE_URI")
+	if len(uri) == 0 {
+		t.Skip("TEST_DATABASE_URI is not set")
+	}
+	cfg := &config.Config{DatabaseURI: uri}
+
+	m := db.NewMigrator(cfg)
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	database, err := db.NewDatabase(cfg)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	defer database.Close()
+
+	storagetest.Run(t, func(t *testing.T) *storagetest.Repositories {
+		const query = `TRUNCATE notifications, notification_emails, follows, holds, loans, copies, watches, list_entries, lists, film_views, idempotency_keys, audit_log, genre_in_movie, genre, actor_in_movie, movie, actor, users RESTART IDENTITY`
+		if _, err := database.GetDB().Exec(query); err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+
+		return &storagetest.Repositories{
+			Films:  film.NewRepository(database.GetDB(), database.GetDialect()),
+			Actors: actor.NewRepository(database.GetDB(), database.GetDialect()),
+			Users:  user.NewRepository(database.GetDB(), database.GetDialect()),
+			Audit:  audit.NewRepository(database.GetDB(), database.GetDialect()),
+			Tx:     importer.NewDatabaseTransactor(database.GetDB(), database.GetDialect()),
+
+			Idempotency: idempotency.NewRepository(database.GetDB(), database.GetDialect()),
+			Search:      search.NewRepository(database.GetDB(), database.GetDialect()),
+
+			Autocomplete: autocomplete.NewRepository(database.GetDB(), database.GetDialect()),
+			Graph:        graph.NewRepository(database.GetDB(), database.GetDialect()),
+			Views:        recommend.NewRepository(database.GetDB(), database.GetDialect()),
+			Collections:  collection.NewRepository(database.GetDB(), database.GetDialect()),
+			History:      history.NewRepository(database.GetDB(), database.GetDialect()),
+			Lending:      lending.NewRepository(database.GetDB(), database.GetDialect()),
+			Follow:       follow.NewRepository(database.GetDB(), database.GetDialect()),
+		}
+	})
+}
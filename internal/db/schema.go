@@ -2,19 +2,46 @@ package db
 
 import (
 	"embed"
+	"io/fs"
 	"log"
 
 	"github.com/Coderovshik/film-library/internal/config"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
 //go:embed migrations/*.sql
 var schemaFS embed.FS
 
+//go:embed migrations_sqlite/*.sql
+var sqliteSchemaFS embed.FS
+
+// MigrationDirs lists the migration directories of every dialect,
+// relative to this package.
+var MigrationDirs = []string{"migrations", "migrations_sqlite"}
+
+// NewMigrationSource returns the embedded migrations for the dialect
+// of the database uri.
+func NewMigrationSource(cfg *config.Config) (source.Driver, error) {
+	dialect, err := DialectOf(cfg.DatabaseURI)
+	if err != nil {
+		return nil, err
+	}
+
+	var fsys fs.FS = schemaFS
+	path := "migrations"
+	if dialect == SQLite {
+		fsys, path = sqliteSchemaFS, "migrations_sqlite"
+	}
+
+	return iofs.New(fsys, path)
+}
+
 func NewMigrator(cfg *config.Config) *migrate.Migrate {
-	d, err := iofs.New(schemaFS, "migrations")
+	d, err := NewMigrationSource(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
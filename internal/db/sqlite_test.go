@@ -0,0 +1,103 @@
+package db_test
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/autocomplete"
+	"github.com/Coderovshik/film-library/internal/collection"
+	"github.com/Coderovshik/film-library/internal/config"
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/follow"
+	"github.com/Coderovshik/film-library/internal/graph"
+	"github.com/Coderovshik/film-library/internal/history"
+	"github.com/Coderovshik/film-library/internal/idempotency"
+	"github.com/Coderovshik/film-library/internal/importer"
+	"github.com/Coderovshik/film-library/internal/lending"
+	"github.com/Coderovshik/film-library/internal/recommend"
+	"github.com/Coderovshik/film-library/internal/search"
+	"github.com/Coderovshik/film-library/internal/storagetest"
+	"github.com/Coderovshik/film-library/internal/user"
+)
+
+// openSQLite returns a migrated database in a temporary file.
+func openSQLite(t *testing.T) *db.Database {
+	cfg := &config.Config{
+		DatabaseURI: "sqlite://" + filepath.Join(t.TempDir(), "filmlib.db"),
+	}
+
+	m := db.NewMigrator(cfg)
+	if err := m.Up(); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	m.Close()
+
+	database, err := db.NewDatabase(cfg)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	t.Cleanup(database.Close)
+
+	return database
+}
+
+func TestSQLiteConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) *storagetest.Repositories {
+		database := openSQLite(t)
+
+		return &storagetest.Repositories{
+			Films:  film.NewRepository(database.GetDB(), database.GetDialect()),
+			Actors: actor.NewRepository(database.GetDB(), database.GetDialect()),
+			Users:  user.NewRepository(database.GetDB(), database.GetDialect()),
+			Audit:  audit.NewRepository(database.GetDB(), database.GetDialect()),
+			Tx:     importer.NewDatabaseTransactor(database.GetDB(), database.GetDialect()),
+
+			Idempotency: idempotency.NewRepository(database.GetDB(), database.GetDialect()),
+			Search:      search.NewRepository(database.GetDB(), database.GetDialect()),
+
+			Autocomplete: autocomplete.NewRepository(database.GetDB(), database.GetDialect()),
+			Graph:        graph.NewRepository(database.GetDB(), database.GetDialect()),
+			Views:        recommend.NewRepository(database.GetDB(), database.GetDialect()),
+			Collections:  collection.NewRepository(database.GetDB(), database.GetDialect()),
+			History:      history.NewRepository(database.GetDB(), database.GetDialect()),
+			Lending:      lending.NewRepository(database.GetDB(), database.GetDialect()),
+			Follow:       follow.NewRepository(database.GetDB(), database.GetDialect()),
+		}
+	})
+}
+
+func TestSQLiteUpdateFilmRollsBackGenres(t *testing.T) {
+	ctx := context.TODO()
+	database := openSQLite(t)
+	r := film.NewRepository(database.GetDB(), database.GetDialect())
+
+	f, err := r.AddFilm(ctx, &film.Film{Name: "film1", ReleaseDate: time.Date(2000, 1, 12, 0, 0, 0, 0, time.UTC), Genres: []string{"drama"}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	const trigger = `CREATE TRIGGER genre_fail BEFORE INSERT ON genre_in_movie
+		BEGIN SELECT RAISE(ABORT, 'genre insert failed'); END`
+	if _, err := database.GetDB().Exec(trigger); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	name, genres := "film2", []string{"comedy"}
+	if err := r.UpdateFilm(ctx, &film.FilmUpdate{ID: f.ID, Name: &name, Genres: &genres}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	got, err := r.GetFilm(ctx, f.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got.Name != "film1" || got.Version != 1 || !slices.Equal(got.Genres, []string{"drama"}) {
+		t.Errorf("Expected film left as it was, got %+v", got)
+	}
+}
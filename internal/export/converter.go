@@ -0,0 +1,101 @@
+package export
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+func ToFilmRow(f *film.Film, cast []*film.ActorShort) *FilmRow {
+	res := film.ToFilmResponse(f)
+
+	row := &FilmRow{
+		ID:        res.ID,
+		Info:      res.Info,
+		Actors:    make([]Ref, 0, len(cast)),
+		CreatedAt: res.CreatedAt,
+		UpdatedAt: res.UpdatedAt,
+		CreatedBy: res.CreatedBy,
+		UpdatedBy: res.UpdatedBy,
+	}
+	for _, v := range cast {
+		row.Actors = append(row.Actors, Ref{ID: v.ID, Name: v.Name})
+	}
+
+	return row
+}
+
+func ToActorRow(a *actor.Actor, films []*actor.FilmShort) *ActorRow {
+	res := actor.ToActorResponse(a)
+
+	row := &ActorRow{
+		ID:        res.ID,
+		Info:      res.Info,
+		Films:     make([]Ref, 0, len(films)),
+		CreatedAt: res.CreatedAt,
+		UpdatedAt: res.UpdatedAt,
+		CreatedBy: res.CreatedBy,
+		UpdatedBy: res.UpdatedBy,
+	}
+	for _, v := range films {
+		row.Films = append(row.Films, Ref{ID: v.ID, Name: v.Name})
+	}
+
+	return row
+}
+
+func (r *FilmRow) Record() []string {
+	ids, names := refColumns(r.Actors)
+
+	return []string{strconv.Itoa(r.ID), r.Info.Name, r.Info.Description, r.Info.ReleaseDate,
+		strconv.Itoa(r.Info.Rating), ids, names, r.CreatedAt, r.UpdatedAt,
+		util.FormatID(r.CreatedBy), util.FormatID(r.UpdatedBy)}
+}
+
+func (r *ActorRow) Record() []string {
+	ids, names := refColumns(r.Films)
+
+	return []string{strconv.Itoa(r.ID), r.Info.Name, r.Info.Sex, r.Info.Birthday,
+		ids, names, r.CreatedAt, r.UpdatedAt,
+		util.FormatID(r.CreatedBy), util.FormatID(r.UpdatedBy)}
+}
+
+// refColumns joins ids and names of the references with ";".
+func refColumns(refs []Ref) (string, string) {
+	ids := make([]string, 0, len(refs))
+	names := make([]string, 0, len(refs))
+	for _, v := range refs {
+		ids = append(ids, strconv.Itoa(int(v.ID)))
+		names = append(names, v.Name)
+	}
+
+	return strings.Join(ids, ";"), strings.Join(names, ";")
+}
+
+func ToFilmExportQuery(req *FilmsRequest) *film.ExportQuery {
+	// validated by ValidateFilmsRequest, empty values give zero values
+	q := film.ToQuery(&req.GetFilmsRequest)
+	q.Sort = nil
+	after, _ := strconv.ParseUint(req.Cursor, 10, 31)
+	limit, _ := strconv.Atoi(req.Limit)
+
+	return &film.ExportQuery{
+		Query: *q,
+		After: int32(after),
+		Limit: limit,
+	}
+}
+
+func ToActorExportQuery(req *ActorsRequest) *actor.ExportQuery {
+	// validated by ValidateActorsRequest, empty values give zero values
+	after, _ := strconv.ParseUint(req.Cursor, 10, 31)
+	limit, _ := strconv.Atoi(req.Limit)
+
+	return &actor.ExportQuery{
+		After: int32(after),
+		Limit: limit,
+	}
+}
@@ -0,0 +1,90 @@
+// Package export streams the catalog as JSON, NDJSON or CSV. Rows come
+// in id order, so an interrupted export resumes after the last id read.
+package export
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+var mediaTypes = map[string]string{
+	FormatJSON:   "application/json",
+	FormatNDJSON: "application/x-ndjson",
+	FormatCSV:    "text/csv",
+}
+
+var (
+	FilmColumns = []string{"id", "name", "description", "releasedate", "rating",
+		"actor_ids", "actors", "created_at", "updated_at", "created_by", "updated_by"}
+	ActorColumns = []string{"id", "name", "sex", "birthday",
+		"film_ids", "films", "created_at", "updated_at", "created_by", "updated_by"}
+)
+
+type ExportService interface {
+	ExportFilms(ctx context.Context, req *FilmsRequest, rw RowWriter) error
+	ExportActors(ctx context.Context, req *ActorsRequest, rw RowWriter) error
+}
+
+type ExportHandler interface {
+	ExportFilms(w http.ResponseWriter, r *http.Request)
+	ExportActors(w http.ResponseWriter, r *http.Request)
+}
+
+// RowWriter encodes exported rows, Close finishes the document.
+type RowWriter interface {
+	WriteRow(row Row) error
+	Close() error
+}
+
+// Row is an exported record, its CSV record follows the columns of its kind.
+type Row interface {
+	Record() []string
+}
+
+// Ref names a bound film or actor.
+type Ref struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type FilmRow struct {
+	ID        int           `json:"id"`
+	Info      film.FilmInfo `json:"info"`
+	Actors    []Ref         `json:"actors"`
+	CreatedAt string        `json:"createdAt"`
+	UpdatedAt string        `json:"updatedAt"`
+	CreatedBy *int32        `json:"createdBy,omitempty"`
+	UpdatedBy *int32        `json:"updatedBy,omitempty"`
+}
+
+type ActorRow struct {
+	ID        int             `json:"id"`
+	Info      actor.ActorInfo `json:"info"`
+	Films     []Ref           `json:"films"`
+	CreatedAt string          `json:"createdAt"`
+	UpdatedAt string          `json:"updatedAt"`
+	CreatedBy *int32          `json:"createdBy,omitempty"`
+	UpdatedBy *int32          `json:"updatedBy,omitempty"`
+}
+
+// Cursor is the id of the last row already read.
+// FilmsRequest takes the filters of GET /films, its sort is ignored.
+type FilmsRequest struct {
+	film.GetFilmsRequest
+	Cursor string
+	Limit  string
+}
+
+type ActorsRequest struct {
+	Cursor string
+	Limit  string
+}
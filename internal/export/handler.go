@@ -0,0 +1,132 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ ExportHandler = (*Handler)(nil)
+
+type Handler struct {
+	service ExportService
+}
+
+func NewHandler(es ExportService) *Handler {
+	return &Handler{
+		service: es,
+	}
+}
+
+// stream sends the response headers with the first byte written,
+// so that an export failing before that still gets an error status.
+type stream struct {
+	w        http.ResponseWriter
+	format   string
+	filename string
+	started  bool
+}
+
+func (s *stream) Write(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		s.w.Header().Set("content-type", util.ContentType(mediaTypes[s.format]))
+		s.w.Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, s.filename, s.format))
+		s.w.WriteHeader(http.StatusOK)
+	}
+
+	return s.w.Write(p)
+}
+
+// format is taken from the format parameter or negotiated by Accept.
+func format(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if f := r.URL.Query().Get("format"); len(f) != 0 {
+		if _, ok := mediaTypes[f]; !ok {
+			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeValidation,
+				Body:      "incorrect format, expected one of: json, ndjson, csv",
+			})
+			return "", false
+		}
+
+		return f, true
+	}
+
+	mt, ok := util.Negotiate(r.Header.Get("accept"),
+		mediaTypes[FormatJSON], mediaTypes[FormatNDJSON], mediaTypes[FormatCSV])
+	if !ok {
+		log.Printf("ERROR: no acceptable media type for %q\n", r.Header.Get("accept"))
+		util.NotAcceptable(w, r)
+		return "", false
+	}
+	for k, v := range mediaTypes {
+		if v == mt {
+			return k, true
+		}
+	}
+
+	return FormatJSON, true
+}
+
+func (h *Handler) ExportFilms(w http.ResponseWriter, r *http.Request) {
+	f, ok := format(w, r)
+	if !ok {
+		return
+	}
+
+	fr := film.ToGetFilmsRequest(util.Query(r))
+	if uc, ok := util.UserClaimsFromContext(r.Context()); ok {
+		fr.UserID = int32(uc.ID)
+	}
+
+	s := &stream{w: w, format: f, filename: "films"}
+	err := h.service.ExportFilms(r.Context(), &FilmsRequest{
+		GetFilmsRequest: *fr,
+		Cursor:          r.URL.Query().Get("cursor"),
+		Limit:           r.URL.Query().Get("limit"),
+	}, NewRowWriter(f, s, FilmColumns))
+	if err != nil {
+		log.Printf("ERROR: failed to export films err=%s\n", err.Error())
+		exportError(w, r, s, err)
+	}
+}
+
+func (h *Handler) ExportActors(w http.ResponseWriter, r *http.Request) {
+	f, ok := format(w, r)
+	if !ok {
+		return
+	}
+
+	s := &stream{w: w, format: f, filename: "actors"}
+	err := h.service.ExportActors(r.Context(), &ActorsRequest{
+		Cursor: r.URL.Query().Get("cursor"),
+		Limit:  r.URL.Query().Get("limit"),
+	}, NewRowWriter(f, s, ActorColumns))
+	if err != nil {
+		log.Printf("ERROR: failed to export actors err=%s\n", err.Error())
+		exportError(w, r, s, err)
+	}
+}
+
+// exportError aborts the response once rows are sent, a truncated export
+// must not pass for a complete one.
+func exportError(w http.ResponseWriter, r *http.Request, s *stream, err error) {
+	if s.started {
+		panic(http.ErrAbortHandler)
+	}
+
+	var ve *util.ValidationError
+	if errors.As(err, &ve) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		})
+		return
+	}
+
+	util.InternalServerError(w, r)
+}
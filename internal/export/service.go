@@ -0,0 +1,76 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+var _ ExportService = (*Service)(nil)
+
+type Service struct {
+	films  film.FilmRepository
+	actors actor.ActorRepository
+}
+
+func NewService(fr film.FilmRepository, ar actor.ActorRepository) *Service {
+	return &Service{
+		films:  fr,
+		actors: ar,
+	}
+}
+
+// ExportFilms validates the request before writing anything.
+func (s *Service) ExportFilms(ctx context.Context, req *FilmsRequest, rw RowWriter) error {
+	const op = "export.Service.ExportFilms"
+
+	vErr := ValidateFilmsRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	err := s.films.ExportFilms(ctx, ToFilmExportQuery(req), func(f *film.Film, cast []*film.ActorShort) error {
+		return rw.WriteRow(ToFilmRow(f, cast))
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to export films from repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := rw.Close(); err != nil {
+		log.Printf("ERROR: failed to finish films export\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ExportActors validates the request before writing anything.
+func (s *Service) ExportActors(ctx context.Context, req *ActorsRequest, rw RowWriter) error {
+	const op = "export.Service.ExportActors"
+
+	vErr := ValidateActorsRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	err := s.actors.ExportActors(ctx, ToActorExportQuery(req), func(a *actor.Actor, films []*actor.FilmShort) error {
+		return rw.WriteRow(ToActorRow(a, films))
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to export actors from repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := rw.Close(); err != nil {
+		log.Printf("ERROR: failed to finish actors export\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestService_ExportFilms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := film.NewMockFilmRepository(ctrl)
+	am := actor.NewMockActorRepository(ctrl)
+
+	s := NewService(fm, am)
+
+	ratingMin := int32(5)
+	q := &film.ExportQuery{
+		Query: film.Query{
+			Actor:         "actor",
+			ActorIDs:      []int32{1, 2},
+			ActorMatchAll: true,
+			RatingMin:     &ratingMin,
+			UpdatedSince:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		After: 3,
+		Limit: 2,
+	}
+	f := &film.Film{ID: 4, Name: "film4", Actors: []string{"actor1"}}
+	cast := []*film.ActorShort{{ID: 1, Name: "actor1"}}
+	fm.EXPECT().ExportFilms(gomock.Any(), q, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, q *film.ExportQuery, fn func(*film.Film, []*film.ActorShort) error) error {
+			return fn(f, cast)
+		}).Times(1)
+
+	var sb strings.Builder
+	err := s.ExportFilms(context.TODO(), &FilmsRequest{
+		GetFilmsRequest: film.GetFilmsRequest{
+			SortQuery:         "name,asc",
+			ActorQuery:        "actor",
+			ActorIDQuery:      []string{"1", "2", "1"},
+			ActorMatchQuery:   film.ActorMatchAll,
+			RatingMinQuery:    "5",
+			UpdatedSinceQuery: "2024-03-01T00:00:00Z",
+		},
+		Cursor: "3",
+		Limit:  "2",
+	}, NewRowWriter(FormatNDJSON, &sb, FilmColumns))
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	var row FilmRow
+	if err := json.Unmarshal([]byte(sb.String()), &row); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := ToFilmRow(f, cast); !reflect.DeepEqual(exp, &row) {
+		t.Errorf("Expected %+v, got %+v", exp, &row)
+	}
+}
+
+func TestService_ExportActors_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := film.NewMockFilmRepository(ctrl)
+	am := actor.NewMockActorRepository(ctrl)
+
+	s := NewService(fm, am)
+
+	// nothing is written before the request is validated
+	var sb strings.Builder
+	err := s.ExportActors(context.TODO(), &ActorsRequest{Cursor: "-1", Limit: "0"}, NewRowWriter(FormatJSON, &sb, ActorColumns))
+	var ve *util.ValidationError
+	if !errors.As(err, &ve) || sb.Len() != 0 {
+		t.Errorf("Expected validation error and no output, got %v, %q", err, sb.String())
+	}
+}
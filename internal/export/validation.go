@@ -0,0 +1,45 @@
+package export
+
+import (
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+func ValidateFilmsRequest(req *FilmsRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if fErr := film.ValidateGetFilmsRequest(&req.GetFilmsRequest); fErr != nil {
+		ve.AddViolation(fErr.Error())
+	}
+	validatePage(ve, req.Cursor, req.Limit)
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateActorsRequest(req *ActorsRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	validatePage(ve, req.Cursor, req.Limit)
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func validatePage(ve *util.ValidationError, cursor, limit string) {
+	if _, err := strconv.ParseUint(cursor, 10, 31); err != nil && len(cursor) != 0 {
+		ve.AddViolation("incorrect cursor, expected id of the last row read")
+	}
+
+	if n, err := strconv.Atoi(limit); len(limit) != 0 && (err != nil || n < 1) {
+		ve.AddViolation("incorrect limit, expected positive integer")
+	}
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// NewRowWriter returns a writer of the format, nothing is written to w
+// before the first row or Close.
+func NewRowWriter(format string, w io.Writer, columns []string) RowWriter {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonWriter{w: w}
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w), columns: columns}
+	}
+
+	return &jsonWriter{w: w}
+}
+
+// jsonWriter writes an array of rows.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (jw *jsonWriter) WriteRow(row Row) error {
+	b, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	sep := ","
+	if jw.count == 0 {
+		sep = "["
+	}
+	jw.count++
+
+	_, err = fmt.Fprintf(jw.w, "%s%s", sep, b)
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	end := "]\n"
+	if jw.count == 0 {
+		end = "[]\n"
+	}
+
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
+
+// ndjsonWriter writes a row per line.
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+func (nw *ndjsonWriter) WriteRow(row Row) error {
+	b, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(nw.w, "%s\n", b)
+	return err
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
+
+// csvWriter writes the header before the first row, even if there is none.
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+	started bool
+}
+
+func (cw *csvWriter) start() error {
+	if cw.started {
+		return nil
+	}
+	cw.started = true
+
+	return cw.w.Write(cw.columns)
+}
+
+func (cw *csvWriter) WriteRow(row Row) error {
+	if err := cw.start(); err != nil {
+		return err
+	}
+
+	return cw.w.Write(row.Record())
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.start(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+
+	return cw.w.Error()
+}
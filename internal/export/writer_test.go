@@ -0,0 +1,48 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRowWriter(t *testing.T) {
+	createdBy := int32(1)
+	rows := []Row{
+		&FilmRow{ID: 1, Actors: []Ref{{ID: 2, Name: "actor2"}, {ID: 3, Name: "actor; 3"}}, CreatedBy: &createdBy},
+		&FilmRow{ID: 4, Actors: []Ref{}},
+	}
+
+	tests := []struct {
+		format string
+		rows   []Row
+		exp    string
+	}{
+		{format: FormatJSON, exp: "[]\n"},
+		{format: FormatJSON, rows: rows, exp: `[{"id":1,"info":{"name":"","description":"","releasedate":"","rating":0},` +
+			`"actors":[{"id":2,"name":"actor2"},{"id":3,"name":"actor; 3"}],"createdAt":"","updatedAt":"","createdBy":1},` +
+			`{"id":4,"info":{"name":"","description":"","releasedate":"","rating":0},"actors":[],"createdAt":"","updatedAt":""}]` + "\n"},
+		{format: FormatNDJSON, rows: rows[1:], exp: `{"id":4,"info":{"name":"","description":"","releasedate":"","rating":0},` +
+			`"actors":[],"createdAt":"","updatedAt":""}` + "\n"},
+		{format: FormatCSV, exp: strings.Join(FilmColumns, ",") + "\n"},
+		{format: FormatCSV, rows: rows, exp: strings.Join(FilmColumns, ",") + "\n" +
+			"1,,,,0,2;3,actor2;actor; 3,,,1,\n" +
+			"4,,,,0,,,,,,\n"},
+	}
+
+	for _, v := range tests {
+		var sb strings.Builder
+		rw := NewRowWriter(v.format, &sb, FilmColumns)
+		for _, row := range v.rows {
+			if err := rw.WriteRow(row); err != nil {
+				t.Fatalf("No error expected, got %s", err.Error())
+			}
+		}
+		if err := rw.Close(); err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+
+		if sb.String() != v.exp {
+			t.Errorf("Expected %s output %q, got %q", v.format, v.exp, sb.String())
+		}
+	}
+}
@@ -2,108 +2,407 @@ package film
 
 import (
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Coderovshik/film-library/internal/util"
 )
 
-func ToQueryableLists(fa *FilmActors, format string) (string, []any) {
+// ToQueryableLists formats a placeholder per actor, the film id is $1
+// and the extra values follow it.
+func ToQueryableLists(fa *FilmActors, format string, extra ...any) (string, []any) {
 	n := len(fa.ActorIDs)
 
-	values := make([]any, 0, n+1)
+	values := make([]any, 0, n+len(extra)+1)
 	values = append(values, fa.ID)
+	values = append(values, extra...)
 
 	qs := make([]string, 0, n)
 
 	for i := 0; i < n; i++ {
-		qs = append(qs, fmt.Sprintf(format, i+2))
+		qs = append(qs, fmt.Sprintf(format, i+len(extra)+2))
 		values = append(values, fa.ActorIDs[i])
 	}
 
 	return strings.Join(qs, ", "), values
 }
 
-func ToQueryableObject(a *Film) *util.QueryableObject {
+func ToQueryableObject(fu *FilmUpdate) *util.QueryableObject {
 	qo := util.NewQueryableObject()
 
-	if len(a.Name) != 0 {
-		qo.Add("movie_name", a.Name)
+	if fu.Name != nil {
+		qo.Add("movie_name", *fu.Name)
 	}
 
-	if len(a.Description) != 0 {
-		qo.Add("movie_description", a.Description)
+	if fu.Description != nil {
+		qo.Add("movie_description", *fu.Description)
 	}
 
-	if !a.ReleaseDate.IsZero() {
-		qo.Add("releasedate", a.ReleaseDate)
+	if fu.ReleaseDate != nil {
+		qo.Add("releasedate", *fu.ReleaseDate)
 	}
 
-	if a.Rating >= 0 {
-		qo.Add("rating", a.Rating)
+	if fu.Rating != nil {
+		qo.Add("rating", *fu.Rating)
 	}
 
 	return qo
 }
 
+// ToFilmUpdate replaces every column of the film.
+func ToFilmUpdate(f *Film) *FilmUpdate {
+	return &FilmUpdate{
+		ID:          f.ID,
+		Version:     f.Version,
+		Name:        &f.Name,
+		Description: &f.Description,
+		ReleaseDate: &f.ReleaseDate,
+		Rating:      &f.Rating,
+		Genres:      &f.Genres,
+	}
+}
+
+// PatchToFilmUpdate expects a validated patch.
+func PatchToFilmUpdate(id, version int32, fp *FilmPatch) *FilmUpdate {
+	fu := &FilmUpdate{
+		ID:          id,
+		Version:     version,
+		Name:        fp.Name.Ptr(),
+		Description: fp.Description.Ptr(),
+	}
+
+	if fp.ReleaseDate.Set {
+		releaseDate, _ := time.Parse("2006-01-02", fp.ReleaseDate.Value)
+		fu.ReleaseDate = &releaseDate
+	}
+
+	if fp.Rating.Set {
+		rating := int32(fp.Rating.Value)
+		fu.Rating = &rating
+	}
+
+	if fp.Genres.Set {
+		genres := ToGenres(fp.Genres.Value)
+		fu.Genres = &genres
+	}
+
+	return fu
+}
+
 var sortMap = map[string]string{
-	"name":        "movie_name",
-	"rating":      "rating",
-	"releasedate": "releasedate",
+	"name":        "m.movie_name",
+	"rating":      "m.rating",
+	"releasedate": "m.releasedate",
 }
 
-func ToQueryConditions(q *Query) [3]string {
-	var conditions [3]string
+// conditions collects WHERE conditions with their placeholder values.
+type conditions struct {
+	where  []string
+	values []any
+}
 
-	var filmCon string
-	if len(q.Film) != 0 {
-		pattern := "'%" + q.Film + "%'"
-		filmCon = "WHERE movie_name LIKE " + pattern
+// add formats the placeholders of cond, one per value, numbered after the
+// values added before.
+func (c *conditions) add(cond string, values ...any) {
+	args := make([]any, 0, len(values))
+	for _, v := range values {
+		c.values = append(c.values, v)
+		args = append(args, len(c.values))
 	}
-	conditions[0] = filmCon
+	c.where = append(c.where, fmt.Sprintf(cond, args...))
+}
+
+func (c *conditions) String() string {
+	return "WHERE " + strings.Join(c.where, " AND ")
+}
+
+// placeholders adds the values and returns their placeholders separated
+// by commas.
+func (c *conditions) placeholders(values []int32) string {
+	ps := make([]string, 0, len(values))
+	for _, v := range values {
+		c.values = append(c.values, v)
+		ps = append(ps, fmt.Sprintf("$%d", len(c.values)))
+	}
+
+	return strings.Join(ps, ", ")
+}
+
+// filterConditions selects films not in the trash matching the query,
+// every value given by the user is passed as a placeholder.
+func filterConditions(q *Query) *conditions {
+	c := &conditions{where: []string{"m.deleted_at IS NULL"}}
 
-	var actorCon string
+	if len(q.Film) != 0 {
+		if q.FilmExact {
+			c.add("m.movie_name = $%d", q.Film)
+		} else {
+			c.add(`m.movie_name LIKE $%d ESCAPE '\'`, "%"+util.EscapeLike(q.Film)+"%")
+		}
+	}
 	if len(q.Actor) != 0 {
-		pattern := "'%" + q.Actor + "%'"
-		actorCon = "HAVING STRING_AGG (a.actor_name, ';') LIKE " + pattern
+		c.add(`EXISTS (SELECT 1 FROM actor_in_movie fam
+			JOIN actor fa ON fa.actor_id = fam.actor_id AND fa.deleted_at IS NULL
+			WHERE fam.movie_id = m.movie_id AND fa.actor_name LIKE $%d ESCAPE '\')`, "%"+util.EscapeLike(q.Actor)+"%")
+	}
+	if len(q.ActorIDs) != 0 {
+		ids := c.placeholders(q.ActorIDs)
+		cast := fmt.Sprintf(`SELECT COUNT(DISTINCT fam.actor_id) FROM actor_in_movie fam
+			JOIN actor fa ON fa.actor_id = fam.actor_id AND fa.deleted_at IS NULL
+			WHERE fam.movie_id = m.movie_id AND fam.actor_id IN (%s)`, ids)
+		if q.ActorMatchAll {
+			c.add("("+cast+") = $%d", len(q.ActorIDs))
+		} else {
+			c.add("(" + cast + ") > 0")
+		}
+	}
+	if len(q.GenreIDs) != 0 {
+		ids := c.placeholders(q.GenreIDs)
+		c.add(fmt.Sprintf(`EXISTS (SELECT 1 FROM genre_in_movie fgm
+			WHERE fgm.movie_id = m.movie_id AND fgm.genre_id IN (%s))`, ids))
+	}
+	if q.RatingMin != nil {
+		c.add("m.rating >= $%d", *q.RatingMin)
+	}
+	if q.RatingMax != nil {
+		c.add("m.rating <= $%d", *q.RatingMax)
+	}
+	if !q.ReleasedFrom.IsZero() {
+		c.add("m.releasedate >= $%d", q.ReleasedFrom)
+	}
+	if !q.ReleasedTo.IsZero() {
+		c.add("m.releasedate <= $%d", q.ReleasedTo)
+	}
+	if !q.UpdatedSince.IsZero() {
+		c.add("m.updated_at >= $%d", q.UpdatedSince.UTC())
+	}
+	if q.Seen != nil {
+		seen := "EXISTS (SELECT 1 FROM watches fw WHERE fw.movie_id = m.movie_id AND fw.user_id = $%d)"
+		if !*q.Seen {
+			seen = "NOT " + seen
+		}
+		c.add(seen, q.SeenBy)
+	}
+
+	return c
+}
+
+// ToQueryConditions returns WHERE and ORDER BY clauses and the values of
+// their placeholders.
+func ToQueryConditions(q *Query) (string, string, []any) {
+	c := filterConditions(q)
+
+	order := make([]string, 0, len(q.Sort)+1)
+	if len(q.Sort) == 0 {
+		order = append(order, "m.rating DESC")
+	}
+	for _, v := range q.Sort {
+		dir := "ASC"
+		if v.Desc {
+			dir = "DESC"
+		}
+		order = append(order, sortMap[v.Field]+" "+dir)
+	}
+	order = append(order, "m.movie_id")
+
+	return c.String(), "ORDER BY " + strings.Join(order, ", "), c.values
+}
+
+// ToExportConditions returns the WHERE clause selecting films of the
+// export and the values of its placeholders.
+func ToExportConditions(q *ExportQuery) (string, []any) {
+	c := filterConditions(&q.Query)
+	if q.After != 0 {
+		c.add("m.movie_id > $%d", q.After)
+	}
+
+	return c.String(), c.values
+}
+
+// ToSortKeys expects a validated sort query.
+func ToSortKeys(sortQuery string) []SortKey {
+	if len(sortQuery) == 0 {
+		return nil
+	}
+
+	var keys []SortKey
+	for _, v := range strings.Split(sortQuery, ";") {
+		field, dir, _ := strings.Cut(v, ",")
+		keys = append(keys, SortKey{Field: field, Desc: dir == "desc"})
+	}
+
+	return keys
+}
+
+// ToGetFilmsRequest reads the film filters from query parameters.
+func ToGetFilmsRequest(v url.Values) *GetFilmsRequest {
+	return &GetFilmsRequest{
+		SortQuery:         v.Get("sort"),
+		FilmQuery:         v.Get("film"),
+		NameMatchQuery:    v.Get("nameMatch"),
+		ActorQuery:        v.Get("actor"),
+		ActorIDQuery:      v["actorId"],
+		ActorMatchQuery:   v.Get("actorMatch"),
+		GenreIDQuery:      v["genreId"],
+		RatingMinQuery:    v.Get("ratingMin"),
+		RatingMaxQuery:    v.Get("ratingMax"),
+		ReleasedFromQuery: v.Get("releasedFrom"),
+		ReleasedToQuery:   v.Get("releasedTo"),
+		UpdatedSinceQuery: v.Get("updatedSince"),
+		FacetsQuery:       v.Get("facets"),
+		SeenQuery:         v.Get("seen"),
+	}
+}
+
+// ToFacetNames expects a validated facets query, repeated names are
+// dropped.
+func ToFacetNames(facetsQuery string) []string {
+	if len(facetsQuery) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, v := range strings.Split(facetsQuery, ",") {
+		if !slices.Contains(names, v) {
+			names = append(names, v)
+		}
 	}
-	conditions[1] = actorCon
 
-	var sortCon string
-	if q.Sort == nil {
-		sortCon = "ORDER BY rating DESC"
-	} else {
-		sortCon = fmt.Sprintf("ORDER BY %s %s",
-			sortMap[q.Sort[0]], strings.ToUpper(q.Sort[1]))
+	return names
+}
+
+// FacetQuery returns the query without the filter on the facet, so the
+// counts show what choosing another value of the facet would give.
+func FacetQuery(q *Query, name string) *Query {
+	fq := *q
+	fq.Sort = nil
+
+	switch name {
+	case FacetRating:
+		fq.RatingMin, fq.RatingMax = nil, nil
+	case FacetDecade:
+		fq.ReleasedFrom, fq.ReleasedTo = time.Time{}, time.Time{}
+	case FacetActor:
+		fq.ActorIDs, fq.ActorMatchAll = nil, false
+	case FacetGenre:
+		fq.GenreIDs = nil
+	}
+
+	return &fq
+}
+
+// ToFacetConditions returns the WHERE clause of the films counted by the
+// facet and the values of its placeholders.
+func ToFacetConditions(q *Query, name string) (string, []any) {
+	c := filterConditions(FacetQuery(q, name))
+
+	return c.String(), c.values
+}
+
+func ToFacetResponse(f *Facet) *FacetResponse {
+	res := &FacetResponse{
+		Name:   f.Name,
+		Values: make([]*FacetValueResponse, 0, len(f.Counts)),
+	}
+	for _, v := range f.Counts {
+		res.Values = append(res.Values, &FacetValueResponse{
+			Value: int(v.Value),
+			Name:  v.Name,
+			Count: v.Count,
+		})
 	}
-	conditions[2] = sortCon
 
-	return conditions
+	return res
 }
 
+// ToQuery expects a validated request, empty values give zero values.
 func ToQuery(req *GetFilmsRequest) *Query {
-	var sort []string
-	if len(req.SortQuery) != 0 {
-		sort = strings.Split(req.SortQuery, ",")
+	updatedSince, _ := time.Parse(time.RFC3339Nano, req.UpdatedSinceQuery)
+	releasedFrom, _ := time.Parse(time.DateOnly, req.ReleasedFromQuery)
+	releasedTo, _ := time.Parse(time.DateOnly, req.ReleasedToQuery)
+
+	q := &Query{
+		Sort:          ToSortKeys(req.SortQuery),
+		Film:          req.FilmQuery,
+		FilmExact:     req.NameMatchQuery == NameMatchExact,
+		Actor:         req.ActorQuery,
+		ActorMatchAll: req.ActorMatchQuery == ActorMatchAll,
+		ReleasedFrom:  releasedFrom,
+		ReleasedTo:    releasedTo,
+		UpdatedSince:  updatedSince,
+	}
+
+	q.ActorIDs = toIDs(req.ActorIDQuery)
+	q.GenreIDs = toIDs(req.GenreIDQuery)
+
+	if len(req.RatingMinQuery) != 0 {
+		n, _ := strconv.Atoi(req.RatingMinQuery)
+		ratingMin := int32(n)
+		q.RatingMin = &ratingMin
 	}
+	if len(req.RatingMaxQuery) != 0 {
+		n, _ := strconv.Atoi(req.RatingMaxQuery)
+		ratingMax := int32(n)
+		q.RatingMax = &ratingMax
+	}
+	if len(req.SeenQuery) != 0 {
+		seen := req.SeenQuery == "true"
+		q.Seen, q.SeenBy = &seen, req.UserID
+	}
+
+	return q
+}
 
-	return &Query{
-		Sort:  sort,
-		Film:  req.FilmQuery,
-		Actor: req.ActorQuery,
+// toIDs expects validated ids, repeated ones are dropped.
+func toIDs(values []string) []int32 {
+	var ids []int32
+	for _, v := range values {
+		id, _ := strconv.ParseInt(v, 10, 32)
+		if !slices.Contains(ids, int32(id)) {
+			ids = append(ids, int32(id))
+		}
 	}
+
+	return ids
 }
 
 func ToFilmResponse(f *Film) *FilmResponse {
-	return &FilmResponse{
+	res := &FilmResponse{
 		ID: int(f.ID),
 		Info: FilmInfo{
 			Name:        f.Name,
 			Description: f.Description,
 			ReleaseDate: f.ReleaseDate.Format("2006-01-02"),
 			Rating:      int(f.Rating),
+			Genres:      f.Genres,
 		},
-		Actors: f.Actors,
+		Actors:    f.Actors,
+		CreatedAt: f.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt: f.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		CreatedBy: f.CreatedBy,
+		UpdatedBy: f.UpdatedBy,
+		DeletedBy: f.DeletedBy,
+	}
+	if f.DeletedAt != nil {
+		res.DeletedAt = f.DeletedAt.UTC().Format(time.RFC3339Nano)
+	}
+	res.ETag = util.ETag(f.Version, res)
+
+	return res
+}
+
+func ToFilmState(f *Film) *FilmState {
+	res := ToFilmResponse(f)
+	if res.Actors == nil {
+		res.Actors = []string{}
+	}
+
+	return &FilmState{
+		Info:   res.Info,
+		Actors: res.Actors,
 	}
 }
 
@@ -115,9 +414,22 @@ func ToFilm(fi *FilmInfo) *Film {
 		Description: fi.Description,
 		ReleaseDate: releaseDate,
 		Rating:      int32(fi.Rating),
+		Genres:      ToGenres(fi.Genres),
 	}
 }
 
+// ToGenres trims and lower-cases the genre names, sorts them and drops
+// the repeated ones.
+func ToGenres(names []string) []string {
+	genres := make([]string, 0, len(names))
+	for _, v := range names {
+		genres = append(genres, strings.ToLower(strings.TrimSpace(v)))
+	}
+	slices.Sort(genres)
+
+	return slices.Compact(genres)
+}
+
 func ToActorIDs32(ids []int) []int32 {
 	a := make([]int32, 0, len(ids))
 	for _, v := range ids {
@@ -0,0 +1,123 @@
+package film
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToQuery(t *testing.T) {
+	v := url.Values{
+		"sort":         {"rating,desc;name,asc"},
+		"film":         {"Kill"},
+		"nameMatch":    {"exact"},
+		"actorId":      {"2", "1", "2"},
+		"actorMatch":   {"all"},
+		"genreId":      {"3"},
+		"ratingMin":    {"5"},
+		"ratingMax":    {"9"},
+		"releasedFrom": {"1990-01-01"},
+		"releasedTo":   {"1999-12-31"},
+		"seen":         {"false"},
+	}
+
+	ratingMin, ratingMax, seen := int32(5), int32(9), false
+	exp := &Query{
+		Sort:          []SortKey{{Field: "rating", Desc: true}, {Field: "name"}},
+		Film:          "Kill",
+		FilmExact:     true,
+		ActorIDs:      []int32{2, 1},
+		ActorMatchAll: true,
+		GenreIDs:      []int32{3},
+		RatingMin:     &ratingMin,
+		RatingMax:     &ratingMax,
+		ReleasedFrom:  time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		ReleasedTo:    time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC),
+		Seen:          &seen,
+		SeenBy:        7,
+	}
+
+	req := ToGetFilmsRequest(v)
+	req.UserID = 7
+	res := ToQuery(req)
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+}
+
+func TestToQueryConditions(t *testing.T) {
+	ratingMin := int32(5)
+	q := &Query{
+		Sort:          []SortKey{{Field: "rating", Desc: true}, {Field: "name"}},
+		Film:          "50%' OR '1'='1",
+		Actor:         "Uma",
+		ActorIDs:      []int32{1, 2},
+		ActorMatchAll: true,
+		RatingMin:     &ratingMin,
+	}
+
+	where, order, values := ToQueryConditions(q)
+	if strings.Contains(where, "OR '1'='1") || strings.Contains(where, "Uma") {
+		t.Errorf("Expected user values in placeholders only, got %s", where)
+	}
+	if !strings.Contains(where, "IN ($3, $4)) = $5") || !strings.Contains(where, "m.rating >= $6") {
+		t.Errorf("Expected numbered placeholders, got %s", where)
+	}
+
+	expValues := []any{`%50\%' OR '1'='1%`, "%Uma%", int32(1), int32(2), 2, int32(5)}
+	if !reflect.DeepEqual(expValues, values) {
+		t.Errorf("Expected %+v, got %+v", expValues, values)
+	}
+
+	expOrder := "ORDER BY m.rating DESC, m.movie_name ASC, m.movie_id"
+	if order != expOrder {
+		t.Errorf("Expected %s, got %s", expOrder, order)
+	}
+
+	_, order, _ = ToQueryConditions(&Query{})
+	if expOrder := "ORDER BY m.rating DESC, m.movie_id"; order != expOrder {
+		t.Errorf("Expected %s, got %s", expOrder, order)
+	}
+}
+
+func TestToFacetNames(t *testing.T) {
+	exp := []string{FacetActor, FacetRating}
+	res := ToFacetNames("actor,rating,actor")
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+}
+
+func TestFacetQuery(t *testing.T) {
+	ratingMin := int32(5)
+	q := &Query{
+		Sort:          []SortKey{{Field: "name"}},
+		Actor:         "Uma",
+		ActorIDs:      []int32{1, 2},
+		ActorMatchAll: true,
+		GenreIDs:      []int32{3},
+		RatingMin:     &ratingMin,
+		ReleasedFrom:  time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+		exp  *Query
+	}{
+		{FacetRating, &Query{Actor: "Uma", ActorIDs: []int32{1, 2}, ActorMatchAll: true, GenreIDs: []int32{3}, ReleasedFrom: q.ReleasedFrom}},
+		{FacetDecade, &Query{Actor: "Uma", ActorIDs: []int32{1, 2}, ActorMatchAll: true, GenreIDs: []int32{3}, RatingMin: &ratingMin}},
+		{FacetActor, &Query{Actor: "Uma", GenreIDs: []int32{3}, RatingMin: &ratingMin, ReleasedFrom: q.ReleasedFrom}},
+		{FacetGenre, &Query{Actor: "Uma", ActorIDs: []int32{1, 2}, ActorMatchAll: true, RatingMin: &ratingMin, ReleasedFrom: q.ReleasedFrom}},
+	}
+	for _, tt := range tests {
+		res := FacetQuery(q, tt.name)
+		if !reflect.DeepEqual(tt.exp, res) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.exp, res)
+		}
+	}
+	if len(q.Sort) == 0 || q.RatingMin == nil || len(q.ActorIDs) == 0 {
+		t.Errorf("Expected the query to be left as it is, got %+v", q)
+	}
+}
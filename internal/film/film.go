@@ -2,8 +2,12 @@ package film
 
 import (
 	"context"
+	"encoding/xml"
 	"net/http"
 	"time"
+
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/util"
 )
 
 type Film struct {
@@ -13,28 +17,86 @@ type Film struct {
 	ReleaseDate time.Time `json:"releasedate"`
 	Rating      int32     `json:"rating"`
 	Actors      []string  `json:"actors"`
+	Genres      []string  `json:"genres"`
+	Version     int32     `json:"version"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	CreatedBy   *int32    `json:"createdBy"`
+	UpdatedBy   *int32    `json:"updatedBy"`
+	// DeletedAt is set for films in the trash.
+	DeletedAt *time.Time `json:"deletedAt"`
+	DeletedBy *int32     `json:"deletedBy"`
+}
+
+// FilmUpdate lists the columns to change, nil fields are left as they are.
+// Version is the expected row version, zero skips the check. Genres
+// replaces every genre of the film.
+type FilmUpdate struct {
+	ID          int32
+	Version     int32
+	Name        *string
+	Description *string
+	ReleaseDate *time.Time
+	Rating      *int32
+	Genres      *[]string
 }
 
 type FilmRepository interface {
+	// Atomic runs fn on a repository and an audit recorder bound to a
+	// single transaction, committed if fn returns nil.
+	Atomic(ctx context.Context, fn func(fr FilmRepository, rec audit.Recorder) error) error
 	GetFilm(ctx context.Context, id int32) (*Film, error)
 	AddFilm(ctx context.Context, f *Film) (*Film, error)
-	DeleteFilm(ctx context.Context, id int32) error
-	UpdateFilm(ctx context.Context, f *Film) error
+	DeleteFilm(ctx context.Context, id int32, version int32) error
+	UpdateFilm(ctx context.Context, fu *FilmUpdate) error
 	GetFilms(ctx context.Context, q *Query) ([]*Film, error)
 	GetFilmActors(ctx context.Context, id int32) ([]*ActorShort, error)
 	AddFilmActors(ctx context.Context, fa *FilmActors) error
 	DeleteFilmActors(ctx context.Context, fa *FilmActors) error
+	RestoreFilm(ctx context.Context, id int32) error
+	GetDeletedFilms(ctx context.Context) ([]*Film, error)
+	// PurgeFilms deletes the films in the trash since before for good and
+	// returns their ids.
+	PurgeFilms(ctx context.Context, before time.Time) ([]int32, error)
+	// ExportFilms calls fn for every selected film with its cast ordered by
+	// actor id, the films are streamed without being loaded all at once.
+	ExportFilms(ctx context.Context, q *ExportQuery, fn func(f *Film, cast []*ActorShort) error) error
+	// GetFilmFacets counts the films of the query for every named facet,
+	// leaving out the filter on the facet itself.
+	GetFilmFacets(ctx context.Context, q *Query, names []string) ([]*Facet, error)
+}
+
+// EventActorsBound tells that ActorIDs were bound to the film, either
+// when it was added or later.
+const EventActorsBound = "actors_bound"
+
+// Event is a change of a film other packages react to, it is published
+// once the change is made.
+type Event struct {
+	Type       string
+	FilmID     int32
+	ActorIDs   []int32
+	OccurredAt time.Time
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, e *Event) error
 }
 
 type FilmService interface {
 	GetFilms(ctx context.Context, req *GetFilmsRequest) ([]*FilmResponse, error)
+	// GetFilmsFacets returns the films of GetFilms with the facet counts
+	// requested by req.FacetsQuery.
+	GetFilmsFacets(ctx context.Context, req *GetFilmsRequest) (*FilmsFacetsResponse, error)
 	AddFilm(ctx context.Context, req *AddFilmRequest) (*FilmResponse, error)
 	GetFilm(ctx context.Context, req *FilmIdRequest) (*FilmResponse, error)
 	UpdateFilm(ctx context.Context, req *FilmIdInfoRequest) (*FilmResponse, error)
+	PatchFilm(ctx context.Context, req *FilmPatchRequest) (*FilmResponse, error)
 	DeleteFilm(ctx context.Context, req *FilmIdRequest) (*FilmResponse, error)
 	GetFilmActors(ctx context.Context, req *FilmIdRequest) ([]*ActorShortResponse, error)
 	AddFilmActors(ctx context.Context, req *FilmActorsRequest) ([]*ActorShortResponse, error)
 	DeleteFilmActors(ctx context.Context, req *FilmActorsRequest) ([]*ActorShortResponse, error)
+	RestoreFilm(ctx context.Context, req *FilmIdRequest) (*FilmResponse, error)
 }
 
 type FilmHandler interface {
@@ -42,18 +104,80 @@ type FilmHandler interface {
 	AddFilm(w http.ResponseWriter, r *http.Request)
 	GetFilm(w http.ResponseWriter, r *http.Request)
 	UpdateFilm(w http.ResponseWriter, r *http.Request)
+	PatchFilm(w http.ResponseWriter, r *http.Request)
 	DeleteFilm(w http.ResponseWriter, r *http.Request)
 	GetFilmActors(w http.ResponseWriter, r *http.Request)
 	AddFilmActors(w http.ResponseWriter, r *http.Request)
 	DeleteFilmActors(w http.ResponseWriter, r *http.Request)
+	RestoreFilm(w http.ResponseWriter, r *http.Request)
 }
 
 type Filmhandler interface{}
 
+// Query selects films, zero fields do not filter. Film is matched as a
+// substring of the name unless FilmExact is set, a film has to feature
+// every one of ActorIDs with ActorMatchAll or else any of them, and any
+// one of GenreIDs.
 type Query struct {
-	Sort  []string
-	Actor string
-	Film  string
+	Sort          []SortKey
+	Actor         string
+	Film          string
+	FilmExact     bool
+	ActorIDs      []int32
+	ActorMatchAll bool
+	GenreIDs      []int32
+	RatingMin     *int32
+	RatingMax     *int32
+	ReleasedFrom  time.Time
+	ReleasedTo    time.Time
+	UpdatedSince  time.Time
+	// Seen keeps the films SeenBy the user watched, or the ones not
+	// watched when false.
+	Seen   *bool
+	SeenBy int32
+}
+
+// SortKey is a film field to sort by, films equal by every key are
+// ordered by id.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// ExportQuery selects films in id order starting after the cursor,
+// zero limit selects all of them. The sort of the query is ignored.
+type ExportQuery struct {
+	Query
+	After int32
+	Limit int
+}
+
+const (
+	FacetRating = "rating"
+	FacetDecade = "decade"
+	FacetActor  = "actor"
+	FacetGenre  = "genre"
+
+	// MaxActorFacetCounts limits the actor facet to the most frequent actors.
+	MaxActorFacetCounts = 20
+	// MaxGenreFacetCounts limits the genre facet to the most frequent genres.
+	MaxGenreFacetCounts = 20
+)
+
+// FacetCount is the number of films sharing a facet value: a rating, the
+// first year of a decade, an actor or a genre id, Name is set for actors
+// and genres.
+type FacetCount struct {
+	Value int32
+	Name  string
+	Count int
+}
+
+// Facet lists the counts of its values, ratings and decades are ordered
+// from the highest, actors and genres by count and then by name.
+type Facet struct {
+	Name   string
+	Counts []*FacetCount
 }
 
 type FilmActors struct {
@@ -67,9 +191,22 @@ type ActorShort struct {
 }
 
 type GetFilmsRequest struct {
-	SortQuery  string
-	FilmQuery  string
-	ActorQuery string
+	SortQuery         string
+	FilmQuery         string
+	NameMatchQuery    string
+	ActorQuery        string
+	ActorIDQuery      []string
+	ActorMatchQuery   string
+	GenreIDQuery      []string
+	RatingMinQuery    string
+	RatingMaxQuery    string
+	ReleasedFromQuery string
+	ReleasedToQuery   string
+	UpdatedSinceQuery string
+	FacetsQuery       string
+	SeenQuery         string
+	// UserID is the user the seen filter is applied for.
+	UserID int32
 }
 
 type AddFilmRequest struct {
@@ -77,26 +214,78 @@ type AddFilmRequest struct {
 	ActorIDs []int    `json:"actorIds"`
 }
 
+// FilmInfo lists genres by name, they are kept lower-cased and sorted.
 type FilmInfo struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	ReleaseDate string `json:"releasedate"`
-	Rating      int    `json:"rating"`
+	Name        string   `json:"name" xml:"name" yaml:"name"`
+	Description string   `json:"description" xml:"description" yaml:"description"`
+	ReleaseDate string   `json:"releasedate" xml:"releasedate" yaml:"releasedate"`
+	Rating      int      `json:"rating" xml:"rating" yaml:"rating"`
+	Genres      []string `json:"genres,omitempty" xml:"genres>genre,omitempty" yaml:"genres,omitempty"`
 }
 
 type FilmResponse struct {
-	ID     int      `json:"id"`
-	Info   FilmInfo `json:"info"`
-	Actors []string `json:"actors,omitempty"`
+	ID        int      `json:"id" xml:"id" yaml:"id"`
+	Info      FilmInfo `json:"info" xml:"info" yaml:"info"`
+	Actors    []string `json:"actors,omitempty" xml:"actors>actor,omitempty" yaml:"actors,omitempty"`
+	CreatedAt string   `json:"createdAt" xml:"createdAt" yaml:"createdAt"`
+	UpdatedAt string   `json:"updatedAt" xml:"updatedAt" yaml:"updatedAt"`
+	CreatedBy *int32   `json:"createdBy,omitempty" xml:"createdBy,omitempty" yaml:"createdBy,omitempty"`
+	UpdatedBy *int32   `json:"updatedBy,omitempty" xml:"updatedBy,omitempty" yaml:"updatedBy,omitempty"`
+	DeletedAt string   `json:"deletedAt,omitempty" xml:"deletedAt,omitempty" yaml:"deletedAt,omitempty"`
+	DeletedBy *int32   `json:"deletedBy,omitempty" xml:"deletedBy,omitempty" yaml:"deletedBy,omitempty"`
+	ETag      string   `json:"-" xml:"-" yaml:"-"`
+}
+
+type FacetValueResponse struct {
+	Value int    `json:"value" xml:"value,attr" yaml:"value"`
+	Name  string `json:"name,omitempty" xml:"name,attr,omitempty" yaml:"name,omitempty"`
+	Count int    `json:"count" xml:"count,attr" yaml:"count"`
+}
+
+type FacetResponse struct {
+	Name   string                `json:"name" xml:"name,attr" yaml:"name"`
+	Values []*FacetValueResponse `json:"values" xml:"value" yaml:"values"`
+}
+
+// FilmsFacetsResponse is the film list response when facets are asked for.
+type FilmsFacetsResponse struct {
+	XMLName xml.Name         `json:"-" xml:"filmList" yaml:"-"`
+	Films   FilmList         `json:"films" xml:"films" yaml:"films"`
+	Facets  []*FacetResponse `json:"facets" xml:"facets>facet" yaml:"facets"`
 }
 
+// IfMatch holds the If-Match header, it is ignored by reads.
 type FilmIdRequest struct {
-	ID string
+	ID      string
+	IfMatch string
 }
 
 type FilmIdInfoRequest struct {
-	ID   string
-	Info FilmInfo
+	ID      string
+	IfMatch string
+	Info    FilmInfo
+}
+
+// FilmPatch is a JSON Merge Patch of FilmInfo. A null description or
+// genres clears them, the other fields cannot be null.
+type FilmPatch struct {
+	Name        util.PatchField[string]   `json:"name"`
+	Description util.PatchField[string]   `json:"description"`
+	ReleaseDate util.PatchField[string]   `json:"releasedate"`
+	Rating      util.PatchField[int]      `json:"rating"`
+	Genres      util.PatchField[[]string] `json:"genres"`
+}
+
+type FilmPatchRequest struct {
+	ID      string
+	IfMatch string
+	Patch   FilmPatch
+}
+
+// FilmState is the film as recorded in the audit log.
+type FilmState struct {
+	Info   FilmInfo `json:"info"`
+	Actors []string `json:"actors"`
 }
 
 type FilmActorsRequest struct {
@@ -105,6 +294,6 @@ type FilmActorsRequest struct {
 }
 
 type ActorShortResponse struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID   int    `json:"id" xml:"id" yaml:"id"`
+	Name string `json:"name" xml:"name" yaml:"name"`
 }
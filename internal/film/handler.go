@@ -21,11 +21,16 @@ func NewHandler(fs FilmService) *Handler {
 }
 
 func (h *Handler) GetFilms(w http.ResponseWriter, r *http.Request) {
-	res, err := h.service.GetFilms(r.Context(), &GetFilmsRequest{
-		SortQuery:  r.URL.Query().Get("sort"),
-		FilmQuery:  r.URL.Query().Get("film"),
-		ActorQuery: r.URL.Query().Get("actor"),
-	})
+	req := ToGetFilmsRequest(util.Query(r))
+	if uc, ok := util.UserClaimsFromContext(r.Context()); ok {
+		req.UserID = int32(uc.ID)
+	}
+	if len(req.FacetsQuery) != 0 {
+		h.getFilmsFacets(w, r, req)
+		return
+	}
+
+	res, err := h.service.GetFilms(r.Context(), req)
 	if err != nil {
 		log.Printf("ERROR: failed to get films err=%s\n", err.Error())
 
@@ -42,7 +47,30 @@ func (h *Handler) GetFilms(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.JSON(w, r, http.StatusOK, res)
+	util.Render(w, r, http.StatusOK, FilmList(res))
+}
+
+// getFilmsFacets responds with an object holding the films and the facets,
+// it has no CSV form.
+func (h *Handler) getFilmsFacets(w http.ResponseWriter, r *http.Request, req *GetFilmsRequest) {
+	res, err := h.service.GetFilmsFacets(r.Context(), req)
+	if err != nil {
+		log.Printf("ERROR: failed to get films with facets err=%s\n", err.Error())
+
+		var ve *util.ValidationError
+		if errors.As(err, &ve) {
+			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeValidation,
+				Body:      ve.Error(),
+			})
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.Render(w, r, http.StatusOK, res)
 }
 
 func (h *Handler) AddFilm(w http.ResponseWriter, r *http.Request) {
@@ -103,12 +131,19 @@ func (h *Handler) GetFilm(w http.ResponseWriter, r *http.Request) {
 		util.InternalServerError(w, r)
 		return
 	}
+	if util.CheckNotModified(w, r, res.ETag) {
+		return
+	}
 
 	util.JSON(w, r, http.StatusOK, res)
 }
 
 func (h *Handler) UpdateFilm(w http.ResponseWriter, r *http.Request) {
 	var req FilmIdInfoRequest
+	var ok bool
+	if req.IfMatch, ok = util.RequireIfMatch(w, r); !ok {
+		return
+	}
 	if ok := util.BindJSON(w, r, &req.Info); !ok {
 		return
 	}
@@ -117,40 +152,75 @@ func (h *Handler) UpdateFilm(w http.ResponseWriter, r *http.Request) {
 	res, err := h.service.UpdateFilm(r.Context(), &req)
 	if err != nil {
 		log.Printf("ERROR: failed to update film err=%s\n", err.Error())
+		updateError(w, r, err)
+		return
+	}
 
-		if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrFilmNotExist) {
-			util.NotFound(w, r)
-			return
-		}
-
-		var ve *util.ValidationError
-		if errors.As(err, &ve) {
-			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
-				ErrorType: util.ErrorTypeValidation,
-				Body:      ve.Error(),
-			})
-			return
-		}
+	w.Header().Set("etag", res.ETag)
+	util.JSON(w, r, http.StatusOK, res)
+}
 
-		if errors.Is(err, ErrEmptyUpdate) {
-			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
-				ErrorType: util.ErrorTypeValidation,
-				Body:      "empty update",
-			})
-			return
-		}
+func (h *Handler) PatchFilm(w http.ResponseWriter, r *http.Request) {
+	var req FilmPatchRequest
+	var ok bool
+	if req.IfMatch, ok = util.RequireIfMatch(w, r); !ok {
+		return
+	}
+	if ok := util.BindMergePatch(w, r, &req.Patch); !ok {
+		return
+	}
+	req.ID = r.PathValue("id")
 
-		util.InternalServerError(w, r)
+	res, err := h.service.PatchFilm(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to patch film err=%s\n", err.Error())
+		updateError(w, r, err)
 		return
 	}
 
+	w.Header().Set("etag", res.ETag)
 	util.JSON(w, r, http.StatusOK, res)
 }
 
+func updateError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrFilmNotExist) {
+		util.NotFound(w, r)
+		return
+	}
+
+	var ve *util.ValidationError
+	if errors.As(err, &ve) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, ErrEmptyUpdate) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      "empty update",
+		})
+		return
+	}
+
+	if errors.Is(err, ErrVersionMismatch) {
+		util.PreconditionFailed(w, r)
+		return
+	}
+
+	util.InternalServerError(w, r)
+}
+
 func (h *Handler) DeleteFilm(w http.ResponseWriter, r *http.Request) {
 	req := FilmIdRequest{
 		ID: r.PathValue("id"),
 	}
+	var ok bool
+	if req.IfMatch, ok = util.RequireIfMatch(w, r); !ok {
+		return
+	}
 
 	res, err := h.service.DeleteFilm(r.Context(), &req)
 	if err != nil {
@@ -161,6 +231,11 @@ func (h *Handler) DeleteFilm(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, ErrVersionMismatch) {
+			util.PreconditionFailed(w, r)
+			return
+		}
+
 		util.InternalServerError(w, r)
 		return
 	}
@@ -186,7 +261,7 @@ func (h *Handler) GetFilmActors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.JSON(w, r, http.StatusOK, res)
+	util.Render(w, r, http.StatusOK, ActorShortList(res))
 }
 
 func (h *Handler) AddFilmActors(w http.ResponseWriter, r *http.Request) {
@@ -247,7 +322,7 @@ func (h *Handler) DeleteFilmActors(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("ERROR: failed to add film related actors err=%s\n", err.Error())
 
-		if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrZeroActors) {
+		if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrFilmNotExist) || errors.Is(err, ErrZeroActors) {
 			util.NotFound(w, r)
 			return
 		}
@@ -266,3 +341,24 @@ func (h *Handler) DeleteFilmActors(w http.ResponseWriter, r *http.Request) {
 
 	util.JSON(w, r, http.StatusOK, res)
 }
+
+func (h *Handler) RestoreFilm(w http.ResponseWriter, r *http.Request) {
+	req := FilmIdRequest{
+		ID: r.PathValue("id"),
+	}
+
+	res, err := h.service.RestoreFilm(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to restore film err=%s\n", err.Error())
+		if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrFilmNotExist) {
+			util.NotFound(w, r)
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	w.Header().Set("etag", res.ETag)
+	util.JSON(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,70 @@
+package film
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var (
+	_ util.Table = FilmList(nil)
+	_ util.Table = ActorShortList(nil)
+)
+
+// FilmList is the film list response, an array in JSON and YAML and
+// a films element in XML.
+type FilmList []*FilmResponse
+
+func (l FilmList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Films []*FilmResponse `xml:"film"`
+	}{l}, xml.StartElement{Name: xml.Name{Local: "films"}})
+}
+
+func (l FilmList) CSVHeader() []string {
+	return []string{"id", "name", "description", "releasedate", "rating", "actors", "createdAt", "updatedAt", "createdBy", "updatedBy"}
+}
+
+func (l FilmList) CSVRecords() [][]string {
+	records := make([][]string, 0, len(l))
+	for _, v := range l {
+		records = append(records, []string{
+			strconv.Itoa(v.ID),
+			v.Info.Name,
+			v.Info.Description,
+			v.Info.ReleaseDate,
+			strconv.Itoa(v.Info.Rating),
+			strings.Join(v.Actors, ";"),
+			v.CreatedAt,
+			v.UpdatedAt,
+			util.FormatID(v.CreatedBy),
+			util.FormatID(v.UpdatedBy),
+		})
+	}
+
+	return records
+}
+
+// ActorShortList is the response listing actors of a film.
+type ActorShortList []*ActorShortResponse
+
+func (l ActorShortList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Actors []*ActorShortResponse `xml:"actor"`
+	}{l}, xml.StartElement{Name: xml.Name{Local: "actors"}})
+}
+
+func (l ActorShortList) CSVHeader() []string {
+	return []string{"id", "name"}
+}
+
+func (l ActorShortList) CSVRecords() [][]string {
+	records := make([][]string, 0, len(l))
+	for _, v := range l {
+		records = append(records, []string{strconv.Itoa(v.ID), v.Name})
+	}
+
+	return records
+}
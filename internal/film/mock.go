@@ -13,7 +13,9 @@ import (
 	context "context"
 	http "net/http"
 	reflect "reflect"
+	time "time"
 
+	audit "github.com/Coderovshik/film-library/internal/audit"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -69,18 +71,32 @@ func (mr *MockFilmRepositoryMockRecorder) AddFilmActors(ctx, fa any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFilmActors", reflect.TypeOf((*MockFilmRepository)(nil).AddFilmActors), ctx, fa)
 }
 
+// Atomic mocks base method.
+func (m *MockFilmRepository) Atomic(ctx context.Context, fn func(FilmRepository, audit.Recorder) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Atomic", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Atomic indicates an expected call of Atomic.
+func (mr *MockFilmRepositoryMockRecorder) Atomic(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Atomic", reflect.TypeOf((*MockFilmRepository)(nil).Atomic), ctx, fn)
+}
+
 // DeleteFilm mocks base method.
-func (m *MockFilmRepository) DeleteFilm(ctx context.Context, id int32) error {
+func (m *MockFilmRepository) DeleteFilm(ctx context.Context, id, version int32) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFilm", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteFilm", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteFilm indicates an expected call of DeleteFilm.
-func (mr *MockFilmRepositoryMockRecorder) DeleteFilm(ctx, id any) *gomock.Call {
+func (mr *MockFilmRepositoryMockRecorder) DeleteFilm(ctx, id, version any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockFilmRepository)(nil).DeleteFilm), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockFilmRepository)(nil).DeleteFilm), ctx, id, version)
 }
 
 // DeleteFilmActors mocks base method.
@@ -97,6 +113,35 @@ func (mr *MockFilmRepositoryMockRecorder) DeleteFilmActors(ctx, fa any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilmActors", reflect.TypeOf((*MockFilmRepository)(nil).DeleteFilmActors), ctx, fa)
 }
 
+// ExportFilms mocks base method.
+func (m *MockFilmRepository) ExportFilms(ctx context.Context, q *ExportQuery, fn func(*Film, []*ActorShort) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportFilms", ctx, q, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportFilms indicates an expected call of ExportFilms.
+func (mr *MockFilmRepositoryMockRecorder) ExportFilms(ctx, q, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportFilms", reflect.TypeOf((*MockFilmRepository)(nil).ExportFilms), ctx, q, fn)
+}
+
+// GetDeletedFilms mocks base method.
+func (m *MockFilmRepository) GetDeletedFilms(ctx context.Context) ([]*Film, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedFilms", ctx)
+	ret0, _ := ret[0].([]*Film)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedFilms indicates an expected call of GetDeletedFilms.
+func (mr *MockFilmRepositoryMockRecorder) GetDeletedFilms(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedFilms", reflect.TypeOf((*MockFilmRepository)(nil).GetDeletedFilms), ctx)
+}
+
 // GetFilm mocks base method.
 func (m *MockFilmRepository) GetFilm(ctx context.Context, id int32) (*Film, error) {
 	m.ctrl.T.Helper()
@@ -127,6 +172,21 @@ func (mr *MockFilmRepositoryMockRecorder) GetFilmActors(ctx, id any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmActors", reflect.TypeOf((*MockFilmRepository)(nil).GetFilmActors), ctx, id)
 }
 
+// GetFilmFacets mocks base method.
+func (m *MockFilmRepository) GetFilmFacets(ctx context.Context, q *Query, names []string) ([]*Facet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilmFacets", ctx, q, names)
+	ret0, _ := ret[0].([]*Facet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilmFacets indicates an expected call of GetFilmFacets.
+func (mr *MockFilmRepositoryMockRecorder) GetFilmFacets(ctx, q, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmFacets", reflect.TypeOf((*MockFilmRepository)(nil).GetFilmFacets), ctx, q, names)
+}
+
 // GetFilms mocks base method.
 func (m *MockFilmRepository) GetFilms(ctx context.Context, q *Query) ([]*Film, error) {
 	m.ctrl.T.Helper()
@@ -142,18 +202,84 @@ func (mr *MockFilmRepositoryMockRecorder) GetFilms(ctx, q any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockFilmRepository)(nil).GetFilms), ctx, q)
 }
 
+// PurgeFilms mocks base method.
+func (m *MockFilmRepository) PurgeFilms(ctx context.Context, before time.Time) ([]int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeFilms", ctx, before)
+	ret0, _ := ret[0].([]int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeFilms indicates an expected call of PurgeFilms.
+func (mr *MockFilmRepositoryMockRecorder) PurgeFilms(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeFilms", reflect.TypeOf((*MockFilmRepository)(nil).PurgeFilms), ctx, before)
+}
+
+// RestoreFilm mocks base method.
+func (m *MockFilmRepository) RestoreFilm(ctx context.Context, id int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFilm", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreFilm indicates an expected call of RestoreFilm.
+func (mr *MockFilmRepositoryMockRecorder) RestoreFilm(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFilm", reflect.TypeOf((*MockFilmRepository)(nil).RestoreFilm), ctx, id)
+}
+
 // UpdateFilm mocks base method.
-func (m *MockFilmRepository) UpdateFilm(ctx context.Context, f *Film) error {
+func (m *MockFilmRepository) UpdateFilm(ctx context.Context, fu *FilmUpdate) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateFilm", ctx, f)
+	ret := m.ctrl.Call(m, "UpdateFilm", ctx, fu)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateFilm indicates an expected call of UpdateFilm.
-func (mr *MockFilmRepositoryMockRecorder) UpdateFilm(ctx, f any) *gomock.Call {
+func (mr *MockFilmRepositoryMockRecorder) UpdateFilm(ctx, fu any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockFilmRepository)(nil).UpdateFilm), ctx, f)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockFilmRepository)(nil).UpdateFilm), ctx, fu)
+}
+
+// MockEventPublisher is a mock of EventPublisher interface.
+type MockEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherMockRecorder
+}
+
+// MockEventPublisherMockRecorder is the mock recorder for MockEventPublisher.
+type MockEventPublisherMockRecorder struct {
+	mock *MockEventPublisher
+}
+
+// NewMockEventPublisher creates a new mock instance.
+func NewMockEventPublisher(ctrl *gomock.Controller) *MockEventPublisher {
+	mock := &MockEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisher) EXPECT() *MockEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventPublisher) Publish(ctx context.Context, e *Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventPublisherMockRecorder) Publish(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventPublisher)(nil).Publish), ctx, e)
 }
 
 // MockFilmService is a mock of FilmService interface.
@@ -284,6 +410,51 @@ func (mr *MockFilmServiceMockRecorder) GetFilms(ctx, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockFilmService)(nil).GetFilms), ctx, req)
 }
 
+// GetFilmsFacets mocks base method.
+func (m *MockFilmService) GetFilmsFacets(ctx context.Context, req *GetFilmsRequest) (*FilmsFacetsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilmsFacets", ctx, req)
+	ret0, _ := ret[0].(*FilmsFacetsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilmsFacets indicates an expected call of GetFilmsFacets.
+func (mr *MockFilmServiceMockRecorder) GetFilmsFacets(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmsFacets", reflect.TypeOf((*MockFilmService)(nil).GetFilmsFacets), ctx, req)
+}
+
+// PatchFilm mocks base method.
+func (m *MockFilmService) PatchFilm(ctx context.Context, req *FilmPatchRequest) (*FilmResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchFilm", ctx, req)
+	ret0, _ := ret[0].(*FilmResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchFilm indicates an expected call of PatchFilm.
+func (mr *MockFilmServiceMockRecorder) PatchFilm(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchFilm", reflect.TypeOf((*MockFilmService)(nil).PatchFilm), ctx, req)
+}
+
+// RestoreFilm mocks base method.
+func (m *MockFilmService) RestoreFilm(ctx context.Context, req *FilmIdRequest) (*FilmResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFilm", ctx, req)
+	ret0, _ := ret[0].(*FilmResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreFilm indicates an expected call of RestoreFilm.
+func (mr *MockFilmServiceMockRecorder) RestoreFilm(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFilm", reflect.TypeOf((*MockFilmService)(nil).RestoreFilm), ctx, req)
+}
+
 // UpdateFilm mocks base method.
 func (m *MockFilmService) UpdateFilm(ctx context.Context, req *FilmIdInfoRequest) (*FilmResponse, error) {
 	m.ctrl.T.Helper()
@@ -406,6 +577,30 @@ func (mr *MockFilmHandlerMockRecorder) GetFilms(w, r any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockFilmHandler)(nil).GetFilms), w, r)
 }
 
+// PatchFilm mocks base method.
+func (m *MockFilmHandler) PatchFilm(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PatchFilm", w, r)
+}
+
+// PatchFilm indicates an expected call of PatchFilm.
+func (mr *MockFilmHandlerMockRecorder) PatchFilm(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchFilm", reflect.TypeOf((*MockFilmHandler)(nil).PatchFilm), w, r)
+}
+
+// RestoreFilm mocks base method.
+func (m *MockFilmHandler) RestoreFilm(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RestoreFilm", w, r)
+}
+
+// RestoreFilm indicates an expected call of RestoreFilm.
+func (mr *MockFilmHandlerMockRecorder) RestoreFilm(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFilm", reflect.TypeOf((*MockFilmHandler)(nil).RestoreFilm), w, r)
+}
+
 // UpdateFilm mocks base method.
 func (m *MockFilmHandler) UpdateFilm(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
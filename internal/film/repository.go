@@ -6,42 +6,62 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/Coderovshik/film-library/internal/audit"
 	"github.com/Coderovshik/film-library/internal/db"
-	"github.com/lib/pq"
+	"github.com/Coderovshik/film-library/internal/util"
 )
 
 var (
-	ErrFilmNotExist   = errors.New("actor does not exist")
-	ErrEmptyUpdate    = errors.New("no updates to apply")
-	ErrFilmActorExist = errors.New("given film and actor are already bound")
-	ErrActorNotExist  = errors.New("actor with given id does not exist")
-	ErrZeroActors     = errors.New("no actors affected")
+	ErrFilmNotExist    = errors.New("actor does not exist")
+	ErrEmptyUpdate     = errors.New("no updates to apply")
+	ErrFilmActorExist  = errors.New("given film and actor are already bound")
+	ErrActorNotExist   = errors.New("actor with given id does not exist")
+	ErrZeroActors      = errors.New("no actors affected")
+	ErrVersionMismatch = errors.New("film was modified since it was read")
+
+	// errZeroRows rolls back a transaction whose update matched no row.
+	errZeroRows = errors.New("zero rows affected")
 )
 
 var _ FilmRepository = (*Repository)(nil)
 
 type Repository struct {
-	db db.DBTX
+	db      db.DBTX
+	dialect db.Dialect
 }
 
-func NewRepository(db db.DBTX) *Repository {
+func NewRepository(db db.DBTX, d db.Dialect) *Repository {
 	return &Repository{
-		db: db,
+		db:      db,
+		dialect: d,
 	}
 }
 
+// Atomic runs fn in a transaction of its own, or in the one the
+// repository is already bound to.
+func (r *Repository) Atomic(ctx context.Context, fn func(fr FilmRepository, rec audit.Recorder) error) error {
+	return db.InTx(ctx, r.db, func(tx db.DBTX) error {
+		return fn(NewRepository(tx, r.dialect), audit.NewService(audit.NewRepository(tx, r.dialect)))
+	})
+}
+
 func (r *Repository) GetFilm(ctx context.Context, id int32) (*Film, error) {
 	const op = "film.Repository.GetFilm"
 
-	const query = `
+	query := `
 		SELECT m.movie_id, m.movie_name, m.movie_description, m.releasedate,
-    		m.rating, STRING_AGG (a.actor_name, ';') movie_list
+    		m.rating, m.version,
+			m.created_at, m.updated_at, m.created_by, m.updated_by,
+			` + r.dialect.StringAgg("a.actor_name", ";") + ` movie_list,
+			` + r.genreList() + ` genre_list
 		FROM movie m
 		LEFT JOIN actor_in_movie am USING (movie_id)
-		LEFT JOIN actor a USING (actor_id)
-		WHERE m.movie_id = $1
+		LEFT JOIN actor a ON a.actor_id = am.actor_id AND a.deleted_at IS NULL
+		WHERE m.movie_id = $1 AND m.deleted_at IS NULL
 		GROUP BY m.movie_id`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
@@ -51,8 +71,9 @@ func (r *Repository) GetFilm(ctx context.Context, id int32) (*Film, error) {
 	defer stmt.Close()
 
 	var f Film
-	var actorString sql.NullString
-	err = stmt.QueryRowContext(ctx, id).Scan(&f.ID, &f.Name, &f.Description, &f.ReleaseDate, &f.Rating, &actorString)
+	var actorString, genreString sql.NullString
+	err = stmt.QueryRowContext(ctx, id).Scan(&f.ID, &f.Name, &f.Description, &f.ReleaseDate, &f.Rating, &f.Version,
+		&f.CreatedAt, &f.UpdatedAt, &f.CreatedBy, &f.UpdatedBy, &actorString, &genreString)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("ERROR: actor with id=%d does not exist\n", id)
@@ -65,6 +86,7 @@ func (r *Repository) GetFilm(ctx context.Context, id int32) (*Film, error) {
 	if len(actorString.String) != 0 {
 		f.Actors = strings.Split(actorString.String, ";")
 	}
+	f.Genres = toGenreList(genreString)
 
 	return &f, nil
 }
@@ -72,21 +94,32 @@ func (r *Repository) GetFilm(ctx context.Context, id int32) (*Film, error) {
 func (r *Repository) AddFilm(ctx context.Context, f *Film) (*Film, error) {
 	const op = "film.Repository.AddFilm"
 
+	f.CreatedAt, f.CreatedBy = db.Now(), util.AuthorFromContext(ctx)
+	f.UpdatedAt, f.UpdatedBy = f.CreatedAt, f.CreatedBy
+
 	const query = `
-		INSERT INTO movie(movie_name, movie_description, releasedate, rating)
-		VALUES ($1, $2, $3, $4) RETURNING movie_id`
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		log.Printf("ERROR: failed to prepare query\n")
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-	defer stmt.Close()
+		INSERT INTO movie(movie_name, movie_description, releasedate, rating,
+			created_at, updated_at, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	err := db.InTx(ctx, r.db, func(tx db.DBTX) error {
+		id, err := r.dialect.InsertReturningID(ctx, tx, query, "movie_id",
+			f.Name, f.Description, f.ReleaseDate, f.Rating,
+			f.CreatedAt, f.UpdatedAt, f.CreatedBy, f.UpdatedBy)
+		if err != nil {
+			log.Printf("ERROR: failed to execute query\n")
+			return err
+		}
+		f.ID = int32(id)
 
-	err = stmt.QueryRowContext(ctx, f.Name, f.Description, f.ReleaseDate, f.Rating).Scan(&f.ID)
+		if len(f.Genres) == 0 {
+			return nil
+		}
+		return setGenres(ctx, tx, f.ID, f.Genres)
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to execute query\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	f.Version = 1
 
 	return f, nil
 }
@@ -94,8 +127,14 @@ func (r *Repository) AddFilm(ctx context.Context, f *Film) (*Film, error) {
 func (r *Repository) AddFilmActors(ctx context.Context, fa *FilmActors) error {
 	const op = "film.Repository.AddFilmActors"
 
-	args, values := ToQueryableLists(fa, "($%d, $1)")
-	query := `INSERT INTO actor_in_movie(actor_id, movie_id) VALUES ` + args
+	if err := r.checkTrash(ctx, fa); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	now, author := db.Now(), util.AuthorFromContext(ctx)
+	args, values := ToQueryableLists(fa, "($%d, $1, $2, $2, $3, $3)", now, author)
+	query := `INSERT INTO actor_in_movie(actor_id, movie_id,
+		created_at, updated_at, created_by, updated_by) VALUES ` + args
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		log.Printf("ERROR: failed to prepare query\n")
@@ -105,23 +144,23 @@ func (r *Repository) AddFilmActors(ctx context.Context, fa *FilmActors) error {
 
 	res, err := stmt.ExecContext(ctx, values...)
 	if err != nil {
-		var pgErr *pq.Error
-		if errors.As(err, &pgErr) {
-			if pgErr.Code.Name() == "unique_violation" {
-				log.Printf("ERROR: one of the film-actor pairs already exists\n")
-				return fmt.Errorf("%s: %w", op, ErrFilmActorExist)
-			}
+		if r.dialect.IsUniqueViolation(err) {
+			log.Printf("ERROR: one of the film-actor pairs already exists\n")
+			return fmt.Errorf("%s: %w", op, ErrFilmActorExist)
 		}
 
-		if errors.As(err, &pgErr) {
-			if pgErr.Code.Name() == "foreign_key_violation" {
-				if strings.Contains(pgErr.Detail, "movie_id") {
-					log.Printf("ERROR: film does not exist\n")
-					return fmt.Errorf("%s: %w", op, ErrFilmNotExist)
-				}
-				log.Printf("ERROR: one of the actors does not exist\n")
-				return fmt.Errorf("%s: %w", op, ErrActorNotExist)
+		if column, ok := r.dialect.ForeignKeyViolation(err); ok {
+			filmMissing := column == "movie_id"
+			if len(column) == 0 {
+				filmMissing = !r.filmExists(ctx, fa.ID)
+			}
+
+			if filmMissing {
+				log.Printf("ERROR: film does not exist\n")
+				return fmt.Errorf("%s: %w", op, ErrFilmNotExist)
 			}
+			log.Printf("ERROR: one of the actors does not exist\n")
+			return fmt.Errorf("%s: %w", op, ErrActorNotExist)
 		}
 
 		log.Printf("ERROR: failed to execute query\n")
@@ -135,13 +174,71 @@ func (r *Repository) AddFilmActors(ctx context.Context, fa *FilmActors) error {
 	}
 	log.Printf("INFO: %d rows inserted\n", count)
 
+	if err := r.touchFilm(ctx, fa.ID, now, author); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// touchFilm marks the film updated when its cast changes, so that
+// incremental pulls by updatedSince see the new actor list.
+func (r *Repository) touchFilm(ctx context.Context, id int32, now time.Time, author *int32) error {
+	const query = `UPDATE movie SET updated_at = $1, updated_by = $2 WHERE movie_id = $3`
+	if _, err := r.db.ExecContext(ctx, query, now, author, id); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+
+	return nil
+}
+
+// checkTrash fails binding a film or actors which are in the trash,
+// foreign keys do not tell them apart from live ones.
+func (r *Repository) checkTrash(ctx context.Context, fa *FilmActors) error {
+	args, values := ToQueryableLists(fa, "$%d")
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM movie WHERE movie_id = $1 AND deleted_at IS NOT NULL),
+			EXISTS (SELECT 1 FROM actor WHERE actor_id IN (` + args + `) AND deleted_at IS NOT NULL)`
+	var filmDeleted, actorDeleted bool
+	if err := r.db.QueryRowContext(ctx, query, values...).Scan(&filmDeleted, &actorDeleted); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+
+	if filmDeleted {
+		log.Printf("ERROR: film is deleted\n")
+		return ErrFilmNotExist
+	}
+	if actorDeleted {
+		log.Printf("ERROR: one of the actors is deleted\n")
+		return ErrActorNotExist
+	}
+
 	return nil
 }
 
-func (r *Repository) DeleteFilm(ctx context.Context, id int32) error {
+// filmExists reports whether the film exists and is not in the trash.
+func (r *Repository) filmExists(ctx context.Context, id int32) bool {
+	const query = `SELECT EXISTS (SELECT 1 FROM movie WHERE movie_id = $1 AND deleted_at IS NULL)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return false
+	}
+
+	return exists
+}
+
+func (r *Repository) DeleteFilm(ctx context.Context, id int32, version int32) error {
 	const op = "film.Repository.DeleteFilm"
 
-	const query = `DELETE FROM movie WHERE movie_id = $1`
+	// the film goes to the trash, its bindings stay for a restore
+	const query = `
+		UPDATE movie SET deleted_at = $3, deleted_by = $4
+		WHERE movie_id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		log.Printf("ERROR: failed to prepare query\n")
@@ -149,7 +246,7 @@ func (r *Repository) DeleteFilm(ctx context.Context, id int32) error {
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, id)
+	res, err := stmt.ExecContext(ctx, id, version, db.Now(), util.AuthorFromContext(ctx))
 	if err != nil {
 		log.Printf("ERROR: failed to execute query\n")
 		return fmt.Errorf("%s: %w", op, err)
@@ -162,63 +259,145 @@ func (r *Repository) DeleteFilm(ctx context.Context, id int32) error {
 	}
 	if count == 0 {
 		log.Printf("ERROR: zero rows addected by deletion\n")
-		return fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+		return fmt.Errorf("%s: %w", op, r.missError(ctx, id, version))
 	}
 
 	return nil
 }
 
-func (r *Repository) UpdateFilm(ctx context.Context, f *Film) error {
+func (r *Repository) UpdateFilm(ctx context.Context, fu *FilmUpdate) error {
 	const op = "film.Repository.UpdateFilm"
 
-	qo := ToQueryableObject(f)
-	if qo.IsEmpty() {
+	qo := ToQueryableObject(fu)
+	if qo.IsEmpty() && fu.Genres == nil {
 		log.Print("ERROR: no updates to apply\n")
 		return fmt.Errorf("%s: %w", op, ErrEmptyUpdate)
 	}
 
-	query := `UPDATE movie SET ` + qo.Args(1) +
-		` WHERE movie_id = ` + fmt.Sprintf("$%d", qo.Len()+1)
-	stmt, err := r.db.PrepareContext(ctx, query)
+	qo.Add("updated_at", db.Now())
+	qo.Add("updated_by", util.AuthorFromContext(ctx))
+
+	n := qo.Len()
+	query := `UPDATE movie SET ` + qo.Args(1) + `, version = version + 1` +
+		fmt.Sprintf(` WHERE movie_id = $%d AND deleted_at IS NULL AND ($%d = 0 OR version = $%d)`, n+1, n+2, n+2)
+	values := qo.Values()
+	values = append(values, fu.ID, fu.Version)
+
+	// the genres are replaced along with the new version or not at all
+	err := db.InTx(ctx, r.db, func(tx db.DBTX) error {
+		res, err := tx.ExecContext(ctx, query, values...)
+		if err != nil {
+			log.Printf("ERROR: failed to execute query\n")
+			return err
+		}
+
+		count, err := res.RowsAffected()
+		if err != nil {
+			log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+			return err
+		}
+		if count == 0 {
+			log.Printf("ERROR: zero rows affected by update\n")
+			return errZeroRows
+		}
+
+		if fu.Genres == nil {
+			return nil
+		}
+		return setGenres(ctx, tx, fu.ID, *fu.Genres)
+	})
+	if errors.Is(err, errZeroRows) {
+		return fmt.Errorf("%s: %w", op, r.missError(ctx, fu.ID, fu.Version))
+	}
 	if err != nil {
-		log.Printf("ERROR: failed to prepare query\n")
 		return fmt.Errorf("%s: %w", op, err)
 	}
-	defer stmt.Close()
 
-	values := qo.Values()
-	values = append(values, f.ID)
-	res, err := stmt.ExecContext(ctx, values...)
-	if err != nil {
+	return nil
+}
+
+// setGenres replaces the genres of the film, the names missing from the
+// genre table are added to it.
+func setGenres(ctx context.Context, tx db.DBTX, id int32, genres []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM genre_in_movie WHERE movie_id = $1`, id); err != nil {
 		log.Printf("ERROR: failed to execute query\n")
-		return fmt.Errorf("%s: %w", op, err)
+		return err
+	}
+	if len(genres) == 0 {
+		return nil
 	}
 
-	count, err := res.RowsAffected()
-	if err != nil {
-		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
-		return fmt.Errorf("%s: %w", op, err)
+	values := make([]any, 0, len(genres)+1)
+	values = append(values, id)
+	names := make([]string, 0, len(genres))
+	ps := make([]string, 0, len(genres))
+	for i, v := range genres {
+		values = append(values, v)
+		names = append(names, fmt.Sprintf("$%d", i+1))
+		ps = append(ps, fmt.Sprintf("$%d", len(values)))
 	}
-	if count == 0 {
-		log.Printf("ERROR: zero rows affected by update\n")
-		return fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+
+	query := `INSERT INTO genre(genre_name) VALUES (` + strings.Join(names, "), (") + `)
+		ON CONFLICT (genre_name) DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, values[1:]...); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+
+	query = `INSERT INTO genre_in_movie(genre_id, movie_id)
+		SELECT genre_id, $1 FROM genre WHERE genre_name IN (` + strings.Join(ps, ", ") + `)`
+	if _, err := tx.ExecContext(ctx, query, values...); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
 	}
 
 	return nil
 }
 
+// genreList selects the genres of the film m joined with ";".
+func (r *Repository) genreList() string {
+	return `(SELECT ` + r.dialect.StringAgg("g.genre_name", ";") + `
+			FROM genre_in_movie gm
+			INNER JOIN genre g ON g.genre_id = gm.genre_id
+			WHERE gm.movie_id = m.movie_id)`
+}
+
+// toGenreList splits the genres selected by genreList, the order of the
+// aggregate is not defined so they are sorted.
+func toGenreList(s sql.NullString) []string {
+	if len(s.String) == 0 {
+		return nil
+	}
+	genres := strings.Split(s.String, ";")
+	slices.Sort(genres)
+
+	return genres
+}
+
+// missError tells why a conditional statement affected no rows.
+func (r *Repository) missError(ctx context.Context, id int32, version int32) error {
+	if version != 0 && r.filmExists(ctx, id) {
+		log.Printf("ERROR: film with id=%d is not of version %d\n", id, version)
+		return ErrVersionMismatch
+	}
+
+	return ErrFilmNotExist
+}
+
 func (r *Repository) GetFilms(ctx context.Context, q *Query) ([]*Film, error) {
 	const op = "film.Repository.GetFilms"
 
-	cons := ToQueryConditions(q)
+	where, order, values := ToQueryConditions(q)
 	query := `
 		SELECT m.movie_id, m.movie_name, m.movie_description, m.releasedate,
-			m.rating, STRING_AGG (a.actor_name, ';') movie_list
-		FROM movie m 
+			m.rating, m.version,
+			m.created_at, m.updated_at, m.created_by, m.updated_by,
+			` + r.dialect.StringAgg("a.actor_name", ";") + ` movie_list,
+			` + r.genreList() + ` genre_list
+		FROM movie m
 		LEFT JOIN actor_in_movie am USING (movie_id)
-		LEFT JOIN actor a USING (actor_id) ` +
-		cons[0] + " GROUP BY m.movie_id " +
-		cons[1] + " " + cons[2]
+		LEFT JOIN actor a ON a.actor_id = am.actor_id AND a.deleted_at IS NULL ` +
+		where + " GROUP BY m.movie_id " + order
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		log.Printf("ERROR: failed to prepare query\n")
@@ -226,7 +405,7 @@ func (r *Repository) GetFilms(ctx context.Context, q *Query) ([]*Film, error) {
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(ctx)
+	rows, err := stmt.QueryContext(ctx, values...)
 	if err != nil {
 		log.Printf("ERROR: failed to execute query\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -236,8 +415,9 @@ func (r *Repository) GetFilms(ctx context.Context, q *Query) ([]*Film, error) {
 
 	for rows.Next() {
 		var f Film
-		var actorString sql.NullString
-		err := rows.Scan(&f.ID, &f.Name, &f.Description, &f.ReleaseDate, &f.Rating, &actorString)
+		var actorString, genreString sql.NullString
+		err := rows.Scan(&f.ID, &f.Name, &f.Description, &f.ReleaseDate, &f.Rating, &f.Version,
+			&f.CreatedAt, &f.UpdatedAt, &f.CreatedBy, &f.UpdatedBy, &actorString, &genreString)
 		if err != nil {
 			log.Printf("ERROR: failed to execute query\n")
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -245,6 +425,7 @@ func (r *Repository) GetFilms(ctx context.Context, q *Query) ([]*Film, error) {
 		if len(actorString.String) != 0 {
 			f.Actors = strings.Split(actorString.String, ";")
 		}
+		f.Genres = toGenreList(genreString)
 
 		films = append(films, &f)
 	}
@@ -263,7 +444,8 @@ func (r *Repository) GetFilmActors(ctx context.Context, id int32) ([]*ActorShort
 		SELECT a.actor_id, a.actor_name
 		FROM actor a
 		INNER JOIN actor_in_movie am USING (actor_id)
-		WHERE movie_id = $1`
+		INNER JOIN movie m USING (movie_id)
+		WHERE movie_id = $1 AND a.deleted_at IS NULL AND m.deleted_at IS NULL`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		log.Printf("ERROR: failed to prepare query\n")
@@ -300,7 +482,8 @@ func (r *Repository) DeleteFilmActors(ctx context.Context, fa *FilmActors) error
 	const op = "film.Repository.DeleteDilmActors"
 
 	args, values := ToQueryableLists(fa, "$%d")
-	var query = "DELETE FROM actor_in_movie WHERE movie_id = $1 AND actor_id IN (" + args + ")"
+	var query = "DELETE FROM actor_in_movie WHERE movie_id = $1 AND actor_id IN (" + args + ")" +
+		" AND movie_id IN (SELECT movie_id FROM movie WHERE deleted_at IS NULL)"
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		log.Printf("ERROR: failed to prepare query\n")
@@ -324,5 +507,285 @@ func (r *Repository) DeleteFilmActors(ctx context.Context, fa *FilmActors) error
 		return fmt.Errorf("%s: %w", op, ErrZeroActors)
 	}
 
+	if err := r.touchFilm(ctx, fa.ID, db.Now(), util.AuthorFromContext(ctx)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	return nil
 }
+
+func (r *Repository) RestoreFilm(ctx context.Context, id int32) error {
+	const op = "film.Repository.RestoreFilm"
+
+	// a restored film shows up again in incremental pulls by updatedSince
+	const query = `
+		UPDATE movie SET deleted_at = NULL, deleted_by = NULL, updated_at = $2, updated_by = $3
+		WHERE movie_id = $1 AND deleted_at IS NOT NULL`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to prepare query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, db.Now(), util.AuthorFromContext(ctx))
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: film with id=%d is not in the trash\n", id)
+		return fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+	}
+
+	return nil
+}
+
+// GetDeletedFilms lists the trash, most recently deleted first.
+func (r *Repository) GetDeletedFilms(ctx context.Context) ([]*Film, error) {
+	const op = "film.Repository.GetDeletedFilms"
+
+	query := `
+		SELECT m.movie_id, m.movie_name, m.movie_description, m.releasedate,
+			m.rating, m.version,
+			m.created_at, m.updated_at, m.created_by, m.updated_by,
+			m.deleted_at, m.deleted_by,
+			` + r.dialect.StringAgg("a.actor_name", ";") + ` movie_list,
+			` + r.genreList() + ` genre_list
+		FROM movie m
+		LEFT JOIN actor_in_movie am USING (movie_id)
+		LEFT JOIN actor a ON a.actor_id = am.actor_id AND a.deleted_at IS NULL
+		WHERE m.deleted_at IS NOT NULL
+		GROUP BY m.movie_id
+		ORDER BY m.deleted_at DESC, m.movie_id`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to prepare query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	films := make([]*Film, 0)
+	for rows.Next() {
+		var f Film
+		var actorString, genreString sql.NullString
+		err := rows.Scan(&f.ID, &f.Name, &f.Description, &f.ReleaseDate, &f.Rating, &f.Version,
+			&f.CreatedAt, &f.UpdatedAt, &f.CreatedBy, &f.UpdatedBy, &f.DeletedAt, &f.DeletedBy, &actorString, &genreString)
+		if err != nil {
+			log.Printf("ERROR: failed to execute query\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if len(actorString.String) != 0 {
+			f.Actors = strings.Split(actorString.String, ";")
+		}
+		f.Genres = toGenreList(genreString)
+
+		films = append(films, &f)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return films, nil
+}
+
+// PurgeFilms removes films deleted before the given time for good,
+// their bindings go with them.
+func (r *Repository) PurgeFilms(ctx context.Context, before time.Time) ([]int32, error) {
+	const op = "film.Repository.PurgeFilms"
+
+	const query = `DELETE FROM movie WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING movie_id`
+	rows, err := r.db.QueryContext(ctx, query, before.UTC())
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int32, 0)
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+// ExportFilms joins the films with their actors, so that each film is
+// streamed as soon as the rows of its cast are read.
+func (r *Repository) ExportFilms(ctx context.Context, q *ExportQuery, fn func(f *Film, cast []*ActorShort) error) error {
+	const op = "film.Repository.ExportFilms"
+
+	where, values := ToExportConditions(q)
+	limit := ""
+	if q.Limit != 0 {
+		values = append(values, q.Limit)
+		limit = fmt.Sprintf("LIMIT $%d", len(values))
+	}
+	query := fmt.Sprintf(`
+		SELECT m.movie_id, m.movie_name, m.movie_description, m.releasedate,
+			m.rating, m.version,
+			m.created_at, m.updated_at, m.created_by, m.updated_by,
+			`+r.genreList()+` genre_list, a.actor_id, a.actor_name
+		FROM (SELECT * FROM movie m %s ORDER BY m.movie_id %s) m
+		LEFT JOIN actor_in_movie am USING (movie_id)
+		LEFT JOIN actor a ON a.actor_id = am.actor_id AND a.deleted_at IS NULL
+		ORDER BY m.movie_id, a.actor_id`, where, limit)
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to prepare query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, values...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var cur *Film
+	var cast []*ActorShort
+	for rows.Next() {
+		var f Film
+		var actorID sql.NullInt32
+		var actorName, genreString sql.NullString
+		err := rows.Scan(&f.ID, &f.Name, &f.Description, &f.ReleaseDate, &f.Rating, &f.Version,
+			&f.CreatedAt, &f.UpdatedAt, &f.CreatedBy, &f.UpdatedBy, &genreString, &actorID, &actorName)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if cur == nil || cur.ID != f.ID {
+			if cur != nil {
+				if err := fn(cur, cast); err != nil {
+					return fmt.Errorf("%s: %w", op, err)
+				}
+			}
+			cur, cast = &f, nil
+			cur.Genres = toGenreList(genreString)
+		}
+		if actorID.Valid {
+			cur.Actors = append(cur.Actors, actorName.String)
+			cast = append(cast, &ActorShort{ID: actorID.Int32, Name: actorName.String})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if cur != nil {
+		if err := fn(cur, cast); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// facetQuery returns the query counting films by the facet, the values
+// are selected as the first column and actor or genre names as the second.
+func (r *Repository) facetQuery(name, where string) string {
+	switch name {
+	case FacetRating:
+		return `
+			SELECT m.rating, '', COUNT(*)
+			FROM movie m ` + where + `
+			GROUP BY m.rating
+			ORDER BY 1 DESC`
+	case FacetDecade:
+		return `
+			SELECT ` + r.dialect.Year("m.releasedate") + ` / 10 * 10, '', COUNT(*)
+			FROM movie m ` + where + `
+			GROUP BY 1
+			ORDER BY 1 DESC`
+	case FacetGenre:
+		return fmt.Sprintf(`
+			SELECT g.genre_id, g.genre_name, COUNT(*)
+			FROM movie m
+			INNER JOIN genre_in_movie gm ON gm.movie_id = m.movie_id
+			INNER JOIN genre g ON g.genre_id = gm.genre_id
+			%s
+			GROUP BY g.genre_id, g.genre_name
+			ORDER BY 3 DESC, g.genre_name, g.genre_id
+			LIMIT %d`, where, MaxGenreFacetCounts)
+	default:
+		return fmt.Sprintf(`
+			SELECT a.actor_id, a.actor_name, COUNT(*)
+			FROM movie m
+			INNER JOIN actor_in_movie am ON am.movie_id = m.movie_id
+			INNER JOIN actor a ON a.actor_id = am.actor_id AND a.deleted_at IS NULL
+			%s
+			GROUP BY a.actor_id, a.actor_name
+			ORDER BY 3 DESC, a.actor_name, a.actor_id
+			LIMIT %d`, where, MaxActorFacetCounts)
+	}
+}
+
+func (r *Repository) GetFilmFacets(ctx context.Context, q *Query, names []string) ([]*Facet, error) {
+	const op = "film.Repository.GetFilmFacets"
+
+	facets := make([]*Facet, 0, len(names))
+	for _, name := range names {
+		where, values := ToFacetConditions(q, name)
+		counts, err := r.facetCounts(ctx, r.facetQuery(name, where), values)
+		if err != nil {
+			log.Printf("ERROR: failed to count films by %s\n", name)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		facets = append(facets, &Facet{Name: name, Counts: counts})
+	}
+
+	return facets, nil
+}
+
+func (r *Repository) facetCounts(ctx context.Context, query string, values []any) ([]*FacetCount, error) {
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]*FacetCount, 0)
+	for rows.Next() {
+		var fc FacetCount
+		if err := rows.Scan(&fc.Value, &fc.Name, &fc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &fc)
+	}
+
+	return counts, rows.Err()
+}
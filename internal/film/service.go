@@ -7,6 +7,8 @@ import (
 	"log"
 	"strconv"
 
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/db"
 	"github.com/Coderovshik/film-library/internal/util"
 )
 
@@ -15,12 +17,57 @@ var (
 )
 
 type Service struct {
-	repo FilmRepository
+	repo   FilmRepository
+	audit  audit.Recorder
+	events EventPublisher
 }
 
-func NewService(fr FilmRepository) *Service {
+func NewService(fr FilmRepository, ep EventPublisher) *Service {
 	return &Service{
-		repo: fr,
+		repo:   fr,
+		events: ep,
+	}
+}
+
+// atomic runs fn on a service bound to a single transaction, so that a
+// change and its audit entry are made together or not at all.
+func (s *Service) atomic(ctx context.Context, fn func(tx *Service) error) error {
+	return s.repo.Atomic(ctx, func(fr FilmRepository, rec audit.Recorder) error {
+		return fn(&Service{repo: fr, audit: rec, events: s.events})
+	})
+}
+
+// record appends the change to the audit log, it is called on a service
+// bound to the transaction of the change.
+func (s *Service) record(ctx context.Context, id int32, action string, before, after *Film) error {
+	var from, to any
+	if before != nil {
+		from = ToFilmState(before)
+	}
+	if after != nil {
+		to = ToFilmState(after)
+	}
+
+	if err := s.audit.Record(ctx, audit.EntityFilm, id, action, from, to); err != nil {
+		log.Printf("ERROR: failed to record film %s\n", action)
+		return err
+	}
+
+	return nil
+}
+
+// publish tells that the actors were bound to the film. Failures are
+// only logged, the binding is already made.
+func (s *Service) publish(ctx context.Context, id int32, actorIDs []int32) {
+	e := &Event{
+		Type:       EventActorsBound,
+		FilmID:     id,
+		ActorIDs:   actorIDs,
+		OccurredAt: db.Now(),
+	}
+
+	if err := s.events.Publish(ctx, e); err != nil {
+		log.Printf("ERROR: failed to publish film %s err=%s\n", e.Type, err.Error())
 	}
 }
 
@@ -48,6 +95,42 @@ func (s *Service) GetFilms(ctx context.Context, req *GetFilmsRequest) ([]*FilmRe
 	return res, nil
 }
 
+func (s *Service) GetFilmsFacets(ctx context.Context, req *GetFilmsRequest) (*FilmsFacetsResponse, error) {
+	const op = "film.Service.GetFilmsFacets"
+
+	vErr := ValidateGetFilmsRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+	q := ToQuery(req)
+
+	films, err := s.repo.GetFilms(ctx, q)
+	if err != nil {
+		log.Printf("ERROR: failed to get films\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	facets, err := s.repo.GetFilmFacets(ctx, q, ToFacetNames(req.FacetsQuery))
+	if err != nil {
+		log.Printf("ERROR: failed to get film facets\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := &FilmsFacetsResponse{
+		Films:  make(FilmList, 0, len(films)),
+		Facets: make([]*FacetResponse, 0, len(facets)),
+	}
+	for _, v := range films {
+		res.Films = append(res.Films, ToFilmResponse(v))
+	}
+	for _, v := range facets {
+		res.Facets = append(res.Facets, ToFacetResponse(v))
+	}
+
+	return res, nil
+}
+
 func (s *Service) AddFilm(ctx context.Context, req *AddFilmRequest) (*FilmResponse, error) {
 	const op = "film.Service.AddFilm"
 
@@ -56,7 +139,7 @@ func (s *Service) AddFilm(ctx context.Context, req *AddFilmRequest) (*FilmRespon
 		log.Printf("ERROR: failed request empty validation\n")
 		return nil, fmt.Errorf("%s: %w", op, vErr)
 	}
-	vErr = ValidateFormatFilmInfo(&req.Info, false)
+	vErr = ValidateFormatFilmInfo(&req.Info)
 	if vErr != nil {
 		log.Printf("ERROR: failed request format validation\n")
 		return nil, fmt.Errorf("%s: %w", op, vErr)
@@ -69,29 +152,37 @@ func (s *Service) AddFilm(ctx context.Context, req *AddFilmRequest) (*FilmRespon
 		return nil, fmt.Errorf("%s: %w", op, vErr)
 	}
 	film := ToFilm(&req.Info)
-
-	film, err := s.repo.AddFilm(ctx, film)
-	if err != nil {
-		log.Printf("ERROR: failed to add film information\n")
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-
 	fa := &FilmActors{
-		ID:       film.ID,
 		ActorIDs: ToActorIDs32(util.RemoveDuplicateInt(req.ActorIDs)),
 	}
-	err = s.repo.AddFilmActors(ctx, fa)
-	if err != nil {
-		log.Printf("ERROR: failed to bind provided actors and film\n")
-		s.repo.DeleteFilm(ctx, film.ID)
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
 
-	film, err = s.repo.GetFilm(ctx, film.ID)
+	// the film is rolled back together with the actors it failed to bind
+	err := s.atomic(ctx, func(tx *Service) error {
+		added, err := tx.repo.AddFilm(ctx, film)
+		if err != nil {
+			log.Printf("ERROR: failed to add film information\n")
+			return err
+		}
+
+		fa.ID = added.ID
+		err = tx.repo.AddFilmActors(ctx, fa)
+		if err != nil {
+			log.Printf("ERROR: failed to bind provided actors and film\n")
+			return err
+		}
+
+		film, err = tx.repo.GetFilm(ctx, added.ID)
+		if err != nil {
+			log.Printf("ERROR: failed to get added film information\n")
+			return err
+		}
+
+		return tx.record(ctx, film.ID, audit.ActionCreate, nil, film)
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to get added film information\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	s.publish(ctx, film.ID, fa.ActorIDs)
 
 	res := ToFilmResponse(film)
 
@@ -118,6 +209,7 @@ func (s *Service) GetFilm(ctx context.Context, req *FilmIdRequest) (*FilmRespons
 	return res, nil
 }
 
+// UpdateFilm replaces all the film fields, omitted ones get zero values.
 func (s *Service) UpdateFilm(ctx context.Context, req *FilmIdInfoRequest) (*FilmResponse, error) {
 	const op = "film.Service.UpdateFilm"
 
@@ -127,24 +219,66 @@ func (s *Service) UpdateFilm(ctx context.Context, req *FilmIdInfoRequest) (*Film
 		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
 	}
 
-	vErr := ValidateFormatFilmInfo(&req.Info, true)
+	vErr := ValidateEmptyFilmInfo(&req.Info)
+	if vErr != nil {
+		log.Printf("ERROR: failed request empty validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+	vErr = ValidateFormatFilmInfo(&req.Info)
 	if vErr != nil {
 		log.Printf("ERROR: failed request format validation\n")
 		return nil, fmt.Errorf("%s: %w", op, vErr)
 	}
 
-	film := ToFilm(&req.Info)
-	film.ID = int32(id)
+	var film *Film
+	err = s.atomic(ctx, func(tx *Service) error {
+		cur, err := tx.current(ctx, int32(id), req.IfMatch)
+		if err != nil {
+			return err
+		}
 
-	err = s.repo.UpdateFilm(ctx, film)
+		fu := ToFilm(&req.Info)
+		fu.ID = cur.ID
+		fu.Version = cur.Version
+
+		film, err = tx.updateFilm(ctx, cur, ToFilmUpdate(fu))
+		return err
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to update film record in repository")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	film, err = s.repo.GetFilm(ctx, int32(id))
+	res := ToFilmResponse(film)
+
+	return res, nil
+}
+
+func (s *Service) PatchFilm(ctx context.Context, req *FilmPatchRequest) (*FilmResponse, error) {
+	const op = "film.Service.PatchFilm"
+
+	id, err := strconv.ParseUint(req.ID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+
+	vErr := ValidateFilmPatch(&req.Patch)
+	if vErr != nil {
+		log.Printf("ERROR: failed patch validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	var film *Film
+	err = s.atomic(ctx, func(tx *Service) error {
+		cur, err := tx.current(ctx, int32(id), req.IfMatch)
+		if err != nil {
+			return err
+		}
+
+		film, err = tx.updateFilm(ctx, cur, PatchToFilmUpdate(cur.ID, cur.Version, &req.Patch))
+		return err
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to get film record from repository\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -153,6 +287,44 @@ func (s *Service) UpdateFilm(ctx context.Context, req *FilmIdInfoRequest) (*Film
 	return res, nil
 }
 
+// current returns the film checked against the If-Match header,
+// an empty header matches any version.
+func (s *Service) current(ctx context.Context, id int32, ifMatch string) (*Film, error) {
+	film, err := s.repo.GetFilm(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to get film record from repository\n")
+		return nil, err
+	}
+
+	if len(ifMatch) == 0 {
+		film.Version = 0
+		return film, nil
+	}
+
+	if !util.MatchETag(ifMatch, ToFilmResponse(film).ETag, false) {
+		log.Printf("ERROR: film with id=%d does not match %s\n", id, ifMatch)
+		return nil, ErrVersionMismatch
+	}
+
+	return film, nil
+}
+
+func (s *Service) updateFilm(ctx context.Context, cur *Film, fu *FilmUpdate) (*Film, error) {
+	err := s.repo.UpdateFilm(ctx, fu)
+	if err != nil {
+		log.Printf("ERROR: failed to update film record in repository")
+		return nil, err
+	}
+
+	film, err := s.repo.GetFilm(ctx, fu.ID)
+	if err != nil {
+		log.Printf("ERROR: failed to get film record from repository\n")
+		return nil, err
+	}
+
+	return film, s.record(ctx, film.ID, audit.ActionUpdate, cur, film)
+}
+
 func (s *Service) DeleteFilm(ctx context.Context, req *FilmIdRequest) (*FilmResponse, error) {
 	const op = "film.Service.DeleteFilm"
 
@@ -162,15 +334,22 @@ func (s *Service) DeleteFilm(ctx context.Context, req *FilmIdRequest) (*FilmResp
 		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
 	}
 
-	film, err := s.repo.GetFilm(ctx, int32(id))
-	if err != nil {
-		log.Printf("ERROR: failed to get film record from repository\n")
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
+	var film *Film
+	err = s.atomic(ctx, func(tx *Service) error {
+		film, err = tx.current(ctx, int32(id), req.IfMatch)
+		if err != nil {
+			return err
+		}
 
-	err = s.repo.DeleteFilm(ctx, int32(id))
+		err = tx.repo.DeleteFilm(ctx, film.ID, film.Version)
+		if err != nil {
+			log.Printf("ERROR: failed to delete film record in repository")
+			return err
+		}
+
+		return tx.record(ctx, film.ID, audit.ActionDelete, film, nil)
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to delete film record in repository")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -221,11 +400,31 @@ func (s *Service) AddFilmActors(ctx context.Context, req *FilmActorsRequest) ([]
 		ID:       int32(id),
 		ActorIDs: ToActorIDs32(util.RemoveDuplicateInt(req.ActorIDs)),
 	}
-	err = s.repo.AddFilmActors(ctx, fa)
+	err = s.atomic(ctx, func(tx *Service) error {
+		before, err := tx.repo.GetFilm(ctx, fa.ID)
+		if err != nil {
+			log.Printf("ERROR: failed to get film record from repository\n")
+			return err
+		}
+
+		err = tx.repo.AddFilmActors(ctx, fa)
+		if err != nil {
+			log.Printf("ERROR: failed to bind provided actors and film\n")
+			return err
+		}
+
+		after, err := tx.repo.GetFilm(ctx, fa.ID)
+		if err != nil {
+			log.Printf("ERROR: failed to get film record from repository\n")
+			return err
+		}
+
+		return tx.record(ctx, after.ID, audit.ActionBind, before, after)
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to bind provided actors and film\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	s.publish(ctx, fa.ID, fa.ActorIDs)
 
 	actors, err := s.repo.GetFilmActors(ctx, int32(id))
 	if err != nil {
@@ -256,9 +455,28 @@ func (s *Service) DeleteFilmActors(ctx context.Context, req *FilmActorsRequest)
 		ID:       int32(id),
 		ActorIDs: ToActorIDs32(util.RemoveDuplicateInt(req.ActorIDs)),
 	}
-	err = s.repo.DeleteFilmActors(ctx, fa)
+	err = s.atomic(ctx, func(tx *Service) error {
+		before, err := tx.repo.GetFilm(ctx, fa.ID)
+		if err != nil {
+			log.Printf("ERROR: failed to get film record from repository\n")
+			return err
+		}
+
+		err = tx.repo.DeleteFilmActors(ctx, fa)
+		if err != nil {
+			log.Printf("ERROR: failed to bind provided actors and film\n")
+			return err
+		}
+
+		after, err := tx.repo.GetFilm(ctx, fa.ID)
+		if err != nil {
+			log.Printf("ERROR: failed to get film record from repository\n")
+			return err
+		}
+
+		return tx.record(ctx, after.ID, audit.ActionUnbind, before, after)
+	})
 	if err != nil {
-		log.Printf("ERROR: failed to bind provided actors and film\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -272,3 +490,38 @@ func (s *Service) DeleteFilmActors(ctx context.Context, req *FilmActorsRequest)
 
 	return res, nil
 }
+
+// RestoreFilm takes the film out of the trash together with its bindings.
+func (s *Service) RestoreFilm(ctx context.Context, req *FilmIdRequest) (*FilmResponse, error) {
+	const op = "film.Service.RestoreFilm"
+
+	id, err := strconv.ParseUint(req.ID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+
+	var film *Film
+	err = s.atomic(ctx, func(tx *Service) error {
+		err := tx.repo.RestoreFilm(ctx, int32(id))
+		if err != nil {
+			log.Printf("ERROR: failed to restore film record in repository\n")
+			return err
+		}
+
+		film, err = tx.repo.GetFilm(ctx, int32(id))
+		if err != nil {
+			log.Printf("ERROR: failed to get film record from repository\n")
+			return err
+		}
+
+		return tx.record(ctx, film.ID, audit.ActionRestore, nil, film)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := ToFilmResponse(film)
+
+	return res, nil
+}
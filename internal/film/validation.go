@@ -1,19 +1,101 @@
 package film
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Coderovshik/film-library/internal/util"
 )
 
-var validSortQuery = regexp.MustCompile("^(name|rating|releasedate),(asc|desc)$")
+const (
+	NameMatchExact     = "exact"
+	NameMatchSubstring = "substring"
+	ActorMatchAll      = "all"
+	ActorMatchAny      = "any"
+
+	maxActorIDs    = 20
+	maxGenreIDs    = 20
+	maxGenres      = 10
+	maxGenreLength = 50
+)
+
+// validFacets are the facets films can be counted by.
+var validFacets = []string{FacetRating, FacetDecade, FacetActor, FacetGenre}
+
+var validSortQuery = regexp.MustCompile("^(name|rating|releasedate),(asc|desc)(;(name|rating|releasedate),(asc|desc))*$")
 
 func ValidateGetFilmsRequest(req *GetFilmsRequest) *util.ValidationError {
 	ve := &util.ValidationError{}
 
-	if len(req.SortQuery) != 0 && !validSortQuery.MatchString(req.SortQuery) {
-		ve.AddViolation("incorrect sort query, expect value of pattern: '^(name|rating|releasedate),(asc|desc)$'")
+	if len(req.SortQuery) != 0 {
+		if !validSortQuery.MatchString(req.SortQuery) {
+			ve.AddViolation("incorrect sort query, expect value of pattern: '^(name|rating|releasedate),(asc|desc)(;(name|rating|releasedate),(asc|desc))*$'")
+		} else if !uniqueSortFields(req.SortQuery) {
+			ve.AddViolation("incorrect sort query, every field can be sorted by once")
+		}
+	}
+
+	switch req.NameMatchQuery {
+	case "", NameMatchExact, NameMatchSubstring:
+	default:
+		ve.AddViolation(fmt.Sprintf("incorrect nameMatch, expected one of: %s, %s", NameMatchExact, NameMatchSubstring))
+	}
+
+	if len(req.ActorIDQuery) > maxActorIDs {
+		ve.AddViolation(fmt.Sprintf("too many actorId values, expected at most %d", maxActorIDs))
+	}
+	for _, v := range req.ActorIDQuery {
+		if id, err := strconv.ParseInt(v, 10, 32); err != nil || id < 1 {
+			ve.AddViolation(fmt.Sprintf("incorrect actorId %q, expected positive integer", v))
+		}
+	}
+	switch req.ActorMatchQuery {
+	case "", ActorMatchAll, ActorMatchAny:
+	default:
+		ve.AddViolation(fmt.Sprintf("incorrect actorMatch, expected one of: %s, %s", ActorMatchAll, ActorMatchAny))
+	}
+
+	if len(req.GenreIDQuery) > maxGenreIDs {
+		ve.AddViolation(fmt.Sprintf("too many genreId values, expected at most %d", maxGenreIDs))
+	}
+	for _, v := range req.GenreIDQuery {
+		if id, err := strconv.ParseInt(v, 10, 32); err != nil || id < 1 {
+			ve.AddViolation(fmt.Sprintf("incorrect genreId %q, expected positive integer", v))
+		}
+	}
+
+	ratingMin, minOk := validateRating(ve, "ratingMin", req.RatingMinQuery)
+	ratingMax, maxOk := validateRating(ve, "ratingMax", req.RatingMaxQuery)
+	if minOk && maxOk && ratingMin > ratingMax {
+		ve.AddViolation("ratingMin is greater than ratingMax")
+	}
+
+	from, fromOk := validateDate(ve, "releasedFrom", req.ReleasedFromQuery)
+	to, toOk := validateDate(ve, "releasedTo", req.ReleasedToQuery)
+	if fromOk && toOk && from.After(to) {
+		ve.AddViolation("releasedFrom is later than releasedTo")
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, req.UpdatedSinceQuery); err != nil && len(req.UpdatedSinceQuery) != 0 {
+		ve.AddViolation("incorrect updatedSince format (expected RFC 3339 timestamp: 2006-01-02T15:04:05Z)")
+	}
+
+	switch req.SeenQuery {
+	case "", "true", "false":
+	default:
+		ve.AddViolation("incorrect seen, expected one of: true, false")
+	}
+
+	if len(req.FacetsQuery) != 0 {
+		for _, v := range strings.Split(req.FacetsQuery, ",") {
+			if !slices.Contains(validFacets, v) {
+				ve.AddViolation(fmt.Sprintf("incorrect facet %q, expected one of: %s", v, strings.Join(validFacets, ", ")))
+			}
+		}
 	}
 
 	if ve.NoViolations() {
@@ -23,7 +105,50 @@ func ValidateGetFilmsRequest(req *GetFilmsRequest) *util.ValidationError {
 	return ve
 }
 
-func ValidateFormatFilmInfo(fi *FilmInfo, allowNegativeRating bool) *util.ValidationError {
+func uniqueSortFields(sortQuery string) bool {
+	seen := make(map[string]bool)
+	for _, v := range strings.Split(sortQuery, ";") {
+		field, _, _ := strings.Cut(v, ",")
+		if seen[field] {
+			return false
+		}
+		seen[field] = true
+	}
+
+	return true
+}
+
+// validateRating reports whether a rating is given and valid.
+func validateRating(ve *util.ValidationError, name, value string) (int, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > 10 {
+		ve.AddViolation(fmt.Sprintf("incorrect %s, expected: 0 <= %s <= 10", name, name))
+		return 0, false
+	}
+
+	return n, true
+}
+
+// validateDate reports whether a date is given and valid.
+func validateDate(ve *util.ValidationError, name, value string) (time.Time, bool) {
+	if len(value) == 0 {
+		return time.Time{}, false
+	}
+
+	d, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		ve.AddViolation(fmt.Sprintf("incorrect %s format (expected format: 2006-01-02)", name))
+		return time.Time{}, false
+	}
+
+	return d, true
+}
+
+func ValidateFormatFilmInfo(fi *FilmInfo) *util.ValidationError {
 	ve := &util.ValidationError{}
 
 	if len(fi.Name) > 150 {
@@ -38,10 +163,21 @@ func ValidateFormatFilmInfo(fi *FilmInfo, allowNegativeRating bool) *util.Valida
 		ve.AddViolation("incorrect date format (expected format: 2006-01-02)")
 	}
 
-	if (fi.Rating < 0 && !allowNegativeRating) || fi.Rating > 10 {
+	if fi.Rating < 0 || fi.Rating > 10 {
 		ve.AddViolation("incorrect rating, expected: 0 <= rating <= 10")
 	}
 
+	if len(fi.Genres) > maxGenres {
+		ve.AddViolation(fmt.Sprintf("more than %d genres", maxGenres))
+	}
+	for _, v := range fi.Genres {
+		if len(strings.TrimSpace(v)) == 0 {
+			ve.AddViolation("genre empty")
+		} else if len(v) > maxGenreLength {
+			ve.AddViolation(fmt.Sprintf("genre length is more than %d symbols", maxGenreLength))
+		}
+	}
+
 	if ve.NoViolations() {
 		return nil
 	}
@@ -66,3 +202,32 @@ func ValidateEmptyFilmInfo(fi *FilmInfo) *util.ValidationError {
 
 	return ve
 }
+
+func ValidateFilmPatch(fp *FilmPatch) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if fp.Name.Null || (fp.Name.Set && len(fp.Name.Value) == 0) {
+		ve.AddViolation("name empty")
+	}
+
+	if fp.ReleaseDate.Null || (fp.ReleaseDate.Set && len(fp.ReleaseDate.Value) == 0) {
+		ve.AddViolation("date empty (expected format: 2006-01-02)")
+	}
+
+	if fp.Rating.Null {
+		ve.AddViolation("rating cannot be null")
+	}
+
+	if !ve.NoViolations() {
+		return ve
+	}
+
+	// absent fields hold zero values which pass the format checks
+	return ValidateFormatFilmInfo(&FilmInfo{
+		Name:        fp.Name.Value,
+		Description: fp.Description.Value,
+		ReleaseDate: fp.ReleaseDate.Value,
+		Rating:      fp.Rating.Value,
+		Genres:      fp.Genres.Value,
+	})
+}
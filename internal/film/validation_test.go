@@ -0,0 +1,46 @@
+package film
+
+import "testing"
+
+func TestValidateGetFilmsRequest(t *testing.T) {
+	valid := []*GetFilmsRequest{
+		{},
+		{SortQuery: "name,asc"},
+		{SortQuery: "rating,desc;name,asc;releasedate,desc"},
+		{NameMatchQuery: NameMatchExact, ActorIDQuery: []string{"1", "2"}, ActorMatchQuery: ActorMatchAll},
+		{RatingMinQuery: "5", RatingMaxQuery: "5", ReleasedFromQuery: "1990-01-01", ReleasedToQuery: "1990-01-01"},
+		{FacetsQuery: "rating,decade,actor,genre,rating", GenreIDQuery: []string{"3"}},
+		{SeenQuery: "false"},
+	}
+	for _, v := range valid {
+		if err := ValidateGetFilmsRequest(v); err != nil {
+			t.Errorf("Expected %+v to be valid, got %s", v, err.Error())
+		}
+	}
+
+	invalid := []*GetFilmsRequest{
+		{SortQuery: "rating,desc;"},
+		{SortQuery: "rating,desc;rating,asc"},
+		{SortQuery: "rating desc"},
+		{NameMatchQuery: "prefix"},
+		{ActorIDQuery: []string{"0"}},
+		{ActorIDQuery: []string{"1 OR 1=1"}},
+		{ActorIDQuery: make([]string, maxActorIDs+1)},
+		{ActorMatchQuery: "none"},
+		{RatingMinQuery: "11"},
+		{RatingMinQuery: "6", RatingMaxQuery: "5"},
+		{ReleasedFromQuery: "01.01.1990"},
+		{ReleasedFromQuery: "2000-01-01", ReleasedToQuery: "1990-01-01"},
+		{UpdatedSinceQuery: "yesterday"},
+		{GenreIDQuery: []string{"-1"}},
+		{GenreIDQuery: make([]string, maxGenreIDs+1)},
+		{FacetsQuery: "rating,studio"},
+		{FacetsQuery: "rating,"},
+		{SeenQuery: "no"},
+	}
+	for _, v := range invalid {
+		if err := ValidateGetFilmsRequest(v); err == nil {
+			t.Errorf("Expected validation error for %+v", v)
+		}
+	}
+}
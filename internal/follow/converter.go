@@ -0,0 +1,55 @@
+package follow
+
+import (
+	"time"
+)
+
+func ToFollowResponse(f *Follow) *FollowResponse {
+	return &FollowResponse{
+		Actor: ActorShortResponse{
+			ID:   f.ActorID,
+			Name: f.ActorName,
+		},
+		FollowedAt: f.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func ToFollowResponses(follows []*Follow) []*FollowResponse {
+	res := make([]*FollowResponse, 0, len(follows))
+	for _, v := range follows {
+		res = append(res, ToFollowResponse(v))
+	}
+
+	return res
+}
+
+func ToNotificationResponse(n *Notification) *NotificationResponse {
+	res := &NotificationResponse{
+		ID: n.ID,
+		Actor: ActorShortResponse{
+			ID:   n.ActorID,
+			Name: n.ActorName,
+		},
+		Film: FilmShortResponse{
+			ID:   n.FilmID,
+			Name: n.FilmName,
+		},
+		Status:    n.Status,
+		Attempts:  n.Attempts,
+		CreatedAt: n.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	if n.SentAt != nil {
+		res.SentAt = n.SentAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return res
+}
+
+func ToNotificationResponses(notifications []*Notification) []*NotificationResponse {
+	res := make([]*NotificationResponse, 0, len(notifications))
+	for _, v := range notifications {
+		res = append(res, ToNotificationResponse(v))
+	}
+
+	return res
+}
@@ -0,0 +1,125 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+// dispatchBatch is the number of notifications claimed at once.
+const dispatchBatch = 100
+
+// Dispatcher delivers the recorded notifications through a Notifier.
+// A notification is claimed before it is sent and never claimed again,
+// so it is delivered at most once even with several dispatchers running.
+// One whose dispatcher stopped while sending it is failed once the claim
+// is older than claimTimeout, so the lost delivery is visible.
+type Dispatcher struct {
+	repo         FollowRepository
+	notifier     Notifier
+	maxAttempts  int
+	retryDelay   time.Duration
+	claimTimeout time.Duration
+}
+
+// NewDispatcher retries failed deliveries after retryDelay times the
+// attempts made, a notification fails for good after maxAttempts.
+func NewDispatcher(fr FollowRepository, n Notifier, maxAttempts int, retryDelay, claimTimeout time.Duration) *Dispatcher {
+	return &Dispatcher{
+		repo:         fr,
+		notifier:     n,
+		maxAttempts:  maxAttempts,
+		retryDelay:   retryDelay,
+		claimTimeout: claimTimeout,
+	}
+}
+
+// Dispatch delivers the notifications due by now and returns how many
+// of them were sent. Every claimed notification is finished even when
+// finishing one of them fails, dispatching stops after that batch.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	const op = "follow.Dispatcher.Dispatch"
+
+	stale, err := d.repo.FailStaleNotifications(ctx, db.Now().Add(-d.claimTimeout), ErrDeliveryUnknown.Error())
+	if err != nil {
+		log.Printf("ERROR: failed to fail stale notifications in repository\n")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if stale != 0 {
+		log.Printf("ERROR: %d notifications were left sending, their delivery is unknown\n", stale)
+	}
+
+	sent := 0
+	for {
+		notifications, err := d.repo.ClaimNotifications(ctx, db.Now(), dispatchBatch)
+		if err != nil {
+			log.Printf("ERROR: failed to claim notifications in repository\n")
+			return sent, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var finishErr error
+		for _, v := range notifications {
+			d.deliver(ctx, v)
+			if v.Status == StatusSent {
+				sent++
+			}
+
+			err := d.repo.FinishNotification(ctx, v)
+			if err != nil {
+				log.Printf("ERROR: failed to finish notification with id=%d in repository\n", v.ID)
+				finishErr = errors.Join(finishErr, err)
+			}
+		}
+		if finishErr != nil {
+			return sent, fmt.Errorf("%s: %w", op, finishErr)
+		}
+
+		if len(notifications) < dispatchBatch {
+			return sent, nil
+		}
+	}
+}
+
+// deliver sends the notification and sets the outcome.
+func (d *Dispatcher) deliver(ctx context.Context, n *Notification) {
+	err := d.notifier.Notify(ctx, n)
+	now := db.Now()
+	n.Attempts++
+
+	switch {
+	case err == nil:
+		n.Status, n.LastError, n.SentAt = StatusSent, "", &now
+	case errors.Is(err, ErrNoRecipient) || n.Attempts >= d.maxAttempts:
+		log.Printf("ERROR: failed to deliver notification with id=%d for good err=%s\n", n.ID, err.Error())
+		n.Status, n.LastError = StatusFailed, err.Error()
+	default:
+		log.Printf("ERROR: failed to deliver notification with id=%d err=%s\n", n.ID, err.Error())
+		n.Status, n.LastError = StatusPending, err.Error()
+		n.NextAttemptAt = now.Add(d.retryDelay * time.Duration(n.Attempts))
+	}
+}
+
+// Run dispatches the notifications every interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := d.Dispatch(ctx)
+		if err != nil {
+			log.Printf("ERROR: failed to dispatch notifications err=%s\n", err.Error())
+		} else if n != 0 {
+			log.Printf("INFO: sent %d notifications\n", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package follow lets users follow actors and notifies them when the
+// actors are bound to films. A notification is recorded once per user,
+// actor and film when the binding is made, and the Dispatcher delivers it
+// later through a Notifier.
+package follow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+const (
+	StatusPending = "pending"
+	// StatusSending marks a notification claimed by a dispatcher, it is
+	// never claimed again so that it is not sent twice. One left sending
+	// longer than the claim timeout is failed with ErrDeliveryUnknown.
+	StatusSending = "sending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+
+	maxEmailLength = 254
+)
+
+var (
+	ErrIdInvalid      = errors.New("invalid id")
+	ErrActorNotExist  = errors.New("actor does not exist")
+	ErrFollowNotExist = errors.New("actor is not followed by the user")
+	// ErrNotificationNotClaimed is returned when finishing a notification
+	// that is not being sent.
+	ErrNotificationNotClaimed = errors.New("notification is not claimed")
+	// ErrNoRecipient is returned by notifiers that have nowhere to deliver
+	// the notification to, it is not retried.
+	ErrNoRecipient = errors.New("user has no address to notify")
+	// ErrDeliveryUnknown is the last error of a notification whose
+	// dispatcher stopped while sending it, it may have been delivered.
+	ErrDeliveryUnknown = errors.New("delivery outcome unknown, the dispatcher stopped while sending")
+)
+
+// Follow is an actor followed by a user, follows of actors in the trash
+// are not listed.
+type Follow struct {
+	UserID    int32
+	ActorID   int32
+	ActorName string
+	CreatedAt time.Time
+}
+
+// Notification tells a user that an actor the user follows was bound to
+// a film. Email is empty for users without a notification address.
+type Notification struct {
+	ID            int32
+	UserID        int32
+	Username      string
+	Email         string
+	ActorID       int32
+	ActorName     string
+	FilmID        int32
+	FilmName      string
+	ReleaseDate   time.Time
+	Status        string
+	Attempts      int
+	LastError     string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+	SentAt        *time.Time
+}
+
+type FollowRepository interface {
+	// GetFollows returns the actors followed by the user, oldest follow
+	// first.
+	GetFollows(ctx context.Context, userID int32) ([]*Follow, error)
+	// AddFollow does nothing if the user follows the actor already, the
+	// follow gets the name of the actor and the time it was made.
+	AddFollow(ctx context.Context, f *Follow) error
+	DeleteFollow(ctx context.Context, userID int32, actorID int32) error
+	// SetEmail sets the address notifications of the user are mailed to,
+	// an empty one removes it.
+	SetEmail(ctx context.Context, userID int32, email string) error
+
+	// AddNotifications records a pending notification of the film for
+	// every follower of the actors and returns how many were recorded,
+	// the ones recorded before are left as they are.
+	AddNotifications(ctx context.Context, filmID int32, actorIDs []int32, createdAt time.Time) (int64, error)
+	// GetNotifications returns the notifications of the user newest first.
+	GetNotifications(ctx context.Context, userID int32) ([]*Notification, error)
+	// ClaimNotifications marks at most limit pending notifications due by
+	// now as sending, claimed at now, and returns them oldest first.
+	ClaimNotifications(ctx context.Context, now time.Time, limit int) ([]*Notification, error)
+	// FailStaleNotifications fails the notifications claimed before the
+	// given time and still sending with lastError and returns how many
+	// were failed.
+	FailStaleNotifications(ctx context.Context, claimedBefore time.Time, lastError string) (int64, error)
+	// FinishNotification stores the status, attempts, last error, next
+	// attempt and sent times of a claimed notification.
+	FinishNotification(ctx context.Context, n *Notification) error
+}
+
+// Notifier delivers a notification to the user, ErrNoRecipient tells
+// that it can not be delivered at all.
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+type FollowService interface {
+	film.EventPublisher
+	GetFollows(ctx context.Context, req *UserRequest) ([]*FollowResponse, error)
+	Follow(ctx context.Context, req *FollowRequest) (*FollowResponse, error)
+	Unfollow(ctx context.Context, req *FollowRequest) error
+	SetEmail(ctx context.Context, req *EmailRequest) (*EmailInfo, error)
+	GetNotifications(ctx context.Context, req *UserRequest) ([]*NotificationResponse, error)
+}
+
+type FollowHandler interface {
+	GetFollows(w http.ResponseWriter, r *http.Request)
+	Follow(w http.ResponseWriter, r *http.Request)
+	Unfollow(w http.ResponseWriter, r *http.Request)
+	SetEmail(w http.ResponseWriter, r *http.Request)
+	GetNotifications(w http.ResponseWriter, r *http.Request)
+}
+
+type UserRequest struct {
+	UserID int32
+}
+
+type FollowRequest struct {
+	UserID  int32
+	ActorID string
+}
+
+type EmailInfo struct {
+	Email string `json:"email"`
+}
+
+type EmailRequest struct {
+	UserID int32
+	Info   EmailInfo
+}
+
+type ActorShortResponse struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type FilmShortResponse struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type FollowResponse struct {
+	Actor      ActorShortResponse `json:"actor"`
+	FollowedAt string             `json:"followedAt"`
+}
+
+type NotificationResponse struct {
+	ID        int32              `json:"id"`
+	Actor     ActorShortResponse `json:"actor"`
+	Film      FilmShortResponse  `json:"film"`
+	Status    string             `json:"status"`
+	Attempts  int                `json:"attempts"`
+	CreatedAt string             `json:"createdAt"`
+	SentAt    string             `json:"sentAt,omitempty"`
+}
@@ -0,0 +1,153 @@
+package follow
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ FollowHandler = (*Handler)(nil)
+
+type Handler struct {
+	service FollowService
+}
+
+func NewHandler(fs FollowService) *Handler {
+	return &Handler{
+		service: fs,
+	}
+}
+
+// userID returns the id of the authenticated user, the follow routes are
+// behind the authentication middleware.
+func userID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	uc, ok := util.UserClaimsFromContext(r.Context())
+	if !ok {
+		log.Printf("ERROR: follow request without authenticated user\n")
+		util.InternalServerError(w, r)
+		return 0, false
+	}
+
+	return int32(uc.ID), true
+}
+
+func serviceError(w http.ResponseWriter, r *http.Request, err error) {
+	var ve *util.ValidationError
+	if errors.As(err, &ve) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, ErrIdInvalid) {
+		util.NotFound(w, r)
+		return
+	}
+
+	for _, v := range []error{ErrActorNotExist, ErrFollowNotExist} {
+		if errors.Is(err, v) {
+			util.JSON(w, r, http.StatusNotFound, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeNotFound,
+				Body:      v.Error(),
+			})
+			return
+		}
+	}
+
+	util.InternalServerError(w, r)
+}
+
+func (h *Handler) GetFollows(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetFollows(r.Context(), &UserRequest{UserID: id})
+	if err != nil {
+		log.Printf("ERROR: failed to get follows err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) Follow(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.Follow(r.Context(), &FollowRequest{
+		UserID:  id,
+		ActorID: r.PathValue("id"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to follow actor err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.Unfollow(r.Context(), &FollowRequest{
+		UserID:  id,
+		ActorID: r.PathValue("id"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to unfollow actor err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.OK(w, r)
+}
+
+func (h *Handler) SetEmail(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	req := EmailRequest{UserID: id}
+	if ok := util.BindJSON(w, r, &req.Info); !ok {
+		return
+	}
+
+	res, err := h.service.SetEmail(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to set email err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetNotifications(r.Context(), &UserRequest{UserID: id})
+	if err != nil {
+		log.Printf("ERROR: failed to get notifications err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,405 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/follow/follow.go
+//
+// Generated by this command:
+//
+//	mockgen -self_package=github.com/Coderovshik/film-library/internal/follow -package=follow -source=internal/follow/follow.go -destination=internal/follow/mock.go
+//
+
+// Package follow is a generated GoMock package.
+package follow
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+	time "time"
+
+	film "github.com/Coderovshik/film-library/internal/film"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFollowRepository is a mock of FollowRepository interface.
+type MockFollowRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowRepositoryMockRecorder
+}
+
+// MockFollowRepositoryMockRecorder is the mock recorder for MockFollowRepository.
+type MockFollowRepositoryMockRecorder struct {
+	mock *MockFollowRepository
+}
+
+// NewMockFollowRepository creates a new mock instance.
+func NewMockFollowRepository(ctrl *gomock.Controller) *MockFollowRepository {
+	mock := &MockFollowRepository{ctrl: ctrl}
+	mock.recorder = &MockFollowRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowRepository) EXPECT() *MockFollowRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddFollow mocks base method.
+func (m *MockFollowRepository) AddFollow(ctx context.Context, f *Follow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFollow", ctx, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFollow indicates an expected call of AddFollow.
+func (mr *MockFollowRepositoryMockRecorder) AddFollow(ctx, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFollow", reflect.TypeOf((*MockFollowRepository)(nil).AddFollow), ctx, f)
+}
+
+// AddNotifications mocks base method.
+func (m *MockFollowRepository) AddNotifications(ctx context.Context, filmID int32, actorIDs []int32, createdAt time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddNotifications", ctx, filmID, actorIDs, createdAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddNotifications indicates an expected call of AddNotifications.
+func (mr *MockFollowRepositoryMockRecorder) AddNotifications(ctx, filmID, actorIDs, createdAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNotifications", reflect.TypeOf((*MockFollowRepository)(nil).AddNotifications), ctx, filmID, actorIDs, createdAt)
+}
+
+// ClaimNotifications mocks base method.
+func (m *MockFollowRepository) ClaimNotifications(ctx context.Context, now time.Time, limit int) ([]*Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimNotifications", ctx, now, limit)
+	ret0, _ := ret[0].([]*Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimNotifications indicates an expected call of ClaimNotifications.
+func (mr *MockFollowRepositoryMockRecorder) ClaimNotifications(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimNotifications", reflect.TypeOf((*MockFollowRepository)(nil).ClaimNotifications), ctx, now, limit)
+}
+
+// DeleteFollow mocks base method.
+func (m *MockFollowRepository) DeleteFollow(ctx context.Context, userID, actorID int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFollow", ctx, userID, actorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFollow indicates an expected call of DeleteFollow.
+func (mr *MockFollowRepositoryMockRecorder) DeleteFollow(ctx, userID, actorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFollow", reflect.TypeOf((*MockFollowRepository)(nil).DeleteFollow), ctx, userID, actorID)
+}
+
+// FailStaleNotifications mocks base method.
+func (m *MockFollowRepository) FailStaleNotifications(ctx context.Context, claimedBefore time.Time, lastError string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailStaleNotifications", ctx, claimedBefore, lastError)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailStaleNotifications indicates an expected call of FailStaleNotifications.
+func (mr *MockFollowRepositoryMockRecorder) FailStaleNotifications(ctx, claimedBefore, lastError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailStaleNotifications", reflect.TypeOf((*MockFollowRepository)(nil).FailStaleNotifications), ctx, claimedBefore, lastError)
+}
+
+// FinishNotification mocks base method.
+func (m *MockFollowRepository) FinishNotification(ctx context.Context, n *Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishNotification", ctx, n)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishNotification indicates an expected call of FinishNotification.
+func (mr *MockFollowRepositoryMockRecorder) FinishNotification(ctx, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishNotification", reflect.TypeOf((*MockFollowRepository)(nil).FinishNotification), ctx, n)
+}
+
+// GetFollows mocks base method.
+func (m *MockFollowRepository) GetFollows(ctx context.Context, userID int32) ([]*Follow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollows", ctx, userID)
+	ret0, _ := ret[0].([]*Follow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollows indicates an expected call of GetFollows.
+func (mr *MockFollowRepositoryMockRecorder) GetFollows(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollows", reflect.TypeOf((*MockFollowRepository)(nil).GetFollows), ctx, userID)
+}
+
+// GetNotifications mocks base method.
+func (m *MockFollowRepository) GetNotifications(ctx context.Context, userID int32) ([]*Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, userID)
+	ret0, _ := ret[0].([]*Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockFollowRepositoryMockRecorder) GetNotifications(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockFollowRepository)(nil).GetNotifications), ctx, userID)
+}
+
+// SetEmail mocks base method.
+func (m *MockFollowRepository) SetEmail(ctx context.Context, userID int32, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmail", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmail indicates an expected call of SetEmail.
+func (mr *MockFollowRepositoryMockRecorder) SetEmail(ctx, userID, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmail", reflect.TypeOf((*MockFollowRepository)(nil).SetEmail), ctx, userID, email)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method.
+func (m *MockNotifier) Notify(ctx context.Context, n *Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, n)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockNotifierMockRecorder) Notify(ctx, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNotifier)(nil).Notify), ctx, n)
+}
+
+// MockFollowService is a mock of FollowService interface.
+type MockFollowService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowServiceMockRecorder
+}
+
+// MockFollowServiceMockRecorder is the mock recorder for MockFollowService.
+type MockFollowServiceMockRecorder struct {
+	mock *MockFollowService
+}
+
+// NewMockFollowService creates a new mock instance.
+func NewMockFollowService(ctrl *gomock.Controller) *MockFollowService {
+	mock := &MockFollowService{ctrl: ctrl}
+	mock.recorder = &MockFollowServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowService) EXPECT() *MockFollowServiceMockRecorder {
+	return m.recorder
+}
+
+// Follow mocks base method.
+func (m *MockFollowService) Follow(ctx context.Context, req *FollowRequest) (*FollowResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Follow", ctx, req)
+	ret0, _ := ret[0].(*FollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Follow indicates an expected call of Follow.
+func (mr *MockFollowServiceMockRecorder) Follow(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockFollowService)(nil).Follow), ctx, req)
+}
+
+// GetFollows mocks base method.
+func (m *MockFollowService) GetFollows(ctx context.Context, req *UserRequest) ([]*FollowResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollows", ctx, req)
+	ret0, _ := ret[0].([]*FollowResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollows indicates an expected call of GetFollows.
+func (mr *MockFollowServiceMockRecorder) GetFollows(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollows", reflect.TypeOf((*MockFollowService)(nil).GetFollows), ctx, req)
+}
+
+// GetNotifications mocks base method.
+func (m *MockFollowService) GetNotifications(ctx context.Context, req *UserRequest) ([]*NotificationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, req)
+	ret0, _ := ret[0].([]*NotificationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockFollowServiceMockRecorder) GetNotifications(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockFollowService)(nil).GetNotifications), ctx, req)
+}
+
+// Publish mocks base method.
+func (m *MockFollowService) Publish(ctx context.Context, e *film.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockFollowServiceMockRecorder) Publish(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockFollowService)(nil).Publish), ctx, e)
+}
+
+// SetEmail mocks base method.
+func (m *MockFollowService) SetEmail(ctx context.Context, req *EmailRequest) (*EmailInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmail", ctx, req)
+	ret0, _ := ret[0].(*EmailInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetEmail indicates an expected call of SetEmail.
+func (mr *MockFollowServiceMockRecorder) SetEmail(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmail", reflect.TypeOf((*MockFollowService)(nil).SetEmail), ctx, req)
+}
+
+// Unfollow mocks base method.
+func (m *MockFollowService) Unfollow(ctx context.Context, req *FollowRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfollow", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unfollow indicates an expected call of Unfollow.
+func (mr *MockFollowServiceMockRecorder) Unfollow(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfollow", reflect.TypeOf((*MockFollowService)(nil).Unfollow), ctx, req)
+}
+
+// MockFollowHandler is a mock of FollowHandler interface.
+type MockFollowHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowHandlerMockRecorder
+}
+
+// MockFollowHandlerMockRecorder is the mock recorder for MockFollowHandler.
+type MockFollowHandlerMockRecorder struct {
+	mock *MockFollowHandler
+}
+
+// NewMockFollowHandler creates a new mock instance.
+func NewMockFollowHandler(ctrl *gomock.Controller) *MockFollowHandler {
+	mock := &MockFollowHandler{ctrl: ctrl}
+	mock.recorder = &MockFollowHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowHandler) EXPECT() *MockFollowHandlerMockRecorder {
+	return m.recorder
+}
+
+// Follow mocks base method.
+func (m *MockFollowHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Follow", w, r)
+}
+
+// Follow indicates an expected call of Follow.
+func (mr *MockFollowHandlerMockRecorder) Follow(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockFollowHandler)(nil).Follow), w, r)
+}
+
+// GetFollows mocks base method.
+func (m *MockFollowHandler) GetFollows(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetFollows", w, r)
+}
+
+// GetFollows indicates an expected call of GetFollows.
+func (mr *MockFollowHandlerMockRecorder) GetFollows(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollows", reflect.TypeOf((*MockFollowHandler)(nil).GetFollows), w, r)
+}
+
+// GetNotifications mocks base method.
+func (m *MockFollowHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetNotifications", w, r)
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockFollowHandlerMockRecorder) GetNotifications(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockFollowHandler)(nil).GetNotifications), w, r)
+}
+
+// SetEmail mocks base method.
+func (m *MockFollowHandler) SetEmail(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetEmail", w, r)
+}
+
+// SetEmail indicates an expected call of SetEmail.
+func (mr *MockFollowHandlerMockRecorder) SetEmail(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmail", reflect.TypeOf((*MockFollowHandler)(nil).SetEmail), w, r)
+}
+
+// Unfollow mocks base method.
+func (m *MockFollowHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Unfollow", w, r)
+}
+
+// Unfollow indicates an expected call of Unfollow.
+func (mr *MockFollowHandlerMockRecorder) Unfollow(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfollow", reflect.TypeOf((*MockFollowHandler)(nil).Unfollow), w, r)
+}
@@ -0,0 +1,234 @@
+package follow
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP is a local SMTP server keeping the mails it receives, it
+// rejects recipients at the blocked domain.
+type fakeSMTP struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	mails []fakeMail
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	s := &fakeSMTP{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeSMTP) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *fakeSMTP) received() []fakeMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]fakeMail(nil), s.mails...)
+}
+
+// path returns the address in angle brackets of a MAIL or RCPT command.
+func path(line string) string {
+	start, end := strings.IndexByte(line, '<'), strings.IndexByte(line, '>')
+	if start == -1 || end < start {
+		return ""
+	}
+
+	return line[start+1 : end]
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+
+	c := textproto.NewConn(conn)
+	c.PrintfLine("220 fake ESMTP")
+
+	var m fakeMail
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		switch cmd := strings.ToUpper(line); {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			c.PrintfLine("250-fake")
+			c.PrintfLine("250 8BITMIME")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			m = fakeMail{from: path(line)}
+			c.PrintfLine("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to := path(line)
+			if strings.HasSuffix(to, "@blocked.example.com") {
+				c.PrintfLine("550 mailbox unavailable")
+				continue
+			}
+			m.to = append(m.to, to)
+			c.PrintfLine("250 OK")
+		case cmd == "DATA":
+			c.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			m.data = string(data)
+			s.mu.Lock()
+			s.mails = append(s.mails, m)
+			s.mu.Unlock()
+			c.PrintfLine("250 OK")
+		case cmd == "QUIT":
+			c.PrintfLine("221 bye")
+			return
+		case cmd == "RSET", cmd == "NOOP":
+			c.PrintfLine("250 OK")
+		default:
+			c.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+func testNotification() *Notification {
+	return &Notification{
+		ID:          12,
+		UserID:      7,
+		Username:    "alice",
+		Email:       "alice@example.com",
+		ActorID:     1,
+		ActorName:   "Keanu Reeves",
+		FilmID:      3,
+		FilmName:    "Матрица",
+		ReleaseDate: time.Date(1999, 3, 31, 0, 0, 0, 0, time.UTC),
+		CreatedAt:   time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSMTPNotifier_Notify(t *testing.T) {
+	server := newFakeSMTP(t)
+	sn := NewSMTPNotifier(server.addr(), "films@example.com", "", "", time.Second)
+
+	if err := sn.Notify(context.TODO(), testNotification()); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	mails := server.received()
+	if len(mails) != 1 || mails[0].from != "films@example.com" || len(mails[0].to) != 1 || mails[0].to[0] != "alice@example.com" {
+		t.Fatalf("Expected a mail from films@example.com to alice@example.com, got %+v", mails)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(mails[0].data))
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := "Keanu Reeves joins Матрица"; subject != exp {
+		t.Errorf("Expected subject %q, got %q", exp, subject)
+	}
+	if exp := "<notification-12@example.com>"; msg.Header.Get("Message-Id") != exp {
+		t.Errorf("Expected message id %s, got %s", exp, msg.Header.Get("Message-Id"))
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if !strings.Contains(string(body), "Keanu Reeves, whom you follow, joins the film Матрица released on 1999-03-31") {
+		t.Errorf("Unexpected body %q", body)
+	}
+
+	// users without an address are not mailed
+	n := testNotification()
+	n.Email = ""
+	if err := sn.Notify(context.TODO(), n); !errors.Is(err, ErrNoRecipient) {
+		t.Errorf("Expected %v, got %v", ErrNoRecipient, err)
+	}
+	n.Email = "alice@blocked.example.com"
+	if err := sn.Notify(context.TODO(), n); err == nil {
+		t.Errorf("Expected error for rejected recipient")
+	}
+	if mails := server.received(); len(mails) != 1 {
+		t.Errorf("Expected a single mail, got %+v", mails)
+	}
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var payload webhookPayload
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		if exp := "sha256=" + hex.EncodeToString(mac.Sum(nil)); r.Header.Get("X-Signature-256") != exp {
+			t.Errorf("Expected signature %s, got %s", exp, r.Header.Get("X-Signature-256"))
+		}
+		if r.Header.Get("X-Notification-Id") != "12" {
+			t.Errorf("Expected notification id 12, got %s", r.Header.Get("X-Notification-Id"))
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("No error expected, got %s", err.Error())
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	wn := NewWebhookNotifier(server.URL, "secret", time.Second)
+
+	if err := wn.Notify(context.TODO(), testNotification()); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := webhookPayload{
+		ID:        12,
+		Event:     WebhookEvent,
+		User:      webhookUser{ID: 7, Username: "alice"},
+		Actor:     ActorShortResponse{ID: 1, Name: "Keanu Reeves"},
+		Film:      webhookFilm{ID: 3, Name: "Матрица", ReleaseDate: "1999-03-31"},
+		CreatedAt: "2024-03-01T12:00:00Z",
+	}
+	if payload != exp {
+		t.Errorf("Expected %+v, got %+v", exp, payload)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := wn.Notify(context.TODO(), testNotification()); err == nil {
+		t.Errorf("Expected error for status %d", status)
+	}
+}
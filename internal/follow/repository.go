@@ -0,0 +1,358 @@
+package follow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ FollowRepository = (*Repository)(nil)
+
+const selectNotifications = `
+	SELECT n.notification_id, n.user_id, u.user_name, COALESCE(e.email, ''), n.actor_id, a.actor_name,
+		n.movie_id, m.movie_name, m.releasedate, n.status, n.attempts, n.last_error, n.created_at,
+		n.next_attempt_at, n.sent_at
+	FROM notifications n
+	INNER JOIN users u ON u.user_id = n.user_id
+	INNER JOIN actor a ON a.actor_id = n.actor_id
+	INNER JOIN movie m ON m.movie_id = n.movie_id
+	LEFT JOIN notification_emails e ON e.user_id = n.user_id`
+
+type Repository struct {
+	db      db.DBTX
+	dialect db.Dialect
+}
+
+func NewRepository(conn db.DBTX, d db.Dialect) *Repository {
+	return &Repository{
+		db:      conn,
+		dialect: d,
+	}
+}
+
+func scanNotification(row interface{ Scan(dest ...any) error }) (*Notification, error) {
+	var n Notification
+	var sentAt sql.NullTime
+	err := row.Scan(&n.ID, &n.UserID, &n.Username, &n.Email, &n.ActorID, &n.ActorName,
+		&n.FilmID, &n.FilmName, &n.ReleaseDate, &n.Status, &n.Attempts, &n.LastError, &n.CreatedAt,
+		&n.NextAttemptAt, &sentAt)
+	if sentAt.Valid {
+		n.SentAt = &sentAt.Time
+	}
+
+	return &n, err
+}
+
+func (r *Repository) GetFollows(ctx context.Context, userID int32) ([]*Follow, error) {
+	const op = "follow.Repository.GetFollows"
+
+	const query = `
+		SELECT f.user_id, f.actor_id, a.actor_name, f.created_at
+		FROM follows f
+		INNER JOIN actor a ON a.actor_id = f.actor_id AND a.deleted_at IS NULL
+		WHERE f.user_id = $1
+		ORDER BY f.created_at, f.actor_id`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	follows := make([]*Follow, 0)
+	for rows.Next() {
+		var f Follow
+		err := rows.Scan(&f.UserID, &f.ActorID, &f.ActorName, &f.CreatedAt)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		follows = append(follows, &f)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return follows, nil
+}
+
+func (r *Repository) AddFollow(ctx context.Context, f *Follow) error {
+	const op = "follow.Repository.AddFollow"
+
+	const actor = `SELECT actor_name FROM actor WHERE actor_id = $1 AND deleted_at IS NULL`
+	err := r.db.QueryRowContext(ctx, actor, f.ActorID).Scan(&f.ActorName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("ERROR: actor with id=%d does not exist\n", f.ActorID)
+			return fmt.Errorf("%s: %w", op, ErrActorNotExist)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	const query = `
+		INSERT INTO follows(user_id, actor_id, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, actor_id) DO NOTHING`
+	_, err = r.db.ExecContext(ctx, query, f.UserID, f.ActorID, db.Now())
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// the follow may have been made before
+	const createdAt = `SELECT created_at FROM follows WHERE user_id = $1 AND actor_id = $2`
+	err = r.db.QueryRowContext(ctx, createdAt, f.UserID, f.ActorID).Scan(&f.CreatedAt)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteFollow(ctx context.Context, userID int32, actorID int32) error {
+	const op = "follow.Repository.DeleteFollow"
+
+	const query = `DELETE FROM follows WHERE user_id = $1 AND actor_id = $2`
+	res, err := r.db.ExecContext(ctx, query, userID, actorID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by delete\n")
+		return fmt.Errorf("%s: %w", op, ErrFollowNotExist)
+	}
+
+	return nil
+}
+
+func (r *Repository) SetEmail(ctx context.Context, userID int32, email string) error {
+	const op = "follow.Repository.SetEmail"
+
+	var err error
+	if len(email) == 0 {
+		const query = `DELETE FROM notification_emails WHERE user_id = $1`
+		_, err = r.db.ExecContext(ctx, query, userID)
+	} else {
+		const query = `
+			INSERT INTO notification_emails(user_id, email, updated_at) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id) DO UPDATE SET email = excluded.email, updated_at = excluded.updated_at`
+		_, err = r.db.ExecContext(ctx, query, userID, email, db.Now())
+	}
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) AddNotifications(ctx context.Context, filmID int32, actorIDs []int32, createdAt time.Time) (int64, error) {
+	const op = "follow.Repository.AddNotifications"
+
+	if len(actorIDs) == 0 {
+		return 0, nil
+	}
+
+	in, values := make([]string, 0, len(actorIDs)), make([]any, 0, len(actorIDs))
+	for i, v := range actorIDs {
+		in = append(in, fmt.Sprintf("$%d", i+1))
+		values = append(values, v)
+	}
+	followers := `SELECT user_id, actor_id FROM follows WHERE actor_id IN (` + strings.Join(in, ", ") + `)`
+	rows, err := r.db.QueryContext(ctx, followers, values...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	// every row takes the film, status and time from the first arguments
+	args, values := make([]string, 0), []any{filmID, StatusPending, createdAt}
+	for rows.Next() {
+		var userID, actorID int32
+		if err := rows.Scan(&userID, &actorID); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		values = append(values, userID, actorID)
+		args = append(args, fmt.Sprintf("($%d, $%d, $1, $2, 0, '', $3, $3)", len(values)-1, len(values)))
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	rows.Close()
+	if len(args) == 0 {
+		return 0, nil
+	}
+
+	query := `INSERT INTO notifications(user_id, actor_id, movie_id, status, attempts, last_error,
+		created_at, next_attempt_at) VALUES ` + strings.Join(args, ", ") + `
+		ON CONFLICT (user_id, actor_id, movie_id) DO NOTHING`
+	res, err := r.db.ExecContext(ctx, query, values...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+func (r *Repository) GetNotifications(ctx context.Context, userID int32) ([]*Notification, error) {
+	const op = "follow.Repository.GetNotifications"
+
+	query := selectNotifications + ` WHERE n.user_id = $1 ORDER BY n.created_at DESC, n.notification_id DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notifications := make([]*Notification, 0)
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notifications, nil
+}
+
+func (r *Repository) ClaimNotifications(ctx context.Context, now time.Time, limit int) ([]*Notification, error) {
+	const op = "follow.Repository.ClaimNotifications"
+
+	const due = `
+		SELECT notification_id FROM notifications
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY notification_id LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, due, StatusPending, now, limit)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int32, 0)
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	rows.Close()
+
+	notifications := make([]*Notification, 0, len(ids))
+	for _, id := range ids {
+		// another dispatcher may have claimed it in the meantime
+		const claim = `UPDATE notifications SET status = $1, claimed_at = $2 WHERE notification_id = $3 AND status = $4`
+		res, err := r.db.ExecContext(ctx, claim, StatusSending, now, id, StatusPending)
+		if err != nil {
+			log.Printf("ERROR: failed to execute query\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		count, err := res.RowsAffected()
+		if err != nil {
+			log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if count == 0 {
+			continue
+		}
+
+		n, err := scanNotification(r.db.QueryRowContext(ctx, selectNotifications+` WHERE n.notification_id = $1`, id))
+		if err != nil {
+			log.Printf("ERROR: failed to execute query\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, nil
+}
+
+// FailStaleNotifications also fails the ones left sending before claims
+// were timed, they have no claim time.
+func (r *Repository) FailStaleNotifications(ctx context.Context, claimedBefore time.Time, lastError string) (int64, error) {
+	const op = "follow.Repository.FailStaleNotifications"
+
+	const query = `
+		UPDATE notifications SET status = $1, last_error = $2
+		WHERE status = $3 AND (claimed_at IS NULL OR claimed_at < $4)`
+	res, err := r.db.ExecContext(ctx, query, StatusFailed, lastError, StatusSending, claimedBefore)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+func (r *Repository) FinishNotification(ctx context.Context, n *Notification) error {
+	const op = "follow.Repository.FinishNotification"
+
+	const query = `
+		UPDATE notifications
+		SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, sent_at = $5
+		WHERE notification_id = $6 AND status = $7`
+	res, err := r.db.ExecContext(ctx, query, n.Status, n.Attempts, n.LastError, n.NextAttemptAt, n.SentAt,
+		n.ID, StatusSending)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, ErrNotificationNotClaimed)
+	}
+
+	return nil
+}
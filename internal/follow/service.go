@@ -0,0 +1,133 @@
+package follow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+var _ FollowService = (*Service)(nil)
+
+type Service struct {
+	repo FollowRepository
+}
+
+func NewService(fr FollowRepository) *Service {
+	return &Service{
+		repo: fr,
+	}
+}
+
+func parseID(s string) (int32, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return 0, ErrIdInvalid
+	}
+
+	return int32(id), nil
+}
+
+// Publish records the notifications of the followers of actors bound to
+// a film, other events are ignored.
+func (s *Service) Publish(ctx context.Context, e *film.Event) error {
+	const op = "follow.Service.Publish"
+
+	if e.Type != film.EventActorsBound || len(e.ActorIDs) == 0 {
+		return nil
+	}
+
+	count, err := s.repo.AddNotifications(ctx, e.FilmID, e.ActorIDs, e.OccurredAt)
+	if err != nil {
+		log.Printf("ERROR: failed to add notifications to repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count != 0 {
+		log.Printf("INFO: recorded %d notifications of film with id=%d\n", count, e.FilmID)
+	}
+
+	return nil
+}
+
+func (s *Service) GetFollows(ctx context.Context, req *UserRequest) ([]*FollowResponse, error) {
+	const op = "follow.Service.GetFollows"
+
+	follows, err := s.repo.GetFollows(ctx, req.UserID)
+	if err != nil {
+		log.Printf("ERROR: failed to get follows from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToFollowResponses(follows), nil
+}
+
+func (s *Service) Follow(ctx context.Context, req *FollowRequest) (*FollowResponse, error) {
+	const op = "follow.Service.Follow"
+
+	id, err := parseID(req.ActorID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	f := &Follow{
+		UserID:  req.UserID,
+		ActorID: id,
+	}
+	err = s.repo.AddFollow(ctx, f)
+	if err != nil {
+		log.Printf("ERROR: failed to add follow to repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToFollowResponse(f), nil
+}
+
+func (s *Service) Unfollow(ctx context.Context, req *FollowRequest) error {
+	const op = "follow.Service.Unfollow"
+
+	id, err := parseID(req.ActorID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.repo.DeleteFollow(ctx, req.UserID, id)
+	if err != nil {
+		log.Printf("ERROR: failed to delete follow from repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Service) SetEmail(ctx context.Context, req *EmailRequest) (*EmailInfo, error) {
+	const op = "follow.Service.SetEmail"
+
+	vErr := ValidateEmailInfo(&req.Info)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	err := s.repo.SetEmail(ctx, req.UserID, req.Info.Email)
+	if err != nil {
+		log.Printf("ERROR: failed to set email in repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &EmailInfo{Email: req.Info.Email}, nil
+}
+
+func (s *Service) GetNotifications(ctx context.Context, req *UserRequest) ([]*NotificationResponse, error) {
+	const op = "follow.Service.GetNotifications"
+
+	notifications, err := s.repo.GetNotifications(ctx, req.UserID)
+	if err != nil {
+		log.Printf("ERROR: failed to get notifications from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToNotificationResponses(notifications), nil
+}
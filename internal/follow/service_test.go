@@ -0,0 +1,191 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestService_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := NewMockFollowRepository(ctrl)
+
+	s := NewService(fm)
+
+	testTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fm.EXPECT().AddNotifications(gomock.Any(), int32(3), []int32{1, 2}, testTime).Return(int64(2), nil).Times(1)
+
+	err := s.Publish(context.TODO(), &film.Event{Type: film.EventActorsBound, FilmID: 3, ActorIDs: []int32{1, 2}, OccurredAt: testTime})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	// other events and events without actors record nothing
+	for _, v := range []*film.Event{{Type: "renamed", FilmID: 3}, {Type: film.EventActorsBound, FilmID: 3}} {
+		if err := s.Publish(context.TODO(), v); err != nil {
+			t.Errorf("No error expected, got %s", err.Error())
+		}
+	}
+}
+
+func TestService_Follow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := NewMockFollowRepository(ctrl)
+
+	s := NewService(fm)
+
+	testTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fm.EXPECT().AddFollow(gomock.Any(), &Follow{UserID: 7, ActorID: 1}).DoAndReturn(func(ctx context.Context, f *Follow) error {
+		f.ActorName, f.CreatedAt = "Keanu Reeves", testTime
+		return nil
+	}).Times(1)
+
+	res, err := s.Follow(context.TODO(), &FollowRequest{UserID: 7, ActorID: "1"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := &FollowResponse{
+		Actor:      ActorShortResponse{ID: 1, Name: "Keanu Reeves"},
+		FollowedAt: "2024-03-01T12:00:00Z",
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	_, err = s.Follow(context.TODO(), &FollowRequest{UserID: 7, ActorID: "-1"})
+	if !errors.Is(err, ErrIdInvalid) {
+		t.Errorf("Expected %v, got %v", ErrIdInvalid, err)
+	}
+}
+
+func TestService_SetEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := NewMockFollowRepository(ctrl)
+
+	s := NewService(fm)
+
+	fm.EXPECT().SetEmail(gomock.Any(), int32(7), "fan@example.com").Return(nil).Times(1)
+	fm.EXPECT().SetEmail(gomock.Any(), int32(7), "").Return(nil).Times(1)
+
+	for _, v := range []string{"fan@example.com", ""} {
+		res, err := s.SetEmail(context.TODO(), &EmailRequest{UserID: 7, Info: EmailInfo{Email: v}})
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		if res.Email != v {
+			t.Errorf("Expected %q, got %+v", v, res)
+		}
+	}
+
+	for _, v := range []string{"fan", "Fan <fan@example.com>", "fan@example.com\r\nBcc: x@example.com"} {
+		var ve *util.ValidationError
+		_, err := s.SetEmail(context.TODO(), &EmailRequest{UserID: 7, Info: EmailInfo{Email: v}})
+		if !errors.As(err, &ve) {
+			t.Errorf("Expected validation error for %q, got %v", v, err)
+		}
+	}
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := NewMockFollowRepository(ctrl)
+	nm := NewMockNotifier(ctrl)
+
+	d := NewDispatcher(fm, nm, 3, time.Minute, time.Hour)
+
+	claimed := []*Notification{
+		{ID: 1, Status: StatusSending},
+		{ID: 2, Status: StatusSending, Attempts: 1},
+		{ID: 3, Status: StatusSending, Attempts: 2},
+		{ID: 4, Status: StatusSending},
+	}
+	// claims older than the timeout are failed first
+	start := time.Now()
+	fm.EXPECT().
+		FailStaleNotifications(gomock.Any(), gomock.Any(), ErrDeliveryUnknown.Error()).
+		DoAndReturn(func(ctx context.Context, claimedBefore time.Time, lastError string) (int64, error) {
+			if diff := claimedBefore.Sub(start.Add(-time.Hour)); diff < -time.Second || diff > time.Second {
+				t.Errorf("Expected claims before %v failed, got %v", start.Add(-time.Hour), claimedBefore)
+			}
+			return 1, nil
+		}).Times(1)
+	fm.EXPECT().ClaimNotifications(gomock.Any(), gomock.Any(), dispatchBatch).Return(claimed, nil).Times(1)
+	nm.EXPECT().Notify(gomock.Any(), claimed[0]).Return(nil).Times(1)
+	nm.EXPECT().Notify(gomock.Any(), claimed[1]).Return(errors.New("unavailable")).Times(1)
+	nm.EXPECT().Notify(gomock.Any(), claimed[2]).Return(errors.New("unavailable")).Times(1)
+	nm.EXPECT().Notify(gomock.Any(), claimed[3]).Return(ErrNoRecipient).Times(1)
+	fm.EXPECT().FinishNotification(gomock.Any(), gomock.Any()).Return(nil).Times(4)
+
+	sent, err := d.Dispatch(context.TODO())
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if sent != 1 {
+		t.Errorf("Expected 1 notification sent, got %d", sent)
+	}
+
+	if n := claimed[0]; n.Status != StatusSent || n.Attempts != 1 || n.SentAt == nil {
+		t.Errorf("Expected sent notification, got %+v", n)
+	}
+	// the second attempt is retried after twice the delay
+	if n := claimed[1]; n.Status != StatusPending || n.Attempts != 2 || n.LastError != "unavailable" ||
+		n.NextAttemptAt.Before(start.Add(2*time.Minute)) {
+		t.Errorf("Expected notification retried in 2 minutes, got %+v", n)
+	}
+	if n := claimed[2]; n.Status != StatusFailed || n.Attempts != 3 {
+		t.Errorf("Expected notification failed after 3 attempts, got %+v", n)
+	}
+	if n := claimed[3]; n.Status != StatusFailed || n.Attempts != 1 || n.LastError != ErrNoRecipient.Error() {
+		t.Errorf("Expected notification without recipient failed, got %+v", n)
+	}
+}
+
+func TestDispatcher_DispatchFinishError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := NewMockFollowRepository(ctrl)
+	nm := NewMockNotifier(ctrl)
+
+	d := NewDispatcher(fm, nm, 3, time.Minute, time.Hour)
+
+	claimed := make([]*Notification, dispatchBatch)
+	for i := range claimed {
+		claimed[i] = &Notification{ID: int32(i + 1), Status: StatusSending}
+	}
+	finishErr := errors.New("connection lost")
+	fm.EXPECT().FailStaleNotifications(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+	fm.EXPECT().ClaimNotifications(gomock.Any(), gomock.Any(), dispatchBatch).Return(claimed, nil).Times(1)
+	nm.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(nil).Times(dispatchBatch)
+	fm.EXPECT().FinishNotification(gomock.Any(), claimed[0]).Return(finishErr).Times(1)
+	fm.EXPECT().FinishNotification(gomock.Any(), gomock.Any()).Return(nil).Times(dispatchBatch - 1)
+
+	sent, err := d.Dispatch(context.TODO())
+	if !errors.Is(err, finishErr) {
+		t.Errorf("Expected %v, got %v", finishErr, err)
+	}
+	if sent != dispatchBatch {
+		t.Errorf("Expected %d notifications sent, got %d", dispatchBatch, sent)
+	}
+}
+
+func TestDispatcher_DispatchStaleError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := NewMockFollowRepository(ctrl)
+	nm := NewMockNotifier(ctrl)
+
+	d := NewDispatcher(fm, nm, 3, time.Minute, time.Hour)
+
+	// nothing is claimed when the stale claims can not be failed
+	staleErr := errors.New("connection lost")
+	fm.EXPECT().FailStaleNotifications(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), staleErr).Times(1)
+
+	_, err := d.Dispatch(context.TODO())
+	if !errors.Is(err, staleErr) {
+		t.Errorf("Expected %v, got %v", staleErr, err)
+	}
+}
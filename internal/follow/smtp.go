@@ -0,0 +1,126 @@
+package follow
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+var _ Notifier = (*SMTPNotifier)(nil)
+
+// SMTPNotifier mails notifications to the addresses users set, it uses
+// STARTTLS when the server offers it and authenticates when a username
+// is given.
+type SMTPNotifier struct {
+	addr     string
+	from     string
+	username string
+	password string
+	timeout  time.Duration
+}
+
+func NewSMTPNotifier(addr, from, username, password string, timeout time.Duration) *SMTPNotifier {
+	return &SMTPNotifier{
+		addr:     addr,
+		from:     from,
+		username: username,
+		password: password,
+		timeout:  timeout,
+	}
+}
+
+func (sn *SMTPNotifier) Notify(ctx context.Context, n *Notification) error {
+	if len(n.Email) == 0 {
+		return ErrNoRecipient
+	}
+
+	host, _, err := net.SplitHostPort(sn.addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sn.timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", sn.addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if len(sn.username) != 0 {
+		if err := c.Auth(smtp.PlainAuth("", sn.username, sn.password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(sn.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(n.Email); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(sn.message(n)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// message returns the mail with the headers, names of actors and films
+// are encoded to keep the headers ASCII.
+func (sn *SMTPNotifier) message(n *Notification) []byte {
+	subject := fmt.Sprintf("%s joins %s", n.ActorName, n.FilmName)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", sn.from)
+	fmt.Fprintf(&b, "To: %s\r\n", n.Email)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <notification-%d@%s>\r\n", n.ID, sn.domain())
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "Hello, %s!\r\n\r\n", n.Username)
+	fmt.Fprintf(&b, "%s, whom you follow, joins the film %s released on %s.\r\n",
+		n.ActorName, n.FilmName, n.ReleaseDate.Format(time.DateOnly))
+
+	return b.Bytes()
+}
+
+// domain returns the domain of the sender address for message ids.
+func (sn *SMTPNotifier) domain() string {
+	if i := strings.LastIndexByte(sn.from, '@'); i != -1 {
+		return sn.from[i+1:]
+	}
+
+	return "localhost"
+}
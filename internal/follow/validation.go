@@ -0,0 +1,29 @@
+package follow
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+// ValidateEmailInfo accepts a bare address or an empty one, which turns
+// email notifications off.
+func ValidateEmailInfo(ei *EmailInfo) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(ei.Email) > maxEmailLength {
+		ve.AddViolation(fmt.Sprintf("email too long (max %d characters)", maxEmailLength))
+	} else if len(ei.Email) != 0 {
+		addr, err := mail.ParseAddress(ei.Email)
+		if err != nil || addr.Address != ei.Email {
+			ve.AddViolation("email invalid (expected address like user@example.com)")
+		}
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
@@ -0,0 +1,106 @@
+package follow
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var _ Notifier = (*WebhookNotifier)(nil)
+
+// WebhookEvent is the type of the notifications posted to webhooks.
+const WebhookEvent = "actor_joined_film"
+
+type webhookUser struct {
+	ID       int32  `json:"id"`
+	Username string `json:"username"`
+}
+
+type webhookFilm struct {
+	ID          int32  `json:"id"`
+	Name        string `json:"name"`
+	ReleaseDate string `json:"releasedate"`
+}
+
+type webhookPayload struct {
+	ID        int32              `json:"id"`
+	Event     string             `json:"event"`
+	User      webhookUser        `json:"user"`
+	Actor     ActorShortResponse `json:"actor"`
+	Film      webhookFilm        `json:"film"`
+	CreatedAt string             `json:"createdAt"`
+}
+
+// WebhookNotifier posts notifications as JSON to a URL. The id of the
+// notification is sent in the X-Notification-Id header and, with a
+// secret, the hex HMAC-SHA256 of the body in X-Signature-256.
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, secret string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (wn *WebhookNotifier) Notify(ctx context.Context, n *Notification) error {
+	body, err := json.Marshal(&webhookPayload{
+		ID:    n.ID,
+		Event: WebhookEvent,
+		User: webhookUser{
+			ID:       n.UserID,
+			Username: n.Username,
+		},
+		Actor: ActorShortResponse{
+			ID:   n.ActorID,
+			Name: n.ActorName,
+		},
+		Film: webhookFilm{
+			ID:          n.FilmID,
+			Name:        n.FilmName,
+			ReleaseDate: n.ReleaseDate.Format(time.DateOnly),
+		},
+		CreatedAt: n.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wn.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Notification-Id", strconv.Itoa(int(n.ID)))
+	if len(wn.secret) != 0 {
+		mac := hmac.New(sha256.New, []byte(wn.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	res, err := wn.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
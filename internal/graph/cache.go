@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Cache keeps the network built from the repository snapshot, it is
+// rebuilt once the stamp changes, that is after films, actors or their
+// bindings do.
+type Cache struct {
+	repo GraphRepository
+
+	mu      sync.Mutex
+	stamp   string
+	network *Network
+}
+
+func NewCache(gr GraphRepository) *Cache {
+	return &Cache{
+		repo: gr,
+	}
+}
+
+func (c *Cache) Network(ctx context.Context) (*Network, error) {
+	stamp, err := c.repo.Stamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// one rebuild at a time, the others wait for it instead of repeating it
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.network != nil && c.stamp == stamp {
+		return c.network, nil
+	}
+
+	s, err := c.repo.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.stamp, c.network = stamp, NewNetwork(s)
+	log.Printf("built co-star graph of %d actors and films", c.network.Len())
+
+	return c.network, nil
+}
@@ -0,0 +1,130 @@
+// Package graph links actors through the films they star in together,
+// for co-star rankings and degrees of separation between actors.
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+const (
+	KindActor = "actor"
+	KindFilm  = "film"
+
+	defaultCostarLimit = 20
+	maxCostarLimit     = 100
+	// maxDegrees bounds the path search, it is the number of films
+	// linking the two actors.
+	maxDegrees = 6
+)
+
+var (
+	ErrIdInvalid     = errors.New("invalid id")
+	ErrActorNotExist = errors.New("actor does not exist")
+	ErrNoPath        = errors.New("actors are not linked within the depth limit")
+)
+
+// Node is an actor or a film.
+type Node struct {
+	ID   int32
+	Name string
+}
+
+type Binding struct {
+	ActorID int32
+	FilmID  int32
+}
+
+// Snapshot holds the actors and films not in the trash and the bindings
+// between them.
+type Snapshot struct {
+	Actors   []*Node
+	Films    []*Node
+	Bindings []*Binding
+}
+
+type GraphRepository interface {
+	// Stamp changes whenever the actors, the films or their bindings do,
+	// so that the graph built from a snapshot is known to be stale.
+	Stamp(ctx context.Context) (string, error)
+	Snapshot(ctx context.Context) (*Snapshot, error)
+}
+
+// Costar is an actor sharing films with another one.
+type Costar struct {
+	ID          int32
+	Name        string
+	SharedFilms int
+}
+
+// Step is an actor or a film of a path, they alternate starting and
+// ending with an actor.
+type Step struct {
+	Kind string
+	ID   int32
+	Name string
+}
+
+type GraphService interface {
+	GetCostars(ctx context.Context, req *CostarsRequest) ([]*CostarResponse, error)
+	GetPath(ctx context.Context, req *PathRequest) (*PathResponse, error)
+}
+
+type GraphHandler interface {
+	GetCostars(w http.ResponseWriter, r *http.Request)
+	GetPath(w http.ResponseWriter, r *http.Request)
+}
+
+type CostarsRequest struct {
+	ID    string
+	Limit string
+}
+
+type PathRequest struct {
+	FromID   string
+	ToID     string
+	MaxDepth string
+}
+
+type CostarResponse struct {
+	ID          int32  `json:"id"`
+	Name        string `json:"name"`
+	SharedFilms int    `json:"sharedFilms"`
+}
+
+type StepResponse struct {
+	Kind string `json:"kind"`
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+// PathResponse is the shortest path, Degrees counts its films.
+type PathResponse struct {
+	Degrees int             `json:"degrees"`
+	Path    []*StepResponse `json:"path"`
+}
+
+func ToCostarResponse(c *Costar) *CostarResponse {
+	return &CostarResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		SharedFilms: c.SharedFilms,
+	}
+}
+
+func ToPathResponse(steps []*Step) *PathResponse {
+	res := &PathResponse{
+		Degrees: len(steps) / 2,
+		Path:    make([]*StepResponse, 0, len(steps)),
+	}
+	for _, v := range steps {
+		res.Path = append(res.Path, &StepResponse{
+			Kind: v.Kind,
+			ID:   v.ID,
+			Name: v.Name,
+		})
+	}
+
+	return res
+}
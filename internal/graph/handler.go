@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ GraphHandler = (*Handler)(nil)
+
+type Handler struct {
+	service GraphService
+}
+
+func NewHandler(gs GraphService) *Handler {
+	return &Handler{
+		service: gs,
+	}
+}
+
+func (h *Handler) GetCostars(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetCostars(r.Context(), &CostarsRequest{
+		ID:    r.PathValue("id"),
+		Limit: r.URL.Query().Get("limit"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get co-stars err=%s\n", err.Error())
+		graphError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetPath(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetPath(r.Context(), &PathRequest{
+		FromID:   r.PathValue("id"),
+		ToID:     r.PathValue("to"),
+		MaxDepth: r.URL.Query().Get("maxDepth"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get path err=%s\n", err.Error())
+
+		if errors.Is(err, ErrNoPath) {
+			util.JSON(w, r, http.StatusNotFound, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeNotFound,
+				Body:      "actors are not linked within the depth limit",
+			})
+			return
+		}
+
+		graphError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func graphError(w http.ResponseWriter, r *http.Request, err error) {
+	var ve *util.ValidationError
+	if errors.As(err, &ve) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrActorNotExist) {
+		util.NotFound(w, r)
+		return
+	}
+
+	util.InternalServerError(w, r)
+}
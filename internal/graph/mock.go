@@ -0,0 +1,171 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/graph/graph.go
+//
+// Generated by this command:
+//
+//	mockgen -self_package=github.com/Coderovshik/film-library/internal/graph -package=graph -source=internal/graph/graph.go -destination=internal/graph/mock.go
+//
+
+// Package graph is a generated GoMock package.
+package graph
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockGraphRepository is a mock of GraphRepository interface.
+type MockGraphRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockGraphRepositoryMockRecorder
+}
+
+// MockGraphRepositoryMockRecorder is the mock recorder for MockGraphRepository.
+type MockGraphRepositoryMockRecorder struct {
+	mock *MockGraphRepository
+}
+
+// NewMockGraphRepository creates a new mock instance.
+func NewMockGraphRepository(ctrl *gomock.Controller) *MockGraphRepository {
+	mock := &MockGraphRepository{ctrl: ctrl}
+	mock.recorder = &MockGraphRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGraphRepository) EXPECT() *MockGraphRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Snapshot mocks base method.
+func (m *MockGraphRepository) Snapshot(ctx context.Context) (*Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", ctx)
+	ret0, _ := ret[0].(*Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockGraphRepositoryMockRecorder) Snapshot(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockGraphRepository)(nil).Snapshot), ctx)
+}
+
+// Stamp mocks base method.
+func (m *MockGraphRepository) Stamp(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stamp", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stamp indicates an expected call of Stamp.
+func (mr *MockGraphRepositoryMockRecorder) Stamp(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stamp", reflect.TypeOf((*MockGraphRepository)(nil).Stamp), ctx)
+}
+
+// MockGraphService is a mock of GraphService interface.
+type MockGraphService struct {
+	ctrl     *gomock.Controller
+	recorder *MockGraphServiceMockRecorder
+}
+
+// MockGraphServiceMockRecorder is the mock recorder for MockGraphService.
+type MockGraphServiceMockRecorder struct {
+	mock *MockGraphService
+}
+
+// NewMockGraphService creates a new mock instance.
+func NewMockGraphService(ctrl *gomock.Controller) *MockGraphService {
+	mock := &MockGraphService{ctrl: ctrl}
+	mock.recorder = &MockGraphServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGraphService) EXPECT() *MockGraphServiceMockRecorder {
+	return m.recorder
+}
+
+// GetCostars mocks base method.
+func (m *MockGraphService) GetCostars(ctx context.Context, req *CostarsRequest) ([]*CostarResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCostars", ctx, req)
+	ret0, _ := ret[0].([]*CostarResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCostars indicates an expected call of GetCostars.
+func (mr *MockGraphServiceMockRecorder) GetCostars(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCostars", reflect.TypeOf((*MockGraphService)(nil).GetCostars), ctx, req)
+}
+
+// GetPath mocks base method.
+func (m *MockGraphService) GetPath(ctx context.Context, req *PathRequest) (*PathResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPath", ctx, req)
+	ret0, _ := ret[0].(*PathResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPath indicates an expected call of GetPath.
+func (mr *MockGraphServiceMockRecorder) GetPath(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPath", reflect.TypeOf((*MockGraphService)(nil).GetPath), ctx, req)
+}
+
+// MockGraphHandler is a mock of GraphHandler interface.
+type MockGraphHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockGraphHandlerMockRecorder
+}
+
+// MockGraphHandlerMockRecorder is the mock recorder for MockGraphHandler.
+type MockGraphHandlerMockRecorder struct {
+	mock *MockGraphHandler
+}
+
+// NewMockGraphHandler creates a new mock instance.
+func NewMockGraphHandler(ctrl *gomock.Controller) *MockGraphHandler {
+	mock := &MockGraphHandler{ctrl: ctrl}
+	mock.recorder = &MockGraphHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGraphHandler) EXPECT() *MockGraphHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetCostars mocks base method.
+func (m *MockGraphHandler) GetCostars(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetCostars", w, r)
+}
+
+// GetCostars indicates an expected call of GetCostars.
+func (mr *MockGraphHandlerMockRecorder) GetCostars(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCostars", reflect.TypeOf((*MockGraphHandler)(nil).GetCostars), w, r)
+}
+
+// GetPath mocks base method.
+func (m *MockGraphHandler) GetPath(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetPath", w, r)
+}
+
+// GetPath indicates an expected call of GetPath.
+func (mr *MockGraphHandlerMockRecorder) GetPath(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPath", reflect.TypeOf((*MockGraphHandler)(nil).GetPath), w, r)
+}
@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"slices"
+	"sort"
+)
+
+// Network is the actor-film bipartite graph, adjacency lists are sorted
+// by id so that ties are broken the same way every time.
+type Network struct {
+	actors     map[int32]string
+	films      map[int32]string
+	actorFilms map[int32][]int32
+	filmActors map[int32][]int32
+}
+
+// NewNetwork skips bindings of actors or films missing from the snapshot.
+func NewNetwork(s *Snapshot) *Network {
+	n := &Network{
+		actors:     make(map[int32]string, len(s.Actors)),
+		films:      make(map[int32]string, len(s.Films)),
+		actorFilms: make(map[int32][]int32),
+		filmActors: make(map[int32][]int32),
+	}
+	for _, v := range s.Actors {
+		n.actors[v.ID] = v.Name
+	}
+	for _, v := range s.Films {
+		n.films[v.ID] = v.Name
+	}
+
+	for _, v := range s.Bindings {
+		_, actorOk := n.actors[v.ActorID]
+		_, filmOk := n.films[v.FilmID]
+		if actorOk && filmOk {
+			n.actorFilms[v.ActorID] = append(n.actorFilms[v.ActorID], v.FilmID)
+			n.filmActors[v.FilmID] = append(n.filmActors[v.FilmID], v.ActorID)
+		}
+	}
+	for k, v := range n.actorFilms {
+		slices.Sort(v)
+		n.actorFilms[k] = slices.Compact(v)
+	}
+	for k, v := range n.filmActors {
+		slices.Sort(v)
+		n.filmActors[k] = slices.Compact(v)
+	}
+
+	return n
+}
+
+func (n *Network) HasActor(id int32) bool {
+	_, ok := n.actors[id]
+	return ok
+}
+
+// Len returns the number of actors and films.
+func (n *Network) Len() int {
+	return len(n.actors) + len(n.films)
+}
+
+// Costars returns up to limit actors sharing films with the actor, the
+// ones sharing more films first and then by name.
+func (n *Network) Costars(id int32, limit int) []*Costar {
+	shared := make(map[int32]int)
+	for _, f := range n.actorFilms[id] {
+		for _, a := range n.filmActors[f] {
+			if a != id {
+				shared[a]++
+			}
+		}
+	}
+
+	costars := make([]*Costar, 0, len(shared))
+	for k, v := range shared {
+		costars = append(costars, &Costar{ID: k, Name: n.actors[k], SharedFilms: v})
+	}
+	sort.Slice(costars, func(i, j int) bool {
+		a, b := costars[i], costars[j]
+		if a.SharedFilms != b.SharedFilms {
+			return a.SharedFilms > b.SharedFilms
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	return costars[:min(len(costars), limit)]
+}
+
+// link is how the search reached an actor: through the film shared with
+// the previous actor.
+type link struct {
+	actor int32
+	film  int32
+}
+
+// side is one of the two searches, parent is nil for its start.
+type side struct {
+	parent   map[int32]*link
+	frontier []int32
+}
+
+func newSide(start int32) *side {
+	return &side{
+		parent:   map[int32]*link{start: nil},
+		frontier: []int32{start},
+	}
+}
+
+// Path returns the shortest path from one actor to the other through at
+// most maxDegrees films, ok is false when there is none. The search runs
+// from both ends, each round widening the smaller frontier by a film.
+func (n *Network) Path(from, to int32, maxDegrees int) (steps []*Step, ok bool) {
+	if from == to {
+		return []*Step{n.actorStep(from)}, true
+	}
+
+	fwd, bwd := newSide(from), newSide(to)
+	for i := 0; i < maxDegrees; i++ {
+		if len(fwd.frontier) == 0 || len(bwd.frontier) == 0 {
+			return nil, false
+		}
+
+		s, other := fwd, bwd
+		if len(bwd.frontier) < len(fwd.frontier) {
+			s, other = bwd, fwd
+		}
+		// the first meeting is on a shortest path, a shorter one would
+		// have met in an earlier round
+		if meet, ok := n.expand(s, other); ok {
+			return n.join(fwd, bwd, meet), true
+		}
+	}
+
+	return nil, false
+}
+
+// expand replaces the frontier with the actors one film away not seen by
+// the search yet, stopping at the first one seen by the other search.
+func (n *Network) expand(s, other *side) (int32, bool) {
+	var next []int32
+	for _, a := range s.frontier {
+		for _, f := range n.actorFilms[a] {
+			for _, b := range n.filmActors[f] {
+				if _, seen := s.parent[b]; seen {
+					continue
+				}
+				s.parent[b] = &link{actor: a, film: f}
+				if _, met := other.parent[b]; met {
+					return b, true
+				}
+				next = append(next, b)
+			}
+		}
+	}
+	s.frontier = next
+
+	return 0, false
+}
+
+// join walks from the meeting actor back to the start of both searches.
+func (n *Network) join(fwd, bwd *side, meet int32) []*Step {
+	steps := []*Step{n.actorStep(meet)}
+	for l := fwd.parent[meet]; l != nil; l = fwd.parent[l.actor] {
+		steps = append(steps, n.filmStep(l.film), n.actorStep(l.actor))
+	}
+	slices.Reverse(steps)
+
+	for l := bwd.parent[meet]; l != nil; l = bwd.parent[l.actor] {
+		steps = append(steps, n.filmStep(l.film), n.actorStep(l.actor))
+	}
+
+	return steps
+}
+
+func (n *Network) actorStep(id int32) *Step {
+	return &Step{Kind: KindActor, ID: id, Name: n.actors[id]}
+}
+
+func (n *Network) filmStep(id int32) *Step {
+	return &Step{Kind: KindFilm, ID: id, Name: n.films[id]}
+}
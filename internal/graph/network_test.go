@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testNetwork links Bacon to Hanks through Apollo 13, to Reeves through
+// Hanks and Bullock, and leaves Lonely unlinked.
+func testNetwork() *Network {
+	return NewNetwork(&Snapshot{
+		Actors: []*Node{
+			{ID: 1, Name: "Kevin Bacon"},
+			{ID: 2, Name: "Tom Hanks"},
+			{ID: 3, Name: "Sandra Bullock"},
+			{ID: 4, Name: "Keanu Reeves"},
+			{ID: 5, Name: "Bill Paxton"},
+			{ID: 6, Name: "Lonely"},
+		},
+		Films: []*Node{
+			{ID: 10, Name: "Apollo 13"},
+			{ID: 11, Name: "Extremely Loud"},
+			{ID: 12, Name: "Speed"},
+			{ID: 13, Name: "Twister"},
+			{ID: 14, Name: "Trashed"},
+		},
+		Bindings: []*Binding{
+			{ActorID: 1, FilmID: 10}, {ActorID: 2, FilmID: 10}, {ActorID: 5, FilmID: 10},
+			{ActorID: 2, FilmID: 11}, {ActorID: 3, FilmID: 11},
+			{ActorID: 3, FilmID: 12}, {ActorID: 4, FilmID: 12},
+			{ActorID: 5, FilmID: 13}, {ActorID: 2, FilmID: 13},
+			// a film missing from the snapshot does not link anyone
+			{ActorID: 1, FilmID: 99}, {ActorID: 4, FilmID: 99},
+		},
+	})
+}
+
+func TestNetwork_Costars(t *testing.T) {
+	n := testNetwork()
+
+	exp := []*Costar{
+		{ID: 5, Name: "Bill Paxton", SharedFilms: 2},
+		{ID: 1, Name: "Kevin Bacon", SharedFilms: 1},
+		{ID: 3, Name: "Sandra Bullock", SharedFilms: 1},
+	}
+	if res := n.Costars(2, 10); !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+	if res := n.Costars(2, 1); !reflect.DeepEqual(exp[:1], res) {
+		t.Errorf("Expected %+v, got %+v", exp[:1], res)
+	}
+	if res := n.Costars(6, 10); len(res) != 0 {
+		t.Errorf("Expected no co-stars, got %+v", res)
+	}
+}
+
+func TestNetwork_Path(t *testing.T) {
+	n := testNetwork()
+
+	actor := func(id int32, name string) *Step { return &Step{Kind: KindActor, ID: id, Name: name} }
+	film := func(id int32, name string) *Step { return &Step{Kind: KindFilm, ID: id, Name: name} }
+
+	exp := []*Step{
+		actor(1, "Kevin Bacon"),
+		film(10, "Apollo 13"),
+		actor(2, "Tom Hanks"),
+		film(11, "Extremely Loud"),
+		actor(3, "Sandra Bullock"),
+		film(12, "Speed"),
+		actor(4, "Keanu Reeves"),
+	}
+	steps, ok := n.Path(1, 4, maxDegrees)
+	if !ok || !reflect.DeepEqual(exp, steps) {
+		t.Errorf("Expected %+v, got %+v", exp, steps)
+	}
+
+	// the same path walked the other way
+	steps, ok = n.Path(4, 1, maxDegrees)
+	for i, j := 0, len(exp)-1; i < j; i, j = i+1, j-1 {
+		exp[i], exp[j] = exp[j], exp[i]
+	}
+	if !ok || !reflect.DeepEqual(exp, steps) {
+		t.Errorf("Expected %+v, got %+v", exp, steps)
+	}
+
+	// the shared film with the lowest id links co-stars
+	exp = []*Step{actor(5, "Bill Paxton"), film(10, "Apollo 13"), actor(2, "Tom Hanks")}
+	if steps, ok := n.Path(5, 2, maxDegrees); !ok || !reflect.DeepEqual(exp, steps) {
+		t.Errorf("Expected %+v, got %+v", exp, steps)
+	}
+
+	exp = []*Step{actor(1, "Kevin Bacon")}
+	if steps, ok := n.Path(1, 1, maxDegrees); !ok || !reflect.DeepEqual(exp, steps) {
+		t.Errorf("Expected %+v, got %+v", exp, steps)
+	}
+
+	if steps, ok := n.Path(1, 4, 2); ok {
+		t.Errorf("Expected no path within 2 degrees, got %+v", steps)
+	}
+	if steps, ok := n.Path(1, 6, maxDegrees); ok {
+		t.Errorf("Expected no path to an unlinked actor, got %+v", steps)
+	}
+}
+
+func TestNetwork_PathIsShortest(t *testing.T) {
+	// a chain 1-2-...-8 with a shortcut film binding 2 and 7
+	s := &Snapshot{}
+	for i := int32(1); i <= 8; i++ {
+		s.Actors = append(s.Actors, &Node{ID: i})
+	}
+	for i := int32(1); i < 8; i++ {
+		s.Films = append(s.Films, &Node{ID: 100 + i})
+		s.Bindings = append(s.Bindings, &Binding{ActorID: i, FilmID: 100 + i}, &Binding{ActorID: i + 1, FilmID: 100 + i})
+	}
+	s.Films = append(s.Films, &Node{ID: 200})
+	s.Bindings = append(s.Bindings, &Binding{ActorID: 2, FilmID: 200}, &Binding{ActorID: 7, FilmID: 200})
+	n := NewNetwork(s)
+
+	for from := int32(1); from <= 8; from++ {
+		for to := int32(1); to <= 8; to++ {
+			steps, ok := n.Path(from, to, maxDegrees)
+			if !ok {
+				t.Fatalf("Expected path from %d to %d", from, to)
+			}
+			if exp := 2*distance(from, to) + 1; len(steps) != exp {
+				t.Errorf("Expected %d steps from %d to %d, got %d", exp, from, to, len(steps))
+			}
+			if steps[0].ID != from || steps[len(steps)-1].ID != to {
+				t.Errorf("Expected path from %d to %d, got %+v", from, to, steps)
+			}
+		}
+	}
+}
+
+// distance is the number of films between actors of the chain with the
+// shortcut.
+func distance(from, to int32) int {
+	abs := func(n int32) int {
+		if n < 0 {
+			return int(-n)
+		}
+		return int(n)
+	}
+	return min(abs(from-to), abs(from-2)+1+abs(to-7), abs(from-7)+1+abs(to-2))
+}
@@ -0,0 +1,114 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ GraphRepository = (*Repository)(nil)
+
+type Repository struct {
+	db      db.DBTX
+	dialect db.Dialect
+}
+
+func NewRepository(conn db.DBTX, d db.Dialect) *Repository {
+	return &Repository{
+		db:      conn,
+		dialect: d,
+	}
+}
+
+// Stamp counts the bindings and, for films and actors, counts them and
+// takes the latest id and change. Binding or unbinding actors updates
+// the film, renames update the names and the trash changes the counts.
+func (r *Repository) Stamp(ctx context.Context) (string, error) {
+	const op = "graph.Repository.Stamp"
+
+	const query = `
+		SELECT (SELECT COUNT(*) FROM actor_in_movie),
+			COUNT(*), COALESCE(MAX(movie_id), 0), COALESCE(CAST(MAX(updated_at) AS TEXT), ''),
+			(SELECT COUNT(*) FROM actor WHERE deleted_at IS NULL),
+			(SELECT COALESCE(MAX(actor_id), 0) FROM actor WHERE deleted_at IS NULL),
+			(SELECT COALESCE(CAST(MAX(updated_at) AS TEXT), '') FROM actor WHERE deleted_at IS NULL)
+		FROM movie WHERE deleted_at IS NULL`
+
+	var bindings, films, maxFilmID, actors, maxActorID int64
+	var filmsUpdatedAt, actorsUpdatedAt string
+	err := r.db.QueryRowContext(ctx, query).Scan(&bindings,
+		&films, &maxFilmID, &filmsUpdatedAt, &actors, &maxActorID, &actorsUpdatedAt)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fmt.Sprintf("%d/%d/%d/%s/%d/%d/%s", bindings,
+		films, maxFilmID, filmsUpdatedAt, actors, maxActorID, actorsUpdatedAt), nil
+}
+
+func (r *Repository) Snapshot(ctx context.Context) (*Snapshot, error) {
+	const op = "graph.Repository.Snapshot"
+
+	var s Snapshot
+	var err error
+	s.Actors, err = r.nodes(ctx, `SELECT actor_id, actor_name FROM actor WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	s.Films, err = r.nodes(ctx, `SELECT movie_id, movie_name FROM movie WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// bindings of films or actors in the trash are skipped by NewNetwork
+	rows, err := r.db.QueryContext(ctx, `SELECT actor_id, movie_id FROM actor_in_movie`)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	s.Bindings = make([]*Binding, 0)
+	for rows.Next() {
+		var b Binding
+		if err := rows.Scan(&b.ActorID, &b.FilmID); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		s.Bindings = append(s.Bindings, &b)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &s, nil
+}
+
+func (r *Repository) nodes(ctx context.Context, query string) ([]*Node, error) {
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := make([]*Node, 0)
+	for rows.Next() {
+		var n Node
+		if err := rows.Scan(&n.ID, &n.Name); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, err
+		}
+		nodes = append(nodes, &n)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, err
+	}
+
+	return nodes, nil
+}
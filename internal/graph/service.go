@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+var _ GraphService = (*Service)(nil)
+
+type Service struct {
+	cache *Cache
+}
+
+func NewService(gr GraphRepository) *Service {
+	return &Service{
+		cache: NewCache(gr),
+	}
+}
+
+func (s *Service) GetCostars(ctx context.Context, req *CostarsRequest) ([]*CostarResponse, error) {
+	const op = "graph.Service.GetCostars"
+
+	id, err := strconv.ParseUint(req.ID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+	vErr := ValidateCostarsRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	// validated by ValidateCostarsRequest
+	limit := defaultCostarLimit
+	if len(req.Limit) != 0 {
+		limit, _ = strconv.Atoi(req.Limit)
+	}
+
+	n, err := s.cache.Network(ctx)
+	if err != nil {
+		log.Printf("ERROR: failed to get co-star graph\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !n.HasActor(int32(id)) {
+		log.Printf("ERROR: actor not found id=%d\n", id)
+		return nil, fmt.Errorf("%s: %w", op, ErrActorNotExist)
+	}
+
+	costars := n.Costars(int32(id), limit)
+	res := make([]*CostarResponse, 0, len(costars))
+	for _, v := range costars {
+		res = append(res, ToCostarResponse(v))
+	}
+
+	return res, nil
+}
+
+func (s *Service) GetPath(ctx context.Context, req *PathRequest) (*PathResponse, error) {
+	const op = "graph.Service.GetPath"
+
+	from, err := strconv.ParseUint(req.FromID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+	to, err := strconv.ParseUint(req.ToID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+	vErr := ValidatePathRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	// validated by ValidatePathRequest
+	maxDepth := maxDegrees
+	if len(req.MaxDepth) != 0 {
+		maxDepth, _ = strconv.Atoi(req.MaxDepth)
+	}
+
+	n, err := s.cache.Network(ctx)
+	if err != nil {
+		log.Printf("ERROR: failed to get co-star graph\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !n.HasActor(int32(from)) || !n.HasActor(int32(to)) {
+		log.Printf("ERROR: actor not found from=%d to=%d\n", from, to)
+		return nil, fmt.Errorf("%s: %w", op, ErrActorNotExist)
+	}
+
+	steps, ok := n.Path(int32(from), int32(to), maxDepth)
+	if !ok {
+		log.Printf("ERROR: no path from=%d to=%d within %d degrees\n", from, to, maxDepth)
+		return nil, fmt.Errorf("%s: %w", op, ErrNoPath)
+	}
+
+	return ToPathResponse(steps), nil
+}
@@ -0,0 +1,132 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestService_GetCostars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockGraphRepository(ctrl)
+
+	s := NewService(m)
+
+	m.EXPECT().Stamp(gomock.Any()).Return("1", nil).Times(2)
+	m.EXPECT().Snapshot(gomock.Any()).Return(&Snapshot{
+		Actors:   []*Node{{ID: 1, Name: "Kevin Bacon"}, {ID: 2, Name: "Tom Hanks"}},
+		Films:    []*Node{{ID: 10, Name: "Apollo 13"}},
+		Bindings: []*Binding{{ActorID: 1, FilmID: 10}, {ActorID: 2, FilmID: 10}},
+	}, nil).Times(1)
+
+	exp := []*CostarResponse{{ID: 2, Name: "Tom Hanks", SharedFilms: 1}}
+	res, err := s.GetCostars(context.TODO(), &CostarsRequest{ID: "1"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	// the graph is reused while the stamp stays
+	_, err = s.GetCostars(context.TODO(), &CostarsRequest{ID: "3"})
+	if !errors.Is(err, ErrActorNotExist) {
+		t.Errorf("Expected %v, got %v", ErrActorNotExist, err)
+	}
+}
+
+func TestService_GetCostarsInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockGraphRepository(ctrl)
+
+	s := NewService(m)
+
+	_, err := s.GetCostars(context.TODO(), &CostarsRequest{ID: "abc"})
+	if !errors.Is(err, ErrIdInvalid) {
+		t.Errorf("Expected %v, got %v", ErrIdInvalid, err)
+	}
+
+	var ve *util.ValidationError
+	_, err = s.GetCostars(context.TODO(), &CostarsRequest{ID: "1", Limit: "0"})
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestService_GetPath(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockGraphRepository(ctrl)
+
+	s := NewService(m)
+
+	snapshot := &Snapshot{
+		Actors: []*Node{{ID: 1, Name: "Kevin Bacon"}, {ID: 2, Name: "Tom Hanks"}, {ID: 3, Name: "Sandra Bullock"}},
+		Films:  []*Node{{ID: 10, Name: "Apollo 13"}, {ID: 11, Name: "Extremely Loud"}},
+		Bindings: []*Binding{
+			{ActorID: 1, FilmID: 10}, {ActorID: 2, FilmID: 10},
+			{ActorID: 2, FilmID: 11}, {ActorID: 3, FilmID: 11},
+		},
+	}
+	gomock.InOrder(
+		m.EXPECT().Stamp(gomock.Any()).Return("1", nil),
+		m.EXPECT().Snapshot(gomock.Any()).Return(snapshot, nil),
+		m.EXPECT().Stamp(gomock.Any()).Return("1", nil),
+		// unbinding Hanks from Extremely Loud changes the stamp
+		m.EXPECT().Stamp(gomock.Any()).Return("2", nil),
+		m.EXPECT().Snapshot(gomock.Any()).Return(&Snapshot{
+			Actors:   snapshot.Actors,
+			Films:    snapshot.Films,
+			Bindings: snapshot.Bindings[:3],
+		}, nil),
+	)
+
+	exp := &PathResponse{
+		Degrees: 2,
+		Path: []*StepResponse{
+			{Kind: KindActor, ID: 1, Name: "Kevin Bacon"},
+			{Kind: KindFilm, ID: 10, Name: "Apollo 13"},
+			{Kind: KindActor, ID: 2, Name: "Tom Hanks"},
+			{Kind: KindFilm, ID: 11, Name: "Extremely Loud"},
+			{Kind: KindActor, ID: 3, Name: "Sandra Bullock"},
+		},
+	}
+	res, err := s.GetPath(context.TODO(), &PathRequest{FromID: "1", ToID: "3"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	_, err = s.GetPath(context.TODO(), &PathRequest{FromID: "1", ToID: "3", MaxDepth: "1"})
+	if !errors.Is(err, ErrNoPath) {
+		t.Errorf("Expected %v, got %v", ErrNoPath, err)
+	}
+
+	_, err = s.GetPath(context.TODO(), &PathRequest{FromID: "1", ToID: "3"})
+	if !errors.Is(err, ErrNoPath) {
+		t.Errorf("Expected %v, got %v", ErrNoPath, err)
+	}
+}
+
+func TestService_GetPathInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockGraphRepository(ctrl)
+
+	s := NewService(m)
+
+	_, err := s.GetPath(context.TODO(), &PathRequest{FromID: "1", ToID: "-2"})
+	if !errors.Is(err, ErrIdInvalid) {
+		t.Errorf("Expected %v, got %v", ErrIdInvalid, err)
+	}
+
+	var ve *util.ValidationError
+	_, err = s.GetPath(context.TODO(), &PathRequest{FromID: "1", ToID: "2", MaxDepth: "7"})
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
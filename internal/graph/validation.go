@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+func ValidateCostarsRequest(req *CostarsRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if n, err := strconv.Atoi(req.Limit); len(req.Limit) != 0 && (err != nil || n < 1 || n > maxCostarLimit) {
+		ve.AddViolation(fmt.Sprintf("incorrect limit, expected integer from 1 to %d", maxCostarLimit))
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidatePathRequest(req *PathRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if n, err := strconv.Atoi(req.MaxDepth); len(req.MaxDepth) != 0 && (err != nil || n < 1 || n > maxDegrees) {
+		ve.AddViolation(fmt.Sprintf("incorrect maxDepth, expected integer from 1 to %d", maxDegrees))
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
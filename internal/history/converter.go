@@ -0,0 +1,105 @@
+package history
+
+import (
+	"math"
+	"time"
+)
+
+// YearRange returns the first days of the year and of the next one.
+func YearRange(year int) (time.Time, time.Time) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	return from, from.AddDate(1, 0, 0)
+}
+
+// Today is the current date in UTC.
+func Today(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// ToWatch expects validated info, the rewatch flag is left to the caller
+// when absent.
+func ToWatch(userID int32, wi *WatchInfo, today time.Time) *Watch {
+	w := &Watch{
+		UserID:    userID,
+		FilmID:    int32(wi.FilmID),
+		WatchedOn: today,
+	}
+	if len(wi.WatchedOn) != 0 {
+		w.WatchedOn, _ = time.Parse(time.DateOnly, wi.WatchedOn)
+	}
+	if wi.Rating != nil {
+		rating := int32(*wi.Rating)
+		w.Rating = &rating
+	}
+	if wi.Rewatch != nil {
+		w.Rewatch = *wi.Rewatch
+	}
+
+	return w
+}
+
+func ToWatchResponse(w *Watch) *WatchResponse {
+	return &WatchResponse{
+		ID: w.ID,
+		Film: FilmShortResponse{
+			ID:   w.FilmID,
+			Name: w.FilmName,
+		},
+		WatchedOn: w.WatchedOn.Format(time.DateOnly),
+		Rating:    w.Rating,
+		Rewatch:   w.Rewatch,
+		Available: w.Available,
+	}
+}
+
+func ToWatchResponses(watches []*Watch) []*WatchResponse {
+	res := make([]*WatchResponse, 0, len(watches))
+	for _, v := range watches {
+		res = append(res, ToWatchResponse(v))
+	}
+
+	return res
+}
+
+// ToStatsResponse sums up the watches of the year, the average rating is
+// rounded to two decimals and left out when no watch is rated.
+func ToStatsResponse(year int, watches []*Watch, actors []*ActorCount) *StatsResponse {
+	res := &StatsResponse{
+		Year:      year,
+		Watches:   len(watches),
+		ByMonth:   make([]int, 12),
+		TopActors: make([]*ActorCountResponse, 0, len(actors)),
+	}
+
+	films := make(map[int32]struct{})
+	var rated, ratingSum int
+	for _, v := range watches {
+		films[v.FilmID] = struct{}{}
+		if v.Rewatch {
+			res.Rewatches++
+		}
+		if v.Rating != nil {
+			rated++
+			ratingSum += int(*v.Rating)
+		}
+		res.ByMonth[v.WatchedOn.Month()-1]++
+	}
+	res.Films = len(films)
+	if rated != 0 {
+		avg := math.Round(float64(ratingSum)/float64(rated)*100) / 100
+		res.AverageRating = &avg
+	}
+
+	for _, v := range actors {
+		res.TopActors = append(res.TopActors, &ActorCountResponse{
+			ID:    v.ID,
+			Name:  v.Name,
+			Films: v.Films,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,151 @@
+package history
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ HistoryHandler = (*Handler)(nil)
+
+type Handler struct {
+	service HistoryService
+}
+
+func NewHandler(hs HistoryService) *Handler {
+	return &Handler{
+		service: hs,
+	}
+}
+
+// userID returns the id of the authenticated user, the history routes
+// are behind the authentication middleware.
+func userID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	uc, ok := util.UserClaimsFromContext(r.Context())
+	if !ok {
+		log.Printf("ERROR: history request without authenticated user\n")
+		util.InternalServerError(w, r)
+		return 0, false
+	}
+
+	return int32(uc.ID), true
+}
+
+func validationError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var ve *util.ValidationError
+	if !errors.As(err, &ve) {
+		return false
+	}
+
+	util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+		ErrorType: util.ErrorTypeValidation,
+		Body:      ve.Error(),
+	})
+	return true
+}
+
+func (h *Handler) AddWatch(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	req := AddWatchRequest{UserID: id}
+	if ok := util.BindJSON(w, r, &req.Info); !ok {
+		return
+	}
+
+	res, err := h.service.AddWatch(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to add watch err=%s\n", err.Error())
+		if validationError(w, r, err) {
+			return
+		}
+
+		if errors.Is(err, ErrFilmNotExist) {
+			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeConflict,
+				Body:      "film is non-existent",
+			})
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetHistory(r.Context(), &HistoryRequest{
+		UserID: id,
+		Year:   r.URL.Query().Get("year"),
+		Limit:  r.URL.Query().Get("limit"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get history err=%s\n", err.Error())
+		if validationError(w, r, err) {
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) DeleteWatch(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteWatch(r.Context(), &WatchIdRequest{
+		UserID: id,
+		ID:     r.PathValue("id"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to delete watch err=%s\n", err.Error())
+		if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrWatchNotExist) {
+			util.NotFound(w, r)
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.OK(w, r)
+}
+
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetStats(r.Context(), &StatsRequest{
+		UserID: id,
+		Year:   r.URL.Query().Get("year"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get stats err=%s\n", err.Error())
+		if validationError(w, r, err) {
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,143 @@
+// Package history logs the films users watched, when and how they liked
+// them, for the viewing history, yearly stats and the seen film filter.
+package history
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+	// topActors is the number of most watched actors in the stats.
+	topActors = 10
+)
+
+var (
+	ErrIdInvalid     = errors.New("invalid id")
+	ErrFilmNotExist  = errors.New("film does not exist")
+	ErrWatchNotExist = errors.New("watch does not exist")
+)
+
+// Watch is a film watched by a user on a day, Rating is nil when the user
+// did not rate it. Watches of films in the trash are kept and are not
+// Available until the film is restored.
+type Watch struct {
+	ID        int32
+	UserID    int32
+	FilmID    int32
+	WatchedOn time.Time
+	Rating    *int32
+	Rewatch   bool
+	CreatedAt time.Time
+	FilmName  string
+	Available bool
+}
+
+// Query selects watches of the user watched from From until To, To
+// excluded. Zero FilmID, dates and Limit match any.
+type Query struct {
+	UserID int32
+	FilmID int32
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
+
+// ActorCount is an actor with the number of distinct films watched with
+// them.
+type ActorCount struct {
+	ID    int32
+	Name  string
+	Films int
+}
+
+type HistoryRepository interface {
+	// AddWatch fails with ErrFilmNotExist for films missing or in the trash.
+	AddWatch(ctx context.Context, w *Watch) (*Watch, error)
+	// GetWatches returns the watches latest first.
+	GetWatches(ctx context.Context, q *Query) ([]*Watch, error)
+	DeleteWatch(ctx context.Context, userID int32, id int32) error
+	// GetTopActors returns up to limit actors not in the trash starring in
+	// the films of the selected watches, the ones in more films first.
+	GetTopActors(ctx context.Context, q *Query, limit int) ([]*ActorCount, error)
+}
+
+type HistoryService interface {
+	AddWatch(ctx context.Context, req *AddWatchRequest) (*WatchResponse, error)
+	GetHistory(ctx context.Context, req *HistoryRequest) ([]*WatchResponse, error)
+	DeleteWatch(ctx context.Context, req *WatchIdRequest) error
+	GetStats(ctx context.Context, req *StatsRequest) (*StatsResponse, error)
+}
+
+type HistoryHandler interface {
+	AddWatch(w http.ResponseWriter, r *http.Request)
+	GetHistory(w http.ResponseWriter, r *http.Request)
+	DeleteWatch(w http.ResponseWriter, r *http.Request)
+	GetStats(w http.ResponseWriter, r *http.Request)
+}
+
+// WatchInfo is a logged watch, WatchedOn defaults to today and Rewatch to
+// whether the user logged the film before.
+type WatchInfo struct {
+	FilmID    int    `json:"filmId"`
+	WatchedOn string `json:"watchedOn"`
+	Rating    *int   `json:"rating"`
+	Rewatch   *bool  `json:"rewatch"`
+}
+
+type AddWatchRequest struct {
+	UserID int32
+	Info   WatchInfo
+}
+
+type HistoryRequest struct {
+	UserID int32
+	Year   string
+	Limit  string
+}
+
+type WatchIdRequest struct {
+	UserID int32
+	ID     string
+}
+
+type StatsRequest struct {
+	UserID int32
+	Year   string
+}
+
+type FilmShortResponse struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type WatchResponse struct {
+	ID        int32             `json:"id"`
+	Film      FilmShortResponse `json:"film"`
+	WatchedOn string            `json:"watchedOn"`
+	Rating    *int32            `json:"rating,omitempty"`
+	Rewatch   bool              `json:"rewatch"`
+	Available bool              `json:"available"`
+}
+
+type ActorCountResponse struct {
+	ID    int32  `json:"id"`
+	Name  string `json:"name"`
+	Films int    `json:"films"`
+}
+
+// StatsResponse sums up a year of watches, ByMonth holds the number of
+// watches of every month from January.
+type StatsResponse struct {
+	Year          int                   `json:"year"`
+	Watches       int                   `json:"watches"`
+	Films         int                   `json:"films"`
+	Rewatches     int                   `json:"rewatches"`
+	AverageRating *float64              `json:"averageRating,omitempty"`
+	ByMonth       []int                 `json:"byMonth"`
+	TopActors     []*ActorCountResponse `json:"topActors"`
+}
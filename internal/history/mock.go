@@ -0,0 +1,253 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/history/history.go
+//
+// Generated by this command:
+//
+//	mockgen -self_package=github.com/Coderovshik/film-library/internal/history -package=history -source=internal/history/history.go -destination=internal/history/mock.go
+//
+
+// Package history is a generated GoMock package.
+package history
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHistoryRepository is a mock of HistoryRepository interface.
+type MockHistoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryRepositoryMockRecorder
+}
+
+// MockHistoryRepositoryMockRecorder is the mock recorder for MockHistoryRepository.
+type MockHistoryRepositoryMockRecorder struct {
+	mock *MockHistoryRepository
+}
+
+// NewMockHistoryRepository creates a new mock instance.
+func NewMockHistoryRepository(ctrl *gomock.Controller) *MockHistoryRepository {
+	mock := &MockHistoryRepository{ctrl: ctrl}
+	mock.recorder = &MockHistoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistoryRepository) EXPECT() *MockHistoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddWatch mocks base method.
+func (m *MockHistoryRepository) AddWatch(ctx context.Context, w *Watch) (*Watch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWatch", ctx, w)
+	ret0, _ := ret[0].(*Watch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddWatch indicates an expected call of AddWatch.
+func (mr *MockHistoryRepositoryMockRecorder) AddWatch(ctx, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWatch", reflect.TypeOf((*MockHistoryRepository)(nil).AddWatch), ctx, w)
+}
+
+// DeleteWatch mocks base method.
+func (m *MockHistoryRepository) DeleteWatch(ctx context.Context, userID, id int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWatch", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWatch indicates an expected call of DeleteWatch.
+func (mr *MockHistoryRepositoryMockRecorder) DeleteWatch(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWatch", reflect.TypeOf((*MockHistoryRepository)(nil).DeleteWatch), ctx, userID, id)
+}
+
+// GetTopActors mocks base method.
+func (m *MockHistoryRepository) GetTopActors(ctx context.Context, q *Query, limit int) ([]*ActorCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopActors", ctx, q, limit)
+	ret0, _ := ret[0].([]*ActorCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopActors indicates an expected call of GetTopActors.
+func (mr *MockHistoryRepositoryMockRecorder) GetTopActors(ctx, q, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopActors", reflect.TypeOf((*MockHistoryRepository)(nil).GetTopActors), ctx, q, limit)
+}
+
+// GetWatches mocks base method.
+func (m *MockHistoryRepository) GetWatches(ctx context.Context, q *Query) ([]*Watch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWatches", ctx, q)
+	ret0, _ := ret[0].([]*Watch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWatches indicates an expected call of GetWatches.
+func (mr *MockHistoryRepositoryMockRecorder) GetWatches(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWatches", reflect.TypeOf((*MockHistoryRepository)(nil).GetWatches), ctx, q)
+}
+
+// MockHistoryService is a mock of HistoryService interface.
+type MockHistoryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryServiceMockRecorder
+}
+
+// MockHistoryServiceMockRecorder is the mock recorder for MockHistoryService.
+type MockHistoryServiceMockRecorder struct {
+	mock *MockHistoryService
+}
+
+// NewMockHistoryService creates a new mock instance.
+func NewMockHistoryService(ctrl *gomock.Controller) *MockHistoryService {
+	mock := &MockHistoryService{ctrl: ctrl}
+	mock.recorder = &MockHistoryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistoryService) EXPECT() *MockHistoryServiceMockRecorder {
+	return m.recorder
+}
+
+// AddWatch mocks base method.
+func (m *MockHistoryService) AddWatch(ctx context.Context, req *AddWatchRequest) (*WatchResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWatch", ctx, req)
+	ret0, _ := ret[0].(*WatchResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddWatch indicates an expected call of AddWatch.
+func (mr *MockHistoryServiceMockRecorder) AddWatch(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWatch", reflect.TypeOf((*MockHistoryService)(nil).AddWatch), ctx, req)
+}
+
+// DeleteWatch mocks base method.
+func (m *MockHistoryService) DeleteWatch(ctx context.Context, req *WatchIdRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWatch", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWatch indicates an expected call of DeleteWatch.
+func (mr *MockHistoryServiceMockRecorder) DeleteWatch(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWatch", reflect.TypeOf((*MockHistoryService)(nil).DeleteWatch), ctx, req)
+}
+
+// GetHistory mocks base method.
+func (m *MockHistoryService) GetHistory(ctx context.Context, req *HistoryRequest) ([]*WatchResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, req)
+	ret0, _ := ret[0].([]*WatchResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockHistoryServiceMockRecorder) GetHistory(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockHistoryService)(nil).GetHistory), ctx, req)
+}
+
+// GetStats mocks base method.
+func (m *MockHistoryService) GetStats(ctx context.Context, req *StatsRequest) (*StatsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, req)
+	ret0, _ := ret[0].(*StatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockHistoryServiceMockRecorder) GetStats(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockHistoryService)(nil).GetStats), ctx, req)
+}
+
+// MockHistoryHandler is a mock of HistoryHandler interface.
+type MockHistoryHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryHandlerMockRecorder
+}
+
+// MockHistoryHandlerMockRecorder is the mock recorder for MockHistoryHandler.
+type MockHistoryHandlerMockRecorder struct {
+	mock *MockHistoryHandler
+}
+
+// NewMockHistoryHandler creates a new mock instance.
+func NewMockHistoryHandler(ctrl *gomock.Controller) *MockHistoryHandler {
+	mock := &MockHistoryHandler{ctrl: ctrl}
+	mock.recorder = &MockHistoryHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistoryHandler) EXPECT() *MockHistoryHandlerMockRecorder {
+	return m.recorder
+}
+
+// AddWatch mocks base method.
+func (m *MockHistoryHandler) AddWatch(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddWatch", w, r)
+}
+
+// AddWatch indicates an expected call of AddWatch.
+func (mr *MockHistoryHandlerMockRecorder) AddWatch(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWatch", reflect.TypeOf((*MockHistoryHandler)(nil).AddWatch), w, r)
+}
+
+// DeleteWatch mocks base method.
+func (m *MockHistoryHandler) DeleteWatch(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteWatch", w, r)
+}
+
+// DeleteWatch indicates an expected call of DeleteWatch.
+func (mr *MockHistoryHandlerMockRecorder) DeleteWatch(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWatch", reflect.TypeOf((*MockHistoryHandler)(nil).DeleteWatch), w, r)
+}
+
+// GetHistory mocks base method.
+func (m *MockHistoryHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetHistory", w, r)
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockHistoryHandlerMockRecorder) GetHistory(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockHistoryHandler)(nil).GetHistory), w, r)
+}
+
+// GetStats mocks base method.
+func (m *MockHistoryHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetStats", w, r)
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockHistoryHandlerMockRecorder) GetStats(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockHistoryHandler)(nil).GetStats), w, r)
+}
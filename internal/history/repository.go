@@ -0,0 +1,185 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ HistoryRepository = (*Repository)(nil)
+
+type Repository struct {
+	db      db.DBTX
+	dialect db.Dialect
+}
+
+func NewRepository(conn db.DBTX, d db.Dialect) *Repository {
+	return &Repository{
+		db:      conn,
+		dialect: d,
+	}
+}
+
+// conditions returns the WHERE clause selecting the watches of the query
+// and the values of its placeholders.
+func conditions(q *Query) (string, []any) {
+	where, values := []string{"w.user_id = $1"}, []any{q.UserID}
+	add := func(cond string, value any) {
+		values = append(values, value)
+		where = append(where, fmt.Sprintf(cond, len(values)))
+	}
+
+	if q.FilmID != 0 {
+		add("w.movie_id = $%d", q.FilmID)
+	}
+	if !q.From.IsZero() {
+		add("w.watched_on >= $%d", q.From)
+	}
+	if !q.To.IsZero() {
+		add("w.watched_on < $%d", q.To)
+	}
+
+	return "WHERE " + strings.Join(where, " AND "), values
+}
+
+func (r *Repository) AddWatch(ctx context.Context, w *Watch) (*Watch, error) {
+	const op = "history.Repository.AddWatch"
+
+	const exists = `SELECT movie_name FROM movie WHERE movie_id = $1 AND deleted_at IS NULL`
+	err := r.db.QueryRowContext(ctx, exists, w.FilmID).Scan(&w.FilmName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("ERROR: film with id=%d does not exist\n", w.FilmID)
+			return nil, fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	w.CreatedAt = db.Now()
+	const query = `
+		INSERT INTO watches(user_id, movie_id, watched_on, rating, rewatch, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, "watch_id", w.UserID, w.FilmID, w.WatchedOn,
+		w.Rating, w.Rewatch, w.CreatedAt)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	w.ID = int32(id)
+	w.Available = true
+
+	return w, nil
+}
+
+func (r *Repository) GetWatches(ctx context.Context, q *Query) ([]*Watch, error) {
+	const op = "history.Repository.GetWatches"
+
+	where, values := conditions(q)
+	query := `
+		SELECT w.watch_id, w.user_id, w.movie_id, w.watched_on, w.rating, w.rewatch,
+			w.created_at, m.movie_name, m.deleted_at IS NULL
+		FROM watches w
+		INNER JOIN movie m ON m.movie_id = w.movie_id
+		` + where + `
+		ORDER BY w.watched_on DESC, w.watch_id DESC`
+	if q.Limit != 0 {
+		values = append(values, q.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(values))
+	}
+	rows, err := r.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	watches := make([]*Watch, 0)
+	for rows.Next() {
+		var w Watch
+		var rating sql.NullInt32
+		err := rows.Scan(&w.ID, &w.UserID, &w.FilmID, &w.WatchedOn, &rating, &w.Rewatch,
+			&w.CreatedAt, &w.FilmName, &w.Available)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if rating.Valid {
+			w.Rating = &rating.Int32
+		}
+		watches = append(watches, &w)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return watches, nil
+}
+
+func (r *Repository) DeleteWatch(ctx context.Context, userID int32, id int32) error {
+	const op = "history.Repository.DeleteWatch"
+
+	const query = `DELETE FROM watches WHERE watch_id = $1 AND user_id = $2`
+	res, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: watch with id=%d of user with id=%d does not exist\n", id, userID)
+		return fmt.Errorf("%s: %w", op, ErrWatchNotExist)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetTopActors(ctx context.Context, q *Query, limit int) ([]*ActorCount, error) {
+	const op = "history.Repository.GetTopActors"
+
+	where, values := conditions(q)
+	values = append(values, limit)
+	query := `
+		SELECT a.actor_id, a.actor_name, COUNT(DISTINCT w.movie_id)
+		FROM watches w
+		INNER JOIN actor_in_movie am ON am.movie_id = w.movie_id
+		INNER JOIN actor a ON a.actor_id = am.actor_id AND a.deleted_at IS NULL
+		` + where + `
+		GROUP BY a.actor_id, a.actor_name
+		ORDER BY 3 DESC, a.actor_name, a.actor_id
+		` + fmt.Sprintf(`LIMIT $%d`, len(values))
+	rows, err := r.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	actors := make([]*ActorCount, 0)
+	for rows.Next() {
+		var a ActorCount
+		if err := rows.Scan(&a.ID, &a.Name, &a.Films); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		actors = append(actors, &a)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return actors, nil
+}
@@ -0,0 +1,138 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ HistoryService = (*Service)(nil)
+
+type Service struct {
+	repo HistoryRepository
+}
+
+func NewService(hr HistoryRepository) *Service {
+	return &Service{
+		repo: hr,
+	}
+}
+
+func (s *Service) AddWatch(ctx context.Context, req *AddWatchRequest) (*WatchResponse, error) {
+	const op = "history.Service.AddWatch"
+
+	today := Today(db.Now())
+	vErr := ValidateWatchInfo(&req.Info, today)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	w := ToWatch(req.UserID, &req.Info, today)
+	if req.Info.Rewatch == nil {
+		// a rewatch if the film was watched on the day or before, watches
+		// logged for later days do not count
+		earlier, err := s.repo.GetWatches(ctx, &Query{
+			UserID: w.UserID,
+			FilmID: w.FilmID,
+			To:     w.WatchedOn.AddDate(0, 0, 1),
+			Limit:  1,
+		})
+		if err != nil {
+			log.Printf("ERROR: failed to get watches from repository\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		w.Rewatch = len(earlier) != 0
+	}
+
+	w, err := s.repo.AddWatch(ctx, w)
+	if err != nil {
+		log.Printf("ERROR: failed to add watch to repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToWatchResponse(w), nil
+}
+
+func (s *Service) GetHistory(ctx context.Context, req *HistoryRequest) ([]*WatchResponse, error) {
+	const op = "history.Service.GetHistory"
+
+	vErr := ValidateHistoryRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	// validated by ValidateHistoryRequest
+	q := &Query{UserID: req.UserID, Limit: defaultLimit}
+	if len(req.Limit) != 0 {
+		q.Limit, _ = strconv.Atoi(req.Limit)
+	}
+	if len(req.Year) != 0 {
+		year, _ := strconv.Atoi(req.Year)
+		q.From, q.To = YearRange(year)
+	}
+
+	watches, err := s.repo.GetWatches(ctx, q)
+	if err != nil {
+		log.Printf("ERROR: failed to get watches from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToWatchResponses(watches), nil
+}
+
+func (s *Service) DeleteWatch(ctx context.Context, req *WatchIdRequest) error {
+	const op = "history.Service.DeleteWatch"
+
+	id, err := strconv.ParseUint(req.ID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+
+	// watches of other users do not exist for the user
+	err = s.repo.DeleteWatch(ctx, req.UserID, int32(id))
+	if err != nil {
+		log.Printf("ERROR: failed to delete watch from repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetStats sums up the watches of the year, the current one by default.
+func (s *Service) GetStats(ctx context.Context, req *StatsRequest) (*StatsResponse, error) {
+	const op = "history.Service.GetStats"
+
+	vErr := ValidateStatsRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	// validated by ValidateStatsRequest
+	year := db.Now().Year()
+	if len(req.Year) != 0 {
+		year, _ = strconv.Atoi(req.Year)
+	}
+	q := &Query{UserID: req.UserID}
+	q.From, q.To = YearRange(year)
+
+	watches, err := s.repo.GetWatches(ctx, q)
+	if err != nil {
+		log.Printf("ERROR: failed to get watches from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	actors, err := s.repo.GetTopActors(ctx, q, topActors)
+	if err != nil {
+		log.Printf("ERROR: failed to get top actors from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToStatsResponse(year, watches, actors), nil
+}
@@ -0,0 +1,160 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func testDate(month time.Month, day int) time.Time {
+	return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestService_AddWatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hm := NewMockHistoryRepository(ctrl)
+
+	s := NewService(hm)
+
+	hm.EXPECT().GetWatches(gomock.Any(), &Query{UserID: 7, FilmID: 3, To: testDate(3, 2), Limit: 1}).
+		Return([]*Watch{{ID: 1, UserID: 7, FilmID: 3, WatchedOn: testDate(1, 5)}}, nil).Times(1)
+	hm.EXPECT().AddWatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, w *Watch) (*Watch, error) {
+		if !w.Rewatch || !w.WatchedOn.Equal(testDate(3, 1)) {
+			t.Errorf("Expected rewatch on 2024-03-01, got %+v", w)
+		}
+		w.ID, w.FilmName, w.Available = 2, "Speed", true
+		return w, nil
+	}).Times(1)
+
+	rating := 8
+	res, err := s.AddWatch(context.TODO(), &AddWatchRequest{
+		UserID: 7,
+		Info:   WatchInfo{FilmID: 3, WatchedOn: "2024-03-01", Rating: &rating},
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	expRating := int32(8)
+	exp := &WatchResponse{
+		ID:        2,
+		Film:      FilmShortResponse{ID: 3, Name: "Speed"},
+		WatchedOn: "2024-03-01",
+		Rating:    &expRating,
+		Rewatch:   true,
+		Available: true,
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	// an explicit flag is taken as is
+	rewatch := false
+	hm.EXPECT().AddWatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, w *Watch) (*Watch, error) {
+		if w.Rewatch {
+			t.Errorf("Expected first watch, got %+v", w)
+		}
+		return w, nil
+	}).Times(1)
+	_, err = s.AddWatch(context.TODO(), &AddWatchRequest{
+		UserID: 7,
+		Info:   WatchInfo{FilmID: 3, WatchedOn: "2024-03-01", Rewatch: &rewatch},
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	var ve *util.ValidationError
+	rating = 11
+	_, err = s.AddWatch(context.TODO(), &AddWatchRequest{
+		UserID: 7,
+		Info:   WatchInfo{FilmID: 3, WatchedOn: "2024-03-01", Rating: &rating},
+	})
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestService_GetHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hm := NewMockHistoryRepository(ctrl)
+
+	s := NewService(hm)
+
+	from, to := YearRange(2024)
+	hm.EXPECT().GetWatches(gomock.Any(), &Query{UserID: 7, From: from, To: to, Limit: 5}).Return([]*Watch{}, nil).Times(1)
+	hm.EXPECT().GetWatches(gomock.Any(), &Query{UserID: 7, Limit: defaultLimit}).Return([]*Watch{}, nil).Times(1)
+
+	if _, err := s.GetHistory(context.TODO(), &HistoryRequest{UserID: 7, Year: "2024", Limit: "5"}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if _, err := s.GetHistory(context.TODO(), &HistoryRequest{UserID: 7}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	var ve *util.ValidationError
+	_, err := s.GetHistory(context.TODO(), &HistoryRequest{UserID: 7, Year: "24", Limit: "0"})
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestService_GetStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hm := NewMockHistoryRepository(ctrl)
+
+	s := NewService(hm)
+
+	from, to := YearRange(2024)
+	q := &Query{UserID: 7, From: from, To: to}
+	seven, nine := int32(7), int32(9)
+	hm.EXPECT().GetWatches(gomock.Any(), q).Return([]*Watch{
+		{ID: 3, FilmID: 3, WatchedOn: testDate(3, 1), Rating: &nine, Rewatch: true},
+		{ID: 2, FilmID: 1, WatchedOn: testDate(2, 14)},
+		{ID: 1, FilmID: 3, WatchedOn: testDate(2, 1), Rating: &seven},
+	}, nil).Times(1)
+	hm.EXPECT().GetTopActors(gomock.Any(), q, topActors).Return([]*ActorCount{
+		{ID: 3, Name: "Sandra Bullock", Films: 1},
+	}, nil).Times(1)
+
+	res, err := s.GetStats(context.TODO(), &StatsRequest{UserID: 7, Year: "2024"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	avg := 8.0
+	exp := &StatsResponse{
+		Year:          2024,
+		Watches:       3,
+		Films:         2,
+		Rewatches:     1,
+		AverageRating: &avg,
+		ByMonth:       []int{0, 2, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		TopActors:     []*ActorCountResponse{{ID: 3, Name: "Sandra Bullock", Films: 1}},
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+}
+
+func TestService_DeleteWatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hm := NewMockHistoryRepository(ctrl)
+
+	s := NewService(hm)
+
+	hm.EXPECT().DeleteWatch(gomock.Any(), int32(8), int32(1)).Return(ErrWatchNotExist).Times(1)
+
+	err := s.DeleteWatch(context.TODO(), &WatchIdRequest{UserID: 8, ID: "1"})
+	if !errors.Is(err, ErrWatchNotExist) {
+		t.Errorf("Expected %v, got %v", ErrWatchNotExist, err)
+	}
+
+	err = s.DeleteWatch(context.TODO(), &WatchIdRequest{UserID: 8, ID: "one"})
+	if !errors.Is(err, ErrIdInvalid) {
+		t.Errorf("Expected %v, got %v", ErrIdInvalid, err)
+	}
+}
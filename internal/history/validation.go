@@ -0,0 +1,72 @@
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+// ValidateWatchInfo checks the watch date against today, a day ahead is
+// accepted for users east of UTC.
+func ValidateWatchInfo(wi *WatchInfo, today time.Time) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if wi.FilmID < 1 {
+		ve.AddViolation("filmId empty (expected positive integer)")
+	}
+
+	if len(wi.WatchedOn) != 0 {
+		watchedOn, err := time.Parse(time.DateOnly, wi.WatchedOn)
+		if err != nil {
+			ve.AddViolation("incorrect watchedOn format (expected format: 2006-01-02)")
+		} else if watchedOn.After(today.AddDate(0, 0, 1)) {
+			ve.AddViolation("watchedOn is in the future")
+		}
+	}
+
+	if wi.Rating != nil && (*wi.Rating < 1 || *wi.Rating > 10) {
+		ve.AddViolation("incorrect rating, expected: 1 <= rating <= 10")
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateHistoryRequest(req *HistoryRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	validateYear(ve, req.Year)
+
+	if n, err := strconv.Atoi(req.Limit); len(req.Limit) != 0 && (err != nil || n < 1 || n > maxLimit) {
+		ve.AddViolation(fmt.Sprintf("incorrect limit, expected integer from 1 to %d", maxLimit))
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateStatsRequest(req *StatsRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	validateYear(ve, req.Year)
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func validateYear(ve *util.ValidationError, year string) {
+	if n, err := strconv.Atoi(year); len(year) != 0 && (err != nil || n < 1000 || n > 9999) {
+		ve.AddViolation("incorrect year, expected four-digit year")
+	}
+}
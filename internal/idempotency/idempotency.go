@@ -0,0 +1,74 @@
+// Package idempotency keeps the first response to a request sent with an
+// Idempotency-Key header, so that retries of the request get it replayed
+// instead of repeating the change.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+var (
+	ErrKeyInvalid  = errors.New("invalid idempotency key")
+	ErrKeyMismatch = errors.New("idempotency key was used with another request")
+	// ErrKeyInProgress is returned while the first request with the key
+	// is still being handled.
+	ErrKeyInProgress = errors.New("request with idempotency key is in progress")
+)
+
+// Record is a key reserved by a request, StatusCode is zero until the
+// response is stored. LockedAt is when the request handling it started,
+// a retry takes the key over once the lock gets stale.
+type Record struct {
+	UserID      int32
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	LockedAt    time.Time
+}
+
+func (r *Record) Completed() bool {
+	return r.StatusCode != 0
+}
+
+type IdempotencyRepository interface {
+	// AddRecord reserves the key, it reports false if the user already
+	// has a record with it.
+	AddRecord(ctx context.Context, rec *Record) (bool, error)
+	GetRecord(ctx context.Context, userID int32, key string) (*Record, error)
+	// LockRecord moves the lock of a record in progress to rec.LockedAt if
+	// it was taken before staleBefore, it reports false if another request
+	// holds the lock or the record is completed.
+	LockRecord(ctx context.Context, rec *Record, staleBefore time.Time) (bool, error)
+	CompleteRecord(ctx context.Context, rec *Record) error
+	DeleteRecord(ctx context.Context, userID int32, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type IdempotencyService interface {
+	// Begin reserves the key for the request and returns nil, or returns
+	// the stored record whose response is to be replayed.
+	Begin(ctx context.Context, req *BeginRequest) (*Record, error)
+	Complete(ctx context.Context, rec *Record) error
+	// Release forgets the key, so that the request can be retried.
+	Release(ctx context.Context, userID int32, key string) error
+}
+
+type BeginRequest struct {
+	UserID      int32
+	Key         string
+	RequestHash string
+}
@@ -0,0 +1,158 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var ErrRecordNotExist = errors.New("idempotency record does not exist")
+
+var _ IdempotencyRepository = (*Repository)(nil)
+
+type Repository struct {
+	db      db.DBTX
+	dialect db.Dialect
+}
+
+func NewRepository(db db.DBTX, d db.Dialect) *Repository {
+	return &Repository{
+		db:      db,
+		dialect: d,
+	}
+}
+
+func (r *Repository) AddRecord(ctx context.Context, rec *Record) (bool, error) {
+	const op = "idempotency.Repository.AddRecord"
+
+	const query = `INSERT INTO idempotency_keys(user_id, idempotency_key, request_hash, created_at, expires_at, locked_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.ExecContext(ctx, query, rec.UserID, rec.Key, rec.RequestHash,
+		rec.CreatedAt.UTC(), rec.ExpiresAt.UTC(), rec.LockedAt.UTC())
+	if r.dialect.IsUniqueViolation(err) {
+		return false, nil
+	}
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return true, nil
+}
+
+func (r *Repository) GetRecord(ctx context.Context, userID int32, key string) (*Record, error) {
+	const op = "idempotency.Repository.GetRecord"
+
+	const query = `SELECT user_id, idempotency_key, request_hash, status_code, response_header, response_body, created_at, expires_at, locked_at
+		FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2`
+
+	var rec Record
+	var header sql.NullString
+	err := r.db.QueryRowContext(ctx, query, userID, key).Scan(&rec.UserID, &rec.Key, &rec.RequestHash,
+		&rec.StatusCode, &header, &rec.Body, &rec.CreatedAt, &rec.ExpiresAt, &rec.LockedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, ErrRecordNotExist)
+	}
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if header.Valid {
+		if err := json.Unmarshal([]byte(header.String), &rec.Header); err != nil {
+			log.Printf("ERROR: failed to decode stored header\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return &rec, nil
+}
+
+func (r *Repository) LockRecord(ctx context.Context, rec *Record, staleBefore time.Time) (bool, error) {
+	const op = "idempotency.Repository.LockRecord"
+
+	// only one of the retries racing for a stale lock gets a row updated
+	const query = `UPDATE idempotency_keys SET locked_at = $1
+		WHERE user_id = $2 AND idempotency_key = $3 AND status_code = 0 AND locked_at < $4`
+	res, err := r.db.ExecContext(ctx, query, rec.LockedAt.UTC(), rec.UserID, rec.Key, staleBefore.UTC())
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count != 0, nil
+}
+
+func (r *Repository) CompleteRecord(ctx context.Context, rec *Record) error {
+	const op = "idempotency.Repository.CompleteRecord"
+
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		log.Printf("ERROR: failed to encode header\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	const query = `UPDATE idempotency_keys SET status_code = $1, response_header = $2, response_body = $3
+		WHERE user_id = $4 AND idempotency_key = $5`
+	res, err := r.db.ExecContext(ctx, query, rec.StatusCode, string(header), rec.Body, rec.UserID, rec.Key)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%s: %w", op, ErrRecordNotExist)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteRecord(ctx context.Context, userID int32, key string) error {
+	const op = "idempotency.Repository.DeleteRecord"
+
+	const query = `DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2`
+	_, err := r.db.ExecContext(ctx, query, userID, key)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes records expired by now, in progress or not.
+func (r *Repository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	const op = "idempotency.Repository.DeleteExpired"
+
+	const query = `DELETE FROM idempotency_keys WHERE expires_at <= $1`
+	res, err := r.db.ExecContext(ctx, query, now.UTC())
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,170 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ IdempotencyService = (*Service)(nil)
+
+type Service struct {
+	repo        IdempotencyRepository
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+// NewService returns a service keeping responses for ttl, a request in
+// progress for longer than lockTimeout is taken to have died and its key
+// is handed over to a retry.
+func NewService(ir IdempotencyRepository, ttl time.Duration, lockTimeout time.Duration) *Service {
+	return &Service{
+		repo:        ir,
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// Fingerprint identifies the request a key was first used with.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", method, path)
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *Service) Begin(ctx context.Context, req *BeginRequest) (*Record, error) {
+	const op = "idempotency.Service.Begin"
+
+	vErr := ValidateKey(req.Key)
+	if vErr != nil {
+		log.Printf("ERROR: failed key validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	now := db.Now()
+	rec := &Record{
+		UserID:      req.UserID,
+		Key:         req.Key,
+		RequestHash: req.RequestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+		LockedAt:    now,
+	}
+
+	// a second attempt is needed if the record found expired or was
+	// released meanwhile
+	for i := 0; i < 2; i++ {
+		added, err := s.repo.AddRecord(ctx, rec)
+		if err != nil {
+			log.Printf("ERROR: failed to add idempotency record to repository\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if added {
+			return nil, nil
+		}
+
+		cur, err := s.repo.GetRecord(ctx, req.UserID, req.Key)
+		if errors.Is(err, ErrRecordNotExist) {
+			continue
+		}
+		if err != nil {
+			log.Printf("ERROR: failed to get idempotency record from repository\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if !cur.ExpiresAt.After(now) {
+			if err := s.repo.DeleteRecord(ctx, req.UserID, req.Key); err != nil {
+				log.Printf("ERROR: failed to delete expired idempotency record in repository\n")
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			continue
+		}
+
+		if cur.RequestHash != req.RequestHash {
+			log.Printf("ERROR: idempotency key %q reused with another request\n", req.Key)
+			return nil, fmt.Errorf("%s: %w", op, ErrKeyMismatch)
+		}
+		if !cur.Completed() {
+			locked, err := s.lock(ctx, rec, now)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			if locked {
+				log.Printf("INFO: taking over stale idempotency key %q\n", req.Key)
+				return nil, nil
+			}
+
+			log.Printf("ERROR: request with idempotency key %q is in progress\n", req.Key)
+			return nil, fmt.Errorf("%s: %w", op, ErrKeyInProgress)
+		}
+
+		return cur, nil
+	}
+
+	return nil, fmt.Errorf("%s: %w", op, ErrKeyInProgress)
+}
+
+// lock takes the key over from a request that has been in progress for
+// longer than the lock timeout.
+func (s *Service) lock(ctx context.Context, rec *Record, now time.Time) (bool, error) {
+	locked, err := s.repo.LockRecord(ctx, rec, now.Add(-s.lockTimeout))
+	if err != nil {
+		log.Printf("ERROR: failed to lock idempotency record in repository\n")
+		return false, err
+	}
+
+	return locked, nil
+}
+
+func (s *Service) Complete(ctx context.Context, rec *Record) error {
+	const op = "idempotency.Service.Complete"
+
+	err := s.repo.CompleteRecord(ctx, rec)
+	if err != nil {
+		log.Printf("ERROR: failed to complete idempotency record in repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Service) Release(ctx context.Context, userID int32, key string) error {
+	const op = "idempotency.Service.Release"
+
+	err := s.repo.DeleteRecord(ctx, userID, key)
+	if err != nil {
+		log.Printf("ERROR: failed to delete idempotency record in repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RunPurge removes expired records every interval until ctx is done.
+func (s *Service) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.repo.DeleteExpired(ctx, db.Now())
+		if err != nil {
+			log.Printf("ERROR: failed to purge idempotency keys err=%s\n", err.Error())
+		} else if n != 0 {
+			log.Printf("INFO: purged %d expired idempotency keys\n", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
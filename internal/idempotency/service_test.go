@@ -0,0 +1,147 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint(http.MethodPost, "/films", []byte(`{"name": "film"}`))
+	if len(a) != 64 || a != Fingerprint(http.MethodPost, "/films", []byte(`{"name": "film"}`)) {
+		t.Errorf("Expected a stable sha256 hex digest, got %q", a)
+	}
+	if a == Fingerprint(http.MethodPost, "/actors", []byte(`{"name": "film"}`)) {
+		t.Errorf("Expected path to change the fingerprint")
+	}
+	if a == Fingerprint(http.MethodPost, "/films", []byte(`{"name": "film2"}`)) {
+		t.Errorf("Expected body to change the fingerprint")
+	}
+}
+
+func TestService_Begin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockIdempotencyRepository(ctrl)
+
+	s := NewService(m, time.Hour, time.Minute)
+	req := &BeginRequest{UserID: 1, Key: "key", RequestHash: "hash"}
+
+	// new key is reserved for the ttl
+	m.EXPECT().
+		AddRecord(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, rec *Record) (bool, error) {
+			if rec.UserID != 1 || rec.Key != "key" || rec.RequestHash != "hash" || rec.ExpiresAt.Sub(rec.CreatedAt) != time.Hour {
+				t.Errorf("Unexpected record %+v", rec)
+			}
+			return true, nil
+		}).Times(1)
+
+	rec, err := s.Begin(context.TODO(), req)
+	if err != nil || rec != nil {
+		t.Errorf("Expected key reserved, got %+v, %v", rec, err)
+	}
+
+	// completed record is replayed
+	stored := &Record{
+		UserID:      1,
+		Key:         "key",
+		RequestHash: "hash",
+		StatusCode:  http.StatusOK,
+		Header:      http.Header{"Content-Type": {"application/json"}},
+		Body:        []byte(`{"id": 1}`),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	m.EXPECT().AddRecord(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+	m.EXPECT().GetRecord(gomock.Any(), int32(1), "key").Return(stored, nil).Times(1)
+
+	rec, err = s.Begin(context.TODO(), req)
+	if err != nil || !reflect.DeepEqual(rec, stored) {
+		t.Errorf("Expected %+v, got %+v, %v", stored, rec, err)
+	}
+
+	// other request with the same key
+	m.EXPECT().AddRecord(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+	m.EXPECT().GetRecord(gomock.Any(), int32(1), "key").Return(stored, nil).Times(1)
+
+	_, err = s.Begin(context.TODO(), &BeginRequest{UserID: 1, Key: "key", RequestHash: "other"})
+	if !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("Expected %v, got %v", ErrKeyMismatch, err)
+	}
+
+	// first request not finished yet
+	inProgress := *stored
+	inProgress.StatusCode = 0
+	inProgress.LockedAt = time.Now()
+	m.EXPECT().AddRecord(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+	m.EXPECT().GetRecord(gomock.Any(), int32(1), "key").Return(&inProgress, nil).Times(1)
+	m.EXPECT().LockRecord(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+
+	_, err = s.Begin(context.TODO(), req)
+	if !errors.Is(err, ErrKeyInProgress) {
+		t.Errorf("Expected %v, got %v", ErrKeyInProgress, err)
+	}
+
+	// first request died, its stale lock is taken over
+	m.EXPECT().AddRecord(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+	m.EXPECT().GetRecord(gomock.Any(), int32(1), "key").Return(&inProgress, nil).Times(1)
+	m.EXPECT().
+		LockRecord(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, rec *Record, staleBefore time.Time) (bool, error) {
+			if rec.LockedAt.Sub(staleBefore) != time.Minute {
+				t.Errorf("Expected locks older than a minute stale, got %s", rec.LockedAt.Sub(staleBefore))
+			}
+			return true, nil
+		}).Times(1)
+
+	rec, err = s.Begin(context.TODO(), req)
+	if err != nil || rec != nil {
+		t.Errorf("Expected key taken over, got %+v, %v", rec, err)
+	}
+
+	// expired record is replaced
+	expired := *stored
+	expired.RequestHash = "other"
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	gomock.InOrder(
+		m.EXPECT().AddRecord(gomock.Any(), gomock.Any()).Return(false, nil),
+		m.EXPECT().GetRecord(gomock.Any(), int32(1), "key").Return(&expired, nil),
+		m.EXPECT().DeleteRecord(gomock.Any(), int32(1), "key").Return(nil),
+		m.EXPECT().AddRecord(gomock.Any(), gomock.Any()).Return(true, nil),
+	)
+
+	rec, err = s.Begin(context.TODO(), req)
+	if err != nil || rec != nil {
+		t.Errorf("Expected key reserved, got %+v, %v", rec, err)
+	}
+
+	// repository failure
+	m.EXPECT().AddRecord(gomock.Any(), gomock.Any()).Return(false, fmt.Errorf("unexpected error")).Times(1)
+
+	_, err = s.Begin(context.TODO(), req)
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestService_Begin_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockIdempotencyRepository(ctrl)
+
+	s := NewService(m, time.Hour, time.Minute)
+
+	for _, v := range []string{"", strings.Repeat("k", 256), "key\n"} {
+		_, err := s.Begin(context.TODO(), &BeginRequest{UserID: 1, Key: v, RequestHash: "hash"})
+		var ve *util.ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("Key %q: expected validation error, got %v", v, err)
+		}
+	}
+}
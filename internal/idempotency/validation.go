@@ -0,0 +1,28 @@
+package idempotency
+
+import (
+	"fmt"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+// ValidateKey accepts up to 255 printable ASCII characters.
+func ValidateKey(key string) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(key) == 0 || len(key) > maxKeyLength {
+		ve.AddViolation(fmt.Sprintf("incorrect %s length, expected 1 to %d characters", HeaderKey, maxKeyLength))
+	}
+	for _, c := range key {
+		if c < 0x20 || c > 0x7e {
+			ve.AddViolation(fmt.Sprintf("incorrect %s, expected printable ASCII characters", HeaderKey))
+			break
+		}
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
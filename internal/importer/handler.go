@@ -0,0 +1,72 @@
+package importer
+
+import (
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+// maxBodySize limits the import body, larger catalogs are split.
+const maxBodySize = 32 << 20
+
+var formats = map[string]string{
+	"text/csv":             FormatCSV,
+	"application/x-ndjson": FormatNDJSON,
+	"application/ndjson":   FormatNDJSON,
+}
+
+var _ ImportHandler = (*Handler)(nil)
+
+type Handler struct {
+	service ImportService
+}
+
+func NewHandler(is ImportService) *Handler {
+	return &Handler{
+		service: is,
+	}
+}
+
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("content-type"))
+	format, ok := formats[mt]
+	if !ok {
+		log.Printf("ERROR: unsupported media type %q\n", r.Header.Get("content-type"))
+		w.Header().Set("accept-post", "text/csv, application/x-ndjson")
+		util.UnsupportedMediaType(w, r)
+		return
+	}
+
+	res, err := h.service.Import(r.Context(), &ImportRequest{
+		Format: format,
+		Body:   http.MaxBytesReader(w, r.Body, maxBodySize),
+		Mode:   r.URL.Query().Get("mode"),
+		DryRun: r.URL.Query().Get("dryRun"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to import err=%s\n", err.Error())
+
+		var ve *util.ValidationError
+		if errors.As(err, &ve) {
+			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeValidation,
+				Body:      ve.Error(),
+			})
+			return
+		}
+
+		var mbErr *http.MaxBytesError
+		if errors.As(err, &mbErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,90 @@
+// Package importer loads films with their cast from CSV or NDJSON.
+// Actors are matched by name and birthday and created when missing,
+// films are matched by name and release date and updated in place.
+package importer
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	// ModeTransaction imports every row or none of them.
+	ModeTransaction = "transaction"
+	// ModeRow commits each row on its own, errored rows are left out.
+	ModeRow = "row"
+)
+
+const (
+	StatusCreated = "created"
+	StatusUpdated = "updated"
+	StatusSkipped = "skipped"
+	StatusErrored = "errored"
+)
+
+// Transactor runs fn on repositories and an audit recorder bound to a
+// single transaction. The transaction is committed if fn returns nil and
+// rolled back otherwise.
+type Transactor interface {
+	Atomic(ctx context.Context, fn func(fr film.FilmRepository, ar actor.ActorRepository, rec audit.Recorder) error) error
+}
+
+type ImportService interface {
+	Import(ctx context.Context, req *ImportRequest) (*Report, error)
+}
+
+type ImportHandler interface {
+	Import(w http.ResponseWriter, r *http.Request)
+}
+
+type ImportRequest struct {
+	Format string
+	Body   io.Reader
+	Mode   string
+	DryRun string
+}
+
+// Row is a film with its cast. An actor given by name only must already
+// exist, sex and birthday are needed to create one.
+type Row struct {
+	Info   film.FilmInfo     `json:"info"`
+	Actors []actor.ActorInfo `json:"actors"`
+}
+
+// Record is a parsed row, Err is set if the row could not be parsed.
+type Record struct {
+	Line int
+	Row  *Row
+	Err  error
+}
+
+// Report lists the outcome of every row in input order. Film ids of
+// created films are only reported once the films are committed.
+type Report struct {
+	Mode          string       `json:"mode"`
+	DryRun        bool         `json:"dryRun"`
+	Committed     bool         `json:"committed"`
+	Created       int          `json:"created"`
+	Updated       int          `json:"updated"`
+	Skipped       int          `json:"skipped"`
+	Errored       int          `json:"errored"`
+	ActorsCreated int          `json:"actorsCreated"`
+	Rows          []*RowResult `json:"rows"`
+}
+
+type RowResult struct {
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	FilmID int32  `json:"filmId,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"context"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+type key struct {
+	name string
+	date string
+}
+
+func toKey(name string, date time.Time) key {
+	return key{name: name, date: date.Format(time.DateOnly)}
+}
+
+// index finds existing actors and films without a query per row. Changes
+// made in a transaction are journaled, so they can be reverted on rollback.
+type index struct {
+	actors map[key]int32
+	names  map[string][]int32
+	films  map[key][]*film.Film
+
+	journal []func()
+}
+
+func newIndex() *index {
+	return &index{
+		actors: make(map[key]int32),
+		names:  make(map[string][]int32),
+		films:  make(map[key][]*film.Film),
+	}
+}
+
+func (idx *index) load(ctx context.Context, fr film.FilmRepository, ar actor.ActorRepository) error {
+	actors, err := ar.GetActors(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range actors {
+		idx.addActor(v)
+	}
+
+	films, err := fr.GetFilms(ctx, &film.Query{})
+	if err != nil {
+		return err
+	}
+	for _, v := range films {
+		k := toKey(v.Name, v.ReleaseDate)
+		idx.films[k] = append(idx.films[k], v)
+	}
+	idx.commit()
+
+	return nil
+}
+
+func (idx *index) addActor(a *actor.Actor) {
+	k := toKey(a.Name, a.Birthday)
+	idx.actors[k] = a.ID
+	idx.names[a.Name] = append(idx.names[a.Name], a.ID)
+
+	idx.journal = append(idx.journal, func() {
+		delete(idx.actors, k)
+		ids := idx.names[a.Name]
+		idx.names[a.Name] = ids[:len(ids)-1]
+	})
+}
+
+// putFilm adds the film or replaces the one with the same id.
+func (idx *index) putFilm(f *film.Film) {
+	k := toKey(f.Name, f.ReleaseDate)
+	prev := idx.films[k]
+
+	films := make([]*film.Film, 0, len(prev)+1)
+	for _, v := range prev {
+		if v.ID != f.ID {
+			films = append(films, v)
+		}
+	}
+	idx.films[k] = append(films, f)
+
+	idx.journal = append(idx.journal, func() {
+		idx.films[k] = prev
+	})
+}
+
+func (idx *index) commit() {
+	idx.journal = nil
+}
+
+func (idx *index) rollback() {
+	for i := len(idx.journal) - 1; i >= 0; i-- {
+		idx.journal[i]()
+	}
+	idx.journal = nil
+}
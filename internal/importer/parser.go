@@ -0,0 +1,254 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+const maxLineSize = 1 << 20
+
+var csvColumns = map[string]bool{
+	"name":        true,
+	"description": true,
+	"releasedate": true,
+	"rating":      true,
+	"actors":      true,
+}
+
+// Parse reads every row of the input. Rows that cannot be parsed are
+// returned with Err set, a malformed input as a whole gives a
+// *util.ValidationError.
+func Parse(format string, r io.Reader) ([]*Record, error) {
+	var records []*Record
+	var err error
+
+	switch format {
+	case FormatCSV:
+		records, err = parseCSV(r)
+	case FormatNDJSON:
+		records, err = parseNDJSON(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		ve := &util.ValidationError{}
+		ve.AddViolation("no rows to import")
+		return nil, ve
+	}
+
+	return records, nil
+}
+
+// parseCSV expects a header naming the columns in any order. Only actors
+// may be left out, so that an update never clears a column by accident.
+func parseCSV(r io.Reader) ([]*Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	columns, vErr := parseHeader(header)
+	if vErr != nil {
+		return nil, vErr
+	}
+
+	var records []*Record
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		if len(fields) != len(columns) {
+			records = append(records, &Record{
+				Line: line,
+				Err:  fmt.Errorf("expected %d fields, got %d", len(columns), len(fields)),
+			})
+			continue
+		}
+
+		row, err := parseCSVRow(columns, fields)
+		records = append(records, &Record{Line: line, Row: row, Err: err})
+	}
+
+	return records, nil
+}
+
+// csvError reports a syntax error as a violation, other errors come
+// from reading the body.
+func csvError(err error) error {
+	var pErr *csv.ParseError
+	if !errors.As(err, &pErr) {
+		return err
+	}
+
+	ve := &util.ValidationError{}
+	ve.AddViolation(fmt.Sprintf("line %d: %s", pErr.Line, pErr.Err.Error()))
+
+	return ve
+}
+
+func parseHeader(header []string) ([]string, *util.ValidationError) {
+	ve := &util.ValidationError{}
+
+	columns := make([]string, 0, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, v := range header {
+		if i == 0 {
+			v = strings.TrimPrefix(v, "\ufeff")
+		}
+		v = strings.ToLower(strings.TrimSpace(v))
+
+		if !csvColumns[v] {
+			ve.AddViolation(fmt.Sprintf("unknown column %q, expected: name, description, releasedate, rating, actors", v))
+		}
+		if seen[v] {
+			ve.AddViolation(fmt.Sprintf("duplicate column %q", v))
+		}
+		seen[v] = true
+		columns = append(columns, v)
+	}
+
+	for _, v := range []string{"name", "description", "releasedate", "rating"} {
+		if !seen[v] {
+			ve.AddViolation(fmt.Sprintf("column %q missing", v))
+		}
+	}
+
+	if ve.NoViolations() {
+		return columns, nil
+	}
+
+	return nil, ve
+}
+
+func parseCSVRow(columns, fields []string) (*Row, error) {
+	var row Row
+
+	for i, v := range fields {
+		v = strings.TrimSpace(v)
+
+		switch columns[i] {
+		case "name":
+			row.Info.Name = v
+		case "description":
+			row.Info.Description = v
+		case "releasedate":
+			row.Info.ReleaseDate = v
+		case "rating":
+			if len(v) == 0 {
+				continue
+			}
+			rating, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("incorrect rating %q, expected integer", v)
+			}
+			row.Info.Rating = rating
+		case "actors":
+			actors, err := parseActors(v)
+			if err != nil {
+				return nil, err
+			}
+			row.Actors = actors
+		}
+	}
+
+	return &row, nil
+}
+
+// parseActors reads actors separated by ";", each one given as name,
+// name|birthday or name|sex|birthday.
+func parseActors(s string) ([]actor.ActorInfo, error) {
+	var actors []actor.ActorInfo
+
+	for _, v := range strings.Split(s, ";") {
+		v = strings.TrimSpace(v)
+		if len(v) == 0 {
+			continue
+		}
+
+		parts := strings.Split(v, "|")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+
+		var ai actor.ActorInfo
+		switch len(parts) {
+		case 1:
+			ai.Name = parts[0]
+		case 2:
+			ai.Name, ai.Birthday = parts[0], parts[1]
+		case 3:
+			ai.Name, ai.Sex, ai.Birthday = parts[0], parts[1], parts[2]
+		default:
+			return nil, fmt.Errorf("incorrect actor %q, expected name, name|birthday or name|sex|birthday", v)
+		}
+		actors = append(actors, ai)
+	}
+
+	return actors, nil
+}
+
+// parseNDJSON expects a Row object per line, blank lines are skipped.
+func parseNDJSON(r io.Reader) ([]*Record, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var records []*Record
+	line := 0
+	for sc.Scan() {
+		line++
+		data := bytes.TrimSpace(sc.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+
+		var row Row
+		if err := dec.Decode(&row); err != nil {
+			records = append(records, &Record{
+				Line: line,
+				Err:  fmt.Errorf("invalid json: %s", strings.TrimPrefix(err.Error(), "json: ")),
+			})
+			continue
+		}
+		records = append(records, &Record{Line: line, Row: &row})
+	}
+
+	if errors.Is(sc.Err(), bufio.ErrTooLong) {
+		ve := &util.ValidationError{}
+		ve.AddViolation(fmt.Sprintf("line %d: longer than %d bytes", line+1, maxLineSize))
+		return nil, ve
+	}
+	if sc.Err() != nil {
+		return nil, sc.Err()
+	}
+
+	return records, nil
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+func TestParse_CSV(t *testing.T) {
+	input := "\ufeffName,releasedate,Rating,description,actors\n" +
+		"film1,2000-01-12,5,\"about film1, a film\",actor1; actor2|1980-02-03 ;actor3|female|1990-01-01\n" +
+		"film2,2001-01-12,high,,\n" +
+		"film3,2002-01-12\n" +
+		"film4,2003-01-12,7,,a|b|c|d\n"
+
+	records, err := Parse(FormatCSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 records, got %d", len(records))
+	}
+
+	exp := &Row{
+		Info: film.FilmInfo{Name: "film1", Description: "about film1, a film", ReleaseDate: "2000-01-12", Rating: 5},
+		Actors: []actor.ActorInfo{
+			{Name: "actor1"},
+			{Name: "actor2", Birthday: "1980-02-03"},
+			{Name: "actor3", Sex: "female", Birthday: "1990-01-01"},
+		},
+	}
+	if records[0].Err != nil || records[0].Line != 2 || !reflect.DeepEqual(exp, records[0].Row) {
+		t.Errorf("Expected %+v on line 2, got %+v", exp, records[0])
+	}
+
+	for i, line := range []int{3, 4, 5} {
+		if rec := records[i+1]; rec.Err == nil || rec.Line != line {
+			t.Errorf("Expected error on line %d, got %+v", line, rec)
+		}
+	}
+}
+
+func TestParse_CSVHeader(t *testing.T) {
+	tests := []string{
+		"name,releasedate,rating\n",
+		"name,description,releasedate,rating,director\n",
+		"name,description,releasedate,rating,name\n",
+		"name,description,releasedate,rating\n\"film1,2000-01-12\n",
+		"",
+	}
+
+	for _, v := range tests {
+		_, err := Parse(FormatCSV, strings.NewReader(v))
+		var ve *util.ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("Expected validation error for %q, got %v", v, err)
+		}
+	}
+}
+
+func TestParse_NDJSON(t *testing.T) {
+	input := `{"info": {"name": "film1", "releasedate": "2000-01-12", "rating": 5}, "actors": [{"name": "actor1"}]}
+
+{"info": {"name": "film2"}, "director": "someone"}
+{"info": 
+`
+
+	records, err := Parse(FormatNDJSON, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	exp := &Row{
+		Info:   film.FilmInfo{Name: "film1", ReleaseDate: "2000-01-12", Rating: 5},
+		Actors: []actor.ActorInfo{{Name: "actor1"}},
+	}
+	if records[0].Err != nil || records[0].Line != 1 || !reflect.DeepEqual(exp, records[0].Row) {
+		t.Errorf("Expected %+v on line 1, got %+v", exp, records[0])
+	}
+
+	for i, line := range []int{3, 4} {
+		if rec := records[i+1]; rec.Err == nil || rec.Line != line {
+			t.Errorf("Expected error on line %d, got %+v", line, rec)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+var _ Transactor = (*DatabaseTransactor)(nil)
+
+type DatabaseTransactor struct {
+	db      *sql.DB
+	dialect db.Dialect
+}
+
+func NewDatabaseTransactor(db *sql.DB, d db.Dialect) *DatabaseTransactor {
+	return &DatabaseTransactor{
+		db:      db,
+		dialect: d,
+	}
+}
+
+func (t *DatabaseTransactor) Atomic(ctx context.Context, fn func(fr film.FilmRepository, ar actor.ActorRepository, rec audit.Recorder) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: failed to begin transaction\n")
+		return err
+	}
+
+	rec := audit.NewService(audit.NewRepository(tx, t.dialect))
+	if err := fn(film.NewRepository(tx, t.dialect), actor.NewRepository(tx, t.dialect), rec); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: failed to commit transaction\n")
+		return err
+	}
+
+	return nil
+}
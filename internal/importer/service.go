@@ -0,0 +1,344 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+// errRollback discards a batch that is not to be kept.
+var errRollback = errors.New("rollback")
+
+var _ ImportService = (*Service)(nil)
+
+type Service struct {
+	tx Transactor
+}
+
+func NewService(t Transactor) *Service {
+	return &Service{
+		tx: t,
+	}
+}
+
+// change is an audit entry, recorded in the transaction of its batch
+// once the batch is to be kept.
+type change struct {
+	entity string
+	id     int32
+	action string
+	before any
+	after  any
+}
+
+// batch is a group of rows imported in a single transaction.
+type batch struct {
+	idx     *index
+	films   film.FilmRepository
+	actors  actor.ActorRepository
+	changes []change
+
+	actorsCreated int
+}
+
+func (s *Service) Import(ctx context.Context, req *ImportRequest) (*Report, error) {
+	const op = "importer.Service.Import"
+
+	vErr := ValidateImportRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	records, err := Parse(req.Format, req.Body)
+	if err != nil {
+		log.Printf("ERROR: failed to parse %s input\n", req.Format)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rep := &Report{
+		Mode: req.Mode,
+		Rows: make([]*RowResult, 0, len(records)),
+	}
+	if len(rep.Mode) == 0 {
+		rep.Mode = ModeTransaction
+	}
+	// validated by ValidateImportRequest, empty value gives false
+	rep.DryRun, _ = strconv.ParseBool(req.DryRun)
+	for _, v := range records {
+		rep.Rows = append(rep.Rows, &RowResult{Line: v.Line})
+	}
+
+	idx := newIndex()
+	err = s.tx.Atomic(ctx, func(fr film.FilmRepository, ar actor.ActorRepository, _ audit.Recorder) error {
+		return idx.load(ctx, fr, ar)
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to load actors and films from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if rep.Mode == ModeRow && !rep.DryRun {
+		for i, v := range records {
+			committed, err := s.importBatch(ctx, idx, records[i:i+1], rep.Rows[i:i+1], rep, func(int) bool { return true })
+			if err != nil {
+				log.Printf("ERROR: failed to import row on line %d err=%s\n", v.Line, err.Error())
+				rep.Rows[i].Status, rep.Rows[i].FilmID, rep.Rows[i].Error = StatusErrored, 0, "internal error"
+			}
+			rep.Committed = rep.Committed || committed
+		}
+	} else {
+		keep := func(errored int) bool {
+			return !rep.DryRun && errored == 0
+		}
+		rep.Committed, err = s.importBatch(ctx, idx, records, rep.Rows, rep, keep)
+		if err != nil {
+			log.Printf("ERROR: failed to import rows\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	for _, v := range rep.Rows {
+		switch v.Status {
+		case StatusCreated:
+			rep.Created++
+		case StatusUpdated:
+			rep.Updated++
+		case StatusSkipped:
+			rep.Skipped++
+		case StatusErrored:
+			rep.Errored++
+		}
+	}
+
+	return rep, nil
+}
+
+// importBatch imports the records in one transaction, which is committed
+// if keep agrees given the number of errored rows. It reports whether
+// any change was committed.
+func (s *Service) importBatch(ctx context.Context, idx *index, records []*Record, results []*RowResult, rep *Report, keep func(errored int) bool) (bool, error) {
+	b := &batch{idx: idx}
+	err := s.tx.Atomic(ctx, func(fr film.FilmRepository, ar actor.ActorRepository, rec audit.Recorder) error {
+		b.films, b.actors = fr, ar
+
+		errored := 0
+		for i, v := range records {
+			if err := b.importRecord(ctx, v, results[i]); err != nil {
+				return err
+			}
+			if results[i].Status == StatusErrored {
+				errored++
+			}
+		}
+
+		if !keep(errored) {
+			return errRollback
+		}
+
+		for _, v := range b.changes {
+			if err := rec.Record(ctx, v.entity, v.id, v.action, v.before, v.after); err != nil {
+				log.Printf("ERROR: failed to record imported %s %s\n", v.entity, v.action)
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		idx.rollback()
+		if !errors.Is(err, errRollback) {
+			return false, err
+		}
+
+		// the rows still tell what the import would do
+		rep.ActorsCreated += b.actorsCreated
+		for _, v := range results {
+			if v.Status == StatusCreated {
+				v.FilmID = 0
+			}
+		}
+		return false, nil
+	}
+	idx.commit()
+	rep.ActorsCreated += b.actorsCreated
+
+	return len(b.changes) != 0, nil
+}
+
+// importRecord sets the outcome of the row, an error is returned only
+// if the repositories fail.
+func (b *batch) importRecord(ctx context.Context, rec *Record, res *RowResult) error {
+	fail := func(err error) error {
+		res.Status, res.Error = StatusErrored, err.Error()
+		return nil
+	}
+
+	if rec.Err != nil {
+		return fail(rec.Err)
+	}
+	if vErr := ValidateRow(rec.Row); vErr != nil {
+		return fail(vErr)
+	}
+
+	f := film.ToFilm(&rec.Row.Info)
+	films := b.idx.films[toKey(f.Name, f.ReleaseDate)]
+	if len(films) > 1 {
+		return fail(fmt.Errorf("film %q released %s is ambiguous", f.Name, rec.Row.Info.ReleaseDate))
+	}
+
+	ids, missing, err := b.resolve(rec.Row.Actors)
+	if err != nil {
+		return fail(err)
+	}
+	if len(films) == 0 && len(ids)+len(missing) == 0 {
+		return fail(errors.New("film with zero actors"))
+	}
+
+	for _, v := range missing {
+		a, err := b.actors.AddActor(ctx, actor.ToActor(&v))
+		if err != nil {
+			return err
+		}
+		b.idx.addActor(a)
+		b.changes = append(b.changes, change{audit.EntityActor, a.ID, audit.ActionCreate, nil, actor.ToActorState(a)})
+		b.actorsCreated++
+		ids = append(ids, a.ID)
+	}
+
+	if len(films) == 0 {
+		return b.createFilm(ctx, f, ids, res)
+	}
+
+	return b.updateFilm(ctx, films[0], f, ids, res)
+}
+
+// resolve returns ids of existing actors and the actors to be created.
+func (b *batch) resolve(actors []actor.ActorInfo) ([]int32, []actor.ActorInfo, error) {
+	var ids []int32
+	var missing []actor.ActorInfo
+	seen := make(map[int32]bool, len(actors))
+	planned := make(map[key]bool)
+
+	for _, v := range actors {
+		var id int32
+		if len(v.Birthday) == 0 {
+			matches := b.idx.names[v.Name]
+			switch len(matches) {
+			case 0:
+				return nil, nil, fmt.Errorf("actor %q not found, sex and birthday are needed to create it", v.Name)
+			case 1:
+				id = matches[0]
+			default:
+				return nil, nil, fmt.Errorf("actor %q is ambiguous, birthday is needed to tell", v.Name)
+			}
+		} else {
+			birthday, _ := time.Parse(time.DateOnly, v.Birthday)
+			k := toKey(v.Name, birthday)
+
+			var ok bool
+			if id, ok = b.idx.actors[k]; !ok {
+				if len(v.Sex) == 0 {
+					return nil, nil, fmt.Errorf("actor %q born %s not found, sex is needed to create it", v.Name, v.Birthday)
+				}
+				if !planned[k] {
+					planned[k] = true
+					missing = append(missing, v)
+				}
+				continue
+			}
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, missing, nil
+}
+
+func (b *batch) createFilm(ctx context.Context, f *film.Film, ids []int32, res *RowResult) error {
+	f, err := b.films.AddFilm(ctx, f)
+	if err != nil {
+		return err
+	}
+
+	if err := b.films.AddFilmActors(ctx, &film.FilmActors{ID: f.ID, ActorIDs: ids}); err != nil {
+		return err
+	}
+
+	f, err = b.films.GetFilm(ctx, f.ID)
+	if err != nil {
+		return err
+	}
+	b.idx.putFilm(f)
+	b.changes = append(b.changes, change{audit.EntityFilm, f.ID, audit.ActionCreate, nil, film.ToFilmState(f)})
+
+	res.Status, res.FilmID = StatusCreated, f.ID
+
+	return nil
+}
+
+// updateFilm takes description and rating from the row and binds the
+// actors not bound yet, the other bindings are kept.
+func (b *batch) updateFilm(ctx context.Context, cur, f *film.Film, ids []int32, res *RowResult) error {
+	bound, err := b.films.GetFilmActors(ctx, cur.ID)
+	if err != nil {
+		return err
+	}
+	isBound := make(map[int32]bool, len(bound))
+	for _, v := range bound {
+		isBound[v.ID] = true
+	}
+
+	fa := &film.FilmActors{ID: cur.ID}
+	for _, v := range ids {
+		if !isBound[v] {
+			fa.ActorIDs = append(fa.ActorIDs, v)
+		}
+	}
+
+	fu := &film.FilmUpdate{ID: cur.ID}
+	if f.Description != cur.Description {
+		fu.Description = &f.Description
+	}
+	if f.Rating != cur.Rating {
+		fu.Rating = &f.Rating
+	}
+
+	res.FilmID = cur.ID
+	if fu.Description == nil && fu.Rating == nil && len(fa.ActorIDs) == 0 {
+		res.Status = StatusSkipped
+		return nil
+	}
+
+	if fu.Description != nil || fu.Rating != nil {
+		if err := b.films.UpdateFilm(ctx, fu); err != nil {
+			return err
+		}
+	}
+	if len(fa.ActorIDs) != 0 {
+		if err := b.films.AddFilmActors(ctx, fa); err != nil {
+			return err
+		}
+	}
+
+	after, err := b.films.GetFilm(ctx, cur.ID)
+	if err != nil {
+		return err
+	}
+	b.idx.putFilm(after)
+	b.changes = append(b.changes, change{audit.EntityFilm, cur.ID, audit.ActionUpdate, film.ToFilmState(cur), film.ToFilmState(after)})
+
+	res.Status = StatusUpdated
+
+	return nil
+}
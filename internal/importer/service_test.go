@@ -0,0 +1,215 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/memory"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+const catalog = `name,description,releasedate,rating,actors
+film1,about film1,2000-01-12,5,actor1|1980-01-01;actor2|female|1985-01-01
+film2,about film2,2001-01-12,6,actor1;actor3|male|1990-01-01;actor2|1985-01-01
+film3,about film3,2002-01-12,7,unknown
+`
+
+// newStore returns a store holding actor1 only.
+func newStore(t *testing.T) *memory.Store {
+	t.Helper()
+
+	s := memory.NewStore()
+	birthday, _ := time.Parse(time.DateOnly, "1980-01-01")
+	_, err := memory.NewActorRepository(s).AddActor(context.TODO(), &actor.Actor{
+		Name:     "actor1",
+		Sex:      "male",
+		Birthday: birthday,
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	return s
+}
+
+func importCSV(t *testing.T, s *Service, input, mode, dryRun string) *Report {
+	t.Helper()
+
+	rep, err := s.Import(context.TODO(), &ImportRequest{
+		Format: FormatCSV,
+		Body:   strings.NewReader(input),
+		Mode:   mode,
+		DryRun: dryRun,
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	return rep
+}
+
+func statuses(rep *Report) []string {
+	res := make([]string, 0, len(rep.Rows))
+	for _, v := range rep.Rows {
+		res = append(res, v.Status)
+	}
+
+	return res
+}
+
+func getFilms(t *testing.T, s *memory.Store) []*film.Film {
+	t.Helper()
+
+	films, err := memory.NewFilmRepository(s).GetFilms(context.TODO(), &film.Query{Sort: []film.SortKey{{Field: "name"}}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	return films
+}
+
+// getChanges returns the audit entries of the store as entity:action,
+// oldest first.
+func getChanges(t *testing.T, s *memory.Store) []string {
+	t.Helper()
+
+	entries, err := memory.NewAuditRepository(s).GetEntries(context.TODO(), &audit.Query{Limit: 100})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	changes := make([]string, 0, len(entries))
+	for _, v := range entries {
+		changes = append(changes, v.Entity+":"+v.Action)
+	}
+	slices.Reverse(changes)
+
+	return changes
+}
+
+func TestService_Import_Transaction(t *testing.T) {
+	store := newStore(t)
+
+	s := NewService(store)
+
+	// an errored row rolls back the others, nothing is recorded
+	rep := importCSV(t, s, catalog, "", "")
+	exp := []string{StatusCreated, StatusCreated, StatusErrored}
+	if !slices.Equal(statuses(rep), exp) {
+		t.Errorf("Expected %v, got %v", exp, statuses(rep))
+	}
+	if rep.Mode != ModeTransaction || rep.Committed || rep.Created != 2 || rep.Errored != 1 || rep.ActorsCreated != 2 {
+		t.Errorf("Unexpected report %+v", rep)
+	}
+	if rep.Rows[0].FilmID != 0 || rep.Rows[2].Line != 4 || len(rep.Rows[2].Error) == 0 {
+		t.Errorf("Unexpected rows %+v, %+v", rep.Rows[0], rep.Rows[2])
+	}
+	if films := getFilms(t, store); len(films) != 0 {
+		t.Errorf("Expected no films, got %+v", films)
+	}
+	if changes := getChanges(t, store); len(changes) != 0 {
+		t.Errorf("Expected no audit entries, got %v", changes)
+	}
+
+	rep = importCSV(t, s, strings.TrimSuffix(catalog, "film3,about film3,2002-01-12,7,unknown\n"), ModeTransaction, "false")
+	if !rep.Committed || rep.Created != 2 || rep.Rows[0].FilmID == 0 {
+		t.Errorf("Unexpected report %+v", rep)
+	}
+	films := getFilms(t, store)
+	if len(films) != 2 {
+		t.Fatalf("Expected 2 films, got %+v", films)
+	}
+	if exp := []string{"actor1", "actor2", "actor3"}; !slices.Equal(films[1].Actors, exp) {
+		t.Errorf("Expected %v, got %v", exp, films[1].Actors)
+	}
+	exp = []string{"actor:create", "film:create", "actor:create", "film:create"}
+	if changes := getChanges(t, store); !slices.Equal(changes, exp) {
+		t.Errorf("Expected %v, got %v", exp, changes)
+	}
+}
+
+func TestService_Import_Row(t *testing.T) {
+	store := newStore(t)
+
+	s := NewService(store)
+
+	rep := importCSV(t, s, catalog, ModeRow, "")
+	exp := []string{StatusCreated, StatusCreated, StatusErrored}
+	if !slices.Equal(statuses(rep), exp) {
+		t.Errorf("Expected %v, got %v", exp, statuses(rep))
+	}
+	if !rep.Committed || rep.Created != 2 || rep.Errored != 1 || rep.ActorsCreated != 2 {
+		t.Errorf("Unexpected report %+v", rep)
+	}
+	if films := getFilms(t, store); len(films) != 2 || films[0].ID != rep.Rows[0].FilmID {
+		t.Errorf("Expected film1 and film2, got %+v", films)
+	}
+	if changes := getChanges(t, store); len(changes) != 4 {
+		t.Errorf("Expected 4 audit entries, got %v", changes)
+	}
+}
+
+func TestService_Import_Update(t *testing.T) {
+	store := newStore(t)
+
+	s := NewService(store)
+
+	importCSV(t, s, catalog, ModeRow, "")
+
+	input := `name,description,releasedate,rating,actors
+film1,about film1,2000-01-12,9,actor3|1990-01-01
+film2,about film2,2001-01-12,6,actor1
+`
+
+	// a dry run reports the changes without making them
+	rep := importCSV(t, s, input, ModeRow, "true")
+	exp := []string{StatusUpdated, StatusSkipped}
+	if !slices.Equal(statuses(rep), exp) {
+		t.Errorf("Expected %v, got %v", exp, statuses(rep))
+	}
+	if rep.Committed || !rep.DryRun || rep.Updated != 1 || rep.Skipped != 1 {
+		t.Errorf("Unexpected report %+v", rep)
+	}
+	films := getFilms(t, store)
+	if films[0].Rating != 5 {
+		t.Errorf("Expected rating %d, got %d", 5, films[0].Rating)
+	}
+	if changes := getChanges(t, store); len(changes) != 4 {
+		t.Errorf("Expected 4 audit entries, got %v", changes)
+	}
+
+	rep = importCSV(t, s, input, "", "")
+	if !slices.Equal(statuses(rep), exp) || !rep.Committed || rep.Rows[1].FilmID != films[1].ID {
+		t.Errorf("Unexpected report %+v", rep)
+	}
+	films = getFilms(t, store)
+	if films[0].Rating != 9 || !slices.Equal(films[0].Actors, []string{"actor1", "actor2", "actor3"}) {
+		t.Errorf("Expected updated film1, got %+v", films[0])
+	}
+	if changes := getChanges(t, store); len(changes) != 5 || changes[4] != "film:update" {
+		t.Errorf("Expected the update of film1 last, got %v", changes)
+	}
+}
+
+func TestService_Import_Validation(t *testing.T) {
+
+	s := NewService(memory.NewStore())
+
+	_, err := s.Import(context.TODO(), &ImportRequest{
+		Format: FormatCSV,
+		Body:   strings.NewReader(catalog),
+		Mode:   "bulk",
+		DryRun: "maybe",
+	})
+	var ve *util.ValidationError
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
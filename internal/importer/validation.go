@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+func ValidateImportRequest(req *ImportRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(req.Mode) != 0 && req.Mode != ModeTransaction && req.Mode != ModeRow {
+		ve.AddViolation("incorrect mode, expected one of: transaction, row")
+	}
+
+	if _, err := strconv.ParseBool(req.DryRun); err != nil && len(req.DryRun) != 0 {
+		ve.AddViolation("incorrect dryRun, expected: true or false")
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateRow(row *Row) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if fErr := film.ValidateEmptyFilmInfo(&row.Info); fErr != nil {
+		ve.AddViolation(fErr.Error())
+	}
+	if fErr := film.ValidateFormatFilmInfo(&row.Info); fErr != nil {
+		ve.AddViolation(fErr.Error())
+	}
+
+	for i, v := range row.Actors {
+		if len(v.Name) == 0 {
+			ve.AddViolation(fmt.Sprintf("actors[%d]: name empty", i))
+		}
+		if aErr := actor.ValidateFormatActorInfo(&v); aErr != nil {
+			ve.AddViolation(fmt.Sprintf("actors[%d]: %s", i, aErr.Error()))
+		}
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
@@ -0,0 +1,129 @@
+package lending
+
+import (
+	"time"
+)
+
+// WithDefaults returns a copy of the info with the condition set to good
+// when absent.
+func WithDefaults(ci *CopyInfo) *CopyInfo {
+	res := *ci
+	if len(res.Condition) == 0 {
+		res.Condition = ConditionGood
+	}
+
+	return &res
+}
+
+func ToCopy(filmID int32, ci *CopyInfo) *Copy {
+	return &Copy{
+		FilmID:    filmID,
+		Format:    ci.Format,
+		Barcode:   ci.Barcode,
+		Condition: ci.Condition,
+	}
+}
+
+// PatchToCopyUpdate expects a validated patch.
+func PatchToCopyUpdate(id int32, cp *CopyPatch) *CopyUpdate {
+	return &CopyUpdate{
+		ID:        id,
+		Format:    cp.Format.Ptr(),
+		Condition: cp.Condition.Ptr(),
+	}
+}
+
+func ToCopyResponse(c *Copy) *CopyResponse {
+	return &CopyResponse{
+		ID:        c.ID,
+		FilmID:    c.FilmID,
+		Format:    c.Format,
+		Barcode:   c.Barcode,
+		Condition: c.Condition,
+		Available: !c.OnLoan,
+		CreatedAt: c.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt: c.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func ToCopyResponses(copies []*Copy) []*CopyResponse {
+	res := make([]*CopyResponse, 0, len(copies))
+	for _, v := range copies {
+		res = append(res, ToCopyResponse(v))
+	}
+
+	return res
+}
+
+// ToLoanResponse tells whether the loan is overdue at the given time.
+func ToLoanResponse(l *Loan, now time.Time) *LoanResponse {
+	res := &LoanResponse{
+		ID: l.ID,
+		Copy: CopyShortResponse{
+			ID:      l.CopyID,
+			Barcode: l.Barcode,
+			Format:  l.Format,
+		},
+		Film: FilmShortResponse{
+			ID:   l.FilmID,
+			Name: l.FilmName,
+		},
+		User: UserShortResponse{
+			ID:       l.UserID,
+			Username: l.Username,
+		},
+		CheckedOutAt: l.CheckedOutAt.UTC().Format(time.RFC3339Nano),
+		DueAt:        l.DueAt.UTC().Format(time.RFC3339Nano),
+		Renewals:     l.Renewals,
+		Overdue:      l.ReturnedAt == nil && now.After(l.DueAt),
+	}
+	if l.ReturnedAt != nil {
+		res.ReturnedAt = l.ReturnedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return res
+}
+
+func ToLoanResponses(loans []*Loan, now time.Time) []*LoanResponse {
+	res := make([]*LoanResponse, 0, len(loans))
+	for _, v := range loans {
+		res = append(res, ToLoanResponse(v, now))
+	}
+
+	return res
+}
+
+func ToHoldResponse(h *Hold) *HoldResponse {
+	return &HoldResponse{
+		Film: FilmShortResponse{
+			ID:   h.FilmID,
+			Name: h.FilmName,
+		},
+		User: UserShortResponse{
+			ID:       h.UserID,
+			Username: h.Username,
+		},
+		Position: h.Position,
+		Ready:    h.Position <= h.AvailableCopies,
+		PlacedAt: h.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func ToHoldResponses(holds []*Hold) []*HoldResponse {
+	res := make([]*HoldResponse, 0, len(holds))
+	for _, v := range holds {
+		res = append(res, ToHoldResponse(v))
+	}
+
+	return res
+}
+
+// renewedDue extends the loan by another period counted from the due
+// time, or from now for overdue loans.
+func renewedDue(l *Loan, now time.Time, period time.Duration) time.Time {
+	if now.After(l.DueAt) {
+		return now.Add(period)
+	}
+
+	return l.DueAt.Add(period)
+}
@@ -0,0 +1,294 @@
+package lending
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ LendingHandler = (*Handler)(nil)
+
+type Handler struct {
+	service LendingService
+}
+
+func NewHandler(ls LendingService) *Handler {
+	return &Handler{
+		service: ls,
+	}
+}
+
+// claims returns the authenticated user, the lending routes are behind
+// the authentication middleware.
+func claims(w http.ResponseWriter, r *http.Request) (*util.UserClaims, bool) {
+	uc, ok := util.UserClaimsFromContext(r.Context())
+	if !ok {
+		log.Printf("ERROR: lending request without authenticated user\n")
+		util.InternalServerError(w, r)
+		return nil, false
+	}
+
+	return uc, true
+}
+
+func serviceError(w http.ResponseWriter, r *http.Request, err error) {
+	var ve *util.ValidationError
+	if errors.As(err, &ve) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, ErrEmptyUpdate) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      "empty update",
+		})
+		return
+	}
+
+	for _, v := range []error{ErrBarcodeTaken, ErrCopyOnLoan, ErrCopyHeld, ErrLoanReturned, ErrRenewalLimit,
+		ErrHoldsWaiting, ErrNoCopies, ErrFilmBorrowed, ErrHoldExists} {
+		if errors.Is(err, v) {
+			util.JSON(w, r, http.StatusConflict, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeConflict,
+				Body:      v.Error(),
+			})
+			return
+		}
+	}
+
+	if errors.Is(err, ErrIdInvalid) {
+		util.NotFound(w, r)
+		return
+	}
+
+	for _, v := range []error{ErrFilmNotExist, ErrUserNotExist, ErrCopyNotExist, ErrLoanNotExist, ErrHoldNotExist} {
+		if errors.Is(err, v) {
+			util.JSON(w, r, http.StatusNotFound, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeNotFound,
+				Body:      v.Error(),
+			})
+			return
+		}
+	}
+
+	util.InternalServerError(w, r)
+}
+
+func (h *Handler) GetCopies(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetCopies(r.Context(), &FilmIdRequest{ID: r.PathValue("id")})
+	if err != nil {
+		log.Printf("ERROR: failed to get copies err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) AddCopy(w http.ResponseWriter, r *http.Request) {
+	req := AddCopyRequest{FilmID: r.PathValue("id")}
+	if ok := util.BindJSON(w, r, &req.Info); !ok {
+		return
+	}
+
+	res, err := h.service.AddCopy(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to add copy err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) PatchCopy(w http.ResponseWriter, r *http.Request) {
+	req := CopyPatchRequest{ID: r.PathValue("id")}
+	if ok := util.BindMergePatch(w, r, &req.Patch); !ok {
+		return
+	}
+
+	res, err := h.service.PatchCopy(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to patch copy err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) DeleteCopy(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DeleteCopy(r.Context(), &CopyIdRequest{ID: r.PathValue("id")})
+	if err != nil {
+		log.Printf("ERROR: failed to delete copy err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.OK(w, r)
+}
+
+func (h *Handler) Checkout(w http.ResponseWriter, r *http.Request) {
+	var req CheckoutRequest
+	if ok := util.BindJSON(w, r, &req.Info); !ok {
+		return
+	}
+
+	res, err := h.service.Checkout(r.Context(), &req)
+	if err != nil {
+		log.Printf("ERROR: failed to check out copy err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) loanIdRequest(w http.ResponseWriter, r *http.Request) (*LoanIdRequest, bool) {
+	uc, ok := claims(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	return &LoanIdRequest{
+		UserID: int32(uc.ID),
+		Admin:  uc.IsAdmin,
+		ID:     r.PathValue("id"),
+	}, true
+}
+
+func (h *Handler) ReturnLoan(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.loanIdRequest(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.ReturnLoan(r.Context(), req)
+	if err != nil {
+		log.Printf("ERROR: failed to return loan err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) RenewLoan(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.loanIdRequest(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.RenewLoan(r.Context(), req)
+	if err != nil {
+		log.Printf("ERROR: failed to renew loan err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetLoans(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetLoans(r.Context(), &LoansRequest{
+		UserQuery: r.URL.Query().Get("userId"),
+		Status:    r.URL.Query().Get("status"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get loans err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetMyLoans(w http.ResponseWriter, r *http.Request) {
+	uc, ok := claims(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetLoans(r.Context(), &LoansRequest{
+		UserID: int32(uc.ID),
+		Status: r.URL.Query().Get("status"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get loans err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetHolds(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetHolds(r.Context(), &FilmIdRequest{ID: r.PathValue("id")})
+	if err != nil {
+		log.Printf("ERROR: failed to get holds err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetMyHolds(w http.ResponseWriter, r *http.Request) {
+	uc, ok := claims(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetUserHolds(r.Context(), &UserRequest{UserID: int32(uc.ID)})
+	if err != nil {
+		log.Printf("ERROR: failed to get holds err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	uc, ok := claims(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.PlaceHold(r.Context(), &HoldRequest{
+		UserID: int32(uc.ID),
+		FilmID: r.PathValue("id"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to place hold err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) CancelHold(w http.ResponseWriter, r *http.Request) {
+	uc, ok := claims(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.CancelHold(r.Context(), &HoldRequest{
+		UserID: int32(uc.ID),
+		FilmID: r.PathValue("id"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to cancel hold err=%s\n", err.Error())
+		serviceError(w, r, err)
+		return
+	}
+
+	util.OK(w, r)
+}
@@ -0,0 +1,292 @@
+// Package lending tracks the physical copies of films and lends them to
+// members: librarians check copies out and take them back, members renew
+// their loans and queue up for films with holds served first come, first
+// served.
+package lending
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+const (
+	FormatDVD    = "dvd"
+	FormatBluRay = "bluray"
+	FormatUHD    = "uhd"
+	FormatVHS    = "vhs"
+
+	ConditionNew     = "new"
+	ConditionGood    = "good"
+	ConditionFair    = "fair"
+	ConditionPoor    = "poor"
+	ConditionDamaged = "damaged"
+
+	StatusActive  = "active"
+	StatusOverdue = "overdue"
+	StatusAll     = "all"
+
+	maxBarcodeLength = 64
+)
+
+var (
+	ErrIdInvalid    = errors.New("invalid id")
+	ErrFilmNotExist = errors.New("film does not exist")
+	ErrUserNotExist = errors.New("user does not exist")
+	ErrCopyNotExist = errors.New("copy does not exist")
+	ErrBarcodeTaken = errors.New("copy with the same barcode already exists")
+	ErrCopyOnLoan   = errors.New("copy is on loan")
+	ErrCopyHeld     = errors.New("copy is held for members ahead in the queue")
+	ErrLoanNotExist = errors.New("loan does not exist")
+	ErrLoanReturned = errors.New("loan is already returned")
+	ErrRenewalLimit = errors.New("renewal limit reached")
+	ErrHoldsWaiting = errors.New("members are waiting for the film")
+	ErrNoCopies     = errors.New("film has no copies")
+	ErrFilmBorrowed = errors.New("film is already on loan to the user")
+	ErrHoldExists   = errors.New("film is already on hold for the user")
+	ErrHoldNotExist = errors.New("film is not on hold for the user")
+	ErrEmptyUpdate  = errors.New("no updates to apply")
+)
+
+// Policy sets how long copies are lent for and how many times a loan can
+// be renewed, each renewal adds another LoanPeriod.
+type Policy struct {
+	LoanPeriod  time.Duration
+	MaxRenewals int
+}
+
+// Copy is a physical copy of a film, copies of films in the trash are
+// not found.
+type Copy struct {
+	ID        int32
+	FilmID    int32
+	Format    string
+	Barcode   string
+	Condition string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	OnLoan    bool
+}
+
+type CopyUpdate struct {
+	ID        int32
+	Format    *string
+	Condition *string
+}
+
+// Loan is a copy lent to a user, ReturnedAt is nil until it is back.
+type Loan struct {
+	ID           int32
+	CopyID       int32
+	UserID       int32
+	CheckedOutAt time.Time
+	DueAt        time.Time
+	ReturnedAt   *time.Time
+	Renewals     int
+	Barcode      string
+	Format       string
+	FilmID       int32
+	FilmName     string
+	Username     string
+}
+
+// Hold is a place of a user in the queue for a film. Position counts
+// from 1 and the first AvailableCopies holders can check a copy out.
+type Hold struct {
+	ID              int32
+	FilmID          int32
+	UserID          int32
+	CreatedAt       time.Time
+	Position        int
+	AvailableCopies int
+	FilmName        string
+	Username        string
+}
+
+// LoanQuery selects loans, zero fields match any. Active keeps the loans
+// not returned and DueBefore the ones due before the given time.
+type LoanQuery struct {
+	UserID    int32
+	FilmID    int32
+	Active    bool
+	DueBefore time.Time
+}
+
+type LendingRepository interface {
+	// GetCopies returns the copies of the film oldest first,
+	// ErrFilmNotExist if the film is missing or in the trash.
+	GetCopies(ctx context.Context, filmID int32) ([]*Copy, error)
+	GetCopy(ctx context.Context, id int32) (*Copy, error)
+	GetCopyByBarcode(ctx context.Context, barcode string) (*Copy, error)
+	AddCopy(ctx context.Context, c *Copy) (*Copy, error)
+	UpdateCopy(ctx context.Context, cu *CopyUpdate) error
+	// DeleteCopy fails with ErrCopyOnLoan while the copy is lent.
+	DeleteCopy(ctx context.Context, id int32) error
+
+	// GetLoans returns the loans earliest due first.
+	GetLoans(ctx context.Context, q *LoanQuery) ([]*Loan, error)
+	GetLoan(ctx context.Context, id int32) (*Loan, error)
+	// AddLoan fails with ErrCopyOnLoan if the copy is lent already.
+	AddLoan(ctx context.Context, l *Loan) (*Loan, error)
+	// RenewLoan moves the due time of a loan not returned yet and counts
+	// the renewal.
+	RenewLoan(ctx context.Context, id int32, dueAt time.Time) error
+	ReturnLoan(ctx context.Context, id int32, returnedAt time.Time) error
+
+	// GetHolds returns the queue for the film, ErrFilmNotExist if the
+	// film is missing or in the trash.
+	GetHolds(ctx context.Context, filmID int32) ([]*Hold, error)
+	// GetUserHolds returns the holds of the user oldest first.
+	GetUserHolds(ctx context.Context, userID int32) ([]*Hold, error)
+	// AddHold puts the user at the end of the queue for the film.
+	AddHold(ctx context.Context, h *Hold) error
+	DeleteHold(ctx context.Context, filmID int32, userID int32) error
+}
+
+type LendingService interface {
+	GetCopies(ctx context.Context, req *FilmIdRequest) ([]*CopyResponse, error)
+	AddCopy(ctx context.Context, req *AddCopyRequest) (*CopyResponse, error)
+	PatchCopy(ctx context.Context, req *CopyPatchRequest) (*CopyResponse, error)
+	DeleteCopy(ctx context.Context, req *CopyIdRequest) error
+	Checkout(ctx context.Context, req *CheckoutRequest) (*LoanResponse, error)
+	ReturnLoan(ctx context.Context, req *LoanIdRequest) (*LoanResponse, error)
+	RenewLoan(ctx context.Context, req *LoanIdRequest) (*LoanResponse, error)
+	GetLoans(ctx context.Context, req *LoansRequest) ([]*LoanResponse, error)
+	GetHolds(ctx context.Context, req *FilmIdRequest) ([]*HoldResponse, error)
+	GetUserHolds(ctx context.Context, req *UserRequest) ([]*HoldResponse, error)
+	PlaceHold(ctx context.Context, req *HoldRequest) (*HoldResponse, error)
+	CancelHold(ctx context.Context, req *HoldRequest) error
+}
+
+type LendingHandler interface {
+	GetCopies(w http.ResponseWriter, r *http.Request)
+	AddCopy(w http.ResponseWriter, r *http.Request)
+	PatchCopy(w http.ResponseWriter, r *http.Request)
+	DeleteCopy(w http.ResponseWriter, r *http.Request)
+	Checkout(w http.ResponseWriter, r *http.Request)
+	ReturnLoan(w http.ResponseWriter, r *http.Request)
+	RenewLoan(w http.ResponseWriter, r *http.Request)
+	GetLoans(w http.ResponseWriter, r *http.Request)
+	GetMyLoans(w http.ResponseWriter, r *http.Request)
+	GetHolds(w http.ResponseWriter, r *http.Request)
+	GetMyHolds(w http.ResponseWriter, r *http.Request)
+	PlaceHold(w http.ResponseWriter, r *http.Request)
+	CancelHold(w http.ResponseWriter, r *http.Request)
+}
+
+type UserRequest struct {
+	UserID int32
+}
+
+type FilmIdRequest struct {
+	ID string
+}
+
+type CopyInfo struct {
+	Format    string `json:"format"`
+	Barcode   string `json:"barcode"`
+	Condition string `json:"condition"`
+}
+
+type AddCopyRequest struct {
+	FilmID string
+	Info   CopyInfo
+}
+
+type CopyIdRequest struct {
+	ID string
+}
+
+// CopyPatch is a JSON Merge Patch of CopyInfo, the barcode can not be
+// changed and none of the fields can be null.
+type CopyPatch struct {
+	Format    util.PatchField[string] `json:"format"`
+	Condition util.PatchField[string] `json:"condition"`
+}
+
+type CopyPatchRequest struct {
+	ID    string
+	Patch CopyPatch
+}
+
+type CheckoutInfo struct {
+	Barcode string `json:"barcode"`
+	UserID  int    `json:"userId"`
+}
+
+type CheckoutRequest struct {
+	Info CheckoutInfo
+}
+
+// LoanIdRequest is made by UserID, members other than admins only see
+// their own loans.
+type LoanIdRequest struct {
+	UserID int32
+	Admin  bool
+	ID     string
+}
+
+// LoansRequest lists the loans of UserID, of everyone when zero, or of
+// the user given by the UserQuery parameter.
+type LoansRequest struct {
+	UserID    int32
+	UserQuery string
+	Status    string
+}
+
+type HoldRequest struct {
+	UserID int32
+	FilmID string
+}
+
+type FilmShortResponse struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserShortResponse struct {
+	ID       int32  `json:"id"`
+	Username string `json:"username"`
+}
+
+type CopyShortResponse struct {
+	ID      int32  `json:"id"`
+	Barcode string `json:"barcode"`
+	Format  string `json:"format"`
+}
+
+type CopyResponse struct {
+	ID        int32  `json:"id"`
+	FilmID    int32  `json:"filmId"`
+	Format    string `json:"format"`
+	Barcode   string `json:"barcode"`
+	Condition string `json:"condition"`
+	Available bool   `json:"available"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type LoanResponse struct {
+	ID           int32             `json:"id"`
+	Copy         CopyShortResponse `json:"copy"`
+	Film         FilmShortResponse `json:"film"`
+	User         UserShortResponse `json:"user"`
+	CheckedOutAt string            `json:"checkedOutAt"`
+	DueAt        string            `json:"dueAt"`
+	ReturnedAt   string            `json:"returnedAt,omitempty"`
+	Renewals     int               `json:"renewals"`
+	Overdue      bool              `json:"overdue"`
+}
+
+// HoldResponse is a place in the queue, Ready tells that a copy is
+// waiting for the user.
+type HoldResponse struct {
+	Film     FilmShortResponse `json:"film"`
+	User     UserShortResponse `json:"user"`
+	Position int               `json:"position"`
+	Ready    bool              `json:"ready"`
+	PlacedAt string            `json:"placedAt"`
+}
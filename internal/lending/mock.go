@@ -0,0 +1,641 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/lending/lending.go
+//
+// Generated by this command:
+//
+//	mockgen -self_package=github.com/Coderovshik/film-library/internal/lending -package=lending -source=internal/lending/lending.go -destination=internal/lending/mock.go
+//
+
+// Package lending is a generated GoMock package.
+package lending
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLendingRepository is a mock of LendingRepository interface.
+type MockLendingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLendingRepositoryMockRecorder
+}
+
+// MockLendingRepositoryMockRecorder is the mock recorder for MockLendingRepository.
+type MockLendingRepositoryMockRecorder struct {
+	mock *MockLendingRepository
+}
+
+// NewMockLendingRepository creates a new mock instance.
+func NewMockLendingRepository(ctrl *gomock.Controller) *MockLendingRepository {
+	mock := &MockLendingRepository{ctrl: ctrl}
+	mock.recorder = &MockLendingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLendingRepository) EXPECT() *MockLendingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddCopy mocks base method.
+func (m *MockLendingRepository) AddCopy(ctx context.Context, c *Copy) (*Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCopy", ctx, c)
+	ret0, _ := ret[0].(*Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCopy indicates an expected call of AddCopy.
+func (mr *MockLendingRepositoryMockRecorder) AddCopy(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCopy", reflect.TypeOf((*MockLendingRepository)(nil).AddCopy), ctx, c)
+}
+
+// AddHold mocks base method.
+func (m *MockLendingRepository) AddHold(ctx context.Context, h *Hold) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddHold", ctx, h)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddHold indicates an expected call of AddHold.
+func (mr *MockLendingRepositoryMockRecorder) AddHold(ctx, h any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddHold", reflect.TypeOf((*MockLendingRepository)(nil).AddHold), ctx, h)
+}
+
+// AddLoan mocks base method.
+func (m *MockLendingRepository) AddLoan(ctx context.Context, l *Loan) (*Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLoan", ctx, l)
+	ret0, _ := ret[0].(*Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddLoan indicates an expected call of AddLoan.
+func (mr *MockLendingRepositoryMockRecorder) AddLoan(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLoan", reflect.TypeOf((*MockLendingRepository)(nil).AddLoan), ctx, l)
+}
+
+// DeleteCopy mocks base method.
+func (m *MockLendingRepository) DeleteCopy(ctx context.Context, id int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCopy", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCopy indicates an expected call of DeleteCopy.
+func (mr *MockLendingRepositoryMockRecorder) DeleteCopy(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCopy", reflect.TypeOf((*MockLendingRepository)(nil).DeleteCopy), ctx, id)
+}
+
+// DeleteHold mocks base method.
+func (m *MockLendingRepository) DeleteHold(ctx context.Context, filmID, userID int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHold", ctx, filmID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHold indicates an expected call of DeleteHold.
+func (mr *MockLendingRepositoryMockRecorder) DeleteHold(ctx, filmID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHold", reflect.TypeOf((*MockLendingRepository)(nil).DeleteHold), ctx, filmID, userID)
+}
+
+// GetCopies mocks base method.
+func (m *MockLendingRepository) GetCopies(ctx context.Context, filmID int32) ([]*Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCopies", ctx, filmID)
+	ret0, _ := ret[0].([]*Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCopies indicates an expected call of GetCopies.
+func (mr *MockLendingRepositoryMockRecorder) GetCopies(ctx, filmID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCopies", reflect.TypeOf((*MockLendingRepository)(nil).GetCopies), ctx, filmID)
+}
+
+// GetCopy mocks base method.
+func (m *MockLendingRepository) GetCopy(ctx context.Context, id int32) (*Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCopy", ctx, id)
+	ret0, _ := ret[0].(*Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCopy indicates an expected call of GetCopy.
+func (mr *MockLendingRepositoryMockRecorder) GetCopy(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCopy", reflect.TypeOf((*MockLendingRepository)(nil).GetCopy), ctx, id)
+}
+
+// GetCopyByBarcode mocks base method.
+func (m *MockLendingRepository) GetCopyByBarcode(ctx context.Context, barcode string) (*Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCopyByBarcode", ctx, barcode)
+	ret0, _ := ret[0].(*Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCopyByBarcode indicates an expected call of GetCopyByBarcode.
+func (mr *MockLendingRepositoryMockRecorder) GetCopyByBarcode(ctx, barcode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCopyByBarcode", reflect.TypeOf((*MockLendingRepository)(nil).GetCopyByBarcode), ctx, barcode)
+}
+
+// GetHolds mocks base method.
+func (m *MockLendingRepository) GetHolds(ctx context.Context, filmID int32) ([]*Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHolds", ctx, filmID)
+	ret0, _ := ret[0].([]*Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHolds indicates an expected call of GetHolds.
+func (mr *MockLendingRepositoryMockRecorder) GetHolds(ctx, filmID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHolds", reflect.TypeOf((*MockLendingRepository)(nil).GetHolds), ctx, filmID)
+}
+
+// GetLoan mocks base method.
+func (m *MockLendingRepository) GetLoan(ctx context.Context, id int32) (*Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoan", ctx, id)
+	ret0, _ := ret[0].(*Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoan indicates an expected call of GetLoan.
+func (mr *MockLendingRepositoryMockRecorder) GetLoan(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoan", reflect.TypeOf((*MockLendingRepository)(nil).GetLoan), ctx, id)
+}
+
+// GetLoans mocks base method.
+func (m *MockLendingRepository) GetLoans(ctx context.Context, q *LoanQuery) ([]*Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoans", ctx, q)
+	ret0, _ := ret[0].([]*Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoans indicates an expected call of GetLoans.
+func (mr *MockLendingRepositoryMockRecorder) GetLoans(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoans", reflect.TypeOf((*MockLendingRepository)(nil).GetLoans), ctx, q)
+}
+
+// GetUserHolds mocks base method.
+func (m *MockLendingRepository) GetUserHolds(ctx context.Context, userID int32) ([]*Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserHolds", ctx, userID)
+	ret0, _ := ret[0].([]*Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserHolds indicates an expected call of GetUserHolds.
+func (mr *MockLendingRepositoryMockRecorder) GetUserHolds(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserHolds", reflect.TypeOf((*MockLendingRepository)(nil).GetUserHolds), ctx, userID)
+}
+
+// RenewLoan mocks base method.
+func (m *MockLendingRepository) RenewLoan(ctx context.Context, id int32, dueAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewLoan", ctx, id, dueAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenewLoan indicates an expected call of RenewLoan.
+func (mr *MockLendingRepositoryMockRecorder) RenewLoan(ctx, id, dueAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewLoan", reflect.TypeOf((*MockLendingRepository)(nil).RenewLoan), ctx, id, dueAt)
+}
+
+// ReturnLoan mocks base method.
+func (m *MockLendingRepository) ReturnLoan(ctx context.Context, id int32, returnedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReturnLoan", ctx, id, returnedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReturnLoan indicates an expected call of ReturnLoan.
+func (mr *MockLendingRepositoryMockRecorder) ReturnLoan(ctx, id, returnedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReturnLoan", reflect.TypeOf((*MockLendingRepository)(nil).ReturnLoan), ctx, id, returnedAt)
+}
+
+// UpdateCopy mocks base method.
+func (m *MockLendingRepository) UpdateCopy(ctx context.Context, cu *CopyUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCopy", ctx, cu)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCopy indicates an expected call of UpdateCopy.
+func (mr *MockLendingRepositoryMockRecorder) UpdateCopy(ctx, cu any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCopy", reflect.TypeOf((*MockLendingRepository)(nil).UpdateCopy), ctx, cu)
+}
+
+// MockLendingService is a mock of LendingService interface.
+type MockLendingService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLendingServiceMockRecorder
+}
+
+// MockLendingServiceMockRecorder is the mock recorder for MockLendingService.
+type MockLendingServiceMockRecorder struct {
+	mock *MockLendingService
+}
+
+// NewMockLendingService creates a new mock instance.
+func NewMockLendingService(ctrl *gomock.Controller) *MockLendingService {
+	mock := &MockLendingService{ctrl: ctrl}
+	mock.recorder = &MockLendingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLendingService) EXPECT() *MockLendingServiceMockRecorder {
+	return m.recorder
+}
+
+// AddCopy mocks base method.
+func (m *MockLendingService) AddCopy(ctx context.Context, req *AddCopyRequest) (*CopyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCopy", ctx, req)
+	ret0, _ := ret[0].(*CopyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCopy indicates an expected call of AddCopy.
+func (mr *MockLendingServiceMockRecorder) AddCopy(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCopy", reflect.TypeOf((*MockLendingService)(nil).AddCopy), ctx, req)
+}
+
+// CancelHold mocks base method.
+func (m *MockLendingService) CancelHold(ctx context.Context, req *HoldRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelHold", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelHold indicates an expected call of CancelHold.
+func (mr *MockLendingServiceMockRecorder) CancelHold(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelHold", reflect.TypeOf((*MockLendingService)(nil).CancelHold), ctx, req)
+}
+
+// Checkout mocks base method.
+func (m *MockLendingService) Checkout(ctx context.Context, req *CheckoutRequest) (*LoanResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkout", ctx, req)
+	ret0, _ := ret[0].(*LoanResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkout indicates an expected call of Checkout.
+func (mr *MockLendingServiceMockRecorder) Checkout(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockLendingService)(nil).Checkout), ctx, req)
+}
+
+// DeleteCopy mocks base method.
+func (m *MockLendingService) DeleteCopy(ctx context.Context, req *CopyIdRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCopy", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCopy indicates an expected call of DeleteCopy.
+func (mr *MockLendingServiceMockRecorder) DeleteCopy(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCopy", reflect.TypeOf((*MockLendingService)(nil).DeleteCopy), ctx, req)
+}
+
+// GetCopies mocks base method.
+func (m *MockLendingService) GetCopies(ctx context.Context, req *FilmIdRequest) ([]*CopyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCopies", ctx, req)
+	ret0, _ := ret[0].([]*CopyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCopies indicates an expected call of GetCopies.
+func (mr *MockLendingServiceMockRecorder) GetCopies(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCopies", reflect.TypeOf((*MockLendingService)(nil).GetCopies), ctx, req)
+}
+
+// GetHolds mocks base method.
+func (m *MockLendingService) GetHolds(ctx context.Context, req *FilmIdRequest) ([]*HoldResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHolds", ctx, req)
+	ret0, _ := ret[0].([]*HoldResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHolds indicates an expected call of GetHolds.
+func (mr *MockLendingServiceMockRecorder) GetHolds(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHolds", reflect.TypeOf((*MockLendingService)(nil).GetHolds), ctx, req)
+}
+
+// GetLoans mocks base method.
+func (m *MockLendingService) GetLoans(ctx context.Context, req *LoansRequest) ([]*LoanResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoans", ctx, req)
+	ret0, _ := ret[0].([]*LoanResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoans indicates an expected call of GetLoans.
+func (mr *MockLendingServiceMockRecorder) GetLoans(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoans", reflect.TypeOf((*MockLendingService)(nil).GetLoans), ctx, req)
+}
+
+// GetUserHolds mocks base method.
+func (m *MockLendingService) GetUserHolds(ctx context.Context, req *UserRequest) ([]*HoldResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserHolds", ctx, req)
+	ret0, _ := ret[0].([]*HoldResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserHolds indicates an expected call of GetUserHolds.
+func (mr *MockLendingServiceMockRecorder) GetUserHolds(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserHolds", reflect.TypeOf((*MockLendingService)(nil).GetUserHolds), ctx, req)
+}
+
+// PatchCopy mocks base method.
+func (m *MockLendingService) PatchCopy(ctx context.Context, req *CopyPatchRequest) (*CopyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchCopy", ctx, req)
+	ret0, _ := ret[0].(*CopyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchCopy indicates an expected call of PatchCopy.
+func (mr *MockLendingServiceMockRecorder) PatchCopy(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCopy", reflect.TypeOf((*MockLendingService)(nil).PatchCopy), ctx, req)
+}
+
+// PlaceHold mocks base method.
+func (m *MockLendingService) PlaceHold(ctx context.Context, req *HoldRequest) (*HoldResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceHold", ctx, req)
+	ret0, _ := ret[0].(*HoldResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlaceHold indicates an expected call of PlaceHold.
+func (mr *MockLendingServiceMockRecorder) PlaceHold(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceHold", reflect.TypeOf((*MockLendingService)(nil).PlaceHold), ctx, req)
+}
+
+// RenewLoan mocks base method.
+func (m *MockLendingService) RenewLoan(ctx context.Context, req *LoanIdRequest) (*LoanResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewLoan", ctx, req)
+	ret0, _ := ret[0].(*LoanResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenewLoan indicates an expected call of RenewLoan.
+func (mr *MockLendingServiceMockRecorder) RenewLoan(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewLoan", reflect.TypeOf((*MockLendingService)(nil).RenewLoan), ctx, req)
+}
+
+// ReturnLoan mocks base method.
+func (m *MockLendingService) ReturnLoan(ctx context.Context, req *LoanIdRequest) (*LoanResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReturnLoan", ctx, req)
+	ret0, _ := ret[0].(*LoanResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReturnLoan indicates an expected call of ReturnLoan.
+func (mr *MockLendingServiceMockRecorder) ReturnLoan(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReturnLoan", reflect.TypeOf((*MockLendingService)(nil).ReturnLoan), ctx, req)
+}
+
+// MockLendingHandler is a mock of LendingHandler interface.
+type MockLendingHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockLendingHandlerMockRecorder
+}
+
+// MockLendingHandlerMockRecorder is the mock recorder for MockLendingHandler.
+type MockLendingHandlerMockRecorder struct {
+	mock *MockLendingHandler
+}
+
+// NewMockLendingHandler creates a new mock instance.
+func NewMockLendingHandler(ctrl *gomock.Controller) *MockLendingHandler {
+	mock := &MockLendingHandler{ctrl: ctrl}
+	mock.recorder = &MockLendingHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLendingHandler) EXPECT() *MockLendingHandlerMockRecorder {
+	return m.recorder
+}
+
+// AddCopy mocks base method.
+func (m *MockLendingHandler) AddCopy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddCopy", w, r)
+}
+
+// AddCopy indicates an expected call of AddCopy.
+func (mr *MockLendingHandlerMockRecorder) AddCopy(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCopy", reflect.TypeOf((*MockLendingHandler)(nil).AddCopy), w, r)
+}
+
+// CancelHold mocks base method.
+func (m *MockLendingHandler) CancelHold(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CancelHold", w, r)
+}
+
+// CancelHold indicates an expected call of CancelHold.
+func (mr *MockLendingHandlerMockRecorder) CancelHold(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelHold", reflect.TypeOf((*MockLendingHandler)(nil).CancelHold), w, r)
+}
+
+// Checkout mocks base method.
+func (m *MockLendingHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Checkout", w, r)
+}
+
+// Checkout indicates an expected call of Checkout.
+func (mr *MockLendingHandlerMockRecorder) Checkout(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockLendingHandler)(nil).Checkout), w, r)
+}
+
+// DeleteCopy mocks base method.
+func (m *MockLendingHandler) DeleteCopy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteCopy", w, r)
+}
+
+// DeleteCopy indicates an expected call of DeleteCopy.
+func (mr *MockLendingHandlerMockRecorder) DeleteCopy(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCopy", reflect.TypeOf((*MockLendingHandler)(nil).DeleteCopy), w, r)
+}
+
+// GetCopies mocks base method.
+func (m *MockLendingHandler) GetCopies(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetCopies", w, r)
+}
+
+// GetCopies indicates an expected call of GetCopies.
+func (mr *MockLendingHandlerMockRecorder) GetCopies(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCopies", reflect.TypeOf((*MockLendingHandler)(nil).GetCopies), w, r)
+}
+
+// GetHolds mocks base method.
+func (m *MockLendingHandler) GetHolds(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetHolds", w, r)
+}
+
+// GetHolds indicates an expected call of GetHolds.
+func (mr *MockLendingHandlerMockRecorder) GetHolds(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHolds", reflect.TypeOf((*MockLendingHandler)(nil).GetHolds), w, r)
+}
+
+// GetLoans mocks base method.
+func (m *MockLendingHandler) GetLoans(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetLoans", w, r)
+}
+
+// GetLoans indicates an expected call of GetLoans.
+func (mr *MockLendingHandlerMockRecorder) GetLoans(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoans", reflect.TypeOf((*MockLendingHandler)(nil).GetLoans), w, r)
+}
+
+// GetMyHolds mocks base method.
+func (m *MockLendingHandler) GetMyHolds(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetMyHolds", w, r)
+}
+
+// GetMyHolds indicates an expected call of GetMyHolds.
+func (mr *MockLendingHandlerMockRecorder) GetMyHolds(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMyHolds", reflect.TypeOf((*MockLendingHandler)(nil).GetMyHolds), w, r)
+}
+
+// GetMyLoans mocks base method.
+func (m *MockLendingHandler) GetMyLoans(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetMyLoans", w, r)
+}
+
+// GetMyLoans indicates an expected call of GetMyLoans.
+func (mr *MockLendingHandlerMockRecorder) GetMyLoans(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMyLoans", reflect.TypeOf((*MockLendingHandler)(nil).GetMyLoans), w, r)
+}
+
+// PatchCopy mocks base method.
+func (m *MockLendingHandler) PatchCopy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PatchCopy", w, r)
+}
+
+// PatchCopy indicates an expected call of PatchCopy.
+func (mr *MockLendingHandlerMockRecorder) PatchCopy(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCopy", reflect.TypeOf((*MockLendingHandler)(nil).PatchCopy), w, r)
+}
+
+// PlaceHold mocks base method.
+func (m *MockLendingHandler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PlaceHold", w, r)
+}
+
+// PlaceHold indicates an expected call of PlaceHold.
+func (mr *MockLendingHandlerMockRecorder) PlaceHold(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceHold", reflect.TypeOf((*MockLendingHandler)(nil).PlaceHold), w, r)
+}
+
+// RenewLoan mocks base method.
+func (m *MockLendingHandler) RenewLoan(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RenewLoan", w, r)
+}
+
+// RenewLoan indicates an expected call of RenewLoan.
+func (mr *MockLendingHandlerMockRecorder) RenewLoan(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewLoan", reflect.TypeOf((*MockLendingHandler)(nil).RenewLoan), w, r)
+}
+
+// ReturnLoan mocks base method.
+func (m *MockLendingHandler) ReturnLoan(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReturnLoan", w, r)
+}
+
+// ReturnLoan indicates an expected call of ReturnLoan.
+func (mr *MockLendingHandlerMockRecorder) ReturnLoan(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReturnLoan", reflect.TypeOf((*MockLendingHandler)(nil).ReturnLoan), w, r)
+}
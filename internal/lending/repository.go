@@ -0,0 +1,504 @@
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ LendingRepository = (*Repository)(nil)
+
+const (
+	// onLoan tells whether the copy c is lent
+	onLoan = `EXISTS (SELECT 1 FROM loans l WHERE l.copy_id = c.copy_id AND l.returned_at IS NULL)`
+
+	selectCopies = `
+		SELECT c.copy_id, c.movie_id, c.format, c.barcode, c.condition, c.created_at, c.updated_at, ` + onLoan + `
+		FROM copies c
+		INNER JOIN movie m ON m.movie_id = c.movie_id AND m.deleted_at IS NULL`
+
+	selectLoans = `
+		SELECT l.loan_id, l.copy_id, l.user_id, l.checked_out_at, l.due_at, l.returned_at, l.renewals,
+			c.barcode, c.format, c.movie_id, m.movie_name, u.user_name
+		FROM loans l
+		INNER JOIN copies c ON c.copy_id = l.copy_id
+		INNER JOIN movie m ON m.movie_id = c.movie_id
+		INNER JOIN users u ON u.user_id = l.user_id`
+
+	// the position of the hold h in its queue and the copies of the film
+	// not lent
+	selectHolds = `
+		SELECT h.hold_id, h.movie_id, h.user_id, h.created_at,
+			(SELECT COUNT(*) FROM holds p WHERE p.movie_id = h.movie_id AND p.hold_id <= h.hold_id),
+			(SELECT COUNT(*) FROM copies c WHERE c.movie_id = h.movie_id AND NOT ` + onLoan + `),
+			m.movie_name, u.user_name
+		FROM holds h
+		INNER JOIN movie m ON m.movie_id = h.movie_id
+		INNER JOIN users u ON u.user_id = h.user_id`
+)
+
+type Repository struct {
+	db      db.DBTX
+	dialect db.Dialect
+}
+
+func NewRepository(conn db.DBTX, d db.Dialect) *Repository {
+	return &Repository{
+		db:      conn,
+		dialect: d,
+	}
+}
+
+func (r *Repository) filmExists(ctx context.Context, id int32) (bool, error) {
+	const query = `SELECT EXISTS (SELECT 1 FROM movie WHERE movie_id = $1 AND deleted_at IS NULL)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
+
+	return exists, err
+}
+
+func scanCopy(row interface{ Scan(dest ...any) error }) (*Copy, error) {
+	var c Copy
+	err := row.Scan(&c.ID, &c.FilmID, &c.Format, &c.Barcode, &c.Condition, &c.CreatedAt, &c.UpdatedAt, &c.OnLoan)
+
+	return &c, err
+}
+
+func scanLoan(row interface{ Scan(dest ...any) error }) (*Loan, error) {
+	var l Loan
+	var returnedAt sql.NullTime
+	err := row.Scan(&l.ID, &l.CopyID, &l.UserID, &l.CheckedOutAt, &l.DueAt, &returnedAt, &l.Renewals,
+		&l.Barcode, &l.Format, &l.FilmID, &l.FilmName, &l.Username)
+	if returnedAt.Valid {
+		l.ReturnedAt = &returnedAt.Time
+	}
+
+	return &l, err
+}
+
+func (r *Repository) getHolds(ctx context.Context, op string, where string, arg any) ([]*Hold, error) {
+	rows, err := r.db.QueryContext(ctx, selectHolds+` WHERE `+where+` ORDER BY h.hold_id`, arg)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	holds := make([]*Hold, 0)
+	for rows.Next() {
+		var h Hold
+		err := rows.Scan(&h.ID, &h.FilmID, &h.UserID, &h.CreatedAt, &h.Position, &h.AvailableCopies,
+			&h.FilmName, &h.Username)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		holds = append(holds, &h)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return holds, nil
+}
+
+func (r *Repository) GetCopies(ctx context.Context, filmID int32) ([]*Copy, error) {
+	const op = "lending.Repository.GetCopies"
+
+	exists, err := r.filmExists(ctx, filmID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		log.Printf("ERROR: film with id=%d does not exist\n", filmID)
+		return nil, fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+	}
+
+	rows, err := r.db.QueryContext(ctx, selectCopies+` WHERE c.movie_id = $1 ORDER BY c.created_at, c.copy_id`, filmID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	copies := make([]*Copy, 0)
+	for rows.Next() {
+		c, err := scanCopy(rows)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		copies = append(copies, c)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return copies, nil
+}
+
+func (r *Repository) getCopy(ctx context.Context, op string, where string, arg any) (*Copy, error) {
+	c, err := scanCopy(r.db.QueryRowContext(ctx, selectCopies+` WHERE `+where, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("ERROR: copy %v does not exist\n", arg)
+			return nil, fmt.Errorf("%s: %w", op, ErrCopyNotExist)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return c, nil
+}
+
+func (r *Repository) GetCopy(ctx context.Context, id int32) (*Copy, error) {
+	return r.getCopy(ctx, "lending.Repository.GetCopy", `c.copy_id = $1`, id)
+}
+
+func (r *Repository) GetCopyByBarcode(ctx context.Context, barcode string) (*Copy, error) {
+	return r.getCopy(ctx, "lending.Repository.GetCopyByBarcode", `c.barcode = $1`, barcode)
+}
+
+func (r *Repository) AddCopy(ctx context.Context, c *Copy) (*Copy, error) {
+	const op = "lending.Repository.AddCopy"
+
+	exists, err := r.filmExists(ctx, c.FilmID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		log.Printf("ERROR: film with id=%d does not exist\n", c.FilmID)
+		return nil, fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+	}
+
+	c.CreatedAt = db.Now()
+	c.UpdatedAt = c.CreatedAt
+	const query = `
+		INSERT INTO copies(movie_id, format, barcode, condition, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, "copy_id", c.FilmID, c.Format, c.Barcode,
+		c.Condition, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			log.Printf("ERROR: copy with barcode %s already exists\n", c.Barcode)
+			return nil, fmt.Errorf("%s: %w", op, ErrBarcodeTaken)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	c.ID = int32(id)
+
+	return c, nil
+}
+
+func (r *Repository) UpdateCopy(ctx context.Context, cu *CopyUpdate) error {
+	const op = "lending.Repository.UpdateCopy"
+
+	qo := util.NewQueryableObject()
+	if cu.Format != nil {
+		qo.Add("format", *cu.Format)
+	}
+	if cu.Condition != nil {
+		qo.Add("condition", *cu.Condition)
+	}
+	if qo.IsEmpty() {
+		log.Print("ERROR: no updates to apply\n")
+		return fmt.Errorf("%s: %w", op, ErrEmptyUpdate)
+	}
+
+	qo.Add("updated_at", db.Now())
+
+	query := `UPDATE copies SET ` + qo.Args(1) + fmt.Sprintf(` WHERE copy_id = $%d`, qo.Len()+1) +
+		` AND movie_id IN (SELECT movie_id FROM movie WHERE deleted_at IS NULL)`
+	res, err := r.db.ExecContext(ctx, query, append(qo.Values(), cu.ID)...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, ErrCopyNotExist)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteCopy(ctx context.Context, id int32) error {
+	const op = "lending.Repository.DeleteCopy"
+
+	c, err := r.GetCopy(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if c.OnLoan {
+		log.Printf("ERROR: copy with id=%d is on loan\n", id)
+		return fmt.Errorf("%s: %w", op, ErrCopyOnLoan)
+	}
+
+	// the returned loans go with the copy
+	const query = `DELETE FROM copies WHERE copy_id = $1`
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by delete\n")
+		return fmt.Errorf("%s: %w", op, ErrCopyNotExist)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetLoans(ctx context.Context, q *LoanQuery) ([]*Loan, error) {
+	const op = "lending.Repository.GetLoans"
+
+	where, values := make([]string, 0), make([]any, 0)
+	add := func(cond string, value any) {
+		values = append(values, value)
+		where = append(where, fmt.Sprintf(cond, len(values)))
+	}
+	if q.UserID != 0 {
+		add("l.user_id = $%d", q.UserID)
+	}
+	if q.FilmID != 0 {
+		add("c.movie_id = $%d", q.FilmID)
+	}
+	if q.Active {
+		where = append(where, "l.returned_at IS NULL")
+	}
+	if !q.DueBefore.IsZero() {
+		add("l.due_at < $%d", q.DueBefore)
+	}
+
+	query := selectLoans
+	if len(where) != 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY l.due_at, l.loan_id`
+	rows, err := r.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	loans := make([]*Loan, 0)
+	for rows.Next() {
+		l, err := scanLoan(rows)
+		if err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		loans = append(loans, l)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return loans, nil
+}
+
+func (r *Repository) GetLoan(ctx context.Context, id int32) (*Loan, error) {
+	const op = "lending.Repository.GetLoan"
+
+	l, err := scanLoan(r.db.QueryRowContext(ctx, selectLoans+` WHERE l.loan_id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("ERROR: loan with id=%d does not exist\n", id)
+			return nil, fmt.Errorf("%s: %w", op, ErrLoanNotExist)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return l, nil
+}
+
+func (r *Repository) AddLoan(ctx context.Context, l *Loan) (*Loan, error) {
+	const op = "lending.Repository.AddLoan"
+
+	const user = `SELECT user_name FROM users WHERE user_id = $1`
+	err := r.db.QueryRowContext(ctx, user, l.UserID).Scan(&l.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("ERROR: user with id=%d does not exist\n", l.UserID)
+			return nil, fmt.Errorf("%s: %w", op, ErrUserNotExist)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	const copyInfo = `
+		SELECT c.barcode, c.format, c.movie_id, m.movie_name
+		FROM copies c
+		INNER JOIN movie m ON m.movie_id = c.movie_id AND m.deleted_at IS NULL
+		WHERE c.copy_id = $1`
+	err = r.db.QueryRowContext(ctx, copyInfo, l.CopyID).Scan(&l.Barcode, &l.Format, &l.FilmID, &l.FilmName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("ERROR: copy with id=%d does not exist\n", l.CopyID)
+			return nil, fmt.Errorf("%s: %w", op, ErrCopyNotExist)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	const query = `
+		INSERT INTO loans(copy_id, user_id, checked_out_at, due_at, renewals)
+		VALUES ($1, $2, $3, $4, $5)`
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, "loan_id", l.CopyID, l.UserID, l.CheckedOutAt,
+		l.DueAt, l.Renewals)
+	if err != nil {
+		// a copy has a single loan not returned
+		if r.dialect.IsUniqueViolation(err) {
+			log.Printf("ERROR: copy with id=%d is on loan\n", l.CopyID)
+			return nil, fmt.Errorf("%s: %w", op, ErrCopyOnLoan)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	l.ID = int32(id)
+
+	return l, nil
+}
+
+func (r *Repository) RenewLoan(ctx context.Context, id int32, dueAt time.Time) error {
+	const op = "lending.Repository.RenewLoan"
+
+	const query = `UPDATE loans SET due_at = $1, renewals = renewals + 1 WHERE loan_id = $2 AND returned_at IS NULL`
+
+	return r.updateLoan(ctx, op, query, dueAt, id)
+}
+
+func (r *Repository) ReturnLoan(ctx context.Context, id int32, returnedAt time.Time) error {
+	const op = "lending.Repository.ReturnLoan"
+
+	const query = `UPDATE loans SET returned_at = $1 WHERE loan_id = $2 AND returned_at IS NULL`
+
+	return r.updateLoan(ctx, op, query, returnedAt, id)
+}
+
+// updateLoan runs the update of a loan not returned yet.
+func (r *Repository) updateLoan(ctx context.Context, op string, query string, args ...any) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, ErrLoanNotExist)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetHolds(ctx context.Context, filmID int32) ([]*Hold, error) {
+	const op = "lending.Repository.GetHolds"
+
+	exists, err := r.filmExists(ctx, filmID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		log.Printf("ERROR: film with id=%d does not exist\n", filmID)
+		return nil, fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+	}
+
+	return r.getHolds(ctx, op, `h.movie_id = $1`, filmID)
+}
+
+func (r *Repository) GetUserHolds(ctx context.Context, userID int32) ([]*Hold, error) {
+	return r.getHolds(ctx, "lending.Repository.GetUserHolds", `h.user_id = $1`, userID)
+}
+
+func (r *Repository) AddHold(ctx context.Context, h *Hold) error {
+	const op = "lending.Repository.AddHold"
+
+	exists, err := r.filmExists(ctx, h.FilmID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		log.Printf("ERROR: film with id=%d does not exist\n", h.FilmID)
+		return fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+	}
+
+	h.CreatedAt = db.Now()
+	const query = `INSERT INTO holds(movie_id, user_id, created_at) VALUES ($1, $2, $3)`
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, "hold_id", h.FilmID, h.UserID, h.CreatedAt)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			log.Printf("ERROR: film with id=%d is on hold for user with id=%d\n", h.FilmID, h.UserID)
+			return fmt.Errorf("%s: %w", op, ErrHoldExists)
+		}
+
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	h.ID = int32(id)
+
+	return nil
+}
+
+func (r *Repository) DeleteHold(ctx context.Context, filmID int32, userID int32) error {
+	const op = "lending.Repository.DeleteHold"
+
+	const query = `DELETE FROM holds WHERE movie_id = $1 AND user_id = $2`
+	res, err := r.db.ExecContext(ctx, query, filmID, userID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by delete\n")
+		return fmt.Errorf("%s: %w", op, ErrHoldNotExist)
+	}
+
+	return nil
+}
@@ -0,0 +1,397 @@
+package lending
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ LendingService = (*Service)(nil)
+
+type Service struct {
+	repo   LendingRepository
+	policy Policy
+}
+
+func NewService(lr LendingRepository, p Policy) *Service {
+	return &Service{
+		repo:   lr,
+		policy: p,
+	}
+}
+
+func parseID(s string) (int32, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return 0, ErrIdInvalid
+	}
+
+	return int32(id), nil
+}
+
+// loan returns the loan made by the request, loans of other members do
+// not exist for members other than admins.
+func (s *Service) loan(ctx context.Context, req *LoanIdRequest) (*Loan, error) {
+	id, err := parseID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := s.repo.GetLoan(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to get loan from repository\n")
+		return nil, err
+	}
+	if !req.Admin && l.UserID != req.UserID {
+		log.Printf("ERROR: loan with id=%d is not made by user with id=%d\n", id, req.UserID)
+		return nil, ErrLoanNotExist
+	}
+
+	return l, nil
+}
+
+func (s *Service) GetCopies(ctx context.Context, req *FilmIdRequest) ([]*CopyResponse, error) {
+	const op = "lending.Service.GetCopies"
+
+	id, err := parseID(req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	copies, err := s.repo.GetCopies(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to get copies from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToCopyResponses(copies), nil
+}
+
+func (s *Service) AddCopy(ctx context.Context, req *AddCopyRequest) (*CopyResponse, error) {
+	const op = "lending.Service.AddCopy"
+
+	id, err := parseID(req.FilmID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	info := WithDefaults(&req.Info)
+	vErr := ValidateCopyInfo(info)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	c, err := s.repo.AddCopy(ctx, ToCopy(id, info))
+	if err != nil {
+		log.Printf("ERROR: failed to add copy to repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToCopyResponse(c), nil
+}
+
+func (s *Service) PatchCopy(ctx context.Context, req *CopyPatchRequest) (*CopyResponse, error) {
+	const op = "lending.Service.PatchCopy"
+
+	id, err := parseID(req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	vErr := ValidateCopyPatch(&req.Patch)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	err = s.repo.UpdateCopy(ctx, PatchToCopyUpdate(id, &req.Patch))
+	if err != nil {
+		log.Printf("ERROR: failed to update copy in repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	c, err := s.repo.GetCopy(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to get copy from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToCopyResponse(c), nil
+}
+
+func (s *Service) DeleteCopy(ctx context.Context, req *CopyIdRequest) error {
+	const op = "lending.Service.DeleteCopy"
+
+	id, err := parseID(req.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.repo.DeleteCopy(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to delete copy from repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Checkout lends the copy for a loan period. Available copies of a film
+// are kept for the members at the head of its hold queue, the hold of
+// the borrower is served by the loan.
+func (s *Service) Checkout(ctx context.Context, req *CheckoutRequest) (*LoanResponse, error) {
+	const op = "lending.Service.Checkout"
+
+	vErr := ValidateCheckoutInfo(&req.Info)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	c, err := s.repo.GetCopyByBarcode(ctx, req.Info.Barcode)
+	if err != nil {
+		log.Printf("ERROR: failed to get copy from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if c.OnLoan {
+		log.Printf("ERROR: copy with id=%d is on loan\n", c.ID)
+		return nil, fmt.Errorf("%s: %w", op, ErrCopyOnLoan)
+	}
+
+	userID := int32(req.Info.UserID)
+	holds, err := s.repo.GetHolds(ctx, c.FilmID)
+	if err != nil {
+		log.Printf("ERROR: failed to get holds from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	held := slices.IndexFunc(holds, func(h *Hold) bool { return h.UserID == userID })
+	ahead := held
+	if held == -1 {
+		ahead = len(holds)
+	}
+	// the copy is available, so every hold has the same count of them
+	if len(holds) != 0 && ahead >= holds[0].AvailableCopies {
+		log.Printf("ERROR: copy with id=%d is held for %d members ahead of user with id=%d\n", c.ID, ahead, userID)
+		return nil, fmt.Errorf("%s: %w", op, ErrCopyHeld)
+	}
+
+	now := db.Now()
+	l, err := s.repo.AddLoan(ctx, &Loan{
+		CopyID:       c.ID,
+		UserID:       userID,
+		CheckedOutAt: now,
+		DueAt:        now.Add(s.policy.LoanPeriod),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to add loan to repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if held != -1 {
+		err = s.repo.DeleteHold(ctx, c.FilmID, userID)
+		if err != nil {
+			log.Printf("ERROR: failed to delete hold from repository\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return ToLoanResponse(l, now), nil
+}
+
+func (s *Service) ReturnLoan(ctx context.Context, req *LoanIdRequest) (*LoanResponse, error) {
+	const op = "lending.Service.ReturnLoan"
+
+	l, err := s.loan(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if l.ReturnedAt != nil {
+		log.Printf("ERROR: loan with id=%d is already returned\n", l.ID)
+		return nil, fmt.Errorf("%s: %w", op, ErrLoanReturned)
+	}
+
+	now := db.Now()
+	err = s.repo.ReturnLoan(ctx, l.ID, now)
+	if err != nil {
+		log.Printf("ERROR: failed to return loan in repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	l.ReturnedAt = &now
+
+	return ToLoanResponse(l, now), nil
+}
+
+// RenewLoan extends the loan unless it was renewed too many times or
+// other members hold the film.
+func (s *Service) RenewLoan(ctx context.Context, req *LoanIdRequest) (*LoanResponse, error) {
+	const op = "lending.Service.RenewLoan"
+
+	l, err := s.loan(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if l.ReturnedAt != nil {
+		log.Printf("ERROR: loan with id=%d is already returned\n", l.ID)
+		return nil, fmt.Errorf("%s: %w", op, ErrLoanReturned)
+	}
+	if l.Renewals >= s.policy.MaxRenewals {
+		log.Printf("ERROR: loan with id=%d was renewed %d times\n", l.ID, l.Renewals)
+		return nil, fmt.Errorf("%s: %w", op, ErrRenewalLimit)
+	}
+
+	holds, err := s.repo.GetHolds(ctx, l.FilmID)
+	if err != nil {
+		log.Printf("ERROR: failed to get holds from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(holds) != 0 {
+		log.Printf("ERROR: film with id=%d is on hold\n", l.FilmID)
+		return nil, fmt.Errorf("%s: %w", op, ErrHoldsWaiting)
+	}
+
+	now := db.Now()
+	due := renewedDue(l, now, s.policy.LoanPeriod)
+	err = s.repo.RenewLoan(ctx, l.ID, due)
+	if err != nil {
+		log.Printf("ERROR: failed to renew loan in repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	l.DueAt = due
+	l.Renewals++
+
+	return ToLoanResponse(l, now), nil
+}
+
+func (s *Service) GetLoans(ctx context.Context, req *LoansRequest) ([]*LoanResponse, error) {
+	const op = "lending.Service.GetLoans"
+
+	vErr := ValidateLoansRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	// validated by ValidateLoansRequest
+	now := db.Now()
+	q := &LoanQuery{UserID: req.UserID}
+	if len(req.UserQuery) != 0 {
+		id, _ := strconv.Atoi(req.UserQuery)
+		q.UserID = int32(id)
+	}
+	switch req.Status {
+	case "", StatusActive:
+		q.Active = true
+	case StatusOverdue:
+		q.Active, q.DueBefore = true, now
+	}
+
+	loans, err := s.repo.GetLoans(ctx, q)
+	if err != nil {
+		log.Printf("ERROR: failed to get loans from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToLoanResponses(loans, now), nil
+}
+
+func (s *Service) GetHolds(ctx context.Context, req *FilmIdRequest) ([]*HoldResponse, error) {
+	const op = "lending.Service.GetHolds"
+
+	id, err := parseID(req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	holds, err := s.repo.GetHolds(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to get holds from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToHoldResponses(holds), nil
+}
+
+func (s *Service) GetUserHolds(ctx context.Context, req *UserRequest) ([]*HoldResponse, error) {
+	const op = "lending.Service.GetUserHolds"
+
+	holds, err := s.repo.GetUserHolds(ctx, req.UserID)
+	if err != nil {
+		log.Printf("ERROR: failed to get holds from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ToHoldResponses(holds), nil
+}
+
+// PlaceHold queues the user for a film with copies the user does not
+// have on loan.
+func (s *Service) PlaceHold(ctx context.Context, req *HoldRequest) (*HoldResponse, error) {
+	const op = "lending.Service.PlaceHold"
+
+	id, err := parseID(req.FilmID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	copies, err := s.repo.GetCopies(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to get copies from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(copies) == 0 {
+		log.Printf("ERROR: film with id=%d has no copies\n", id)
+		return nil, fmt.Errorf("%s: %w", op, ErrNoCopies)
+	}
+
+	loans, err := s.repo.GetLoans(ctx, &LoanQuery{UserID: req.UserID, FilmID: id, Active: true})
+	if err != nil {
+		log.Printf("ERROR: failed to get loans from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(loans) != 0 {
+		log.Printf("ERROR: film with id=%d is on loan to user with id=%d\n", id, req.UserID)
+		return nil, fmt.Errorf("%s: %w", op, ErrFilmBorrowed)
+	}
+
+	err = s.repo.AddHold(ctx, &Hold{FilmID: id, UserID: req.UserID})
+	if err != nil {
+		log.Printf("ERROR: failed to add hold to repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	holds, err := s.repo.GetHolds(ctx, id)
+	if err != nil {
+		log.Printf("ERROR: failed to get holds from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	i := slices.IndexFunc(holds, func(h *Hold) bool { return h.UserID == req.UserID })
+	if i == -1 {
+		log.Printf("ERROR: hold of user with id=%d for film with id=%d is gone\n", req.UserID, id)
+		return nil, fmt.Errorf("%s: %w", op, ErrHoldNotExist)
+	}
+
+	return ToHoldResponse(holds[i]), nil
+}
+
+func (s *Service) CancelHold(ctx context.Context, req *HoldRequest) error {
+	const op = "lending.Service.CancelHold"
+
+	id, err := parseID(req.FilmID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.repo.DeleteHold(ctx, id, req.UserID)
+	if err != nil {
+		log.Printf("ERROR: failed to delete hold from repository\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package lending
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+var testPolicy = Policy{LoanPeriod: 14 * 24 * time.Hour, MaxRenewals: 2}
+
+func TestService_AddCopy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	lm := NewMockLendingRepository(ctrl)
+
+	s := NewService(lm, testPolicy)
+
+	testTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	lm.EXPECT().AddCopy(gomock.Any(), &Copy{FilmID: 3, Format: FormatDVD, Barcode: "FL-0001", Condition: ConditionGood}).
+		DoAndReturn(func(ctx context.Context, c *Copy) (*Copy, error) {
+			c.ID, c.CreatedAt, c.UpdatedAt = 1, testTime, testTime
+			return c, nil
+		}).Times(1)
+
+	res, err := s.AddCopy(context.TODO(), &AddCopyRequest{FilmID: "3", Info: CopyInfo{Format: FormatDVD, Barcode: "FL-0001"}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := &CopyResponse{
+		ID:        1,
+		FilmID:    3,
+		Format:    FormatDVD,
+		Barcode:   "FL-0001",
+		Condition: ConditionGood,
+		Available: true,
+		CreatedAt: "2024-03-01T12:00:00Z",
+		UpdatedAt: "2024-03-01T12:00:00Z",
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	var ve *util.ValidationError
+	_, err = s.AddCopy(context.TODO(), &AddCopyRequest{FilmID: "3", Info: CopyInfo{Format: "laserdisc", Barcode: "FL 0002"}})
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestService_Checkout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	lm := NewMockLendingRepository(ctrl)
+
+	s := NewService(lm, testPolicy)
+
+	c := &Copy{ID: 1, FilmID: 3, Format: FormatDVD, Barcode: "FL-0001"}
+	// one copy is available and user 8 is second in the queue
+	holds := []*Hold{
+		{ID: 1, FilmID: 3, UserID: 7, Position: 1, AvailableCopies: 1},
+		{ID: 2, FilmID: 3, UserID: 8, Position: 2, AvailableCopies: 1},
+	}
+	lm.EXPECT().GetCopyByBarcode(gomock.Any(), "FL-0001").Return(c, nil).Times(3)
+	lm.EXPECT().GetHolds(gomock.Any(), int32(3)).Return(holds, nil).Times(3)
+
+	for _, id := range []int{8, 9} {
+		_, err := s.Checkout(context.TODO(), &CheckoutRequest{Info: CheckoutInfo{Barcode: "FL-0001", UserID: id}})
+		if !errors.Is(err, ErrCopyHeld) {
+			t.Errorf("Expected %v, got %v", ErrCopyHeld, err)
+		}
+	}
+
+	lm.EXPECT().AddLoan(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, l *Loan) (*Loan, error) {
+		if l.CopyID != 1 || l.UserID != 7 || l.DueAt.Sub(l.CheckedOutAt) != testPolicy.LoanPeriod {
+			t.Errorf("Expected loan of copy 1 to user 7 for the loan period, got %+v", l)
+		}
+		l.ID = 1
+		return l, nil
+	}).Times(1)
+	// the hold is served by the loan
+	lm.EXPECT().DeleteHold(gomock.Any(), int32(3), int32(7)).Return(nil).Times(1)
+
+	res, err := s.Checkout(context.TODO(), &CheckoutRequest{Info: CheckoutInfo{Barcode: "FL-0001", UserID: 7}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if res.ID != 1 || res.Overdue || len(res.ReturnedAt) != 0 {
+		t.Errorf("Expected active loan 1, got %+v", res)
+	}
+
+	lm.EXPECT().GetCopyByBarcode(gomock.Any(), "FL-0002").Return(&Copy{ID: 2, FilmID: 3, OnLoan: true}, nil).Times(1)
+	_, err = s.Checkout(context.TODO(), &CheckoutRequest{Info: CheckoutInfo{Barcode: "FL-0002", UserID: 7}})
+	if !errors.Is(err, ErrCopyOnLoan) {
+		t.Errorf("Expected %v, got %v", ErrCopyOnLoan, err)
+	}
+}
+
+func TestService_RenewLoan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	lm := NewMockLendingRepository(ctrl)
+
+	s := NewService(lm, testPolicy)
+
+	due := time.Now().Add(24 * time.Hour).UTC()
+	lm.EXPECT().GetLoan(gomock.Any(), int32(1)).DoAndReturn(func(ctx context.Context, id int32) (*Loan, error) {
+		return &Loan{ID: 1, UserID: 7, FilmID: 3, DueAt: due}, nil
+	}).Times(4)
+	lm.EXPECT().GetHolds(gomock.Any(), int32(3)).Return([]*Hold{}, nil).Times(2)
+	lm.EXPECT().RenewLoan(gomock.Any(), int32(1), due.Add(testPolicy.LoanPeriod)).Return(nil).Times(2)
+
+	res, err := s.RenewLoan(context.TODO(), &LoanIdRequest{UserID: 7, ID: "1"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if res.Renewals != 1 {
+		t.Errorf("Expected 1 renewal, got %+v", res)
+	}
+
+	// admins renew loans of members
+	if _, err := s.RenewLoan(context.TODO(), &LoanIdRequest{UserID: 1, Admin: true, ID: "1"}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	// loans of other members do not exist for the member
+	_, err = s.RenewLoan(context.TODO(), &LoanIdRequest{UserID: 8, ID: "1"})
+	if !errors.Is(err, ErrLoanNotExist) {
+		t.Errorf("Expected %v, got %v", ErrLoanNotExist, err)
+	}
+
+	lm.EXPECT().GetHolds(gomock.Any(), int32(3)).Return([]*Hold{{ID: 1, FilmID: 3, UserID: 8}}, nil).Times(1)
+	_, err = s.RenewLoan(context.TODO(), &LoanIdRequest{UserID: 7, ID: "1"})
+	if !errors.Is(err, ErrHoldsWaiting) {
+		t.Errorf("Expected %v, got %v", ErrHoldsWaiting, err)
+	}
+
+	lm.EXPECT().GetLoan(gomock.Any(), int32(2)).Return(&Loan{ID: 2, UserID: 7, FilmID: 3, Renewals: 2}, nil).Times(1)
+	_, err = s.RenewLoan(context.TODO(), &LoanIdRequest{UserID: 7, ID: "2"})
+	if !errors.Is(err, ErrRenewalLimit) {
+		t.Errorf("Expected %v, got %v", ErrRenewalLimit, err)
+	}
+}
+
+func TestService_GetLoans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	lm := NewMockLendingRepository(ctrl)
+
+	s := NewService(lm, testPolicy)
+
+	lm.EXPECT().GetLoans(gomock.Any(), &LoanQuery{UserID: 7, Active: true}).Return([]*Loan{}, nil).Times(1)
+	lm.EXPECT().GetLoans(gomock.Any(), &LoanQuery{UserID: 2}).Return([]*Loan{}, nil).Times(1)
+	lm.EXPECT().GetLoans(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, q *LoanQuery) ([]*Loan, error) {
+		if !q.Active || q.DueBefore.IsZero() {
+			t.Errorf("Expected overdue query, got %+v", q)
+		}
+		return []*Loan{{ID: 1, DueAt: q.DueBefore.Add(-time.Hour)}}, nil
+	}).Times(1)
+
+	if _, err := s.GetLoans(context.TODO(), &LoansRequest{UserID: 7}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if _, err := s.GetLoans(context.TODO(), &LoansRequest{UserQuery: "2", Status: StatusAll}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	res, err := s.GetLoans(context.TODO(), &LoansRequest{Status: StatusOverdue})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(res) != 1 || !res[0].Overdue {
+		t.Errorf("Expected an overdue loan, got %+v", res)
+	}
+
+	var ve *util.ValidationError
+	_, err = s.GetLoans(context.TODO(), &LoansRequest{UserQuery: "0", Status: "late"})
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestService_PlaceHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	lm := NewMockLendingRepository(ctrl)
+
+	s := NewService(lm, testPolicy)
+
+	lm.EXPECT().GetCopies(gomock.Any(), int32(4)).Return([]*Copy{}, nil).Times(1)
+	_, err := s.PlaceHold(context.TODO(), &HoldRequest{UserID: 7, FilmID: "4"})
+	if !errors.Is(err, ErrNoCopies) {
+		t.Errorf("Expected %v, got %v", ErrNoCopies, err)
+	}
+
+	lm.EXPECT().GetCopies(gomock.Any(), int32(3)).Return([]*Copy{{ID: 1, FilmID: 3, OnLoan: true}}, nil).Times(2)
+	lm.EXPECT().GetLoans(gomock.Any(), &LoanQuery{UserID: 7, FilmID: 3, Active: true}).
+		Return([]*Loan{{ID: 1, UserID: 7, FilmID: 3}}, nil).Times(1)
+	_, err = s.PlaceHold(context.TODO(), &HoldRequest{UserID: 7, FilmID: "3"})
+	if !errors.Is(err, ErrFilmBorrowed) {
+		t.Errorf("Expected %v, got %v", ErrFilmBorrowed, err)
+	}
+
+	testTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	lm.EXPECT().GetLoans(gomock.Any(), &LoanQuery{UserID: 8, FilmID: 3, Active: true}).Return([]*Loan{}, nil).Times(1)
+	lm.EXPECT().AddHold(gomock.Any(), &Hold{FilmID: 3, UserID: 8}).Return(nil).Times(1)
+	lm.EXPECT().GetHolds(gomock.Any(), int32(3)).Return([]*Hold{
+		{ID: 1, FilmID: 3, UserID: 9, Position: 1, CreatedAt: testTime, FilmName: "Speed", Username: "carol"},
+		{ID: 2, FilmID: 3, UserID: 8, Position: 2, CreatedAt: testTime, FilmName: "Speed", Username: "bob"},
+	}, nil).Times(1)
+
+	res, err := s.PlaceHold(context.TODO(), &HoldRequest{UserID: 8, FilmID: "3"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := &HoldResponse{
+		Film:     FilmShortResponse{ID: 3, Name: "Speed"},
+		User:     UserShortResponse{ID: 8, Username: "bob"},
+		Position: 2,
+		Ready:    false,
+		PlacedAt: "2024-03-01T12:00:00Z",
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+}
@@ -0,0 +1,111 @@
+package lending
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var (
+	formats    = []string{FormatDVD, FormatBluRay, FormatUHD, FormatVHS}
+	conditions = []string{ConditionNew, ConditionGood, ConditionFair, ConditionPoor, ConditionDamaged}
+	statuses   = []string{StatusActive, StatusOverdue, StatusAll}
+)
+
+// ValidateCopyInfo expects the defaults applied, see WithDefaults.
+func ValidateCopyInfo(ci *CopyInfo) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	validateFormat(ve, ci.Format)
+	validateBarcode(ve, ci.Barcode)
+	validateCondition(ve, ci.Condition)
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateCopyPatch(cp *CopyPatch) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if cp.Format.Null {
+		ve.AddViolation("format empty (expected one of [dvd, bluray, uhd, vhs])")
+	} else if cp.Format.Set {
+		validateFormat(ve, cp.Format.Value)
+	}
+
+	if cp.Condition.Null {
+		ve.AddViolation("condition empty (expected one of [new, good, fair, poor, damaged])")
+	} else if cp.Condition.Set {
+		validateCondition(ve, cp.Condition.Value)
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateCheckoutInfo(ci *CheckoutInfo) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(ci.Barcode) == 0 {
+		ve.AddViolation("barcode empty")
+	}
+
+	if ci.UserID < 1 {
+		ve.AddViolation("userId empty (expected positive integer)")
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func ValidateLoansRequest(req *LoansRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(req.Status) != 0 && !slices.Contains(statuses, req.Status) {
+		ve.AddViolation("incorrect status (expected one of [active, overdue, all])")
+	}
+
+	if n, err := strconv.ParseUint(req.UserQuery, 10, 31); len(req.UserQuery) != 0 && (err != nil || n == 0) {
+		ve.AddViolation("incorrect userId (expected positive integer)")
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func validateFormat(ve *util.ValidationError, format string) {
+	if !slices.Contains(formats, format) {
+		ve.AddViolation("incorrect format (expected one of [dvd, bluray, uhd, vhs])")
+	}
+}
+
+func validateBarcode(ve *util.ValidationError, barcode string) {
+	if len(barcode) == 0 {
+		ve.AddViolation("barcode empty")
+	} else if len(barcode) > maxBarcodeLength {
+		ve.AddViolation(fmt.Sprintf("barcode too long (expected at most %d characters)", maxBarcodeLength))
+	} else if strings.ContainsFunc(barcode, func(r rune) bool { return r <= ' ' || r > '~' }) {
+		ve.AddViolation("incorrect barcode (expected printable ASCII characters without spaces)")
+	}
+}
+
+func validateCondition(ve *util.ValidationError, condition string) {
+	if !slices.Contains(conditions, condition) {
+		ve.AddViolation("incorrect condition (expected one of [new, good, fair, poor, damaged])")
+	}
+}
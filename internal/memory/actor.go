@@ -0,0 +1,273 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+)
+
+var _ actor.ActorRepository = (*ActorRepository)(nil)
+
+type ActorRepository struct {
+	store *Store
+}
+
+func NewActorRepository(s *Store) *ActorRepository {
+	return &ActorRepository{
+		store: s,
+	}
+}
+
+func (r *ActorRepository) Atomic(ctx context.Context, fn func(ar actor.ActorRepository, rec audit.Recorder) error) error {
+	return r.store.atomic(func(c *Store) error {
+		return fn(NewActorRepository(c), audit.NewService(NewAuditRepository(c)))
+	})
+}
+
+func (r *ActorRepository) toActor(ar *actorRecord) *actor.Actor {
+	a := &actor.Actor{
+		ID:        ar.id,
+		Name:      ar.name,
+		Sex:       ar.sex,
+		Birthday:  ar.birthday,
+		Version:   ar.version,
+		CreatedAt: ar.createdAt,
+		UpdatedAt: ar.updatedAt,
+		CreatedBy: ar.createdBy,
+		UpdatedBy: ar.updatedBy,
+		DeletedAt: ar.deletedAt,
+		DeletedBy: ar.deletedBy,
+	}
+	for _, v := range r.store.actorFilms(ar.id) {
+		a.Films = append(a.Films, v.name)
+	}
+
+	return a
+}
+
+func (r *ActorRepository) GetActor(ctx context.Context, id int32) (*actor.Actor, error) {
+	const op = "memory.ActorRepository.GetActor"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ar, ok := r.store.actor(id)
+	if !ok {
+		log.Printf("ERROR: actor with id=%d does not exist\n", id)
+		return nil, fmt.Errorf("%s: %w", op, actor.ErrActorNotExist)
+	}
+
+	return r.toActor(ar), nil
+}
+
+func (r *ActorRepository) AddActor(ctx context.Context, a *actor.Actor) (*actor.Actor, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.actorSeq++
+	a.ID = r.store.actorSeq
+	a.Version = 1
+	ar := &actorRecord{
+		stamp:    newStamp(ctx),
+		id:       a.ID,
+		name:     a.Name,
+		sex:      a.Sex,
+		birthday: a.Birthday,
+		version:  a.Version,
+	}
+	r.store.actors[a.ID] = ar
+	a.CreatedAt, a.UpdatedAt = ar.createdAt, ar.updatedAt
+	a.CreatedBy, a.UpdatedBy = ar.createdBy, ar.updatedBy
+
+	return a, nil
+}
+
+func (r *ActorRepository) DeleteActor(ctx context.Context, id int32, version int32) error {
+	const op = "memory.ActorRepository.DeleteActor"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ar, ok := r.store.actor(id)
+	if !ok {
+		log.Printf("ERROR: zero rows affected by deletion\n")
+		return fmt.Errorf("%s: %w", op, actor.ErrActorNotExist)
+	}
+	if version != 0 && ar.version != version {
+		log.Printf("ERROR: actor with id=%d is not of version %d\n", id, version)
+		return fmt.Errorf("%s: %w", op, actor.ErrVersionMismatch)
+	}
+
+	// the actor goes to the trash, its bindings stay for a restore
+	ar.trash(ctx)
+
+	return nil
+}
+
+func (r *ActorRepository) UpdateActor(ctx context.Context, au *actor.ActorUpdate) error {
+	const op = "memory.ActorRepository.UpdateActor"
+
+	if au.Name == nil && au.Sex == nil && au.Birthday == nil {
+		log.Print("ERROR: no updates to apply\n")
+		return fmt.Errorf("%s: %w", op, actor.ErrEmptyUpdate)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ar, ok := r.store.actor(au.ID)
+	if !ok {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, actor.ErrActorNotExist)
+	}
+	if au.Version != 0 && ar.version != au.Version {
+		log.Printf("ERROR: actor with id=%d is not of version %d\n", au.ID, au.Version)
+		return fmt.Errorf("%s: %w", op, actor.ErrVersionMismatch)
+	}
+	ar.version++
+	ar.touch(ctx)
+
+	if au.Name != nil {
+		ar.name = *au.Name
+	}
+	if au.Sex != nil {
+		ar.sex = *au.Sex
+	}
+	if au.Birthday != nil {
+		ar.birthday = *au.Birthday
+	}
+
+	return nil
+}
+
+func (r *ActorRepository) GetActors(ctx context.Context) ([]*actor.Actor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	actors := make([]*actor.Actor, 0, len(r.store.actors))
+	for _, v := range r.store.actors {
+		if !v.deleted() {
+			actors = append(actors, r.toActor(v))
+		}
+	}
+	sort.Slice(actors, func(i, j int) bool {
+		return actors[i].ID < actors[j].ID
+	})
+
+	return actors, nil
+}
+
+func (r *ActorRepository) RestoreActor(ctx context.Context, id int32) error {
+	const op = "memory.ActorRepository.RestoreActor"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ar, ok := r.store.actors[id]
+	if !ok || !ar.deleted() {
+		log.Printf("ERROR: actor with id=%d is not in the trash\n", id)
+		return fmt.Errorf("%s: %w", op, actor.ErrActorNotExist)
+	}
+	ar.restore(ctx)
+
+	return nil
+}
+
+func (r *ActorRepository) GetDeletedActors(ctx context.Context) ([]*actor.Actor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	actors := make([]*actor.Actor, 0)
+	for _, ar := range r.store.actors {
+		if ar.deleted() {
+			actors = append(actors, r.toActor(ar))
+		}
+	}
+	sort.Slice(actors, func(i, j int) bool {
+		a, b := actors[i], actors[j]
+		if !a.DeletedAt.Equal(*b.DeletedAt) {
+			return a.DeletedAt.After(*b.DeletedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	return actors, nil
+}
+
+func (r *ActorRepository) PurgeActors(ctx context.Context, before time.Time) ([]int32, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ids := make([]int32, 0)
+	for id, ar := range r.store.actors {
+		if !ar.purgeable(before) {
+			continue
+		}
+
+		delete(r.store.actors, id)
+		r.store.removeBindings(func(b binding) bool {
+			return b.actorID != id
+		})
+		r.store.follows = slices.DeleteFunc(r.store.follows, func(f *followRecord) bool {
+			return f.actorID == id
+		})
+		r.store.notifications = slices.DeleteFunc(r.store.notifications, func(n *notificationRecord) bool {
+			return n.actorID == id
+		})
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+// ExportActors copies the selected actors under the lock and calls fn once
+// it is released, so that a slow consumer does not block writers.
+func (r *ActorRepository) ExportActors(ctx context.Context, q *actor.ExportQuery, fn func(a *actor.Actor, films []*actor.FilmShort) error) error {
+	type entry struct {
+		actor *actor.Actor
+		films []*actor.FilmShort
+	}
+
+	r.store.mu.RLock()
+	var actors []entry
+	for _, ar := range r.store.actors {
+		if ar.deleted() || ar.id <= q.After {
+			continue
+		}
+
+		films := r.store.actorFilms(ar.id)
+		sort.Slice(films, func(i, j int) bool {
+			return films[i].id < films[j].id
+		})
+		e := entry{actor: r.toActor(ar)}
+		e.actor.Films = nil
+		for _, v := range films {
+			e.actor.Films = append(e.actor.Films, v.name)
+			e.films = append(e.films, &actor.FilmShort{ID: v.id, Name: v.name})
+		}
+		actors = append(actors, e)
+	}
+	r.store.mu.RUnlock()
+
+	sort.Slice(actors, func(i, j int) bool {
+		return actors[i].actor.ID < actors[j].actor.ID
+	})
+	if q.Limit != 0 && len(actors) > q.Limit {
+		actors = actors[:q.Limit]
+	}
+
+	for _, v := range actors {
+		if err := fn(v.actor, v.films); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Coderovshik/film-library/internal/audit"
+)
+
+var _ audit.AuditRepository = (*AuditRepository)(nil)
+
+type AuditRepository struct {
+	store *Store
+}
+
+func NewAuditRepository(s *Store) *AuditRepository {
+	return &AuditRepository{
+		store: s,
+	}
+}
+
+func (r *AuditRepository) AddEntry(ctx context.Context, e *audit.Entry) (*audit.Entry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.auditSeq++
+	e.ID = r.store.auditSeq
+	r.store.audit = append(r.store.audit, *e)
+
+	return e, nil
+}
+
+func (r *AuditRepository) GetEntries(ctx context.Context, q *audit.Query) ([]*audit.Entry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entries := make([]*audit.Entry, 0)
+	// entries are appended in id order, walk them newest first
+	for i := len(r.store.audit) - 1; i >= 0 && len(entries) < q.Limit; i-- {
+		e := r.store.audit[i]
+		if !matches(&e, q) {
+			continue
+		}
+
+		entries = append(entries, &e)
+	}
+
+	return entries, nil
+}
+
+func matches(e *audit.Entry, q *audit.Query) bool {
+	switch {
+	case len(q.Entity) != 0 && e.Entity != q.Entity:
+		return false
+	case q.EntityID != 0 && e.EntityID != q.EntityID:
+		return false
+	case q.UserID != 0 && (e.UserID == nil || *e.UserID != q.UserID):
+		return false
+	case len(q.Action) != 0 && e.Action != q.Action:
+		return false
+	case e.OccurredAt.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !e.OccurredAt.Before(q.Until):
+		return false
+	case q.BeforeID != 0 && e.ID >= q.BeforeID:
+		return false
+	}
+
+	return true
+}
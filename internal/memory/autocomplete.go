@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/autocomplete"
+)
+
+var _ autocomplete.NameSource = (*nameSource)(nil)
+
+// AutocompleteRepository suggests names with the Go fallback index.
+type AutocompleteRepository struct {
+	*autocomplete.Fallback
+}
+
+func NewAutocompleteRepository(s *Store) *AutocompleteRepository {
+	return &AutocompleteRepository{
+		Fallback: autocomplete.NewFallback(&nameSource{store: s}),
+	}
+}
+
+type nameSource struct {
+	store *Store
+}
+
+// Stamp counts the names and takes the latest id and change, as the
+// database source does.
+func (n *nameSource) Stamp(ctx context.Context, kind string) (string, error) {
+	n.store.mu.RLock()
+	defer n.store.mu.RUnlock()
+
+	var count int
+	var maxID int32
+	var updatedAt time.Time
+	add := func(id int32, s *stamp) {
+		if s.deleted() {
+			return
+		}
+		count++
+		maxID = max(maxID, id)
+		if s.updatedAt.After(updatedAt) {
+			updatedAt = s.updatedAt
+		}
+	}
+
+	switch kind {
+	case autocomplete.KindFilm:
+		for _, v := range n.store.films {
+			add(v.id, &v.stamp)
+		}
+	case autocomplete.KindActor:
+		for _, v := range n.store.actors {
+			add(v.id, &v.stamp)
+		}
+	default:
+		return "", fmt.Errorf("memory.nameSource.Stamp: unknown kind %q", kind)
+	}
+
+	return fmt.Sprintf("%d/%d/%s", count, maxID, updatedAt.Format(time.RFC3339Nano)), nil
+}
+
+func (n *nameSource) Names(ctx context.Context, kind string) ([]*autocomplete.Entry, error) {
+	n.store.mu.RLock()
+	defer n.store.mu.RUnlock()
+
+	entries := make([]*autocomplete.Entry, 0)
+	switch kind {
+	case autocomplete.KindFilm:
+		for _, v := range n.store.films {
+			if !v.deleted() {
+				entries = append(entries, &autocomplete.Entry{ID: v.id, Name: v.name})
+			}
+		}
+	case autocomplete.KindActor:
+		for _, v := range n.store.actors {
+			if !v.deleted() {
+				entries = append(entries, &autocomplete.Entry{ID: v.id, Name: v.name})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("memory.nameSource.Names: unknown kind %q", kind)
+	}
+
+	return entries, nil
+}
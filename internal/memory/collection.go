@@ -0,0 +1,253 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Coderovshik/film-library/internal/collection"
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ collection.CollectionRepository = (*CollectionRepository)(nil)
+
+type CollectionRepository struct {
+	store *Store
+}
+
+func NewCollectionRepository(s *Store) *CollectionRepository {
+	return &CollectionRepository{
+		store: s,
+	}
+}
+
+func (r *CollectionRepository) toCollection(cr *collectionRecord) *collection.Collection {
+	return &collection.Collection{
+		ID:          cr.id,
+		UserID:      cr.userID,
+		Name:        cr.name,
+		Description: cr.description,
+		Kind:        cr.kind,
+		Visibility:  cr.visibility,
+		ShareToken:  cr.shareToken,
+		FilmCount:   len(r.store.entries[cr.id]),
+		CreatedAt:   cr.createdAt,
+		UpdatedAt:   cr.updatedAt,
+	}
+}
+
+func (r *CollectionRepository) GetCollections(ctx context.Context, q *collection.Query) ([]*collection.Collection, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	collections := make([]*collection.Collection, 0)
+	for _, v := range r.store.collections {
+		if (q.UserID == 0 || v.userID == q.UserID) && (len(q.Visibility) == 0 || v.visibility == q.Visibility) {
+			collections = append(collections, r.toCollection(v))
+		}
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		a, b := collections[i], collections[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	return collections, nil
+}
+
+func (r *CollectionRepository) GetCollection(ctx context.Context, id int32) (*collection.Collection, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	cr, ok := r.store.collections[id]
+	if !ok {
+		return nil, collection.ErrCollectionNotExist
+	}
+
+	return r.toCollection(cr), nil
+}
+
+func (r *CollectionRepository) GetCollectionByToken(ctx context.Context, token string) (*collection.Collection, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, v := range r.store.collections {
+		if v.shareToken == token {
+			return r.toCollection(v), nil
+		}
+	}
+
+	return nil, collection.ErrCollectionNotExist
+}
+
+// taken checks the unique constraints of the lists table, it is called
+// with the store locked.
+func (r *CollectionRepository) taken(id, userID int32, name, kind string) error {
+	for _, v := range r.store.collections {
+		if v.id == id || v.userID != userID {
+			continue
+		}
+		if v.name == name {
+			return collection.ErrNameTaken
+		}
+		if kind != collection.KindCustom && v.kind == kind {
+			return collection.ErrKindTaken
+		}
+	}
+
+	return nil
+}
+
+func (r *CollectionRepository) AddCollection(ctx context.Context, c *collection.Collection) (*collection.Collection, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.taken(0, c.UserID, c.Name, c.Kind); err != nil {
+		return nil, err
+	}
+
+	r.store.collectionSeq++
+	c.ID = r.store.collectionSeq
+	c.CreatedAt = db.Now()
+	c.UpdatedAt = c.CreatedAt
+	c.FilmCount = 0
+	r.store.collections[c.ID] = &collectionRecord{
+		id:          c.ID,
+		userID:      c.UserID,
+		name:        c.Name,
+		description: c.Description,
+		kind:        c.Kind,
+		visibility:  c.Visibility,
+		shareToken:  c.ShareToken,
+		createdAt:   c.CreatedAt,
+		updatedAt:   c.UpdatedAt,
+	}
+
+	return c, nil
+}
+
+func (r *CollectionRepository) UpdateCollection(ctx context.Context, cu *collection.CollectionUpdate) error {
+	if cu.Name == nil && cu.Description == nil && cu.Visibility == nil {
+		return collection.ErrEmptyUpdate
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	cr, ok := r.store.collections[cu.ID]
+	if !ok {
+		return collection.ErrCollectionNotExist
+	}
+
+	if cu.Name != nil {
+		if err := r.taken(cr.id, cr.userID, *cu.Name, collection.KindCustom); err != nil {
+			return err
+		}
+		cr.name = *cu.Name
+	}
+	if cu.Description != nil {
+		cr.description = *cu.Description
+	}
+	if cu.Visibility != nil {
+		cr.visibility = *cu.Visibility
+	}
+	cr.updatedAt = db.Now()
+
+	return nil
+}
+
+func (r *CollectionRepository) DeleteCollection(ctx context.Context, id int32) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.collections[id]; !ok {
+		return collection.ErrCollectionNotExist
+	}
+	delete(r.store.collections, id)
+	delete(r.store.entries, id)
+
+	return nil
+}
+
+func (r *CollectionRepository) GetEntries(ctx context.Context, id int32) ([]*collection.Entry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entries := make([]*collection.Entry, 0, len(r.store.entries[id]))
+	for i, v := range r.store.entries[id] {
+		fr := r.store.films[v.filmID]
+		entries = append(entries, &collection.Entry{
+			CollectionID: id,
+			FilmID:       v.filmID,
+			Position:     i + 1,
+			Note:         v.note,
+			AddedAt:      v.addedAt,
+			FilmName:     fr.name,
+			ReleaseDate:  fr.releaseDate,
+			Rating:       fr.rating,
+			Available:    !fr.deleted(),
+		})
+	}
+
+	return entries, nil
+}
+
+func (r *CollectionRepository) PutEntry(ctx context.Context, e *collection.Entry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	cr, ok := r.store.collections[e.CollectionID]
+	if !ok {
+		return collection.ErrCollectionNotExist
+	}
+
+	entries := r.store.entries[cr.id]
+	i := slices.IndexFunc(entries, func(v *entryRecord) bool { return v.filmID == e.FilmID })
+
+	var er *entryRecord
+	if i >= 0 {
+		er = entries[i]
+		entries = slices.Delete(entries, i, i+1)
+	} else {
+		if _, ok := r.store.film(e.FilmID); !ok {
+			return collection.ErrFilmNotExist
+		}
+		er = &entryRecord{filmID: e.FilmID, addedAt: db.Now()}
+	}
+	er.note = e.Note
+
+	// position 0 keeps an existing film in place and appends a new one
+	pos := e.Position - 1
+	if e.Position == 0 {
+		pos = i
+	}
+	if pos < 0 || pos > len(entries) {
+		pos = len(entries)
+	}
+	r.store.entries[cr.id] = slices.Insert(entries, pos, er)
+	cr.updatedAt = db.Now()
+
+	return nil
+}
+
+func (r *CollectionRepository) DeleteEntry(ctx context.Context, id int32, filmID int32) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	cr, ok := r.store.collections[id]
+	if !ok {
+		return collection.ErrCollectionNotExist
+	}
+
+	entries := r.store.entries[id]
+	i := slices.IndexFunc(entries, func(v *entryRecord) bool { return v.filmID == filmID })
+	if i < 0 {
+		return collection.ErrEntryNotExist
+	}
+	r.store.entries[id] = slices.Delete(entries, i, i+1)
+	cr.updatedAt = db.Now()
+
+	return nil
+}
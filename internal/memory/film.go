@@ -0,0 +1,547 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+var _ film.FilmRepository = (*FilmRepository)(nil)
+
+type FilmRepository struct {
+	store *Store
+}
+
+func NewFilmRepository(s *Store) *FilmRepository {
+	return &FilmRepository{
+		store: s,
+	}
+}
+
+func (r *FilmRepository) Atomic(ctx context.Context, fn func(fr film.FilmRepository, rec audit.Recorder) error) error {
+	return r.store.atomic(func(c *Store) error {
+		return fn(NewFilmRepository(c), audit.NewService(NewAuditRepository(c)))
+	})
+}
+
+func (r *FilmRepository) toFilm(fr *filmRecord) *film.Film {
+	f := &film.Film{
+		ID:          fr.id,
+		Name:        fr.name,
+		Description: fr.description,
+		ReleaseDate: fr.releaseDate,
+		Rating:      fr.rating,
+		Version:     fr.version,
+		CreatedAt:   fr.createdAt,
+		UpdatedAt:   fr.updatedAt,
+		CreatedBy:   fr.createdBy,
+		UpdatedBy:   fr.updatedBy,
+		DeletedAt:   fr.deletedAt,
+		DeletedBy:   fr.deletedBy,
+	}
+	for _, v := range r.store.filmActors(fr.id) {
+		f.Actors = append(f.Actors, v.name)
+	}
+	if len(fr.genres) != 0 {
+		f.Genres = slices.Clone(fr.genres)
+	}
+
+	return f
+}
+
+func (r *FilmRepository) GetFilm(ctx context.Context, id int32) (*film.Film, error) {
+	const op = "memory.FilmRepository.GetFilm"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	fr, ok := r.store.film(id)
+	if !ok {
+		log.Printf("ERROR: film with id=%d does not exist\n", id)
+		return nil, fmt.Errorf("%s: %w", op, film.ErrFilmNotExist)
+	}
+
+	return r.toFilm(fr), nil
+}
+
+func (r *FilmRepository) AddFilm(ctx context.Context, f *film.Film) (*film.Film, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.filmSeq++
+	f.ID = r.store.filmSeq
+	f.Version = 1
+	fr := &filmRecord{
+		stamp:       newStamp(ctx),
+		id:          f.ID,
+		name:        f.Name,
+		description: f.Description,
+		releaseDate: f.ReleaseDate,
+		rating:      f.Rating,
+		version:     f.Version,
+	}
+	r.store.setGenres(fr, f.Genres)
+	r.store.films[f.ID] = fr
+	f.CreatedAt, f.UpdatedAt = fr.createdAt, fr.updatedAt
+	f.CreatedBy, f.UpdatedBy = fr.createdBy, fr.updatedBy
+
+	return f, nil
+}
+
+func (r *FilmRepository) DeleteFilm(ctx context.Context, id int32, version int32) error {
+	const op = "memory.FilmRepository.DeleteFilm"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	fr, ok := r.store.film(id)
+	if !ok {
+		log.Printf("ERROR: zero rows affected by deletion\n")
+		return fmt.Errorf("%s: %w", op, film.ErrFilmNotExist)
+	}
+	if version != 0 && fr.version != version {
+		log.Printf("ERROR: film with id=%d is not of version %d\n", id, version)
+		return fmt.Errorf("%s: %w", op, film.ErrVersionMismatch)
+	}
+
+	// the film goes to the trash, its bindings stay for a restore
+	fr.trash(ctx)
+
+	return nil
+}
+
+func (r *FilmRepository) UpdateFilm(ctx context.Context, fu *film.FilmUpdate) error {
+	const op = "memory.FilmRepository.UpdateFilm"
+
+	if fu.Name == nil && fu.Description == nil && fu.ReleaseDate == nil && fu.Rating == nil && fu.Genres == nil {
+		log.Print("ERROR: no updates to apply\n")
+		return fmt.Errorf("%s: %w", op, film.ErrEmptyUpdate)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	fr, ok := r.store.film(fu.ID)
+	if !ok {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, film.ErrFilmNotExist)
+	}
+	if fu.Version != 0 && fr.version != fu.Version {
+		log.Printf("ERROR: film with id=%d is not of version %d\n", fu.ID, fu.Version)
+		return fmt.Errorf("%s: %w", op, film.ErrVersionMismatch)
+	}
+	fr.version++
+	fr.touch(ctx)
+
+	if fu.Name != nil {
+		fr.name = *fu.Name
+	}
+	if fu.Description != nil {
+		fr.description = *fu.Description
+	}
+	if fu.ReleaseDate != nil {
+		fr.releaseDate = *fu.ReleaseDate
+	}
+	if fu.Rating != nil {
+		fr.rating = *fu.Rating
+	}
+	if fu.Genres != nil {
+		r.store.setGenres(fr, *fu.Genres)
+	}
+
+	return nil
+}
+
+var filmLess = map[string]func(a, b *film.Film) bool{
+	"name": func(a, b *film.Film) bool {
+		return a.Name < b.Name
+	},
+	"rating": func(a, b *film.Film) bool {
+		return a.Rating < b.Rating
+	},
+	"releasedate": func(a, b *film.Film) bool {
+		return a.ReleaseDate.Before(b.ReleaseDate)
+	},
+}
+
+// matches reports whether the film satisfies the filters of the query,
+// it is called with the store locked.
+func (r *FilmRepository) matches(q *film.Query, fr *filmRecord) bool {
+	if fr.deleted() {
+		return false
+	}
+	if len(q.Film) != 0 {
+		if q.FilmExact && fr.name != q.Film || !q.FilmExact && !strings.Contains(fr.name, q.Film) {
+			return false
+		}
+	}
+	if q.RatingMin != nil && fr.rating < *q.RatingMin || q.RatingMax != nil && fr.rating > *q.RatingMax {
+		return false
+	}
+	if !q.ReleasedFrom.IsZero() && fr.releaseDate.Before(q.ReleasedFrom) ||
+		!q.ReleasedTo.IsZero() && fr.releaseDate.After(q.ReleasedTo) {
+		return false
+	}
+	if fr.updatedAt.Before(q.UpdatedSince) {
+		return false
+	}
+
+	actors := r.store.filmActors(fr.id)
+	if len(q.Actor) != 0 && !slices.ContainsFunc(actors, func(a *actorRecord) bool {
+		return strings.Contains(a.name, q.Actor)
+	}) {
+		return false
+	}
+	if len(q.ActorIDs) != 0 {
+		found := 0
+		for _, id := range q.ActorIDs {
+			if slices.ContainsFunc(actors, func(a *actorRecord) bool { return a.id == id }) {
+				found++
+			}
+		}
+		if found == 0 || q.ActorMatchAll && found != len(q.ActorIDs) {
+			return false
+		}
+	}
+	if len(q.GenreIDs) != 0 && !slices.ContainsFunc(fr.genres, func(g string) bool {
+		return slices.Contains(q.GenreIDs, r.store.genres[g])
+	}) {
+		return false
+	}
+	if q.Seen != nil && r.store.watched(q.SeenBy, fr.id) != *q.Seen {
+		return false
+	}
+
+	return true
+}
+
+func (r *FilmRepository) GetFilms(ctx context.Context, q *film.Query) ([]*film.Film, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var films []*film.Film
+	for _, fr := range r.store.films {
+		if r.matches(q, fr) {
+			films = append(films, r.toFilm(fr))
+		}
+	}
+
+	keys := q.Sort
+	if len(keys) == 0 {
+		keys = []film.SortKey{{Field: "rating", Desc: true}}
+	}
+
+	sort.Slice(films, func(i, j int) bool {
+		for _, k := range keys {
+			a, b := films[i], films[j]
+			if k.Desc {
+				a, b = b, a
+			}
+			less := filmLess[k.Field]
+			if less(a, b) {
+				return true
+			}
+			if less(b, a) {
+				return false
+			}
+		}
+		return films[i].ID < films[j].ID
+	})
+
+	return films, nil
+}
+
+func (r *FilmRepository) GetFilmFacets(ctx context.Context, q *film.Query, names []string) ([]*film.Facet, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	facets := make([]*film.Facet, 0, len(names))
+	for _, name := range names {
+		fq := film.FacetQuery(q, name)
+
+		counts := make(map[int32]*film.FacetCount)
+		count := func(value int32, label string) {
+			if fc, ok := counts[value]; ok {
+				fc.Count++
+				return
+			}
+			counts[value] = &film.FacetCount{Value: value, Name: label, Count: 1}
+		}
+		for _, fr := range r.store.films {
+			if !r.matches(fq, fr) {
+				continue
+			}
+			switch name {
+			case film.FacetRating:
+				count(fr.rating, "")
+			case film.FacetDecade:
+				count(int32(fr.releaseDate.Year()/10*10), "")
+			case film.FacetActor:
+				for _, a := range r.store.filmActors(fr.id) {
+					count(a.id, a.name)
+				}
+			case film.FacetGenre:
+				for _, g := range fr.genres {
+					count(r.store.genres[g], g)
+				}
+			}
+		}
+
+		f := &film.Facet{Name: name, Counts: make([]*film.FacetCount, 0, len(counts))}
+		for _, v := range counts {
+			f.Counts = append(f.Counts, v)
+		}
+		switch name {
+		case film.FacetActor, film.FacetGenre:
+			sort.Slice(f.Counts, func(i, j int) bool {
+				a, b := f.Counts[i], f.Counts[j]
+				if a.Count != b.Count {
+					return a.Count > b.Count
+				}
+				if a.Name != b.Name {
+					return a.Name < b.Name
+				}
+				return a.Value < b.Value
+			})
+			limit := film.MaxActorFacetCounts
+			if name == film.FacetGenre {
+				limit = film.MaxGenreFacetCounts
+			}
+			f.Counts = f.Counts[:min(len(f.Counts), limit)]
+		default:
+			sort.Slice(f.Counts, func(i, j int) bool { return f.Counts[i].Value > f.Counts[j].Value })
+		}
+
+		facets = append(facets, f)
+	}
+
+	return facets, nil
+}
+
+func (r *FilmRepository) GetFilmActors(ctx context.Context, id int32) ([]*film.ActorShort, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.film(id); !ok {
+		return nil, nil
+	}
+
+	var actors []*film.ActorShort
+	for _, v := range r.store.filmActors(id) {
+		actors = append(actors, &film.ActorShort{
+			ID:   v.id,
+			Name: v.name,
+		})
+	}
+
+	return actors, nil
+}
+
+func (r *FilmRepository) AddFilmActors(ctx context.Context, fa *film.FilmActors) error {
+	const op = "memory.FilmRepository.AddFilmActors"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	fr, ok := r.store.film(fa.ID)
+	if !ok {
+		log.Printf("ERROR: film does not exist\n")
+		return fmt.Errorf("%s: %w", op, film.ErrFilmNotExist)
+	}
+	for _, v := range fa.ActorIDs {
+		if ar, ok := r.store.actors[v]; ok && ar.deleted() {
+			log.Printf("ERROR: one of the actors is deleted\n")
+			return fmt.Errorf("%s: %w", op, film.ErrActorNotExist)
+		}
+	}
+
+	// validate everything before inserting so that a failure leaves
+	// no partial bindings behind, as with a single INSERT statement
+	bs := make([]binding, 0, len(fa.ActorIDs))
+	for _, v := range fa.ActorIDs {
+		b := binding{actorID: v, filmID: fa.ID}
+		if r.store.hasBinding(b) || slices.Contains(bs, b) {
+			log.Printf("ERROR: one of the film-actor pairs already exists\n")
+			return fmt.Errorf("%s: %w", op, film.ErrFilmActorExist)
+		}
+		if _, ok := r.store.actors[v]; !ok {
+			log.Printf("ERROR: one of the actors does not exist\n")
+			return fmt.Errorf("%s: %w", op, film.ErrActorNotExist)
+		}
+		bs = append(bs, b)
+	}
+	r.store.bindings = append(r.store.bindings, bs...)
+	log.Printf("INFO: %d rows inserted\n", len(bs))
+	// cast changes count as film updates, see film.Repository.touchFilm
+	fr.touch(ctx)
+
+	return nil
+}
+
+func (r *FilmRepository) DeleteFilmActors(ctx context.Context, fa *film.FilmActors) error {
+	const op = "memory.FilmRepository.DeleteFilmActors"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	fr, ok := r.store.film(fa.ID)
+	if !ok {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, film.ErrZeroActors)
+	}
+
+	ids := make(map[int32]struct{}, len(fa.ActorIDs))
+	for _, v := range fa.ActorIDs {
+		ids[v] = struct{}{}
+	}
+
+	count := r.store.removeBindings(func(b binding) bool {
+		_, ok := ids[b.actorID]
+		return b.filmID != fa.ID || !ok
+	})
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, film.ErrZeroActors)
+	}
+	fr.touch(ctx)
+
+	return nil
+}
+
+func (r *FilmRepository) RestoreFilm(ctx context.Context, id int32) error {
+	const op = "memory.FilmRepository.RestoreFilm"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	fr, ok := r.store.films[id]
+	if !ok || !fr.deleted() {
+		log.Printf("ERROR: film with id=%d is not in the trash\n", id)
+		return fmt.Errorf("%s: %w", op, film.ErrFilmNotExist)
+	}
+	fr.restore(ctx)
+
+	return nil
+}
+
+func (r *FilmRepository) GetDeletedFilms(ctx context.Context) ([]*film.Film, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	films := make([]*film.Film, 0)
+	for _, fr := range r.store.films {
+		if fr.deleted() {
+			films = append(films, r.toFilm(fr))
+		}
+	}
+	sort.Slice(films, func(i, j int) bool {
+		a, b := films[i], films[j]
+		if !a.DeletedAt.Equal(*b.DeletedAt) {
+			return a.DeletedAt.After(*b.DeletedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	return films, nil
+}
+
+func (r *FilmRepository) PurgeFilms(ctx context.Context, before time.Time) ([]int32, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ids := make([]int32, 0)
+	for id, fr := range r.store.films {
+		if !fr.purgeable(before) {
+			continue
+		}
+
+		r.store.deleteFilm(id)
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+// deleteFilm removes the film with everything that references it, the
+// caller holds the write lock.
+func (s *Store) deleteFilm(id int32) {
+	delete(s.films, id)
+	s.removeBindings(func(b binding) bool {
+		return b.filmID != id
+	})
+	for _, v := range s.views {
+		delete(v, id)
+	}
+	for k, v := range s.entries {
+		s.entries[k] = slices.DeleteFunc(v, func(e *entryRecord) bool {
+			return e.filmID == id
+		})
+	}
+	s.watches = slices.DeleteFunc(s.watches, func(w *watchRecord) bool {
+		return w.filmID == id
+	})
+	for copyID, cr := range s.copies {
+		if cr.filmID == id {
+			s.deleteCopy(copyID)
+		}
+	}
+	s.holds = slices.DeleteFunc(s.holds, func(h *holdRecord) bool {
+		return h.filmID == id
+	})
+	s.notifications = slices.DeleteFunc(s.notifications, func(n *notificationRecord) bool {
+		return n.filmID == id
+	})
+}
+
+// ExportFilms copies the selected films under the lock and calls fn once
+// it is released, so that a slow consumer does not block writers.
+func (r *FilmRepository) ExportFilms(ctx context.Context, q *film.ExportQuery, fn func(f *film.Film, cast []*film.ActorShort) error) error {
+	type entry struct {
+		film *film.Film
+		cast []*film.ActorShort
+	}
+
+	r.store.mu.RLock()
+	var films []entry
+	for _, fr := range r.store.films {
+		if fr.id <= q.After || !r.matches(&q.Query, fr) {
+			continue
+		}
+
+		actors := r.store.filmActors(fr.id)
+		sort.Slice(actors, func(i, j int) bool {
+			return actors[i].id < actors[j].id
+		})
+		e := entry{film: r.toFilm(fr)}
+		e.film.Actors = nil
+		for _, v := range actors {
+			e.film.Actors = append(e.film.Actors, v.name)
+			e.cast = append(e.cast, &film.ActorShort{ID: v.id, Name: v.name})
+		}
+		films = append(films, e)
+	}
+	r.store.mu.RUnlock()
+
+	sort.Slice(films, func(i, j int) bool {
+		return films[i].film.ID < films[j].film.ID
+	})
+	if q.Limit != 0 && len(films) > q.Limit {
+		films = films[:q.Limit]
+	}
+
+	for _, v := range films {
+		if err := fn(v.film, v.cast); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
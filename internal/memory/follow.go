@@ -0,0 +1,242 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/follow"
+)
+
+var _ follow.FollowRepository = (*FollowRepository)(nil)
+
+type FollowRepository struct {
+	store *Store
+}
+
+func NewFollowRepository(s *Store) *FollowRepository {
+	return &FollowRepository{
+		store: s,
+	}
+}
+
+func (r *FollowRepository) toNotification(nr *notificationRecord) *follow.Notification {
+	fr := r.store.films[nr.filmID]
+
+	return &follow.Notification{
+		ID:            nr.id,
+		UserID:        nr.userID,
+		Username:      r.store.users[nr.userID].username,
+		Email:         r.store.emails[nr.userID],
+		ActorID:       nr.actorID,
+		ActorName:     r.store.actors[nr.actorID].name,
+		FilmID:        nr.filmID,
+		FilmName:      fr.name,
+		ReleaseDate:   fr.releaseDate,
+		Status:        nr.status,
+		Attempts:      nr.attempts,
+		LastError:     nr.lastError,
+		CreatedAt:     nr.createdAt,
+		NextAttemptAt: nr.nextAttemptAt,
+		SentAt:        nr.sentAt,
+	}
+}
+
+func (r *FollowRepository) GetFollows(ctx context.Context, userID int32) ([]*follow.Follow, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	follows := make([]*follow.Follow, 0)
+	for _, v := range r.store.follows {
+		if v.userID != userID {
+			continue
+		}
+		ar, ok := r.store.actor(v.actorID)
+		if !ok {
+			continue
+		}
+
+		follows = append(follows, &follow.Follow{
+			UserID:    v.userID,
+			ActorID:   v.actorID,
+			ActorName: ar.name,
+			CreatedAt: v.createdAt,
+		})
+	}
+	sort.Slice(follows, func(i, j int) bool {
+		a, b := follows[i], follows[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ActorID < b.ActorID
+	})
+
+	return follows, nil
+}
+
+func (r *FollowRepository) AddFollow(ctx context.Context, f *follow.Follow) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ar, ok := r.store.actor(f.ActorID)
+	if !ok {
+		return follow.ErrActorNotExist
+	}
+	f.ActorName = ar.name
+
+	i := slices.IndexFunc(r.store.follows, func(v *followRecord) bool {
+		return v.userID == f.UserID && v.actorID == f.ActorID
+	})
+	if i != -1 {
+		f.CreatedAt = r.store.follows[i].createdAt
+		return nil
+	}
+
+	f.CreatedAt = db.Now()
+	r.store.follows = append(r.store.follows, &followRecord{
+		userID:    f.UserID,
+		actorID:   f.ActorID,
+		createdAt: f.CreatedAt,
+	})
+
+	return nil
+}
+
+func (r *FollowRepository) DeleteFollow(ctx context.Context, userID int32, actorID int32) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	i := slices.IndexFunc(r.store.follows, func(v *followRecord) bool {
+		return v.userID == userID && v.actorID == actorID
+	})
+	if i == -1 {
+		return follow.ErrFollowNotExist
+	}
+	r.store.follows = slices.Delete(r.store.follows, i, i+1)
+
+	return nil
+}
+
+func (r *FollowRepository) SetEmail(ctx context.Context, userID int32, email string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if len(email) == 0 {
+		delete(r.store.emails, userID)
+		return nil
+	}
+	r.store.emails[userID] = email
+
+	return nil
+}
+
+func (r *FollowRepository) AddNotifications(ctx context.Context, filmID int32, actorIDs []int32, createdAt time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for _, v := range r.store.follows {
+		if !slices.Contains(actorIDs, v.actorID) {
+			continue
+		}
+		if slices.ContainsFunc(r.store.notifications, func(n *notificationRecord) bool {
+			return n.userID == v.userID && n.actorID == v.actorID && n.filmID == filmID
+		}) {
+			continue
+		}
+
+		r.store.notificationSeq++
+		r.store.notifications = append(r.store.notifications, &notificationRecord{
+			id:            r.store.notificationSeq,
+			userID:        v.userID,
+			actorID:       v.actorID,
+			filmID:        filmID,
+			status:        follow.StatusPending,
+			createdAt:     createdAt,
+			nextAttemptAt: createdAt,
+		})
+		count++
+	}
+
+	return count, nil
+}
+
+func (r *FollowRepository) GetNotifications(ctx context.Context, userID int32) ([]*follow.Notification, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	notifications := make([]*follow.Notification, 0)
+	for _, v := range r.store.notifications {
+		if v.userID == userID {
+			notifications = append(notifications, r.toNotification(v))
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		a, b := notifications[i], notifications[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+
+	return notifications, nil
+}
+
+func (r *FollowRepository) ClaimNotifications(ctx context.Context, now time.Time, limit int) ([]*follow.Notification, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// notifications are kept in id order
+	notifications := make([]*follow.Notification, 0)
+	for _, v := range r.store.notifications {
+		if len(notifications) == limit {
+			break
+		}
+		if v.status != follow.StatusPending || v.nextAttemptAt.After(now) {
+			continue
+		}
+
+		claimedAt := now
+		v.status, v.claimedAt = follow.StatusSending, &claimedAt
+		notifications = append(notifications, r.toNotification(v))
+	}
+
+	return notifications, nil
+}
+
+func (r *FollowRepository) FailStaleNotifications(ctx context.Context, claimedBefore time.Time, lastError string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for _, v := range r.store.notifications {
+		if v.status != follow.StatusSending || (v.claimedAt != nil && !v.claimedAt.Before(claimedBefore)) {
+			continue
+		}
+
+		v.status, v.lastError = follow.StatusFailed, lastError
+		count++
+	}
+
+	return count, nil
+}
+
+func (r *FollowRepository) FinishNotification(ctx context.Context, n *follow.Notification) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	i := slices.IndexFunc(r.store.notifications, func(v *notificationRecord) bool {
+		return v.id == n.ID && v.status == follow.StatusSending
+	})
+	if i == -1 {
+		return follow.ErrNotificationNotClaimed
+	}
+
+	nr := r.store.notifications[i]
+	nr.status, nr.attempts, nr.lastError = n.Status, n.Attempts, n.LastError
+	nr.nextAttemptAt, nr.sentAt = n.NextAttemptAt, n.SentAt
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/graph"
+)
+
+var _ graph.GraphRepository = (*GraphRepository)(nil)
+
+type GraphRepository struct {
+	store *Store
+}
+
+func NewGraphRepository(s *Store) *GraphRepository {
+	return &GraphRepository{
+		store: s,
+	}
+}
+
+// Stamp counts the bindings and, for films and actors, counts them and
+// takes the latest id and change, as the database repository does.
+func (r *GraphRepository) Stamp(ctx context.Context) (string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var films, actors int
+	var maxFilmID, maxActorID int32
+	var filmsUpdatedAt, actorsUpdatedAt time.Time
+	for _, v := range r.store.films {
+		if !v.deleted() {
+			films++
+			maxFilmID = max(maxFilmID, v.id)
+			if v.updatedAt.After(filmsUpdatedAt) {
+				filmsUpdatedAt = v.updatedAt
+			}
+		}
+	}
+	for _, v := range r.store.actors {
+		if !v.deleted() {
+			actors++
+			maxActorID = max(maxActorID, v.id)
+			if v.updatedAt.After(actorsUpdatedAt) {
+				actorsUpdatedAt = v.updatedAt
+			}
+		}
+	}
+
+	return fmt.Sprintf("%d/%d/%d/%s/%d/%d/%s", len(r.store.bindings),
+		films, maxFilmID, filmsUpdatedAt.Format(time.RFC3339Nano),
+		actors, maxActorID, actorsUpdatedAt.Format(time.RFC3339Nano)), nil
+}
+
+func (r *GraphRepository) Snapshot(ctx context.Context) (*graph.Snapshot, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	s := &graph.Snapshot{
+		Actors:   make([]*graph.Node, 0, len(r.store.actors)),
+		Films:    make([]*graph.Node, 0, len(r.store.films)),
+		Bindings: make([]*graph.Binding, 0, len(r.store.bindings)),
+	}
+	for _, v := range r.store.actors {
+		if !v.deleted() {
+			s.Actors = append(s.Actors, &graph.Node{ID: v.id, Name: v.name})
+		}
+	}
+	for _, v := range r.store.films {
+		if !v.deleted() {
+			s.Films = append(s.Films, &graph.Node{ID: v.id, Name: v.name})
+		}
+	}
+	for _, v := range r.store.bindings {
+		s.Bindings = append(s.Bindings, &graph.Binding{ActorID: v.actorID, FilmID: v.filmID})
+	}
+
+	return s, nil
+}
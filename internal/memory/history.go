@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/history"
+)
+
+var _ history.HistoryRepository = (*HistoryRepository)(nil)
+
+type HistoryRepository struct {
+	store *Store
+}
+
+func NewHistoryRepository(s *Store) *HistoryRepository {
+	return &HistoryRepository{
+		store: s,
+	}
+}
+
+// matches reports whether the watch satisfies the query, it is called
+// with the store locked.
+func (r *HistoryRepository) matches(q *history.Query, wr *watchRecord) bool {
+	return wr.userID == q.UserID &&
+		(q.FilmID == 0 || wr.filmID == q.FilmID) &&
+		(q.From.IsZero() || !wr.watchedOn.Before(q.From)) &&
+		(q.To.IsZero() || wr.watchedOn.Before(q.To))
+}
+
+func (r *HistoryRepository) AddWatch(ctx context.Context, w *history.Watch) (*history.Watch, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	fr, ok := r.store.film(w.FilmID)
+	if !ok {
+		return nil, history.ErrFilmNotExist
+	}
+
+	r.store.watchSeq++
+	wr := &watchRecord{
+		id:        r.store.watchSeq,
+		userID:    w.UserID,
+		filmID:    w.FilmID,
+		watchedOn: w.WatchedOn,
+		rating:    w.Rating,
+		rewatch:   w.Rewatch,
+		createdAt: db.Now(),
+	}
+	r.store.watches = append(r.store.watches, wr)
+
+	w.ID, w.CreatedAt, w.FilmName, w.Available = wr.id, wr.createdAt, fr.name, true
+
+	return w, nil
+}
+
+func (r *HistoryRepository) GetWatches(ctx context.Context, q *history.Query) ([]*history.Watch, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	watches := make([]*history.Watch, 0)
+	for _, v := range r.store.watches {
+		if !r.matches(q, v) {
+			continue
+		}
+
+		fr := r.store.films[v.filmID]
+		watches = append(watches, &history.Watch{
+			ID:        v.id,
+			UserID:    v.userID,
+			FilmID:    v.filmID,
+			WatchedOn: v.watchedOn,
+			Rating:    v.rating,
+			Rewatch:   v.rewatch,
+			CreatedAt: v.createdAt,
+			FilmName:  fr.name,
+			Available: !fr.deleted(),
+		})
+	}
+	sort.Slice(watches, func(i, j int) bool {
+		a, b := watches[i], watches[j]
+		if !a.WatchedOn.Equal(b.WatchedOn) {
+			return a.WatchedOn.After(b.WatchedOn)
+		}
+		return a.ID > b.ID
+	})
+	if q.Limit != 0 && len(watches) > q.Limit {
+		watches = watches[:q.Limit]
+	}
+
+	return watches, nil
+}
+
+func (r *HistoryRepository) DeleteWatch(ctx context.Context, userID int32, id int32) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	i := slices.IndexFunc(r.store.watches, func(w *watchRecord) bool {
+		return w.id == id && w.userID == userID
+	})
+	if i == -1 {
+		return history.ErrWatchNotExist
+	}
+	r.store.watches = slices.Delete(r.store.watches, i, i+1)
+
+	return nil
+}
+
+func (r *HistoryRepository) GetTopActors(ctx context.Context, q *history.Query, limit int) ([]*history.ActorCount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	films := make(map[int32]struct{})
+	for _, v := range r.store.watches {
+		if r.matches(q, v) {
+			films[v.filmID] = struct{}{}
+		}
+	}
+
+	counts := make(map[int32]*history.ActorCount)
+	for id := range films {
+		for _, v := range r.store.filmActors(id) {
+			ac, ok := counts[v.id]
+			if !ok {
+				ac = &history.ActorCount{ID: v.id, Name: v.name}
+				counts[v.id] = ac
+			}
+			ac.Films++
+		}
+	}
+
+	actors := make([]*history.ActorCount, 0, len(counts))
+	for _, v := range counts {
+		actors = append(actors, v)
+	}
+	sort.Slice(actors, func(i, j int) bool {
+		a, b := actors[i], actors[j]
+		if a.Films != b.Films {
+			return a.Films > b.Films
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+	if len(actors) > limit {
+		actors = actors[:limit]
+	}
+
+	return actors, nil
+}
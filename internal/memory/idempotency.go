@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/idempotency"
+)
+
+var _ idempotency.IdempotencyRepository = (*IdempotencyRepository)(nil)
+
+type IdempotencyRepository struct {
+	store *Store
+}
+
+func NewIdempotencyRepository(s *Store) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		store: s,
+	}
+}
+
+func (r *IdempotencyRepository) AddRecord(ctx context.Context, rec *idempotency.Record) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := idempotencyKey{userID: rec.UserID, key: rec.Key}
+	if _, ok := r.store.idempotency[k]; ok {
+		return false, nil
+	}
+	r.store.idempotency[k] = idempotency.Record{
+		UserID:      rec.UserID,
+		Key:         rec.Key,
+		RequestHash: rec.RequestHash,
+		CreatedAt:   rec.CreatedAt,
+		ExpiresAt:   rec.ExpiresAt,
+		LockedAt:    rec.LockedAt,
+	}
+
+	return true, nil
+}
+
+func (r *IdempotencyRepository) GetRecord(ctx context.Context, userID int32, key string) (*idempotency.Record, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.idempotency[idempotencyKey{userID: userID, key: key}]
+	if !ok {
+		return nil, idempotency.ErrRecordNotExist
+	}
+	rec.Header = rec.Header.Clone()
+	rec.Body = slices.Clone(rec.Body)
+
+	return &rec, nil
+}
+
+func (r *IdempotencyRepository) LockRecord(ctx context.Context, rec *idempotency.Record, staleBefore time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := idempotencyKey{userID: rec.UserID, key: rec.Key}
+	cur, ok := r.store.idempotency[k]
+	if !ok || cur.Completed() || !cur.LockedAt.Before(staleBefore) {
+		return false, nil
+	}
+	cur.LockedAt = rec.LockedAt
+	r.store.idempotency[k] = cur
+
+	return true, nil
+}
+
+func (r *IdempotencyRepository) CompleteRecord(ctx context.Context, rec *idempotency.Record) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := idempotencyKey{userID: rec.UserID, key: rec.Key}
+	cur, ok := r.store.idempotency[k]
+	if !ok {
+		return idempotency.ErrRecordNotExist
+	}
+	cur.StatusCode = rec.StatusCode
+	cur.Header = rec.Header.Clone()
+	cur.Body = slices.Clone(rec.Body)
+	r.store.idempotency[k] = cur
+
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteRecord(ctx context.Context, userID int32, key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.idempotency, idempotencyKey{userID: userID, key: key})
+
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var n int64
+	for k, v := range r.store.idempotency {
+		if !v.ExpiresAt.After(now) {
+			delete(r.store.idempotency, k)
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,393 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/lending"
+)
+
+var _ lending.LendingRepository = (*LendingRepository)(nil)
+
+type LendingRepository struct {
+	store *Store
+}
+
+func NewLendingRepository(s *Store) *LendingRepository {
+	return &LendingRepository{
+		store: s,
+	}
+}
+
+func (r *LendingRepository) toCopy(cr *copyRecord) *lending.Copy {
+	return &lending.Copy{
+		ID:        cr.id,
+		FilmID:    cr.filmID,
+		Format:    cr.format,
+		Barcode:   cr.barcode,
+		Condition: cr.condition,
+		CreatedAt: cr.createdAt,
+		UpdatedAt: cr.updatedAt,
+		OnLoan:    r.store.onLoan(cr.id),
+	}
+}
+
+func (r *LendingRepository) toLoan(lr *loanRecord) *lending.Loan {
+	cr := r.store.copies[lr.copyID]
+
+	return &lending.Loan{
+		ID:           lr.id,
+		CopyID:       lr.copyID,
+		UserID:       lr.userID,
+		CheckedOutAt: lr.checkedOutAt,
+		DueAt:        lr.dueAt,
+		ReturnedAt:   lr.returnedAt,
+		Renewals:     lr.renewals,
+		Barcode:      cr.barcode,
+		Format:       cr.format,
+		FilmID:       cr.filmID,
+		FilmName:     r.store.films[cr.filmID].name,
+		Username:     r.store.users[lr.userID].username,
+	}
+}
+
+// copy returns the copy unless its film is in the trash.
+func (r *LendingRepository) copy(id int32) (*copyRecord, bool) {
+	cr, ok := r.store.copies[id]
+	if !ok {
+		return nil, false
+	}
+	if _, ok := r.store.film(cr.filmID); !ok {
+		return nil, false
+	}
+
+	return cr, true
+}
+
+// holds returns the queue for the film, it is called with the store
+// locked.
+func (r *LendingRepository) holds(filmID int32) []*lending.Hold {
+	available := 0
+	for _, v := range r.store.copies {
+		if v.filmID == filmID && !r.store.onLoan(v.id) {
+			available++
+		}
+	}
+
+	holds := make([]*lending.Hold, 0)
+	for _, v := range r.store.holds {
+		if v.filmID != filmID {
+			continue
+		}
+
+		holds = append(holds, &lending.Hold{
+			ID:              v.id,
+			FilmID:          v.filmID,
+			UserID:          v.userID,
+			CreatedAt:       v.createdAt,
+			Position:        len(holds) + 1,
+			AvailableCopies: available,
+			FilmName:        r.store.films[v.filmID].name,
+			Username:        r.store.users[v.userID].username,
+		})
+	}
+
+	return holds
+}
+
+func (r *LendingRepository) GetCopies(ctx context.Context, filmID int32) ([]*lending.Copy, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.film(filmID); !ok {
+		return nil, lending.ErrFilmNotExist
+	}
+
+	copies := make([]*lending.Copy, 0)
+	for _, v := range r.store.copies {
+		if v.filmID == filmID {
+			copies = append(copies, r.toCopy(v))
+		}
+	}
+	sort.Slice(copies, func(i, j int) bool {
+		a, b := copies[i], copies[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	return copies, nil
+}
+
+func (r *LendingRepository) GetCopy(ctx context.Context, id int32) (*lending.Copy, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	cr, ok := r.copy(id)
+	if !ok {
+		return nil, lending.ErrCopyNotExist
+	}
+
+	return r.toCopy(cr), nil
+}
+
+func (r *LendingRepository) GetCopyByBarcode(ctx context.Context, barcode string) (*lending.Copy, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, v := range r.store.copies {
+		if v.barcode != barcode {
+			continue
+		}
+		if cr, ok := r.copy(v.id); ok {
+			return r.toCopy(cr), nil
+		}
+	}
+
+	return nil, lending.ErrCopyNotExist
+}
+
+func (r *LendingRepository) AddCopy(ctx context.Context, c *lending.Copy) (*lending.Copy, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.film(c.FilmID); !ok {
+		return nil, lending.ErrFilmNotExist
+	}
+	for _, v := range r.store.copies {
+		if v.barcode == c.Barcode {
+			return nil, lending.ErrBarcodeTaken
+		}
+	}
+
+	r.store.copySeq++
+	now := db.Now()
+	cr := &copyRecord{
+		id:        r.store.copySeq,
+		filmID:    c.FilmID,
+		format:    c.Format,
+		barcode:   c.Barcode,
+		condition: c.Condition,
+		createdAt: now,
+		updatedAt: now,
+	}
+	r.store.copies[cr.id] = cr
+
+	return r.toCopy(cr), nil
+}
+
+func (r *LendingRepository) UpdateCopy(ctx context.Context, cu *lending.CopyUpdate) error {
+	if cu.Format == nil && cu.Condition == nil {
+		return lending.ErrEmptyUpdate
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	cr, ok := r.copy(cu.ID)
+	if !ok {
+		return lending.ErrCopyNotExist
+	}
+
+	if cu.Format != nil {
+		cr.format = *cu.Format
+	}
+	if cu.Condition != nil {
+		cr.condition = *cu.Condition
+	}
+	cr.updatedAt = db.Now()
+
+	return nil
+}
+
+func (r *LendingRepository) DeleteCopy(ctx context.Context, id int32) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.copy(id); !ok {
+		return lending.ErrCopyNotExist
+	}
+	if r.store.onLoan(id) {
+		return lending.ErrCopyOnLoan
+	}
+	r.store.deleteCopy(id)
+
+	return nil
+}
+
+func (r *LendingRepository) GetLoans(ctx context.Context, q *lending.LoanQuery) ([]*lending.Loan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	loans := make([]*lending.Loan, 0)
+	for _, v := range r.store.loans {
+		if q.UserID != 0 && v.userID != q.UserID ||
+			q.FilmID != 0 && r.store.copies[v.copyID].filmID != q.FilmID ||
+			q.Active && v.returnedAt != nil ||
+			!q.DueBefore.IsZero() && !v.dueAt.Before(q.DueBefore) {
+			continue
+		}
+		loans = append(loans, r.toLoan(v))
+	}
+	sort.Slice(loans, func(i, j int) bool {
+		a, b := loans[i], loans[j]
+		if !a.DueAt.Equal(b.DueAt) {
+			return a.DueAt.Before(b.DueAt)
+		}
+		return a.ID < b.ID
+	})
+
+	return loans, nil
+}
+
+func (r *LendingRepository) GetLoan(ctx context.Context, id int32) (*lending.Loan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	i := slices.IndexFunc(r.store.loans, func(l *loanRecord) bool { return l.id == id })
+	if i == -1 {
+		return nil, lending.ErrLoanNotExist
+	}
+
+	return r.toLoan(r.store.loans[i]), nil
+}
+
+func (r *LendingRepository) AddLoan(ctx context.Context, l *lending.Loan) (*lending.Loan, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[l.UserID]; !ok {
+		return nil, lending.ErrUserNotExist
+	}
+	if _, ok := r.copy(l.CopyID); !ok {
+		return nil, lending.ErrCopyNotExist
+	}
+	if r.store.onLoan(l.CopyID) {
+		return nil, lending.ErrCopyOnLoan
+	}
+
+	r.store.loanSeq++
+	lr := &loanRecord{
+		id:           r.store.loanSeq,
+		copyID:       l.CopyID,
+		userID:       l.UserID,
+		checkedOutAt: l.CheckedOutAt,
+		dueAt:        l.DueAt,
+		renewals:     l.Renewals,
+	}
+	r.store.loans = append(r.store.loans, lr)
+
+	return r.toLoan(lr), nil
+}
+
+// activeLoan returns the loan unless it is returned, it is called with
+// the store locked.
+func (r *LendingRepository) activeLoan(id int32) (*loanRecord, bool) {
+	i := slices.IndexFunc(r.store.loans, func(l *loanRecord) bool {
+		return l.id == id && l.returnedAt == nil
+	})
+	if i == -1 {
+		return nil, false
+	}
+
+	return r.store.loans[i], true
+}
+
+func (r *LendingRepository) RenewLoan(ctx context.Context, id int32, dueAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	lr, ok := r.activeLoan(id)
+	if !ok {
+		return lending.ErrLoanNotExist
+	}
+	lr.dueAt = dueAt
+	lr.renewals++
+
+	return nil
+}
+
+func (r *LendingRepository) ReturnLoan(ctx context.Context, id int32, returnedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	lr, ok := r.activeLoan(id)
+	if !ok {
+		return lending.ErrLoanNotExist
+	}
+	lr.returnedAt = &returnedAt
+
+	return nil
+}
+
+func (r *LendingRepository) GetHolds(ctx context.Context, filmID int32) ([]*lending.Hold, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.film(filmID); !ok {
+		return nil, lending.ErrFilmNotExist
+	}
+
+	return r.holds(filmID), nil
+}
+
+func (r *LendingRepository) GetUserHolds(ctx context.Context, userID int32) ([]*lending.Hold, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	holds := make([]*lending.Hold, 0)
+	for _, v := range r.store.holds {
+		if v.userID != userID {
+			continue
+		}
+		queue := r.holds(v.filmID)
+		i := slices.IndexFunc(queue, func(h *lending.Hold) bool { return h.UserID == userID })
+		holds = append(holds, queue[i])
+	}
+
+	return holds, nil
+}
+
+func (r *LendingRepository) AddHold(ctx context.Context, h *lending.Hold) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.film(h.FilmID); !ok {
+		return lending.ErrFilmNotExist
+	}
+	if slices.ContainsFunc(r.store.holds, func(v *holdRecord) bool {
+		return v.filmID == h.FilmID && v.userID == h.UserID
+	}) {
+		return lending.ErrHoldExists
+	}
+
+	r.store.holdSeq++
+	h.ID, h.CreatedAt = r.store.holdSeq, db.Now()
+	r.store.holds = append(r.store.holds, &holdRecord{
+		id:        h.ID,
+		filmID:    h.FilmID,
+		userID:    h.UserID,
+		createdAt: h.CreatedAt,
+	})
+
+	return nil
+}
+
+func (r *LendingRepository) DeleteHold(ctx context.Context, filmID int32, userID int32) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	i := slices.IndexFunc(r.store.holds, func(v *holdRecord) bool {
+		return v.filmID == filmID && v.userID == userID
+	})
+	if i == -1 {
+		return lending.ErrHoldNotExist
+	}
+	r.store.holds = slices.Delete(r.store.holds, i, i+1)
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/Coderovshik/film-library/internal/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) *storagetest.Repositories {
+		s := NewStore()
+
+		return &storagetest.Repositories{
+			Films:  NewFilmRepository(s),
+			Actors: NewActorRepository(s),
+			Users:  NewUserRepository(s),
+			Audit:  NewAuditRepository(s),
+			Tx:     s,
+
+			Idempotency: NewIdempotencyRepository(s),
+			Search:      NewSearchRepository(s),
+
+			Autocomplete: NewAutocompleteRepository(s),
+			Graph:        NewGraphRepository(s),
+			Views:        NewViewRepository(s),
+			Collections:  NewCollectionRepository(s),
+			History:      NewHistoryRepository(s),
+			Lending:      NewLendingRepository(s),
+			Follow:       NewFollowRepository(s),
+		}
+	})
+}
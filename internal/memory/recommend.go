@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/recommend"
+)
+
+var _ recommend.ViewRepository = (*ViewRepository)(nil)
+
+type ViewRepository struct {
+	store *Store
+}
+
+func NewViewRepository(s *Store) *ViewRepository {
+	return &ViewRepository{
+		store: s,
+	}
+}
+
+// views returns the views of the user latest first, it is called with
+// the store locked.
+func (r *ViewRepository) views(userID int32) []*recommend.View {
+	views := make([]*recommend.View, 0, len(r.store.views[userID]))
+	for k, v := range r.store.views[userID] {
+		views = append(views, &recommend.View{UserID: userID, FilmID: k, ViewedAt: v})
+	}
+	sort.Slice(views, func(i, j int) bool {
+		a, b := views[i], views[j]
+		if !a.ViewedAt.Equal(b.ViewedAt) {
+			return a.ViewedAt.After(b.ViewedAt)
+		}
+		return a.FilmID > b.FilmID
+	})
+
+	return views
+}
+
+func (r *ViewRepository) AddView(ctx context.Context, v *recommend.View, keep int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.views[v.UserID] == nil {
+		r.store.views[v.UserID] = make(map[int32]time.Time)
+	}
+	r.store.views[v.UserID][v.FilmID] = v.ViewedAt
+
+	views := r.views(v.UserID)
+	for _, old := range views[min(len(views), keep):] {
+		delete(r.store.views[v.UserID], old.FilmID)
+	}
+
+	return nil
+}
+
+func (r *ViewRepository) GetViews(ctx context.Context, userID int32, limit int) ([]*recommend.View, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	views := make([]*recommend.View, 0, limit)
+	for _, v := range r.views(userID) {
+		if len(views) == limit {
+			break
+		}
+		if _, ok := r.store.film(v.FilmID); ok {
+			views = append(views, v)
+		}
+	}
+
+	return views, nil
+}
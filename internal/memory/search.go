@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Coderovshik/film-library/internal/search"
+)
+
+var _ search.SearchRepository = (*SearchRepository)(nil)
+
+// SearchRepository matches words by prefix, which stands in for the
+// stemming done by the databases.
+type SearchRepository struct {
+	store *Store
+}
+
+func NewSearchRepository(s *Store) *SearchRepository {
+	return &SearchRepository{
+		store: s,
+	}
+}
+
+// nameWeight ranks a word found in a name above one found in a description.
+const nameWeight = 10
+
+func (r *SearchRepository) Search(ctx context.Context, q *search.Query) ([]*search.Hit, error) {
+	terms := search.Words(q.Text)
+	hits := make([]*search.Hit, 0)
+	if len(terms) == 0 {
+		return hits, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, v := range r.store.films {
+		if v.deleted() {
+			continue
+		}
+		if rank, ok := rankText(terms, v.name, v.description); ok {
+			hits = append(hits, &search.Hit{
+				Kind:    search.KindFilm,
+				ID:      v.id,
+				Name:    v.name,
+				Rank:    rank,
+				Snippet: highlight(terms, v.name+". "+v.description),
+			})
+		}
+	}
+	for _, v := range r.store.actors {
+		if v.deleted() {
+			continue
+		}
+		if rank, ok := rankText(terms, v.name, ""); ok {
+			hits = append(hits, &search.Hit{
+				Kind:    search.KindActor,
+				ID:      v.id,
+				Name:    v.name,
+				Rank:    rank,
+				Snippet: highlight(terms, v.name),
+			})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		if hits[i].Kind != hits[j].Kind {
+			return hits[i].Kind < hits[j].Kind
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+
+	return hits, nil
+}
+
+// rankText reports whether every term is found in the name or the
+// description and how many times, name matches weighted.
+func rankText(terms []string, name, description string) (float64, bool) {
+	nameWords, descWords := search.Words(name), search.Words(description)
+
+	var rank float64
+	for _, t := range terms {
+		n, d := countPrefixed(nameWords, t), countPrefixed(descWords, t)
+		if n+d == 0 {
+			return 0, false
+		}
+		rank += float64(nameWeight*n + d)
+	}
+
+	return rank, true
+}
+
+func countPrefixed(words []string, prefix string) int {
+	n := 0
+	for _, v := range words {
+		if strings.HasPrefix(v, prefix) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// highlight escapes the text and wraps the words starting with one of the
+// terms in <b></b>.
+func highlight(terms []string, text string) string {
+	var b strings.Builder
+
+	word := func(w string) {
+		for _, t := range terms {
+			if strings.HasPrefix(strings.ToLower(w), t) {
+				b.WriteString("<b>" + w + "</b>")
+				return
+			}
+		}
+		b.WriteString(w)
+	}
+
+	start := -1
+	for i, c := range text {
+		isWord := unicode.IsLetter(c) || unicode.IsDigit(c)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			word(text[start:i])
+			start = -1
+			b.WriteString(html.EscapeString(string(c)))
+		case !isWord:
+			b.WriteString(html.EscapeString(string(c)))
+		}
+	}
+	if start >= 0 {
+		word(text[start:])
+	}
+
+	return b.String()
+}
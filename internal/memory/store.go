@@ -0,0 +1,353 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/idempotency"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+// stamp records when and by whom a record was created, last changed
+// and moved to the trash.
+type stamp struct {
+	createdAt time.Time
+	updatedAt time.Time
+	createdBy *int32
+	updatedBy *int32
+	deletedAt *time.Time
+	deletedBy *int32
+}
+
+func newStamp(ctx context.Context) stamp {
+	now, author := db.Now(), util.AuthorFromContext(ctx)
+
+	return stamp{
+		createdAt: now,
+		updatedAt: now,
+		createdBy: author,
+		updatedBy: author,
+	}
+}
+
+func (s *stamp) touch(ctx context.Context) {
+	s.updatedAt, s.updatedBy = db.Now(), util.AuthorFromContext(ctx)
+}
+
+func (s *stamp) trash(ctx context.Context) {
+	now := db.Now()
+	s.deletedAt, s.deletedBy = &now, util.AuthorFromContext(ctx)
+}
+
+// restore takes the record out of the trash, which counts as a change.
+func (s *stamp) restore(ctx context.Context) {
+	s.deletedAt, s.deletedBy = nil, nil
+	s.touch(ctx)
+}
+
+func (s *stamp) deleted() bool {
+	return s.deletedAt != nil
+}
+
+// purgeable reports whether the record was deleted before the given time.
+func (s *stamp) purgeable(before time.Time) bool {
+	return s.deletedAt != nil && s.deletedAt.Before(before)
+}
+
+type filmRecord struct {
+	stamp
+
+	id          int32
+	name        string
+	description string
+	releaseDate time.Time
+	rating      int32
+	version     int32
+	// genres holds the sorted genre names
+	genres []string
+}
+
+type actorRecord struct {
+	stamp
+
+	id       int32
+	name     string
+	sex      string
+	birthday time.Time
+	version  int32
+}
+
+type userRecord struct {
+	id       int32
+	username string
+	passhash string
+	isAdmin  bool
+}
+
+type collectionRecord struct {
+	id          int32
+	userID      int32
+	name        string
+	description string
+	kind        string
+	visibility  string
+	shareToken  string
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+type entryRecord struct {
+	filmID  int32
+	note    string
+	addedAt time.Time
+}
+
+type watchRecord struct {
+	id        int32
+	userID    int32
+	filmID    int32
+	watchedOn time.Time
+	rating    *int32
+	rewatch   bool
+	createdAt time.Time
+}
+
+type copyRecord struct {
+	id        int32
+	filmID    int32
+	format    string
+	barcode   string
+	condition string
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+type loanRecord struct {
+	id           int32
+	copyID       int32
+	userID       int32
+	checkedOutAt time.Time
+	dueAt        time.Time
+	returnedAt   *time.Time
+	renewals     int
+}
+
+type holdRecord struct {
+	id        int32
+	filmID    int32
+	userID    int32
+	createdAt time.Time
+}
+
+type followRecord struct {
+	userID    int32
+	actorID   int32
+	createdAt time.Time
+}
+
+type notificationRecord struct {
+	id            int32
+	userID        int32
+	actorID       int32
+	filmID        int32
+	status        string
+	attempts      int
+	lastError     string
+	createdAt     time.Time
+	nextAttemptAt time.Time
+	sentAt        *time.Time
+	claimedAt     *time.Time
+}
+
+type idempotencyKey struct {
+	userID int32
+	key    string
+}
+
+type binding struct {
+	actorID int32
+	filmID  int32
+}
+
+// Store holds the state shared by the in-memory repositories, so that
+// deleting a film or an actor cascades to their bindings the same way
+// ON DELETE CASCADE does in the database.
+type Store struct {
+	mu sync.RWMutex
+	state
+}
+
+// state is everything a Store holds, a transaction works on a copy of it.
+type state struct {
+	films    map[int32]*filmRecord
+	actors   map[int32]*actorRecord
+	users    map[int32]*userRecord
+	bindings []binding
+	audit    []audit.Entry
+
+	idempotency map[idempotencyKey]idempotency.Record
+	// views holds the time each user last viewed each film
+	views map[int32]map[int32]time.Time
+
+	collections map[int32]*collectionRecord
+	// entries holds the films of each list in order
+	entries map[int32][]*entryRecord
+
+	watches []*watchRecord
+
+	copies map[int32]*copyRecord
+	loans  []*loanRecord
+	// holds are kept in the order they were placed
+	holds []*holdRecord
+
+	follows []*followRecord
+	// emails holds the notification address of each user
+	emails        map[int32]string
+	notifications []*notificationRecord
+
+	// genres holds the id of each genre name ever given to a film
+	genres map[string]int32
+
+	filmSeq  int32
+	actorSeq int32
+	userSeq  int32
+	auditSeq int64
+
+	collectionSeq   int32
+	watchSeq        int32
+	copySeq         int32
+	loanSeq         int32
+	holdSeq         int32
+	notificationSeq int32
+	genreSeq        int32
+}
+
+func NewStore() *Store {
+	return &Store{state: state{
+		films:  make(map[int32]*filmRecord),
+		actors: make(map[int32]*actorRecord),
+		users:  make(map[int32]*userRecord),
+
+		idempotency: make(map[idempotencyKey]idempotency.Record),
+		views:       make(map[int32]map[int32]time.Time),
+
+		collections: make(map[int32]*collectionRecord),
+		entries:     make(map[int32][]*entryRecord),
+
+		copies: make(map[int32]*copyRecord),
+
+		emails: make(map[int32]string),
+
+		genres: make(map[string]int32),
+	}}
+}
+
+func (s *Store) hasBinding(b binding) bool {
+	for _, v := range s.bindings {
+		if v == b {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setGenres replaces the genres of the film, the names new to the store
+// get an id the way the genre table gives one.
+func (s *Store) setGenres(fr *filmRecord, genres []string) {
+	fr.genres = slices.Clone(genres)
+	slices.Sort(fr.genres)
+	for _, v := range genres {
+		if _, ok := s.genres[v]; !ok {
+			s.genreSeq++
+			s.genres[v] = s.genreSeq
+		}
+	}
+}
+
+// film returns the film unless it is in the trash.
+func (s *Store) film(id int32) (*filmRecord, bool) {
+	fr, ok := s.films[id]
+	if !ok || fr.deleted() {
+		return nil, false
+	}
+
+	return fr, true
+}
+
+// actor returns the actor unless it is in the trash.
+func (s *Store) actor(id int32) (*actorRecord, bool) {
+	ar, ok := s.actors[id]
+	if !ok || ar.deleted() {
+		return nil, false
+	}
+
+	return ar, true
+}
+
+// filmActors returns actors bound to the film in binding order,
+// skipping the ones in the trash.
+func (s *Store) filmActors(filmID int32) []*actorRecord {
+	var actors []*actorRecord
+	for _, v := range s.bindings {
+		if v.filmID == filmID && !s.actors[v.actorID].deleted() {
+			actors = append(actors, s.actors[v.actorID])
+		}
+	}
+
+	return actors
+}
+
+// actorFilms returns films the actor is bound to in binding order,
+// skipping the ones in the trash.
+func (s *Store) actorFilms(actorID int32) []*filmRecord {
+	var films []*filmRecord
+	for _, v := range s.bindings {
+		if v.actorID == actorID && !s.films[v.filmID].deleted() {
+			films = append(films, s.films[v.filmID])
+		}
+	}
+
+	return films
+}
+
+func (s *Store) removeBindings(keep func(b binding) bool) int {
+	n := 0
+	kept := s.bindings[:0]
+	for _, v := range s.bindings {
+		if keep(v) {
+			kept = append(kept, v)
+			continue
+		}
+		n++
+	}
+	s.bindings = kept
+
+	return n
+}
+
+// watched reports whether the user logged a watch of the film.
+func (s *Store) watched(userID int32, filmID int32) bool {
+	return slices.ContainsFunc(s.watches, func(w *watchRecord) bool {
+		return w.userID == userID && w.filmID == filmID
+	})
+}
+
+// onLoan reports whether the copy is lent.
+func (s *Store) onLoan(copyID int32) bool {
+	return slices.ContainsFunc(s.loans, func(l *loanRecord) bool {
+		return l.copyID == copyID && l.returnedAt == nil
+	})
+}
+
+// deleteCopy deletes the copy with its loans.
+func (s *Store) deleteCopy(id int32) {
+	delete(s.copies, id)
+	s.loans = slices.DeleteFunc(s.loans, func(l *loanRecord) bool {
+		return l.copyID == id
+	})
+}
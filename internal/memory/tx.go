@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+// Atomic runs fn on repositories over a copy of the store and keeps the
+// changes only if fn succeeds. The store is locked for the whole run, so
+// the copy sees no concurrent changes and loses none.
+func (s *Store) Atomic(ctx context.Context, fn func(fr film.FilmRepository, ar actor.ActorRepository, rec audit.Recorder) error) error {
+	return s.atomic(func(c *Store) error {
+		return fn(NewFilmRepository(c), NewActorRepository(c), audit.NewService(NewAuditRepository(c)))
+	})
+}
+
+// atomic runs fn on a copy of the store and swaps it in if fn succeeds.
+func (s *Store) atomic(fn func(c *Store) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &Store{state: s.clone()}
+	if err := fn(c); err != nil {
+		return err
+	}
+	s.state = c.state
+
+	return nil
+}
+
+// clone copies the state, the records included since the repositories
+// change them in place.
+func (st *state) clone() state {
+	c := *st
+
+	c.films = cloneRecords(st.films)
+	c.actors = cloneRecords(st.actors)
+	c.users = cloneRecords(st.users)
+	c.bindings = slices.Clone(st.bindings)
+	c.audit = slices.Clone(st.audit)
+
+	c.idempotency = maps.Clone(st.idempotency)
+	c.views = make(map[int32]map[int32]time.Time, len(st.views))
+	for k, v := range st.views {
+		c.views[k] = maps.Clone(v)
+	}
+
+	c.collections = cloneRecords(st.collections)
+	c.entries = make(map[int32][]*entryRecord, len(st.entries))
+	for k, v := range st.entries {
+		c.entries[k] = cloneList(v)
+	}
+
+	c.watches = cloneList(st.watches)
+
+	c.copies = cloneRecords(st.copies)
+	c.loans = cloneList(st.loans)
+	c.holds = cloneList(st.holds)
+
+	c.follows = cloneList(st.follows)
+	c.emails = maps.Clone(st.emails)
+	c.notifications = cloneList(st.notifications)
+
+	c.genres = maps.Clone(st.genres)
+
+	return c
+}
+
+func cloneRecords[K comparable, V any](m map[K]*V) map[K]*V {
+	c := make(map[K]*V, len(m))
+	for k, v := range m {
+		r := *v
+		c[k] = &r
+	}
+
+	return c
+}
+
+func cloneList[V any](l []*V) []*V {
+	c := make([]*V, 0, len(l))
+	for _, v := range l {
+		r := *v
+		c = append(c, &r)
+	}
+
+	return c
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Coderovshik/film-library/internal/user"
+)
+
+var _ user.UserRepository = (*UserRepository)(nil)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(s *Store) *UserRepository {
+	return &UserRepository{
+		store: s,
+	}
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, u *user.User) (*user.User, error) {
+	const op = "memory.UserRepository.CreateUser"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, v := range r.store.users {
+		if v.username == u.Username {
+			log.Printf("ERROR: user %s already exists\n", u.Username)
+			return nil, fmt.Errorf("%s: %w", op, user.ErrUserExist)
+		}
+	}
+
+	r.store.userSeq++
+	u.ID = r.store.userSeq
+	r.store.users[u.ID] = &userRecord{
+		id:       u.ID,
+		username: u.Username,
+		passhash: u.Passhash,
+		isAdmin:  u.IsAdmin,
+	}
+
+	return u, nil
+}
+
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
+	const op = "memory.UserRepository.GetUserByUsername"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, v := range r.store.users {
+		if v.username == username {
+			return &user.User{
+				ID:       v.id,
+				Username: v.username,
+				Passhash: v.passhash,
+				IsAdmin:  v.isAdmin,
+			}, nil
+		}
+	}
+
+	log.Printf("ERROR: user %s does not exist\n", username)
+	return nil, fmt.Errorf("%s: %w", op, user.ErrUserNotExist)
+}
+
+func (r *UserRepository) UpdateUser(ctx context.Context, u *user.User) error {
+	const op = "memory.UserRepository.UpdateUser"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ur, ok := r.store.users[u.ID]
+	if !ok {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, user.ErrUserNotExist)
+	}
+	ur.passhash = u.Passhash
+	ur.isAdmin = u.IsAdmin
+
+	return nil
+}
+
+func (r *UserRepository) HasAdmin(ctx context.Context) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, v := range r.store.users {
+		if v.isAdmin {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
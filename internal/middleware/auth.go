@@ -60,7 +60,7 @@ func NewAuthMiddleware(key string, adminOnly bool) func(next http.Handler) http.
 				log.Printf("INFO: admin request")
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(util.WithUserClaims(r.Context(), uc)))
 		})
 	}
 }
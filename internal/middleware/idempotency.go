@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/idempotency"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+// replayedHeaders are stored with the response body.
+var replayedHeaders = []string{"content-type", "etag", "location"}
+
+// responseRecorder keeps a copy of the response written through it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	if rr.statusCode == 0 {
+		rr.statusCode = statusCode
+	}
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.statusCode == 0 {
+		rr.statusCode = http.StatusOK
+	}
+	rr.body.Write(b)
+
+	return rr.ResponseWriter.Write(b)
+}
+
+// NewIdempotencyMiddleware stores the response to a request carrying an
+// Idempotency-Key header and replays it to retries of the request. It has
+// to run after the auth middleware, keys are scoped to the user. Server
+// errors and panics are not stored, so that the request can be retried.
+func NewIdempotencyMiddleware(is idempotency.IdempotencyService) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotency.HeaderKey)
+			if len(key) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			uc, ok := util.UserClaimsFromContext(r.Context())
+			if !ok {
+				log.Printf("ERROR: idempotency key without authenticated user\n")
+				util.InternalServerError(w, r)
+				return
+			}
+			userID := int32(uc.ID)
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				log.Printf("ERROR: failed to read request body err=%s\n", err.Error())
+				util.BadRequest(w, r)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			rec, err := is.Begin(r.Context(), &idempotency.BeginRequest{
+				UserID:      userID,
+				Key:         key,
+				RequestHash: idempotency.Fingerprint(r.Method, r.URL.Path, body),
+			})
+			if err != nil {
+				idempotencyError(w, r, err)
+				return
+			}
+
+			if rec != nil {
+				log.Printf("INFO: replaying response to idempotency key %q\n", key)
+				for k, v := range rec.Header {
+					w.Header()[k] = v
+				}
+				w.Header().Set(idempotency.HeaderReplayed, "true")
+				w.WriteHeader(rec.StatusCode)
+				w.Write(rec.Body)
+				return
+			}
+
+			// the outcome is kept even if the client is gone by now
+			ctx := context.WithoutCancel(r.Context())
+			release := func() {
+				if err := is.Release(ctx, userID, key); err != nil {
+					log.Printf("ERROR: failed to release idempotency key err=%s\n", err.Error())
+				}
+			}
+
+			// the panic is passed on once the key is released
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+
+			rr := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rr, r)
+
+			if rr.statusCode == 0 {
+				rr.statusCode = http.StatusOK
+			}
+			if rr.statusCode >= http.StatusInternalServerError {
+				release()
+				return
+			}
+
+			header := http.Header{}
+			for _, k := range replayedHeaders {
+				for _, v := range w.Header().Values(k) {
+					header.Add(k, v)
+				}
+			}
+			err = is.Complete(ctx, &idempotency.Record{
+				UserID:     userID,
+				Key:        key,
+				StatusCode: rr.statusCode,
+				Header:     header,
+				Body:       rr.body.Bytes(),
+			})
+			if err != nil {
+				log.Printf("ERROR: failed to store response to idempotency key err=%s\n", err.Error())
+			}
+		})
+	}
+}
+
+func idempotencyError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("ERROR: failed to begin idempotent request err=%s\n", err.Error())
+
+	var ve *util.ValidationError
+	if errors.As(err, &ve) {
+		util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeValidation,
+			Body:      ve.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, idempotency.ErrKeyMismatch) {
+		util.JSON(w, r, http.StatusUnprocessableEntity, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeConflict,
+			Body:      "idempotency key was already used with a different request",
+		})
+		return
+	}
+
+	if errors.Is(err, idempotency.ErrKeyInProgress) {
+		util.JSON(w, r, http.StatusConflict, &util.ErrorMessage{
+			ErrorType: util.ErrorTypeConflict,
+			Body:      "request with the same idempotency key is in progress",
+		})
+		return
+	}
+
+	util.InternalServerError(w, r)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/recommend"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+// statusRecorder keeps the status code of the response written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (sr *statusRecorder) WriteHeader(statusCode int) {
+	if sr.statusCode == 0 {
+		sr.statusCode = statusCode
+	}
+	sr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.statusCode == 0 {
+		sr.statusCode = http.StatusOK
+	}
+
+	return sr.ResponseWriter.Write(b)
+}
+
+// NewViewMiddleware records that the authenticated user viewed the film
+// of the {id} path value once the film is served, a 304 counts as well.
+// It has to run after the auth middleware.
+func NewViewMiddleware(rs recommend.RecommendService) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sr := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(sr, r)
+
+			if sr.statusCode != http.StatusOK && sr.statusCode != http.StatusNotModified && sr.statusCode != 0 {
+				return
+			}
+			uc, ok := util.UserClaimsFromContext(r.Context())
+			if !ok {
+				return
+			}
+
+			// the view is kept even if the client is gone by now
+			err := rs.RecordView(context.WithoutCancel(r.Context()), &recommend.ViewRequest{
+				UserID: int32(uc.ID),
+				FilmID: r.PathValue("id"),
+			})
+			if err != nil {
+				log.Printf("ERROR: failed to record film view err=%s\n", err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package recommend
+
+import (
+	"math"
+	"slices"
+
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+func ToFeatures(f *film.Film, cast []*film.ActorShort) *Features {
+	ft := &Features{
+		ID:          f.ID,
+		Name:        f.Name,
+		ReleaseDate: f.ReleaseDate,
+		Rating:      f.Rating,
+		Cast:        make([]int32, 0, len(cast)),
+	}
+	for _, v := range cast {
+		ft.Cast = append(ft.Cast, v.ID)
+	}
+	slices.Sort(ft.Cast)
+
+	return ft
+}
+
+// ToScoredResponse rounds the score to three decimals.
+func ToScoredResponse(s *Scored) *ScoredResponse {
+	res := &ScoredResponse{
+		ID:    s.Film.ID,
+		Name:  s.Film.Name,
+		Score: math.Round(s.Score*1000) / 1000,
+	}
+	if s.BecauseOf != nil {
+		res.BecauseOf = &FilmShortResponse{
+			ID:   s.BecauseOf.ID,
+			Name: s.BecauseOf.Name,
+		}
+	}
+
+	return res
+}
+
+func ToScoredResponses(scored []*Scored) []*ScoredResponse {
+	res := make([]*ScoredResponse, 0, len(scored))
+	for _, v := range scored {
+		res = append(res, ToScoredResponse(v))
+	}
+
+	return res
+}
@@ -0,0 +1,81 @@
+package recommend
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ RecommendHandler = (*Handler)(nil)
+
+type Handler struct {
+	service RecommendService
+}
+
+func NewHandler(rs RecommendService) *Handler {
+	return &Handler{
+		service: rs,
+	}
+}
+
+func (h *Handler) GetSimilarFilms(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetSimilarFilms(r.Context(), &SimilarRequest{
+		ID:    r.PathValue("id"),
+		Limit: r.URL.Query().Get("limit"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get similar films err=%s\n", err.Error())
+
+		var ve *util.ValidationError
+		if errors.As(err, &ve) {
+			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeValidation,
+				Body:      ve.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, ErrIdInvalid) || errors.Is(err, ErrFilmNotExist) {
+			util.NotFound(w, r)
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	uc, ok := util.UserClaimsFromContext(r.Context())
+	if !ok {
+		log.Printf("ERROR: recommendations without authenticated user\n")
+		util.InternalServerError(w, r)
+		return
+	}
+
+	res, err := h.service.GetRecommendations(r.Context(), &RecommendationsRequest{
+		UserID: int32(uc.ID),
+		Limit:  r.URL.Query().Get("limit"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to get recommendations err=%s\n", err.Error())
+
+		var ve *util.ValidationError
+		if errors.As(err, &ve) {
+			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeValidation,
+				Body:      ve.Error(),
+			})
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
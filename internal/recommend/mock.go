@@ -0,0 +1,184 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/recommend/recommend.go
+//
+// Generated by this command:
+//
+//	mockgen -self_package=github.com/Coderovshik/film-library/internal/recommend -package=recommend -source=internal/recommend/recommend.go -destination=internal/recommend/mock.go
+//
+
+// Package recommend is a generated GoMock package.
+package recommend
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockViewRepository is a mock of ViewRepository interface.
+type MockViewRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockViewRepositoryMockRecorder
+}
+
+// MockViewRepositoryMockRecorder is the mock recorder for MockViewRepository.
+type MockViewRepositoryMockRecorder struct {
+	mock *MockViewRepository
+}
+
+// NewMockViewRepository creates a new mock instance.
+func NewMockViewRepository(ctrl *gomock.Controller) *MockViewRepository {
+	mock := &MockViewRepository{ctrl: ctrl}
+	mock.recorder = &MockViewRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockViewRepository) EXPECT() *MockViewRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddView mocks base method.
+func (m *MockViewRepository) AddView(ctx context.Context, v *View, keep int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddView", ctx, v, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddView indicates an expected call of AddView.
+func (mr *MockViewRepositoryMockRecorder) AddView(ctx, v, keep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddView", reflect.TypeOf((*MockViewRepository)(nil).AddView), ctx, v, keep)
+}
+
+// GetViews mocks base method.
+func (m *MockViewRepository) GetViews(ctx context.Context, userID int32, limit int) ([]*View, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetViews", ctx, userID, limit)
+	ret0, _ := ret[0].([]*View)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetViews indicates an expected call of GetViews.
+func (mr *MockViewRepositoryMockRecorder) GetViews(ctx, userID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetViews", reflect.TypeOf((*MockViewRepository)(nil).GetViews), ctx, userID, limit)
+}
+
+// MockRecommendService is a mock of RecommendService interface.
+type MockRecommendService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecommendServiceMockRecorder
+}
+
+// MockRecommendServiceMockRecorder is the mock recorder for MockRecommendService.
+type MockRecommendServiceMockRecorder struct {
+	mock *MockRecommendService
+}
+
+// NewMockRecommendService creates a new mock instance.
+func NewMockRecommendService(ctrl *gomock.Controller) *MockRecommendService {
+	mock := &MockRecommendService{ctrl: ctrl}
+	mock.recorder = &MockRecommendServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecommendService) EXPECT() *MockRecommendServiceMockRecorder {
+	return m.recorder
+}
+
+// GetRecommendations mocks base method.
+func (m *MockRecommendService) GetRecommendations(ctx context.Context, req *RecommendationsRequest) ([]*ScoredResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecommendations", ctx, req)
+	ret0, _ := ret[0].([]*ScoredResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecommendations indicates an expected call of GetRecommendations.
+func (mr *MockRecommendServiceMockRecorder) GetRecommendations(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecommendations", reflect.TypeOf((*MockRecommendService)(nil).GetRecommendations), ctx, req)
+}
+
+// GetSimilarFilms mocks base method.
+func (m *MockRecommendService) GetSimilarFilms(ctx context.Context, req *SimilarRequest) ([]*ScoredResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSimilarFilms", ctx, req)
+	ret0, _ := ret[0].([]*ScoredResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSimilarFilms indicates an expected call of GetSimilarFilms.
+func (mr *MockRecommendServiceMockRecorder) GetSimilarFilms(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSimilarFilms", reflect.TypeOf((*MockRecommendService)(nil).GetSimilarFilms), ctx, req)
+}
+
+// RecordView mocks base method.
+func (m *MockRecommendService) RecordView(ctx context.Context, req *ViewRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordView", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordView indicates an expected call of RecordView.
+func (mr *MockRecommendServiceMockRecorder) RecordView(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordView", reflect.TypeOf((*MockRecommendService)(nil).RecordView), ctx, req)
+}
+
+// MockRecommendHandler is a mock of RecommendHandler interface.
+type MockRecommendHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecommendHandlerMockRecorder
+}
+
+// MockRecommendHandlerMockRecorder is the mock recorder for MockRecommendHandler.
+type MockRecommendHandlerMockRecorder struct {
+	mock *MockRecommendHandler
+}
+
+// NewMockRecommendHandler creates a new mock instance.
+func NewMockRecommendHandler(ctrl *gomock.Controller) *MockRecommendHandler {
+	mock := &MockRecommendHandler{ctrl: ctrl}
+	mock.recorder = &MockRecommendHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecommendHandler) EXPECT() *MockRecommendHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetRecommendations mocks base method.
+func (m *MockRecommendHandler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetRecommendations", w, r)
+}
+
+// GetRecommendations indicates an expected call of GetRecommendations.
+func (mr *MockRecommendHandlerMockRecorder) GetRecommendations(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecommendations", reflect.TypeOf((*MockRecommendHandler)(nil).GetRecommendations), w, r)
+}
+
+// GetSimilarFilms mocks base method.
+func (m *MockRecommendHandler) GetSimilarFilms(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetSimilarFilms", w, r)
+}
+
+// GetSimilarFilms indicates an expected call of GetSimilarFilms.
+func (mr *MockRecommendHandlerMockRecorder) GetSimilarFilms(w, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSimilarFilms", reflect.TypeOf((*MockRecommendHandler)(nil).GetSimilarFilms), w, r)
+}
@@ -0,0 +1,104 @@
+// Package recommend suggests films like a given one, scored by shared
+// cast, release era and rating, and films like the ones a user viewed
+// recently.
+package recommend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+	// seedViews is the number of latest views recommendations are based on.
+	seedViews = 10
+	// keptViews is the number of latest views kept per user.
+	keptViews = 50
+)
+
+var (
+	ErrIdInvalid    = errors.New("invalid id")
+	ErrFilmNotExist = errors.New("film does not exist")
+)
+
+// Weights of the similarity components, films released EraYears apart
+// or more are no longer of the same era.
+type Weights struct {
+	Cast     float64
+	Era      float64
+	Rating   float64
+	EraYears float64
+}
+
+// Features are what films are compared by, Cast holds sorted actor ids.
+type Features struct {
+	ID          int32
+	Name        string
+	ReleaseDate time.Time
+	Rating      int32
+	Cast        []int32
+}
+
+// Scored is a film with its similarity from 0 to 1, BecauseOf is the
+// viewed film it is most similar to for recommendations.
+type Scored struct {
+	Film      *Features
+	Score     float64
+	BecauseOf *Features
+}
+
+type View struct {
+	UserID   int32
+	FilmID   int32
+	ViewedAt time.Time
+}
+
+type ViewRepository interface {
+	// AddView records the view, a repeated view of a film replaces the
+	// earlier one. Only the latest keep views of the user are kept.
+	AddView(ctx context.Context, v *View, keep int) error
+	// GetViews returns up to limit views of films not in the trash,
+	// latest first.
+	GetViews(ctx context.Context, userID int32, limit int) ([]*View, error)
+}
+
+type RecommendService interface {
+	GetSimilarFilms(ctx context.Context, req *SimilarRequest) ([]*ScoredResponse, error)
+	GetRecommendations(ctx context.Context, req *RecommendationsRequest) ([]*ScoredResponse, error)
+	RecordView(ctx context.Context, req *ViewRequest) error
+}
+
+type RecommendHandler interface {
+	GetSimilarFilms(w http.ResponseWriter, r *http.Request)
+	GetRecommendations(w http.ResponseWriter, r *http.Request)
+}
+
+type SimilarRequest struct {
+	ID    string
+	Limit string
+}
+
+type RecommendationsRequest struct {
+	UserID int32
+	Limit  string
+}
+
+type ViewRequest struct {
+	UserID int32
+	FilmID string
+}
+
+type FilmShortResponse struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type ScoredResponse struct {
+	ID        int32              `json:"id"`
+	Name      string             `json:"name"`
+	Score     float64            `json:"score"`
+	BecauseOf *FilmShortResponse `json:"becauseOf,omitempty"`
+}
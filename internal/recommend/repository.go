@@ -0,0 +1,80 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ ViewRepository = (*Repository)(nil)
+
+type Repository struct {
+	db      db.DBTX
+	dialect db.Dialect
+}
+
+func NewRepository(conn db.DBTX, d db.Dialect) *Repository {
+	return &Repository{
+		db:      conn,
+		dialect: d,
+	}
+}
+
+func (r *Repository) AddView(ctx context.Context, v *View, keep int) error {
+	const op = "recommend.Repository.AddView"
+
+	const query = `
+		INSERT INTO film_views (user_id, movie_id, viewed_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, movie_id) DO UPDATE SET viewed_at = excluded.viewed_at`
+	if _, err := r.db.ExecContext(ctx, query, v.UserID, v.FilmID, v.ViewedAt); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	const prune = `
+		DELETE FROM film_views WHERE user_id = $1 AND movie_id NOT IN (
+			SELECT movie_id FROM film_views WHERE user_id = $1
+			ORDER BY viewed_at DESC, movie_id DESC LIMIT $2)`
+	if _, err := r.db.ExecContext(ctx, prune, v.UserID, keep); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetViews(ctx context.Context, userID int32, limit int) ([]*View, error) {
+	const op = "recommend.Repository.GetViews"
+
+	const query = `
+		SELECT v.user_id, v.movie_id, v.viewed_at
+		FROM film_views v
+		INNER JOIN movie m ON m.movie_id = v.movie_id AND m.deleted_at IS NULL
+		WHERE v.user_id = $1
+		ORDER BY v.viewed_at DESC, v.movie_id DESC
+		LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	views := make([]*View, 0)
+	for rows.Next() {
+		var v View
+		if err := rows.Scan(&v.UserID, &v.FilmID, &v.ViewedAt); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		views = append(views, &v)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return views, nil
+}
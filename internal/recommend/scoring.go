@@ -0,0 +1,127 @@
+package recommend
+
+import (
+	"math"
+	"sort"
+)
+
+const hoursPerYear = 365.25 * 24
+
+// Similarity is the weighted mean of the cast Jaccard index, the era
+// proximity and the rating closeness of the films, from 0 to 1.
+func Similarity(a, b *Features, w *Weights) float64 {
+	total := w.Cast + w.Era + w.Rating
+	if total == 0 {
+		return 0
+	}
+
+	score := w.Cast*jaccard(a.Cast, b.Cast) +
+		w.Era*eraProximity(a, b, w.EraYears) +
+		w.Rating*(1-math.Abs(float64(a.Rating-b.Rating))/10)
+
+	return score / total
+}
+
+// jaccard divides the number of shared actors by the number of actors
+// of either film, the ids are sorted.
+func jaccard(a, b []int32) float64 {
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(union)
+}
+
+// eraProximity falls linearly from 1 for films released together to 0
+// for films eraYears apart.
+func eraProximity(a, b *Features, eraYears float64) float64 {
+	years := math.Abs(a.ReleaseDate.Sub(b.ReleaseDate).Hours()) / hoursPerYear
+	if eraYears <= 0 {
+		if years == 0 {
+			return 1
+		}
+		return 0
+	}
+
+	return max(0, 1-years/eraYears)
+}
+
+// Similar returns up to limit films most similar to the film, ties are
+// ordered by id.
+func Similar(f *Features, films []*Features, w *Weights, limit int) []*Scored {
+	scored := make([]*Scored, 0, len(films))
+	for _, v := range films {
+		if v.ID != f.ID {
+			scored = append(scored, &Scored{Film: v, Score: Similarity(f, v, w)})
+		}
+	}
+
+	return best(scored, limit)
+}
+
+// Recommend scores films not among the seeds by their similarity to the
+// seeds, the earlier seeds weigh more. BecauseOf is the seed adding the
+// most to the score.
+func Recommend(seeds, films []*Features, w *Weights, limit int) []*Scored {
+	seen := make(map[int32]bool, len(seeds))
+	for _, v := range seeds {
+		seen[v.ID] = true
+	}
+
+	var total float64
+	for i := range seeds {
+		total += seedWeight(i)
+	}
+
+	scored := make([]*Scored, 0, len(films))
+	for _, v := range films {
+		if seen[v.ID] || len(seeds) == 0 {
+			continue
+		}
+
+		s := &Scored{Film: v}
+		var top float64
+		for i, seed := range seeds {
+			part := seedWeight(i) * Similarity(seed, v, w)
+			s.Score += part
+			if s.BecauseOf == nil || part > top {
+				s.BecauseOf, top = seed, part
+			}
+		}
+		s.Score /= total
+		scored = append(scored, s)
+	}
+
+	return best(scored, limit)
+}
+
+// seedWeight is the weight of the i-th latest view.
+func seedWeight(i int) float64 {
+	return 1 / float64(i+1)
+}
+
+func best(scored []*Scored, limit int) []*Scored {
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].Film.ID < scored[j].Film.ID
+	})
+
+	return scored[:min(len(scored), limit)]
+}
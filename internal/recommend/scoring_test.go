@@ -0,0 +1,94 @@
+package recommend
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func features(id int32, year int, rating int32, cast ...int32) *Features {
+	return &Features{
+		ID:          id,
+		Name:        "film",
+		ReleaseDate: time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC),
+		Rating:      rating,
+		Cast:        cast,
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	w := &Weights{Cast: 1, EraYears: 20}
+
+	tests := []struct {
+		a, b *Features
+		w    *Weights
+		exp  float64
+	}{
+		{features(1, 2000, 5, 1, 2, 3), features(2, 2000, 5, 2, 3, 4), w, 0.5},
+		{features(1, 2000, 5, 1, 2), features(2, 2000, 5, 3), w, 0},
+		{features(1, 2000, 5), features(2, 2000, 5), w, 0},
+		{features(1, 1990, 5), features(2, 2000, 5), &Weights{Era: 1, EraYears: 20}, 0.5},
+		{features(1, 1970, 5), features(2, 2000, 5), &Weights{Era: 1, EraYears: 20}, 0},
+		{features(1, 2000, 9), features(2, 2000, 6), &Weights{Rating: 1}, 0.7},
+		// weighted mean of cast 1, era 0.5 and rating 0.9
+		{features(1, 1990, 9, 1), features(2, 2000, 8, 1), &Weights{Cast: 2, Era: 1, Rating: 1, EraYears: 20}, 0.85},
+		{features(1, 2000, 9, 1), features(2, 2000, 9, 1), &Weights{}, 0},
+	}
+	for _, tt := range tests {
+		if res := Similarity(tt.a, tt.b, tt.w); math.Abs(res-tt.exp) > 0.001 {
+			t.Errorf("Expected %v for %+v and %+v, got %v", tt.exp, tt.a, tt.b, res)
+		}
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	w := &Weights{Cast: 0.6, Era: 0.25, Rating: 0.15, EraYears: 20}
+	target := features(1, 1994, 9, 1, 2)
+	films := []*Features{
+		target,
+		features(2, 1997, 8, 2),
+		features(3, 1994, 9),
+		features(4, 1994, 9),
+		features(5, 1980, 1, 1, 2),
+	}
+
+	res := Similar(target, films, w, 3)
+	var ids []int32
+	for _, v := range res {
+		ids = append(ids, v.Film.ID)
+	}
+	// shared cast weighs most, the rest are ordered by id
+	if exp := []int32{5, 2, 3}; len(ids) != len(exp) || ids[0] != exp[0] || ids[1] != exp[1] || ids[2] != exp[2] {
+		t.Errorf("Expected %v, got %v", exp, ids)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	w := &Weights{Cast: 1, EraYears: 20}
+	seeds := []*Features{features(1, 2000, 5, 1), features(2, 2000, 5, 2)}
+	films := []*Features{
+		seeds[0], seeds[1],
+		features(3, 2000, 5, 1),
+		features(4, 2000, 5, 2),
+		features(5, 2000, 5, 3),
+	}
+
+	res := Recommend(seeds, films, w, 10)
+	if len(res) != 3 {
+		t.Fatalf("Expected %d films, got %d", 3, len(res))
+	}
+	// the latest view weighs more
+	if res[0].Film.ID != 3 || res[0].BecauseOf.ID != 1 || math.Abs(res[0].Score-2.0/3) > 0.001 {
+		t.Errorf("Expected film 3 because of film 1 scored 2/3, got %+v", res[0])
+	}
+	if res[1].Film.ID != 4 || res[1].BecauseOf.ID != 2 || math.Abs(res[1].Score-1.0/3) > 0.001 {
+		t.Errorf("Expected film 4 because of film 2 scored 1/3, got %+v", res[1])
+	}
+	if res[2].Film.ID != 5 || res[2].Score != 0 {
+		t.Errorf("Expected film 5 scored 0, got %+v", res[2])
+	}
+
+	if res := Recommend(nil, films, w, 10); len(res) != 0 {
+		t.Errorf("Expected no films without seeds, got %+v", res)
+	}
+}
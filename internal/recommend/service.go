@@ -0,0 +1,137 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+var _ RecommendService = (*Service)(nil)
+
+type Service struct {
+	films   film.FilmRepository
+	views   ViewRepository
+	weights Weights
+}
+
+func NewService(fr film.FilmRepository, vr ViewRepository, w Weights) *Service {
+	return &Service{
+		films:   fr,
+		views:   vr,
+		weights: w,
+	}
+}
+
+// features reads the films not in the trash with their cast.
+func (s *Service) features(ctx context.Context) ([]*Features, error) {
+	films := make([]*Features, 0)
+	err := s.films.ExportFilms(ctx, &film.ExportQuery{}, func(f *film.Film, cast []*film.ActorShort) error {
+		films = append(films, ToFeatures(f, cast))
+		return nil
+	})
+
+	return films, err
+}
+
+func (s *Service) GetSimilarFilms(ctx context.Context, req *SimilarRequest) ([]*ScoredResponse, error) {
+	const op = "recommend.Service.GetSimilarFilms"
+
+	id, err := strconv.ParseUint(req.ID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return nil, fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+	vErr := ValidateLimit(req.Limit)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	// validated by ValidateLimit
+	limit := defaultLimit
+	if len(req.Limit) != 0 {
+		limit, _ = strconv.Atoi(req.Limit)
+	}
+
+	films, err := s.features(ctx)
+	if err != nil {
+		log.Printf("ERROR: failed to get films\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	i := slices.IndexFunc(films, func(f *Features) bool { return f.ID == int32(id) })
+	if i < 0 {
+		log.Printf("ERROR: film not found id=%d\n", id)
+		return nil, fmt.Errorf("%s: %w", op, ErrFilmNotExist)
+	}
+
+	return ToScoredResponses(Similar(films[i], films, &s.weights, limit)), nil
+}
+
+func (s *Service) GetRecommendations(ctx context.Context, req *RecommendationsRequest) ([]*ScoredResponse, error) {
+	const op = "recommend.Service.GetRecommendations"
+
+	vErr := ValidateLimit(req.Limit)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	// validated by ValidateLimit
+	limit := defaultLimit
+	if len(req.Limit) != 0 {
+		limit, _ = strconv.Atoi(req.Limit)
+	}
+
+	views, err := s.views.GetViews(ctx, req.UserID, seedViews)
+	if err != nil {
+		log.Printf("ERROR: failed to get views\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(views) == 0 {
+		return make([]*ScoredResponse, 0), nil
+	}
+
+	films, err := s.features(ctx)
+	if err != nil {
+		log.Printf("ERROR: failed to get films\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	seeds := make([]*Features, 0, len(views))
+	for _, v := range views {
+		if i := slices.IndexFunc(films, func(f *Features) bool { return f.ID == v.FilmID }); i >= 0 {
+			seeds = append(seeds, films[i])
+		}
+	}
+
+	return ToScoredResponses(Recommend(seeds, films, &s.weights, limit)), nil
+}
+
+// RecordView records that the user viewed the film just now.
+func (s *Service) RecordView(ctx context.Context, req *ViewRequest) error {
+	const op = "recommend.Service.RecordView"
+
+	id, err := strconv.ParseUint(req.FilmID, 10, 32)
+	if err != nil {
+		log.Printf("ERROR: failed id parameter conversion (string -> int32)\n")
+		return fmt.Errorf("%s: %w", op, ErrIdInvalid)
+	}
+
+	err = s.views.AddView(ctx, &View{
+		UserID:   req.UserID,
+		FilmID:   int32(id),
+		ViewedAt: db.Now(),
+	}, keptViews)
+	if err != nil {
+		log.Printf("ERROR: failed to add view\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
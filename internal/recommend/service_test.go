@@ -0,0 +1,132 @@
+package recommend
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+var testWeights = Weights{Cast: 1, EraYears: 20}
+
+// exportFilms streams Matrix and Speed with Keanu Reeves and Pulp Fiction
+// without him.
+func exportFilms(ctx context.Context, q *film.ExportQuery, fn func(f *film.Film, cast []*film.ActorShort) error) error {
+	keanu := &film.ActorShort{ID: 1, Name: "Keanu Reeves"}
+	films := []struct {
+		f    *film.Film
+		cast []*film.ActorShort
+	}{
+		{&film.Film{ID: 1, Name: "The Matrix", ReleaseDate: time.Date(1999, 3, 31, 0, 0, 0, 0, time.UTC), Rating: 9}, []*film.ActorShort{keanu}},
+		{&film.Film{ID: 2, Name: "Pulp Fiction", ReleaseDate: time.Date(1994, 5, 21, 0, 0, 0, 0, time.UTC), Rating: 9}, []*film.ActorShort{{ID: 2, Name: "Uma Thurman"}}},
+		{&film.Film{ID: 3, Name: "Speed", ReleaseDate: time.Date(1994, 6, 10, 0, 0, 0, 0, time.UTC), Rating: 7}, []*film.ActorShort{keanu}},
+	}
+	for _, v := range films {
+		if err := fn(v.f, v.cast); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestService_GetSimilarFilms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := film.NewMockFilmRepository(ctrl)
+	vm := NewMockViewRepository(ctrl)
+
+	s := NewService(fm, vm, testWeights)
+
+	fm.EXPECT().ExportFilms(gomock.Any(), &film.ExportQuery{}, gomock.Any()).DoAndReturn(exportFilms).Times(2)
+
+	exp := []*ScoredResponse{{ID: 3, Name: "Speed", Score: 1}}
+	res, err := s.GetSimilarFilms(context.TODO(), &SimilarRequest{ID: "1", Limit: "1"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	_, err = s.GetSimilarFilms(context.TODO(), &SimilarRequest{ID: "4"})
+	if !errors.Is(err, ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", ErrFilmNotExist, err)
+	}
+
+	var ve *util.ValidationError
+	_, err = s.GetSimilarFilms(context.TODO(), &SimilarRequest{ID: "1", Limit: "51"})
+	if !errors.As(err, &ve) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestService_GetRecommendations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := film.NewMockFilmRepository(ctrl)
+	vm := NewMockViewRepository(ctrl)
+
+	s := NewService(fm, vm, testWeights)
+
+	vm.EXPECT().GetViews(gomock.Any(), int32(7), seedViews).Return([]*View{{UserID: 7, FilmID: 3}}, nil).Times(1)
+	fm.EXPECT().ExportFilms(gomock.Any(), &film.ExportQuery{}, gomock.Any()).DoAndReturn(exportFilms).Times(1)
+
+	exp := []*ScoredResponse{
+		{ID: 1, Name: "The Matrix", Score: 1, BecauseOf: &FilmShortResponse{ID: 3, Name: "Speed"}},
+		{ID: 2, Name: "Pulp Fiction", Score: 0, BecauseOf: &FilmShortResponse{ID: 3, Name: "Speed"}},
+	}
+	res, err := s.GetRecommendations(context.TODO(), &RecommendationsRequest{UserID: 7})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+}
+
+func TestService_GetRecommendationsWithoutViews(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := film.NewMockFilmRepository(ctrl)
+	vm := NewMockViewRepository(ctrl)
+
+	s := NewService(fm, vm, testWeights)
+
+	vm.EXPECT().GetViews(gomock.Any(), int32(7), seedViews).Return([]*View{}, nil).Times(1)
+
+	res, err := s.GetRecommendations(context.TODO(), &RecommendationsRequest{UserID: 7})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(res) != 0 {
+		t.Errorf("Expected no films, got %+v", res)
+	}
+}
+
+func TestService_RecordView(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := film.NewMockFilmRepository(ctrl)
+	vm := NewMockViewRepository(ctrl)
+
+	s := NewService(fm, vm, testWeights)
+
+	vm.EXPECT().
+		AddView(gomock.Any(), gomock.Any(), keptViews).
+		DoAndReturn(func(ctx context.Context, v *View, keep int) error {
+			if v.UserID != 7 || v.FilmID != 3 || v.ViewedAt.IsZero() {
+				t.Errorf("Unexpected view %+v", v)
+			}
+			return nil
+		}).
+		Times(1)
+
+	if err := s.RecordView(context.TODO(), &ViewRequest{UserID: 7, FilmID: "3"}); err != nil {
+		t.Errorf("No error expected, got %s", err.Error())
+	}
+	if err := s.RecordView(context.TODO(), &ViewRequest{UserID: 7, FilmID: "x"}); !errors.Is(err, ErrIdInvalid) {
+		t.Errorf("Expected %v, got %v", ErrIdInvalid, err)
+	}
+}
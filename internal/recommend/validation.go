@@ -0,0 +1,22 @@
+package recommend
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+func ValidateLimit(limit string) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if n, err := strconv.Atoi(limit); len(limit) != 0 && (err != nil || n < 1 || n > maxLimit) {
+		ve.AddViolation(fmt.Sprintf("incorrect limit, expected integer from 1 to %d", maxLimit))
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
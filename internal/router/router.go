@@ -4,9 +4,23 @@ import (
 	"net/http"
 
 	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/autocomplete"
+	"github.com/Coderovshik/film-library/internal/batch"
+	"github.com/Coderovshik/film-library/internal/collection"
 	"github.com/Coderovshik/film-library/internal/config"
+	"github.com/Coderovshik/film-library/internal/export"
 	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/follow"
+	"github.com/Coderovshik/film-library/internal/graph"
+	"github.com/Coderovshik/film-library/internal/history"
+	"github.com/Coderovshik/film-library/internal/idempotency"
+	"github.com/Coderovshik/film-library/internal/importer"
+	"github.com/Coderovshik/film-library/internal/lending"
 	"github.com/Coderovshik/film-library/internal/middleware"
+	"github.com/Coderovshik/film-library/internal/recommend"
+	"github.com/Coderovshik/film-library/internal/search"
+	"github.com/Coderovshik/film-library/internal/trash"
 	"github.com/Coderovshik/film-library/internal/user"
 )
 
@@ -14,12 +28,14 @@ type Router struct {
 	mux *http.ServeMux
 }
 
-func NewRouter(cfg *config.Config, uh user.UserHandler, ah actor.ActorHandler, fh film.FilmHandler) *Router {
+func NewRouter(cfg *config.Config, uh user.UserHandler, ah actor.ActorHandler, fh film.FilmHandler, auh audit.AuditHandler, th trash.TrashHandler, ih importer.ImportHandler, eh export.ExportHandler, bh batch.BatchHandler, sh search.SearchHandler, ach autocomplete.AutocompleteHandler, gh graph.GraphHandler, rh recommend.RecommendHandler, ch collection.CollectionHandler, hh history.HistoryHandler, lh lending.LendingHandler, foh follow.FollowHandler, is idempotency.IdempotencyService, rs recommend.RecommendService) *Router {
 	mux := http.NewServeMux()
 
 	authMW := middleware.NewAuthMiddleware(cfg.SigningKey, false)
 	adminOnlyMW := middleware.NewAuthMiddleware(cfg.SigningKey, true)
 	logMW := middleware.NewLogMiddleware()
+	idempotencyMW := middleware.NewIdempotencyMiddleware(is)
+	viewMW := middleware.NewViewMiddleware(rs)
 
 	mux.HandleFunc("GET /ping", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -39,19 +55,78 @@ func NewRouter(cfg *config.Config, uh user.UserHandler, ah actor.ActorHandler, f
 	mux.Handle("DELETE /signout", logMW(http.HandlerFunc(uh.Logout)))
 
 	mux.Handle("GET /actors", logMW(authMW(http.HandlerFunc(ah.GetActors))))
-	mux.Handle("POST /actors", logMW(adminOnlyMW(http.HandlerFunc(ah.AddActor))))
+	mux.Handle("POST /actors", logMW(adminOnlyMW(idempotencyMW(http.HandlerFunc(ah.AddActor)))))
 	mux.Handle("GET /actors/{id}", logMW(authMW(http.HandlerFunc(ah.GetActor))))
 	mux.Handle("PUT /actors/{id}", logMW(adminOnlyMW(http.HandlerFunc(ah.UpdateActor))))
+	mux.Handle("PATCH /actors/{id}", logMW(adminOnlyMW(http.HandlerFunc(ah.PatchActor))))
 	mux.Handle("DELETE /actors/{id}", logMW(adminOnlyMW(http.HandlerFunc(ah.DeleteActor))))
+	mux.Handle("POST /actors/{id}/restore", logMW(adminOnlyMW(http.HandlerFunc(ah.RestoreActor))))
+	mux.Handle("GET /actors/{id}/history", logMW(authMW(http.HandlerFunc(auh.GetActorHistory))))
+	mux.Handle("GET /actors/{id}/costars", logMW(authMW(http.HandlerFunc(gh.GetCostars))))
+	mux.Handle("GET /actors/{id}/path/{to}", logMW(authMW(http.HandlerFunc(gh.GetPath))))
 
 	mux.Handle("GET /films", logMW(authMW(http.HandlerFunc(fh.GetFilms))))
-	mux.Handle("POST /films", logMW(adminOnlyMW(http.HandlerFunc(fh.AddFilm))))
-	mux.Handle("GET /films/{id}", logMW(authMW(http.HandlerFunc(fh.GetFilm))))
+	mux.Handle("POST /films", logMW(adminOnlyMW(idempotencyMW(http.HandlerFunc(fh.AddFilm)))))
+	mux.Handle("GET /films/{id}", logMW(authMW(viewMW(http.HandlerFunc(fh.GetFilm)))))
+	mux.Handle("GET /films/{id}/similar", logMW(authMW(http.HandlerFunc(rh.GetSimilarFilms))))
 	mux.Handle("PUT /films/{id}", logMW(adminOnlyMW(http.HandlerFunc(fh.UpdateFilm))))
+	mux.Handle("PATCH /films/{id}", logMW(adminOnlyMW(http.HandlerFunc(fh.PatchFilm))))
 	mux.Handle("DELETE /films/{id}", logMW(adminOnlyMW(http.HandlerFunc(fh.DeleteFilm))))
 	mux.Handle("GET /films/{id}/actors", logMW(authMW(http.HandlerFunc(fh.GetFilmActors))))
 	mux.Handle("PUT /films/{id}/actors", logMW(adminOnlyMW(http.HandlerFunc(fh.AddFilmActors))))
 	mux.Handle("DELETE /films/{id}/actors", logMW(adminOnlyMW(http.HandlerFunc(fh.DeleteFilmActors))))
+	mux.Handle("POST /films/{id}/restore", logMW(adminOnlyMW(http.HandlerFunc(fh.RestoreFilm))))
+	mux.Handle("GET /films/{id}/history", logMW(authMW(http.HandlerFunc(auh.GetFilmHistory))))
+
+	mux.Handle("POST /import", logMW(adminOnlyMW(http.HandlerFunc(ih.Import))))
+	mux.Handle("GET /export/films", logMW(authMW(http.HandlerFunc(eh.ExportFilms))))
+	mux.Handle("GET /export/actors", logMW(authMW(http.HandlerFunc(eh.ExportActors))))
+	mux.Handle("POST /batch", logMW(adminOnlyMW(idempotencyMW(http.HandlerFunc(bh.Execute)))))
+
+	mux.Handle("GET /search", logMW(authMW(http.HandlerFunc(sh.Search))))
+	mux.Handle("GET /autocomplete", logMW(authMW(http.HandlerFunc(ach.Suggest))))
+	mux.Handle("GET /me/recommendations", logMW(authMW(http.HandlerFunc(rh.GetRecommendations))))
+
+	mux.Handle("GET /me/lists", logMW(authMW(http.HandlerFunc(ch.GetCollections))))
+	mux.Handle("POST /me/lists", logMW(authMW(idempotencyMW(http.HandlerFunc(ch.AddCollection)))))
+	mux.Handle("GET /me/lists/{id}", logMW(authMW(http.HandlerFunc(ch.GetCollection))))
+	mux.Handle("PATCH /me/lists/{id}", logMW(authMW(http.HandlerFunc(ch.PatchCollection))))
+	mux.Handle("DELETE /me/lists/{id}", logMW(authMW(http.HandlerFunc(ch.DeleteCollection))))
+	mux.Handle("PUT /me/lists/{id}/films/{filmId}", logMW(authMW(http.HandlerFunc(ch.PutEntry))))
+	mux.Handle("DELETE /me/lists/{id}/films/{filmId}", logMW(authMW(http.HandlerFunc(ch.DeleteEntry))))
+	mux.Handle("GET /lists", logMW(authMW(http.HandlerFunc(ch.GetPublicCollections))))
+	// shared lists are read by token without signing in
+	mux.Handle("GET /lists/{token}", logMW(http.HandlerFunc(ch.GetSharedCollection)))
+
+	mux.Handle("GET /me/history", logMW(authMW(http.HandlerFunc(hh.GetHistory))))
+	mux.Handle("POST /me/history", logMW(authMW(idempotencyMW(http.HandlerFunc(hh.AddWatch)))))
+	mux.Handle("DELETE /me/history/{id}", logMW(authMW(http.HandlerFunc(hh.DeleteWatch))))
+	mux.Handle("GET /me/stats", logMW(authMW(http.HandlerFunc(hh.GetStats))))
+
+	mux.Handle("GET /films/{id}/copies", logMW(authMW(http.HandlerFunc(lh.GetCopies))))
+	mux.Handle("POST /films/{id}/copies", logMW(adminOnlyMW(idempotencyMW(http.HandlerFunc(lh.AddCopy)))))
+	mux.Handle("PATCH /copies/{id}", logMW(adminOnlyMW(http.HandlerFunc(lh.PatchCopy))))
+	mux.Handle("DELETE /copies/{id}", logMW(adminOnlyMW(http.HandlerFunc(lh.DeleteCopy))))
+	mux.Handle("GET /loans", logMW(adminOnlyMW(http.HandlerFunc(lh.GetLoans))))
+	mux.Handle("POST /loans", logMW(adminOnlyMW(idempotencyMW(http.HandlerFunc(lh.Checkout)))))
+	mux.Handle("POST /loans/{id}/return", logMW(adminOnlyMW(http.HandlerFunc(lh.ReturnLoan))))
+	// members renew their own loans, admins any of them
+	mux.Handle("POST /loans/{id}/renew", logMW(authMW(http.HandlerFunc(lh.RenewLoan))))
+	mux.Handle("GET /me/loans", logMW(authMW(http.HandlerFunc(lh.GetMyLoans))))
+	mux.Handle("GET /films/{id}/holds", logMW(adminOnlyMW(http.HandlerFunc(lh.GetHolds))))
+	mux.Handle("POST /films/{id}/holds", logMW(authMW(idempotencyMW(http.HandlerFunc(lh.PlaceHold)))))
+	mux.Handle("DELETE /films/{id}/holds", logMW(authMW(http.HandlerFunc(lh.CancelHold))))
+	mux.Handle("GET /me/holds", logMW(authMW(http.HandlerFunc(lh.GetMyHolds))))
+
+	mux.Handle("GET /me/following", logMW(authMW(http.HandlerFunc(foh.GetFollows))))
+	mux.Handle("PUT /me/following/{id}", logMW(authMW(http.HandlerFunc(foh.Follow))))
+	mux.Handle("DELETE /me/following/{id}", logMW(authMW(http.HandlerFunc(foh.Unfollow))))
+	mux.Handle("PUT /me/email", logMW(authMW(http.HandlerFunc(foh.SetEmail))))
+	mux.Handle("GET /me/notifications", logMW(authMW(http.HandlerFunc(foh.GetNotifications))))
+
+	mux.Handle("GET /trash", logMW(adminOnlyMW(http.HandlerFunc(th.GetTrash))))
+
+	mux.Handle("GET /audit", logMW(adminOnlyMW(http.HandlerFunc(auh.GetEntries))))
 
 	return &Router{
 		mux: mux,
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ SearchHandler = (*Handler)(nil)
+
+type Handler struct {
+	service SearchService
+}
+
+func NewHandler(ss SearchService) *Handler {
+	return &Handler{
+		service: ss,
+	}
+}
+
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.Search(r.Context(), &SearchRequest{
+		Query: r.URL.Query().Get("q"),
+		Limit: r.URL.Query().Get("limit"),
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to search err=%s\n", err.Error())
+
+		var ve *util.ValidationError
+		if errors.As(err, &ve) {
+			util.JSON(w, r, http.StatusBadRequest, &util.ErrorMessage{
+				ErrorType: util.ErrorTypeValidation,
+				Body:      ve.Error(),
+			})
+			return
+		}
+
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
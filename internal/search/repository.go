@@ -0,0 +1,152 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/Coderovshik/film-library/internal/db"
+)
+
+var _ SearchRepository = (*Repository)(nil)
+
+type Repository struct {
+	db      db.DBTX
+	dialect db.Dialect
+}
+
+func NewRepository(db db.DBTX, d db.Dialect) *Repository {
+	return &Repository{
+		db:      db,
+		dialect: d,
+	}
+}
+
+// The databases highlight matches between these control characters, the
+// snippet is escaped before they are turned into <b></b> so that the text
+// can not carry markup of its own.
+const (
+	markStart = '\x02'
+	markStop  = '\x03'
+)
+
+// postgresQuery ranks the search_vector columns and highlights only the
+// hits within the limit, ts_headline being costly.
+const postgresQuery = `WITH q AS (SELECT websearch_to_tsquery('russian', $1) AS query),
+	hits AS (
+		SELECT 'film' AS kind, m.movie_id AS id, m.movie_name AS name,
+			m.movie_name || '. ' || m.movie_description AS body, ts_rank(m.search_vector, q.query) AS rank
+		FROM movie m, q
+		WHERE m.search_vector @@ q.query AND m.deleted_at IS NULL
+		UNION ALL
+		SELECT 'actor', a.actor_id, a.actor_name, a.actor_name, ts_rank(a.search_vector, q.query)
+		FROM actor a, q
+		WHERE a.search_vector @@ q.query AND a.deleted_at IS NULL
+		ORDER BY rank DESC, kind, id
+		LIMIT $2
+	)
+	SELECT kind, id, name, rank, ts_headline('russian', body, q.query,
+		'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxWords=30, MinWords=10')
+	FROM hits, q
+	ORDER BY rank DESC, kind, id`
+
+// sqliteQuery ranks by bm25, which is lower for better matches, with film
+// names weighted above descriptions.
+const sqliteQuery = `SELECT kind, id, name, rank, snippet FROM (
+		SELECT 'film' AS kind, m.movie_id AS id, m.movie_name AS name, -bm25(movie_search, 10.0, 1.0) AS rank,
+			snippet(movie_search, -1, char(2), char(3), '…', 16) AS snippet
+		FROM movie_search
+		JOIN movie m ON m.movie_id = movie_search.rowid
+		WHERE movie_search MATCH $1 AND m.deleted_at IS NULL
+		UNION ALL
+		SELECT 'actor', a.actor_id, a.actor_name, -bm25(actor_search),
+			snippet(actor_search, -1, char(2), char(3), '…', 16)
+		FROM actor_search
+		JOIN actor a ON a.actor_id = actor_search.rowid
+		WHERE actor_search MATCH $1 AND a.deleted_at IS NULL
+	)
+	ORDER BY rank DESC, kind, id
+	LIMIT $2`
+
+func (r *Repository) Search(ctx context.Context, q *Query) ([]*Hit, error) {
+	const op = "search.Repository.Search"
+
+	query, text := postgresQuery, q.Text
+	if r.dialect == db.SQLite {
+		query, text = sqliteQuery, matchExpression(q.Text)
+		if len(text) == 0 {
+			return []*Hit{}, nil
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, text, q.Limit)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	hits := make([]*Hit, 0)
+	for rows.Next() {
+		var h Hit
+		if err := rows.Scan(&h.Kind, &h.ID, &h.Name, &h.Rank, &h.Snippet); err != nil {
+			log.Printf("ERROR: failed to scan row\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		h.Snippet = markup(h.Snippet)
+		hits = append(hits, &h)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: failed to iterate over rows\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hits, nil
+}
+
+// markup escapes the snippet and wraps the marked matches in <b></b>, the
+// tags are always balanced even if the text holds stray markers.
+func markup(snippet string) string {
+	var b strings.Builder
+
+	open := false
+	for _, c := range snippet {
+		switch {
+		case c == markStart && !open:
+			b.WriteString("<b>")
+			open = true
+		case c == markStop && open:
+			b.WriteString("</b>")
+			open = false
+		case c == markStart, c == markStop:
+		default:
+			b.WriteString(html.EscapeString(string(c)))
+		}
+	}
+	if open {
+		b.WriteString("</b>")
+	}
+
+	return b.String()
+}
+
+// matchExpression turns the words of the text into an FTS5 query matching
+// all of them, quoting each so that none is read as an operator.
+func matchExpression(text string) string {
+	words := Words(text)
+	for i, v := range words {
+		words[i] = `"` + v + `"`
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Words splits the text into lower-cased runs of letters and digits.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+}
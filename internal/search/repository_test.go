@@ -0,0 +1,21 @@
+package search
+
+import "testing"
+
+func TestMarkup(t *testing.T) {
+	tests := []struct {
+		snippet string
+		exp     string
+	}{
+		{"the \x02matrix\x03 <script>", "the <b>matrix</b> &lt;script&gt;"},
+		// stray markers of the text do not unbalance the tags
+		{"\x03a \x02b\x02 c", "a <b>b c</b>"},
+		{"a & b", "a &amp; b"},
+	}
+
+	for _, v := range tests {
+		if got := markup(v.snippet); got != v.exp {
+			t.Errorf("Expected %q, got %q", v.exp, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package search looks films and actors up by words of their names and
+// film descriptions.
+package search
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	KindFilm  = "film"
+	KindActor = "actor"
+
+	maxQueryLength = 256
+	defaultLimit   = 20
+	maxLimit       = 100
+)
+
+// Hit is a film or an actor matching the query, Snippet is a piece of
+// its HTML escaped text with the matched words wrapped in <b></b>.
+type Hit struct {
+	Kind    string
+	ID      int32
+	Name    string
+	Rank    float64
+	Snippet string
+}
+
+type Query struct {
+	Text  string
+	Limit int
+}
+
+type SearchRepository interface {
+	// Search returns the hits best ranked first, films and actors mixed.
+	Search(ctx context.Context, q *Query) ([]*Hit, error)
+}
+
+type SearchService interface {
+	Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error)
+}
+
+type SearchHandler interface {
+	Search(w http.ResponseWriter, r *http.Request)
+}
+
+type SearchRequest struct {
+	Query string
+	Limit string
+}
+
+type HitResponse struct {
+	Kind    string  `json:"kind"`
+	ID      int32   `json:"id"`
+	Name    string  `json:"name"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type SearchResponse struct {
+	Hits []*HitResponse `json:"hits"`
+}
+
+func ToHitResponse(h *Hit) *HitResponse {
+	return &HitResponse{
+		Kind:    h.Kind,
+		ID:      h.ID,
+		Name:    h.Name,
+		Rank:    h.Rank,
+		Snippet: h.Snippet,
+	}
+}
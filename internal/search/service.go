@@ -0,0 +1,55 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+var _ SearchService = (*Service)(nil)
+
+type Service struct {
+	repo SearchRepository
+}
+
+func NewService(sr SearchRepository) *Service {
+	return &Service{
+		repo: sr,
+	}
+}
+
+func (s *Service) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	const op = "search.Service.Search"
+
+	vErr := ValidateSearchRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	// validated by ValidateSearchRequest
+	limit := defaultLimit
+	if len(req.Limit) != 0 {
+		limit, _ = strconv.Atoi(req.Limit)
+	}
+
+	hits, err := s.repo.Search(ctx, &Query{
+		Text:  strings.TrimSpace(req.Query),
+		Limit: limit,
+	})
+	if err != nil {
+		log.Printf("ERROR: failed to search\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := &SearchResponse{
+		Hits: make([]*HitResponse, 0, len(hits)),
+	}
+	for _, v := range hits {
+		res.Hits = append(res.Hits, ToHitResponse(v))
+	}
+
+	return res, nil
+}
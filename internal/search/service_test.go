@@ -0,0 +1,79 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Coderovshik/film-library/internal/util"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestService_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockSearchRepository(ctrl)
+
+	s := NewService(m)
+
+	hits := []*Hit{
+		{Kind: KindFilm, ID: 1, Name: "Matrix", Rank: 0.9, Snippet: "<b>Matrix</b>"},
+		{Kind: KindActor, ID: 2, Name: "Keanu Reeves", Rank: 0.5, Snippet: "Keanu Reeves"},
+	}
+	m.EXPECT().Search(gomock.Any(), &Query{Text: "matrix", Limit: defaultLimit}).Return(hits, nil).Times(1)
+	m.EXPECT().Search(gomock.Any(), &Query{Text: "reeves", Limit: 5}).Return([]*Hit{}, nil).Times(1)
+
+	exp := &SearchResponse{Hits: []*HitResponse{ToHitResponse(hits[0]), ToHitResponse(hits[1])}}
+	res, err := s.Search(context.TODO(), &SearchRequest{Query: "  matrix "})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	res, err = s.Search(context.TODO(), &SearchRequest{Query: "reeves", Limit: "5"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if res.Hits == nil || len(res.Hits) != 0 {
+		t.Errorf("Expected empty hits, got %+v", res.Hits)
+	}
+}
+
+func TestService_SearchInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockSearchRepository(ctrl)
+
+	s := NewService(m)
+
+	tests := []*SearchRequest{
+		{Query: " "},
+		{Query: strings.Repeat("я", maxQueryLength+1)},
+		{Query: "matrix", Limit: "0"},
+		{Query: "matrix", Limit: "101"},
+		{Query: "matrix", Limit: "ten"},
+	}
+	for _, v := range tests {
+		_, err := s.Search(context.TODO(), v)
+		var ve *util.ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("Expected validation error for %+v, got %v", v, err)
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := map[string]string{
+		"Matrix":              `"matrix"`,
+		`keanu "reeves" OR -`: `"keanu" "reeves" "or"`,
+		"Брат-2":              `"брат" "2"`,
+		"* ^ :":               "",
+	}
+	for in, exp := range tests {
+		if got := matchExpression(in); got != exp {
+			t.Errorf("Expected %q, got %q", exp, got)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+func ValidateSearchRequest(req *SearchRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(strings.TrimSpace(req.Query)) == 0 {
+		ve.AddViolation("empty query")
+	}
+	if utf8.RuneCountInString(req.Query) > maxQueryLength {
+		ve.AddViolation(fmt.Sprintf("query is too long, expected at most %d characters", maxQueryLength))
+	}
+
+	if n, err := strconv.Atoi(req.Limit); len(req.Limit) != 0 && (err != nil || n < 1 || n > maxLimit) {
+		ve.AddViolation(fmt.Sprintf("incorrect limit, expected integer from 1 to %d", maxLimit))
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
@@ -0,0 +1,122 @@
+package seed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown fixture format, expected .json, .yaml or .yml")
+)
+
+// Fixture is a set of catalog records to be loaded into the database.
+// Films reference their cast by actor name, so actor names are unique.
+type Fixture struct {
+	Users  []UserFixture     `json:"users" yaml:"users"`
+	Actors []actor.ActorInfo `json:"actors" yaml:"actors"`
+	Films  []FilmFixture     `json:"films" yaml:"films"`
+}
+
+// UserFixture holds either a plain password, hashed while seeding,
+// or a ready bcrypt hash.
+type UserFixture struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	Passhash string `json:"passhash" yaml:"passhash"`
+	IsAdmin  bool   `json:"isAdmin" yaml:"isAdmin"`
+}
+
+type FilmFixture struct {
+	film.FilmInfo `yaml:",inline"`
+	Actors        []string `json:"actors" yaml:"actors"`
+}
+
+func Load(path string) (*Fixture, error) {
+	const op = "seed.Load"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var f Fixture
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(data, &f)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &f)
+	default:
+		err = ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", op, path, err)
+	}
+
+	return &f, nil
+}
+
+// Merge appends records of other fixtures to f.
+func (f *Fixture) Merge(other *Fixture) {
+	f.Users = append(f.Users, other.Users...)
+	f.Actors = append(f.Actors, other.Actors...)
+	f.Films = append(f.Films, other.Films...)
+}
+
+func (f *Fixture) Validate() *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	for i, v := range f.Users {
+		if len(v.Username) == 0 {
+			ve.AddViolation(fmt.Sprintf("users[%d]: username empty", i))
+		}
+		if len(v.Password) == 0 && len(v.Passhash) == 0 {
+			ve.AddViolation(fmt.Sprintf("users[%d]: either password or passhash expected", i))
+		}
+	}
+
+	// birthdays by actor name, a repeated actor is fine but a namesake is
+	// not since films could not tell them apart
+	names := make(map[string]string, len(f.Actors))
+	for i := range f.Actors {
+		ai := &f.Actors[i]
+		if vErr := actor.ValidateEmptyActorInfo(ai); vErr != nil {
+			ve.AddViolation(fmt.Sprintf("actors[%d]: %s", i, vErr.Error()))
+		}
+		if vErr := actor.ValidateFormatActorInfo(ai); vErr != nil {
+			ve.AddViolation(fmt.Sprintf("actors[%d]: %s", i, vErr.Error()))
+		}
+		if birthday, ok := names[ai.Name]; ok && birthday != ai.Birthday {
+			ve.AddViolation(fmt.Sprintf("actors[%d]: duplicate actor name %q", i, ai.Name))
+		}
+		names[ai.Name] = ai.Birthday
+	}
+
+	for i := range f.Films {
+		fi := &f.Films[i]
+		if vErr := film.ValidateEmptyFilmInfo(&fi.FilmInfo); vErr != nil {
+			ve.AddViolation(fmt.Sprintf("films[%d]: %s", i, vErr.Error()))
+		}
+		if vErr := film.ValidateFormatFilmInfo(&fi.FilmInfo); vErr != nil {
+			ve.AddViolation(fmt.Sprintf("films[%d]: %s", i, vErr.Error()))
+		}
+		for _, name := range fi.Actors {
+			if _, ok := names[name]; !ok {
+				ve.AddViolation(fmt.Sprintf("films[%d]: unknown actor %q", i, name))
+			}
+		}
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
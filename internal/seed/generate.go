@@ -0,0 +1,76 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+type GenerateOptions struct {
+	Films    int
+	Actors   int
+	Bindings int
+	// Seed makes generation reproducible, equal options produce equal
+	// fixtures and therefore seed the same records.
+	Seed int64
+}
+
+func randomDate(rnd *rand.Rand, fromYear, toYear int) string {
+	from := time.Date(fromYear, time.January, 1, 0, 0, 0, 0, time.UTC)
+	days := int(time.Date(toYear, time.December, 31, 0, 0, 0, 0, time.UTC).Sub(from).Hours() / 24)
+
+	return from.AddDate(0, 0, rnd.Intn(days+1)).Format(time.DateOnly)
+}
+
+// Generate returns a synthetic fixture for load testing. Bindings are
+// capped by the number of distinct film-actor pairs.
+func Generate(opts GenerateOptions) *Fixture {
+	rnd := rand.New(rand.NewSource(opts.Seed))
+	f := &Fixture{}
+
+	sexes := []string{"male", "female"}
+	for i := 0; i < opts.Actors; i++ {
+		f.Actors = append(f.Actors, actor.ActorInfo{
+			Name:     fmt.Sprintf("Synthetic Actor %06d", i+1),
+			Sex:      sexes[rnd.Intn(len(sexes))],
+			Birthday: randomDate(rnd, 1930, 2010),
+		})
+	}
+
+	for i := 0; i < opts.Films; i++ {
+		name := fmt.Sprintf("Synthetic Film %06d", i+1)
+		f.Films = append(f.Films, FilmFixture{
+			FilmInfo: film.FilmInfo{
+				Name:        name,
+				Description: "about " + name,
+				ReleaseDate: randomDate(rnd, 1950, 2025),
+				Rating:      rnd.Intn(11),
+			},
+		})
+	}
+
+	if opts.Films == 0 || opts.Actors == 0 {
+		return f
+	}
+
+	n := min(opts.Bindings, opts.Films*opts.Actors)
+	bound := make(map[[2]int]bool, n)
+	// go over films round-robin so bindings spread evenly, picking
+	// the next unbound actor after a random one
+	for i := 0; len(bound) < n; i++ {
+		fi, start := i%opts.Films, rnd.Intn(opts.Actors)
+		for j := 0; j < opts.Actors; j++ {
+			ai := (start + j) % opts.Actors
+			if !bound[[2]int{fi, ai}] {
+				bound[[2]int{fi, ai}] = true
+				f.Films[fi].Actors = append(f.Films[fi].Actors, f.Actors[ai].Name)
+				break
+			}
+		}
+	}
+
+	return f
+}
@@ -0,0 +1,197 @@
+// Package seed loads demo and synthetic catalog data. Seeding is
+// idempotent: users are matched by username, actors by name and birthday,
+// films by name and release date, so running it twice creates nothing new.
+// Actors and films in the trash are not matched, they are seeded anew.
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/user"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Counter struct {
+	Created int
+	Skipped int
+}
+
+type Report struct {
+	Users    Counter
+	Actors   Counter
+	Films    Counter
+	Bindings Counter
+}
+
+func (r *Report) String() string {
+	return fmt.Sprintf("users %+v, actors %+v, films %+v, bindings %+v",
+		r.Users, r.Actors, r.Films, r.Bindings)
+}
+
+type Seeder struct {
+	db     db.DBTX
+	users  user.UserRepository
+	actors actor.ActorRepository
+	films  film.FilmRepository
+}
+
+// NewSeeder returns a seeder writing through dbtx, usually a transaction,
+// so that a failed run leaves nothing behind.
+func NewSeeder(dbtx db.DBTX, d db.Dialect) *Seeder {
+	return &Seeder{
+		db:     dbtx,
+		users:  user.NewRepository(dbtx, d),
+		actors: actor.NewRepository(dbtx, d),
+		films:  film.NewRepository(dbtx, d),
+	}
+}
+
+func (s *Seeder) Seed(ctx context.Context, f *Fixture) (*Report, error) {
+	const op = "seed.Seeder.Seed"
+
+	if vErr := f.Validate(); vErr != nil {
+		log.Printf("ERROR: failed fixture validation\n")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	var rep Report
+
+	for _, v := range f.Users {
+		if err := s.seedUser(ctx, &v, &rep.Users); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	actorIDs := make(map[string]int32, len(f.Actors))
+	for _, v := range f.Actors {
+		id, err := s.seedActor(ctx, &v, &rep.Actors)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		actorIDs[v.Name] = id
+	}
+
+	for _, v := range f.Films {
+		if err := s.seedFilm(ctx, &v, actorIDs, &rep); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return &rep, nil
+}
+
+func (s *Seeder) seedUser(ctx context.Context, uf *UserFixture, c *Counter) error {
+	var exists bool
+	const query = `SELECT EXISTS (SELECT 1 FROM users WHERE user_name = $1)`
+	if err := s.db.QueryRowContext(ctx, query, uf.Username).Scan(&exists); err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+	if exists {
+		c.Skipped++
+		return nil
+	}
+
+	passhash := uf.Passhash
+	if len(passhash) == 0 {
+		b, err := bcrypt.GenerateFromPassword([]byte(uf.Password), bcrypt.DefaultCost)
+		if err != nil {
+			log.Printf("ERROR: failed to generate password hash\n")
+			return err
+		}
+		passhash = string(b)
+	}
+
+	_, err := s.users.CreateUser(ctx, &user.User{
+		Username: uf.Username,
+		Passhash: passhash,
+		IsAdmin:  uf.IsAdmin,
+	})
+	if err != nil {
+		return err
+	}
+	c.Created++
+
+	return nil
+}
+
+func (s *Seeder) seedActor(ctx context.Context, ai *actor.ActorInfo, c *Counter) (int32, error) {
+	a := actor.ToActor(ai)
+
+	var id int32
+	const query = `SELECT actor_id FROM actor WHERE actor_name = $1 AND birthday = $2 AND deleted_at IS NULL`
+	err := s.db.QueryRowContext(ctx, query, a.Name, a.Birthday).Scan(&id)
+	if err == nil {
+		c.Skipped++
+		return id, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("ERROR: failed to execute query\n")
+		return 0, err
+	}
+
+	a, err = s.actors.AddActor(ctx, a)
+	if err != nil {
+		return 0, err
+	}
+	c.Created++
+
+	return a.ID, nil
+}
+
+func (s *Seeder) seedFilm(ctx context.Context, ff *FilmFixture, actorIDs map[string]int32, rep *Report) error {
+	f := film.ToFilm(&ff.FilmInfo)
+
+	const query = `SELECT movie_id FROM movie WHERE movie_name = $1 AND releasedate = $2 AND deleted_at IS NULL`
+	err := s.db.QueryRowContext(ctx, query, f.Name, f.ReleaseDate).Scan(&f.ID)
+	switch {
+	case err == nil:
+		rep.Films.Skipped++
+	case errors.Is(err, sql.ErrNoRows):
+		f, err = s.films.AddFilm(ctx, f)
+		if err != nil {
+			return err
+		}
+		rep.Films.Created++
+	default:
+		log.Printf("ERROR: failed to execute query\n")
+		return err
+	}
+
+	bound, err := s.films.GetFilmActors(ctx, f.ID)
+	if err != nil {
+		return err
+	}
+	isBound := make(map[int32]bool, len(bound))
+	for _, v := range bound {
+		isBound[v.ID] = true
+	}
+
+	fa := &film.FilmActors{ID: f.ID}
+	for _, name := range ff.Actors {
+		id := actorIDs[name]
+		if isBound[id] {
+			rep.Bindings.Skipped++
+			continue
+		}
+		isBound[id] = true
+		fa.ActorIDs = append(fa.ActorIDs, id)
+	}
+	if len(fa.ActorIDs) == 0 {
+		return nil
+	}
+
+	if err := s.films.AddFilmActors(ctx, fa); err != nil {
+		return err
+	}
+	rep.Bindings.Created += len(fa.ActorIDs)
+
+	return nil
+}
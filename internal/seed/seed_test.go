@@ -0,0 +1,142 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/config"
+	"github.com/Coderovshik/film-library/internal/db"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+func newDatabase(t *testing.T) *db.Database {
+	cfg := &config.Config{
+		DatabaseURI: "sqlite://" + filepath.Join(t.TempDir(), "filmlib.db"),
+	}
+
+	m := db.NewMigrator(cfg)
+	if err := m.Up(); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	m.Close()
+
+	database, err := db.NewDatabase(cfg)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	t.Cleanup(database.Close)
+
+	return database
+}
+
+func TestSeeder_Seed(t *testing.T) {
+	database := newDatabase(t)
+	s := NewSeeder(database.GetDB(), database.GetDialect())
+
+	f, err := Load("../../fixtures/demo.yaml")
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	rep, err := s.Seed(context.TODO(), f)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := Report{
+		Actors:   Counter{Created: 5},
+		Films:    Counter{Created: 3},
+		Bindings: Counter{Created: 7},
+	}
+	if *rep != exp {
+		t.Errorf("Expected %s, got %s", &exp, rep)
+	}
+
+	// seeding is idempotent
+	rep, err = s.Seed(context.TODO(), f)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp = Report{
+		Actors:   Counter{Skipped: 5},
+		Films:    Counter{Skipped: 3},
+		Bindings: Counter{Skipped: 7},
+	}
+	if *rep != exp {
+		t.Errorf("Expected %s, got %s", &exp, rep)
+	}
+
+	// actors and films in the trash are seeded anew
+	if err := actor.NewRepository(database.GetDB(), database.GetDialect()).DeleteActor(context.TODO(), 1, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := film.NewRepository(database.GetDB(), database.GetDialect()).DeleteFilm(context.TODO(), 1, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	rep, err = s.Seed(context.TODO(), f)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if rep.Actors != (Counter{Created: 1, Skipped: 4}) || rep.Films != (Counter{Created: 1, Skipped: 2}) {
+		t.Errorf("Expected the deleted actor and film created, got %s", rep)
+	}
+
+	// unknown actor
+	f.Films[0].Actors = append(f.Films[0].Actors, "nobody")
+	_, err = s.Seed(context.TODO(), f)
+	vErr := &util.ValidationError{}
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+	f.Films[0].Actors = f.Films[0].Actors[:len(f.Films[0].Actors)-1]
+
+	// namesakes can not be told apart by films
+	namesake := f.Actors[0]
+	namesake.Birthday = "2000-01-01"
+	f.Actors = append(f.Actors, namesake)
+	_, err = s.Seed(context.TODO(), f)
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	f := Generate(GenerateOptions{Films: 20, Actors: 10, Bindings: 50, Seed: 1})
+	if len(f.Films) != 20 || len(f.Actors) != 10 {
+		t.Fatalf("Expected %d films and %d actors, got %d and %d", 20, 10, len(f.Films), len(f.Actors))
+	}
+	if vErr := f.Validate(); vErr != nil {
+		t.Fatalf("No error expected, got %s", vErr.Error())
+	}
+
+	n := 0
+	for _, v := range f.Films {
+		n += len(v.Actors)
+	}
+	if n != 50 {
+		t.Errorf("Expected %d bindings, got %d", 50, n)
+	}
+
+	// bindings are capped by the number of distinct pairs
+	f = Generate(GenerateOptions{Films: 2, Actors: 3, Bindings: 100, Seed: 1})
+	n = 0
+	for _, v := range f.Films {
+		n += len(v.Actors)
+	}
+	if n != 6 {
+		t.Errorf("Expected %d bindings, got %d", 6, n)
+	}
+
+	database := newDatabase(t)
+	f = Generate(GenerateOptions{Films: 20, Actors: 10, Bindings: 50, Seed: 1})
+	rep, err := NewSeeder(database.GetDB(), database.GetDialect()).Seed(context.TODO(), f)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if rep.Films.Created != 20 || rep.Actors.Created != 10 || rep.Bindings.Created != 50 {
+		t.Errorf("Unexpected report %s", rep)
+	}
+}
@@ -0,0 +1,2392 @@
+// Package storagetest contains the conformance suite every storage backend
+// of the film library has to pass.
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/autocomplete"
+	"github.com/Coderovshik/film-library/internal/collection"
+	"github.com/Coderovshik/film-library/internal/film"
+	"github.com/Coderovshik/film-library/internal/follow"
+	"github.com/Coderovshik/film-library/internal/graph"
+	"github.com/Coderovshik/film-library/internal/history"
+	"github.com/Coderovshik/film-library/internal/idempotency"
+	"github.com/Coderovshik/film-library/internal/importer"
+	"github.com/Coderovshik/film-library/internal/lending"
+	"github.com/Coderovshik/film-library/internal/recommend"
+	"github.com/Coderovshik/film-library/internal/search"
+	"github.com/Coderovshik/film-library/internal/user"
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+type Repositories struct {
+	Films  film.FilmRepository
+	Actors actor.ActorRepository
+	Users  user.UserRepository
+	Audit  audit.AuditRepository
+	Tx     importer.Transactor
+
+	Idempotency idempotency.IdempotencyRepository
+	Search      search.SearchRepository
+
+	Autocomplete autocomplete.AutocompleteRepository
+	Graph        graph.GraphRepository
+	Views        recommend.ViewRepository
+	Collections  collection.CollectionRepository
+	History      history.HistoryRepository
+	Lending      lending.LendingRepository
+	Follow       follow.FollowRepository
+}
+
+// Factory returns repositories backed by a fresh, empty storage.
+type Factory func(t *testing.T) *Repositories
+
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newRepos(t)) })
+	t.Run("Actors", func(t *testing.T) { testActors(t, newRepos(t)) })
+	t.Run("Films", func(t *testing.T) { testFilms(t, newRepos(t)) })
+	t.Run("FilmList", func(t *testing.T) { testFilmList(t, newRepos(t)) })
+	t.Run("Facets", func(t *testing.T) { testFacets(t, newRepos(t)) })
+	t.Run("FilmActors", func(t *testing.T) { testFilmActors(t, newRepos(t)) })
+	t.Run("CascadingDelete", func(t *testing.T) { testCascadingDelete(t, newRepos(t)) })
+	t.Run("Versions", func(t *testing.T) { testVersions(t, newRepos(t)) })
+	t.Run("Stamps", func(t *testing.T) { testStamps(t, newRepos(t)) })
+	t.Run("Audit", func(t *testing.T) { testAudit(t, newRepos(t)) })
+	t.Run("Trash", func(t *testing.T) { testTrash(t, newRepos(t)) })
+	t.Run("Atomic", func(t *testing.T) { testAtomic(t, newRepos(t)) })
+	t.Run("Export", func(t *testing.T) { testExport(t, newRepos(t)) })
+	t.Run("Idempotency", func(t *testing.T) { testIdempotency(t, newRepos(t)) })
+	t.Run("Search", func(t *testing.T) { testSearch(t, newRepos(t)) })
+	t.Run("Autocomplete", func(t *testing.T) { testAutocomplete(t, newRepos(t)) })
+	t.Run("Graph", func(t *testing.T) { testGraph(t, newRepos(t)) })
+	t.Run("Views", func(t *testing.T) { testViews(t, newRepos(t)) })
+	t.Run("Collections", func(t *testing.T) { testCollections(t, newRepos(t)) })
+	t.Run("History", func(t *testing.T) { testHistory(t, newRepos(t)) })
+	t.Run("Lending", func(t *testing.T) { testLending(t, newRepos(t)) })
+	t.Run("Follow", func(t *testing.T) { testFollow(t, newRepos(t)) })
+}
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	return d
+}
+
+func addActor(t *testing.T, r *Repositories, name string) int32 {
+	t.Helper()
+
+	a, err := r.Actors.AddActor(context.TODO(), &actor.Actor{
+		Name:     name,
+		Sex:      "male",
+		Birthday: date(t, "1990-01-01"),
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	return a.ID
+}
+
+func addFilm(t *testing.T, r *Repositories, name string, rating int32, releaseDate string, actorIDs ...int32) int32 {
+	t.Helper()
+
+	f, err := r.Films.AddFilm(context.TODO(), &film.Film{
+		Name:        name,
+		Description: "about " + name,
+		ReleaseDate: date(t, releaseDate),
+		Rating:      rating,
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	if len(actorIDs) != 0 {
+		err = r.Films.AddFilmActors(context.TODO(), &film.FilmActors{ID: f.ID, ActorIDs: actorIDs})
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+	}
+
+	return f.ID
+}
+
+func filmNames(films []*film.Film) []string {
+	names := make([]string, 0, len(films))
+	for _, v := range films {
+		names = append(names, v.Name)
+	}
+
+	return names
+}
+
+func sorted(s []string) []string {
+	s = slices.Clone(s)
+	slices.Sort(s)
+
+	return s
+}
+
+func testUsers(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	u, err := r.Users.CreateUser(ctx, &user.User{Username: "user", Passhash: "hash", IsAdmin: true})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if u.ID == 0 {
+		t.Errorf("Expected assigned id, got %d", u.ID)
+	}
+
+	_, err = r.Users.CreateUser(ctx, &user.User{Username: "user", Passhash: "other"})
+	if !errors.Is(err, user.ErrUserExist) {
+		t.Errorf("Expected %v, got %v", user.ErrUserExist, err)
+	}
+
+	got, err := r.Users.GetUserByUsername(ctx, "user")
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := &user.User{ID: u.ID, Username: "user", Passhash: "hash", IsAdmin: true}
+	if *got != *exp {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+
+	_, err = r.Users.GetUserByUsername(ctx, "nobody")
+	if !errors.Is(err, user.ErrUserNotExist) {
+		t.Errorf("Expected %v, got %v", user.ErrUserNotExist, err)
+	}
+
+	hasAdmin, err := r.Users.HasAdmin(ctx)
+	if err != nil || !hasAdmin {
+		t.Errorf("Expected admin to exist, got %t, %v", hasAdmin, err)
+	}
+
+	err = r.Users.UpdateUser(ctx, &user.User{ID: u.ID, Passhash: "new", IsAdmin: false})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, _ = r.Users.GetUserByUsername(ctx, "user")
+	exp = &user.User{ID: u.ID, Username: "user", Passhash: "new", IsAdmin: false}
+	if *got != *exp {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+
+	hasAdmin, err = r.Users.HasAdmin(ctx)
+	if err != nil || hasAdmin {
+		t.Errorf("Expected no admin, got %t, %v", hasAdmin, err)
+	}
+
+	err = r.Users.UpdateUser(ctx, &user.User{ID: u.ID + 100, Passhash: "new"})
+	if !errors.Is(err, user.ErrUserNotExist) {
+		t.Errorf("Expected %v, got %v", user.ErrUserNotExist, err)
+	}
+}
+
+func testActors(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	a, err := r.Actors.AddActor(ctx, &actor.Actor{Name: "actor1", Sex: "female", Birthday: date(t, "1995-05-03")})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	got, err := r.Actors.GetActor(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got.Name != "actor1" || got.Sex != "female" || !got.Birthday.Equal(date(t, "1995-05-03")) || len(got.Films) != 0 {
+		t.Errorf("Unexpected actor %+v", got)
+	}
+
+	// partial update keeps omitted fields
+	name := "renamed"
+	err = r.Actors.UpdateActor(ctx, &actor.ActorUpdate{ID: a.ID, Name: &name})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, _ = r.Actors.GetActor(ctx, a.ID)
+	if got.Name != "renamed" || got.Sex != "female" {
+		t.Errorf("Unexpected actor after update %+v", got)
+	}
+
+	err = r.Actors.UpdateActor(ctx, &actor.ActorUpdate{ID: a.ID})
+	if !errors.Is(err, actor.ErrEmptyUpdate) {
+		t.Errorf("Expected %v, got %v", actor.ErrEmptyUpdate, err)
+	}
+
+	err = r.Actors.UpdateActor(ctx, &actor.ActorUpdate{ID: a.ID + 100, Name: &name})
+	if !errors.Is(err, actor.ErrActorNotExist) {
+		t.Errorf("Expected %v, got %v", actor.ErrActorNotExist, err)
+	}
+
+	id2 := addActor(t, r, "actor2")
+	actors, err := r.Actors.GetActors(ctx)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(actors) != 2 {
+		t.Errorf("Expected %d actors, got %d", 2, len(actors))
+	}
+
+	if err := r.Actors.DeleteActor(ctx, id2, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	_, err = r.Actors.GetActor(ctx, id2)
+	if !errors.Is(err, actor.ErrActorNotExist) {
+		t.Errorf("Expected %v, got %v", actor.ErrActorNotExist, err)
+	}
+	err = r.Actors.DeleteActor(ctx, id2, 0)
+	if !errors.Is(err, actor.ErrActorNotExist) {
+		t.Errorf("Expected %v, got %v", actor.ErrActorNotExist, err)
+	}
+}
+
+func testFilms(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	id := addFilm(t, r, "film1", 5, "2000-01-12")
+
+	got, err := r.Films.GetFilm(ctx, id)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got.Name != "film1" || got.Description != "about film1" || got.Rating != 5 ||
+		!got.ReleaseDate.Equal(date(t, "2000-01-12")) || len(got.Actors) != 0 {
+		t.Errorf("Unexpected film %+v", got)
+	}
+
+	_, err = r.Films.GetFilm(ctx, id+100)
+	if !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+
+	// omitted fields are kept, zero values are written
+	description, rating := "", int32(0)
+	err = r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: id, Description: &description, Rating: &rating})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, _ = r.Films.GetFilm(ctx, id)
+	if got.Name != "film1" || got.Description != "" || got.Rating != 0 {
+		t.Errorf("Unexpected film after update %+v", got)
+	}
+
+	err = r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: id})
+	if !errors.Is(err, film.ErrEmptyUpdate) {
+		t.Errorf("Expected %v, got %v", film.ErrEmptyUpdate, err)
+	}
+
+	name := "ghost"
+	err = r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: id + 100, Name: &name})
+	if !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+
+	if err := r.Films.DeleteFilm(ctx, id, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	err = r.Films.DeleteFilm(ctx, id, 0)
+	if !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+
+	// genres are replaced as a whole and listed sorted
+	f, err := r.Films.AddFilm(ctx, &film.Film{
+		Name:        "film3",
+		ReleaseDate: date(t, "2000-01-12"),
+		Genres:      []string{"drama", "crime"},
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, _ = r.Films.GetFilm(ctx, f.ID)
+	if exp := []string{"crime", "drama"}; !slices.Equal(got.Genres, exp) {
+		t.Errorf("Expected genres %v, got %v", exp, got.Genres)
+	}
+	genres := []string{"comedy"}
+	if err := r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: f.ID, Genres: &genres}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, _ = r.Films.GetFilm(ctx, f.ID)
+	if !slices.Equal(got.Genres, genres) || got.Version != 2 {
+		t.Errorf("Expected genres %v at version 2, got %+v", genres, got)
+	}
+	genres = []string{}
+	if err := r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: f.ID, Genres: &genres}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, _ = r.Films.GetFilm(ctx, f.ID)
+	if len(got.Genres) != 0 {
+		t.Errorf("Expected no genres, got %v", got.Genres)
+	}
+}
+
+func testFilmList(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	a1 := addActor(t, r, "Uma Thurman")
+	a2 := addActor(t, r, "John Travolta")
+	addFilm(t, r, "Pulp Fiction", 9, "1994-05-21", a1, a2)
+	addFilm(t, r, "Kill Bill", 8, "2003-09-29", a1)
+	addFilm(t, r, "Grease", 7, "1978-06-16", a2)
+
+	rating := func(n int32) *int32 { return &n }
+	asc := func(field string) film.SortKey { return film.SortKey{Field: field} }
+	desc := func(field string) film.SortKey { return film.SortKey{Field: field, Desc: true} }
+
+	tests := []struct {
+		name string
+		q    *film.Query
+		exp  []string
+	}{
+		{"default sort", &film.Query{}, []string{"Pulp Fiction", "Kill Bill", "Grease"}},
+		{"name asc", &film.Query{Sort: []film.SortKey{asc("name")}}, []string{"Grease", "Kill Bill", "Pulp Fiction"}},
+		{"releasedate desc", &film.Query{Sort: []film.SortKey{desc("releasedate")}}, []string{"Kill Bill", "Pulp Fiction", "Grease"}},
+		{"rating asc", &film.Query{Sort: []film.SortKey{asc("rating")}}, []string{"Grease", "Kill Bill", "Pulp Fiction"}},
+		{"film substring", &film.Query{Film: "ll"}, []string{"Kill Bill"}},
+		{"film is case sensitive", &film.Query{Film: "kill"}, []string{}},
+		{"film exact", &film.Query{Film: "Kill Bill", FilmExact: true}, []string{"Kill Bill"}},
+		{"film exact is whole name", &film.Query{Film: "Kill", FilmExact: true}, []string{}},
+		{"film wildcards are literal", &film.Query{Film: "_ill%"}, []string{}},
+		{"film quotes are literal", &film.Query{Film: "' OR '1'='1"}, []string{}},
+		{"actor substring", &film.Query{Actor: "Travolta", Sort: []film.SortKey{asc("name")}}, []string{"Grease", "Pulp Fiction"}},
+		{"actor wildcards are literal", &film.Query{Actor: "%"}, []string{}},
+		{"actor ids any", &film.Query{ActorIDs: []int32{a1, a2}}, []string{"Pulp Fiction", "Kill Bill", "Grease"}},
+		{"actor ids all", &film.Query{ActorIDs: []int32{a1, a2}, ActorMatchAll: true}, []string{"Pulp Fiction"}},
+		{"actor ids unknown", &film.Query{ActorIDs: []int32{a1 + a2 + 100}}, []string{}},
+		{"rating min", &film.Query{RatingMin: rating(8)}, []string{"Pulp Fiction", "Kill Bill"}},
+		{"rating range", &film.Query{RatingMin: rating(8), RatingMax: rating(8)}, []string{"Kill Bill"}},
+		{"released range", &film.Query{ReleasedFrom: date(t, "1990-01-01"), ReleasedTo: date(t, "2000-01-01")}, []string{"Pulp Fiction"}},
+		{"released to is inclusive", &film.Query{ReleasedTo: date(t, "1994-05-21")}, []string{"Pulp Fiction", "Grease"}},
+		{"released from is inclusive", &film.Query{ReleasedFrom: date(t, "2003-09-29")}, []string{"Kill Bill"}},
+		{"filters combined", &film.Query{ActorIDs: []int32{a2}, RatingMax: rating(8), Actor: "Travolta"}, []string{"Grease"}},
+	}
+
+	for _, tt := range tests {
+		films, err := r.Films.GetFilms(ctx, tt.q)
+		if err != nil {
+			t.Fatalf("%s: no error expected, got %s", tt.name, err.Error())
+		}
+		if names := filmNames(films); !slices.Equal(names, tt.exp) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.exp, names)
+		}
+	}
+
+	// later keys order films equal by the earlier ones
+	addFilm(t, r, "Jackie Brown", 8, "1997-12-25", a2)
+	tests = []struct {
+		name string
+		q    *film.Query
+		exp  []string
+	}{
+		{"ties by id", &film.Query{}, []string{"Pulp Fiction", "Kill Bill", "Jackie Brown", "Grease"}},
+		{"rating desc, name asc", &film.Query{Sort: []film.SortKey{desc("rating"), asc("name")}}, []string{"Pulp Fiction", "Jackie Brown", "Kill Bill", "Grease"}},
+		{"rating asc, releasedate desc", &film.Query{Sort: []film.SortKey{asc("rating"), desc("releasedate")}}, []string{"Grease", "Kill Bill", "Jackie Brown", "Pulp Fiction"}},
+	}
+
+	for _, tt := range tests {
+		films, err := r.Films.GetFilms(ctx, tt.q)
+		if err != nil {
+			t.Fatalf("%s: no error expected, got %s", tt.name, err.Error())
+		}
+		if names := filmNames(films); !slices.Equal(names, tt.exp) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.exp, names)
+		}
+	}
+}
+
+func testFacets(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	a1 := addActor(t, r, "Uma Thurman")
+	a2 := addActor(t, r, "John Travolta")
+	a3 := addActor(t, r, "Olivia Newton-John")
+	genres := map[int32][]string{
+		addFilm(t, r, "Pulp Fiction", 9, "1994-05-21", a1, a2): {"crime", "drama"},
+		addFilm(t, r, "Kill Bill", 8, "2003-09-29", a1):        {"action", "crime"},
+		addFilm(t, r, "Jackie Brown", 8, "1997-12-25", a2):     {"crime"},
+		addFilm(t, r, "Grease", 7, "1978-06-16", a2, a3):       {"musical"},
+	}
+	deleted := addFilm(t, r, "Deleted", 7, "1978-01-01", a3)
+	genres[deleted] = []string{"musical"}
+	for id, v := range genres {
+		if err := r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: id, Genres: &v}); err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+	}
+	if err := r.Films.DeleteFilm(ctx, deleted, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	// counts formats a facet as value=count pairs, actors and genres by
+	// their names
+	counts := func(f *film.Facet) []string {
+		res := make([]string, 0, len(f.Counts))
+		for _, v := range f.Counts {
+			label := strconv.Itoa(int(v.Value))
+			if len(v.Name) != 0 {
+				label = v.Name
+			}
+			res = append(res, fmt.Sprintf("%s=%d", label, v.Count))
+		}
+		return res
+	}
+	rating := func(n int32) *int32 { return &n }
+	names := []string{film.FacetRating, film.FacetDecade, film.FacetActor, film.FacetGenre}
+
+	tests := []struct {
+		name string
+		q    *film.Query
+		exp  [][]string
+	}{
+		{"no filters", &film.Query{}, [][]string{
+			{"9=1", "8=2", "7=1"},
+			{"2000=1", "1990=2", "1970=1"},
+			{"John Travolta=3", "Uma Thurman=2", "Olivia Newton-John=1"},
+			{"crime=3", "action=1", "drama=1", "musical=1"},
+		}},
+		{"other filters apply", &film.Query{ActorIDs: []int32{a1}, ReleasedFrom: date(t, "2000-01-01")}, [][]string{
+			{"8=1"},
+			{"2000=1", "1990=1"},
+			{"Uma Thurman=1"},
+			{"action=1", "crime=1"},
+		}},
+		{"own filter is left out", &film.Query{RatingMin: rating(8), RatingMax: rating(8)}, [][]string{
+			{"9=1", "8=2", "7=1"},
+			{"2000=1", "1990=1"},
+			{"John Travolta=1", "Uma Thurman=1"},
+			{"crime=2", "action=1"},
+		}},
+		{"no films", &film.Query{Film: "Missing"}, [][]string{{}, {}, {}, {}}},
+	}
+
+	for _, tt := range tests {
+		facets, err := r.Films.GetFilmFacets(ctx, tt.q, names)
+		if err != nil {
+			t.Fatalf("%s: no error expected, got %s", tt.name, err.Error())
+		}
+		if len(facets) != len(names) {
+			t.Fatalf("%s: expected %d facets, got %d", tt.name, len(names), len(facets))
+		}
+		for i, f := range facets {
+			if f.Name != names[i] {
+				t.Errorf("%s: expected facet %s, got %s", tt.name, names[i], f.Name)
+			}
+			if got := counts(f); !slices.Equal(got, tt.exp[i]) {
+				t.Errorf("%s: expected %s counts %v, got %v", tt.name, f.Name, tt.exp[i], got)
+			}
+		}
+	}
+
+	// genres are filtered by the ids the genre facet gives
+	facets, err := r.Films.GetFilmFacets(ctx, &film.Query{}, []string{film.FacetGenre})
+	if err != nil || len(facets) != 1 || len(facets[0].Counts) == 0 {
+		t.Fatalf("Expected genre facet, got %v, %v", facets, err)
+	}
+	crime := facets[0].Counts[0].Value
+	films, err := r.Films.GetFilms(ctx, &film.Query{GenreIDs: []int32{crime}, Sort: []film.SortKey{{Field: "name"}}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := []string{"Jackie Brown", "Kill Bill", "Pulp Fiction"}; !slices.Equal(filmNames(films), exp) {
+		t.Errorf("Expected %v, got %v", exp, filmNames(films))
+	}
+	facets, err = r.Films.GetFilmFacets(ctx, &film.Query{GenreIDs: []int32{crime}}, names)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := []string{"9=1", "8=2"}; !slices.Equal(counts(facets[0]), exp) {
+		t.Errorf("Expected rating counts %v, got %v", exp, counts(facets[0]))
+	}
+	if exp := []string{"crime=3", "action=1", "drama=1", "musical=1"}; !slices.Equal(counts(facets[3]), exp) {
+		t.Errorf("Expected genre counts %v, got %v", exp, counts(facets[3]))
+	}
+}
+
+func testFilmActors(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	a1 := addActor(t, r, "actor1")
+	a2 := addActor(t, r, "actor2")
+	a3 := addActor(t, r, "actor3")
+	id := addFilm(t, r, "film1", 5, "2000-01-12", a1, a2)
+
+	actors, err := r.Films.GetFilmActors(ctx, id)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(actors) != 2 {
+		t.Errorf("Expected %d actors, got %d", 2, len(actors))
+	}
+
+	f, _ := r.Films.GetFilm(ctx, id)
+	if exp := []string{"actor1", "actor2"}; !slices.Equal(sorted(f.Actors), exp) {
+		t.Errorf("Expected %v, got %v", exp, f.Actors)
+	}
+
+	a, _ := r.Actors.GetActor(ctx, a1)
+	if exp := []string{"film1"}; !slices.Equal(a.Films, exp) {
+		t.Errorf("Expected %v, got %v", exp, a.Films)
+	}
+
+	// a failed bind must not leave partial bindings behind
+	err = r.Films.AddFilmActors(ctx, &film.FilmActors{ID: id, ActorIDs: []int32{a3, a1}})
+	if !errors.Is(err, film.ErrFilmActorExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmActorExist, err)
+	}
+	err = r.Films.AddFilmActors(ctx, &film.FilmActors{ID: id, ActorIDs: []int32{a3, a3 + 100}})
+	if !errors.Is(err, film.ErrActorNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrActorNotExist, err)
+	}
+	actors, _ = r.Films.GetFilmActors(ctx, id)
+	if len(actors) != 2 {
+		t.Errorf("Expected %d actors, got %d", 2, len(actors))
+	}
+
+	err = r.Films.AddFilmActors(ctx, &film.FilmActors{ID: id + 100, ActorIDs: []int32{a3}})
+	if !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+
+	err = r.Films.DeleteFilmActors(ctx, &film.FilmActors{ID: id, ActorIDs: []int32{a1, a3}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	actors, _ = r.Films.GetFilmActors(ctx, id)
+	if len(actors) != 1 || actors[0].ID != a2 || actors[0].Name != "actor2" {
+		t.Errorf("Unexpected actors %+v", actors)
+	}
+
+	err = r.Films.DeleteFilmActors(ctx, &film.FilmActors{ID: id, ActorIDs: []int32{a3}})
+	if !errors.Is(err, film.ErrZeroActors) {
+		t.Errorf("Expected %v, got %v", film.ErrZeroActors, err)
+	}
+}
+
+func testCascadingDelete(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	a1 := addActor(t, r, "actor1")
+	a2 := addActor(t, r, "actor2")
+	id1 := addFilm(t, r, "film1", 5, "2000-01-12", a1, a2)
+	id2 := addFilm(t, r, "film2", 6, "2001-01-12", a1)
+
+	if err := r.Actors.DeleteActor(ctx, a2, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	f, _ := r.Films.GetFilm(ctx, id1)
+	if exp := []string{"actor1"}; !slices.Equal(f.Actors, exp) {
+		t.Errorf("Expected %v, got %v", exp, f.Actors)
+	}
+
+	if err := r.Films.DeleteFilm(ctx, id2, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	a, _ := r.Actors.GetActor(ctx, a1)
+	if exp := []string{"film1"}; !slices.Equal(a.Films, exp) {
+		t.Errorf("Expected %v, got %v", exp, a.Films)
+	}
+	actors, _ := r.Films.GetFilmActors(ctx, id2)
+	if len(actors) != 0 {
+		t.Errorf("Expected no actors, got %+v", actors)
+	}
+}
+
+func testVersions(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	id := addFilm(t, r, "film1", 5, "2000-01-12")
+	got, _ := r.Films.GetFilm(ctx, id)
+	if got.Version != 1 {
+		t.Errorf("Expected version %d, got %d", 1, got.Version)
+	}
+
+	name := "renamed"
+	err := r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: id, Version: 1, Name: &name})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, _ = r.Films.GetFilm(ctx, id)
+	if got.Version != 2 || got.Name != name {
+		t.Errorf("Unexpected film after update %+v", got)
+	}
+
+	// a stale version is rejected, a missing record is still reported as such
+	err = r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: id, Version: 1, Name: &name})
+	if !errors.Is(err, film.ErrVersionMismatch) {
+		t.Errorf("Expected %v, got %v", film.ErrVersionMismatch, err)
+	}
+	err = r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: id + 100, Version: 1, Name: &name})
+	if !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+	err = r.Films.DeleteFilm(ctx, id, 1)
+	if !errors.Is(err, film.ErrVersionMismatch) {
+		t.Errorf("Expected %v, got %v", film.ErrVersionMismatch, err)
+	}
+	if err := r.Films.DeleteFilm(ctx, id, 2); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	a, err := r.Actors.AddActor(ctx, &actor.Actor{Name: "actor1", Sex: "female", Birthday: date(t, "1995-05-03")})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if a.Version != 1 {
+		t.Errorf("Expected version %d, got %d", 1, a.Version)
+	}
+
+	err = r.Actors.UpdateActor(ctx, &actor.ActorUpdate{ID: a.ID, Version: 1, Name: &name})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	err = r.Actors.UpdateActor(ctx, &actor.ActorUpdate{ID: a.ID, Version: 1, Name: &name})
+	if !errors.Is(err, actor.ErrVersionMismatch) {
+		t.Errorf("Expected %v, got %v", actor.ErrVersionMismatch, err)
+	}
+	err = r.Actors.DeleteActor(ctx, a.ID, 1)
+	if !errors.Is(err, actor.ErrVersionMismatch) {
+		t.Errorf("Expected %v, got %v", actor.ErrVersionMismatch, err)
+	}
+	if err := r.Actors.DeleteActor(ctx, a.ID, 2); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+}
+
+func testStamps(t *testing.T, r *Repositories) {
+	u, err := r.Users.CreateUser(context.TODO(), &user.User{Username: "editor", Passhash: "hash", IsAdmin: true})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	authored := util.WithUserClaims(context.TODO(), util.NewUserClaims(int(u.ID), u.Username, true))
+	anonymous := context.TODO()
+
+	before := time.Now().Add(-time.Second)
+	f, err := r.Films.AddFilm(authored, &film.Film{Name: "film1", ReleaseDate: date(t, "2000-01-12")})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	got, _ := r.Films.GetFilm(anonymous, f.ID)
+	if !got.CreatedAt.Equal(f.CreatedAt) || !got.UpdatedAt.Equal(got.CreatedAt) || got.CreatedAt.Before(before) {
+		t.Errorf("Unexpected timestamps %v, %v, expected %v", got.CreatedAt, got.UpdatedAt, f.CreatedAt)
+	}
+	if got.CreatedBy == nil || *got.CreatedBy != u.ID || got.UpdatedBy == nil || *got.UpdatedBy != u.ID {
+		t.Errorf("Expected film authored by %d, got %v, %v", u.ID, got.CreatedBy, got.UpdatedBy)
+	}
+	created := got.CreatedAt
+
+	// a change without an authenticated user keeps the creator
+	name := "renamed"
+	time.Sleep(time.Millisecond)
+	err = r.Films.UpdateFilm(anonymous, &film.FilmUpdate{ID: f.ID, Name: &name})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, _ = r.Films.GetFilm(anonymous, f.ID)
+	if !got.CreatedAt.Equal(created) || !got.UpdatedAt.After(created) {
+		t.Errorf("Unexpected timestamps %v, %v after update", got.CreatedAt, got.UpdatedAt)
+	}
+	if got.CreatedBy == nil || *got.CreatedBy != u.ID || got.UpdatedBy != nil {
+		t.Errorf("Expected film created by %d and updated by nobody, got %v, %v", u.ID, got.CreatedBy, got.UpdatedBy)
+	}
+	updated := got.UpdatedAt
+
+	id2 := addFilm(t, r, "film2", 3, "2001-01-12")
+	films, err := r.Films.GetFilms(anonymous, &film.Query{UpdatedSince: updated})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := []string{"renamed", "film2"}; !slices.Equal(sorted(filmNames(films)), sorted(exp)) {
+		t.Errorf("Expected %v, got %v", exp, filmNames(films))
+	}
+
+	got2, _ := r.Films.GetFilm(anonymous, id2)
+	films, _ = r.Films.GetFilms(anonymous, &film.Query{UpdatedSince: got2.UpdatedAt.Add(time.Microsecond)})
+	if len(films) != 0 {
+		t.Errorf("Expected no films, got %v", filmNames(films))
+	}
+
+	// cast changes count as film updates
+	time.Sleep(time.Millisecond)
+	a := addActor(t, r, "actor1")
+	err = r.Films.AddFilmActors(authored, &film.FilmActors{ID: f.ID, ActorIDs: []int32{a}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, _ = r.Films.GetFilm(anonymous, f.ID)
+	if !got.UpdatedAt.After(updated) || got.UpdatedBy == nil || *got.UpdatedBy != u.ID {
+		t.Errorf("Expected film touched by %d after %v, got %v, %v", u.ID, updated, got.UpdatedBy, got.UpdatedAt)
+	}
+
+	ac, _ := r.Actors.GetActor(anonymous, a)
+	if ac.CreatedAt.IsZero() || !ac.UpdatedAt.Equal(ac.CreatedAt) || ac.CreatedBy != nil {
+		t.Errorf("Unexpected actor stamps %+v", ac)
+	}
+	err = r.Actors.UpdateActor(authored, &actor.ActorUpdate{ID: a, Name: &name})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	ac, _ = r.Actors.GetActor(anonymous, a)
+	if ac.UpdatedBy == nil || *ac.UpdatedBy != u.ID || ac.UpdatedAt.Before(ac.CreatedAt) {
+		t.Errorf("Unexpected actor stamps %+v", ac)
+	}
+}
+
+func entryIDs(entries []*audit.Entry) []int64 {
+	ids := make([]int64, 0, len(entries))
+	for _, v := range entries {
+		ids = append(ids, v.ID)
+	}
+
+	return ids
+}
+
+func testAudit(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	u, err := r.Users.CreateUser(ctx, &user.User{Username: "editor", Passhash: "hash", IsAdmin: true})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	start := time.Now().UTC().Truncate(time.Microsecond)
+	add := func(entity string, id int32, action string, userID *int32, before, after string) int64 {
+		t.Helper()
+
+		e := &audit.Entry{
+			OccurredAt: start.Add(time.Duration(len(entity)+int(id)) * time.Second),
+			UserID:     userID,
+			Entity:     entity,
+			EntityID:   id,
+			Action:     action,
+		}
+		if len(before) != 0 {
+			e.Before = json.RawMessage(before)
+		}
+		if len(after) != 0 {
+			e.After = json.RawMessage(after)
+		}
+
+		e, err := r.Audit.AddEntry(ctx, e)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+
+		return e.ID
+	}
+
+	e1 := add(audit.EntityFilm, 1, audit.ActionCreate, &u.ID, "", `{"name": "film1"}`)
+	e2 := add(audit.EntityFilm, 1, audit.ActionUpdate, nil, `{"name": "film1"}`, `{"name": "film2"}`)
+	e3 := add(audit.EntityActor, 1, audit.ActionDelete, &u.ID, `{"name": "actor1"}`, "")
+	e4 := add(audit.EntityFilm, 2, audit.ActionCreate, &u.ID, "", `{"name": "film3"}`)
+
+	entries, err := r.Audit.GetEntries(ctx, &audit.Query{Limit: 10})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := []int64{e4, e3, e2, e1}; !slices.Equal(exp, entryIDs(entries)) {
+		t.Fatalf("Expected entries %v newest first, got %v", exp, entryIDs(entries))
+	}
+
+	got := entries[2]
+	if got.Entity != audit.EntityFilm || got.EntityID != 1 || got.Action != audit.ActionUpdate || got.UserID != nil ||
+		!got.OccurredAt.Equal(start.Add(5*time.Second)) {
+		t.Errorf("Unexpected entry %+v", got)
+	}
+	var before, after any
+	json.Unmarshal(got.Before, &before)
+	json.Unmarshal(got.After, &after)
+	if !reflect.DeepEqual(before, map[string]any{"name": "film1"}) || !reflect.DeepEqual(after, map[string]any{"name": "film2"}) {
+		t.Errorf("Unexpected states %s, %s", got.Before, got.After)
+	}
+	if len(entries[0].Before) != 0 || len(entries[1].After) != 0 {
+		t.Errorf("Expected missing states, got %s, %s", entries[0].Before, entries[1].After)
+	}
+
+	queries := []struct {
+		q   audit.Query
+		exp []int64
+	}{
+		{audit.Query{Entity: audit.EntityFilm, EntityID: 1}, []int64{e2, e1}},
+		{audit.Query{UserID: u.ID}, []int64{e4, e3, e1}},
+		{audit.Query{Action: audit.ActionCreate}, []int64{e4, e1}},
+		{audit.Query{Since: start.Add(6 * time.Second)}, []int64{e4, e3}},
+		{audit.Query{Until: start.Add(6 * time.Second)}, []int64{e2, e1}},
+		{audit.Query{BeforeID: e3}, []int64{e2, e1}},
+		{audit.Query{BeforeID: e4, Limit: 1}, []int64{e3}},
+	}
+	for _, v := range queries {
+		if v.q.Limit == 0 {
+			v.q.Limit = 10
+		}
+
+		entries, err := r.Audit.GetEntries(ctx, &v.q)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		if !slices.Equal(v.exp, entryIDs(entries)) {
+			t.Errorf("Query %+v: expected %v, got %v", v.q, v.exp, entryIDs(entries))
+		}
+	}
+}
+
+func testTrash(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	a1 := addActor(t, r, "actor1")
+	a2 := addActor(t, r, "actor2")
+	id1 := addFilm(t, r, "film1", 5, "2000-01-12", a1, a2)
+	id2 := addFilm(t, r, "film2", 6, "2001-01-12", a1)
+
+	if err := r.Films.DeleteFilm(ctx, id1, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	// deleted films are hidden everywhere but the trash
+	if _, err := r.Films.GetFilm(ctx, id1); !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+	films, _ := r.Films.GetFilms(ctx, &film.Query{})
+	if exp := []string{"film2"}; !slices.Equal(filmNames(films), exp) {
+		t.Errorf("Expected %v, got %v", exp, filmNames(films))
+	}
+	a, _ := r.Actors.GetActor(ctx, a1)
+	if exp := []string{"film2"}; !slices.Equal(a.Films, exp) {
+		t.Errorf("Expected %v, got %v", exp, a.Films)
+	}
+	if err := r.Films.DeleteFilm(ctx, id1, 0); !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+	name := "renamed"
+	if err := r.Films.UpdateFilm(ctx, &film.FilmUpdate{ID: id1, Name: &name}); !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+	err := r.Films.AddFilmActors(ctx, &film.FilmActors{ID: id1, ActorIDs: []int32{a1}})
+	if !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+
+	deleted, err := r.Films.GetDeletedFilms(ctx)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(deleted) != 1 || deleted[0].ID != id1 || deleted[0].DeletedAt == nil ||
+		!slices.Equal(sorted(deleted[0].Actors), []string{"actor1", "actor2"}) {
+		t.Fatalf("Unexpected trash %+v", deleted)
+	}
+
+	// restoring brings the bindings back
+	if err := r.Films.RestoreFilm(ctx, id1); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	f, err := r.Films.GetFilm(ctx, id1)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := []string{"actor1", "actor2"}; !slices.Equal(sorted(f.Actors), exp) || f.DeletedAt != nil {
+		t.Errorf("Expected restored film with %v, got %+v", exp, f)
+	}
+	if err := r.Films.RestoreFilm(ctx, id1); !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+
+	// deleted actors cannot be bound
+	if err := r.Actors.DeleteActor(ctx, a2, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	err = r.Films.AddFilmActors(ctx, &film.FilmActors{ID: id2, ActorIDs: []int32{a2}})
+	if !errors.Is(err, film.ErrActorNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrActorNotExist, err)
+	}
+	trashed, _ := r.Actors.GetDeletedActors(ctx)
+	if len(trashed) != 1 || trashed[0].ID != a2 || trashed[0].DeletedAt == nil {
+		t.Errorf("Unexpected trash %+v", trashed)
+	}
+	if err := r.Actors.RestoreActor(ctx, a2); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	actors, _ := r.Films.GetFilmActors(ctx, id1)
+	if len(actors) != 2 {
+		t.Errorf("Expected 2 actors, got %+v", actors)
+	}
+
+	// only records deleted before the cutoff are purged
+	if err := r.Films.DeleteFilm(ctx, id1, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.Actors.DeleteActor(ctx, a2, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	ids, err := r.Films.PurgeFilms(ctx, time.Now().Add(-time.Hour))
+	if err != nil || len(ids) != 0 {
+		t.Errorf("Expected nothing purged, got %v, %v", ids, err)
+	}
+	ids, err = r.Films.PurgeFilms(ctx, time.Now().Add(time.Hour))
+	if exp := []int32{id1}; err != nil || !slices.Equal(ids, exp) {
+		t.Errorf("Expected %v purged, got %v, %v", exp, ids, err)
+	}
+	ids, err = r.Actors.PurgeActors(ctx, time.Now().Add(time.Hour))
+	if exp := []int32{a2}; err != nil || !slices.Equal(ids, exp) {
+		t.Errorf("Expected %v purged, got %v, %v", exp, ids, err)
+	}
+	if err := r.Films.RestoreFilm(ctx, id1); !errors.Is(err, film.ErrFilmNotExist) {
+		t.Errorf("Expected %v, got %v", film.ErrFilmNotExist, err)
+	}
+	if err := r.Actors.RestoreActor(ctx, a2); !errors.Is(err, actor.ErrActorNotExist) {
+		t.Errorf("Expected %v, got %v", actor.ErrActorNotExist, err)
+	}
+	deleted, _ = r.Films.GetDeletedFilms(ctx)
+	trashed, _ = r.Actors.GetDeletedActors(ctx)
+	if len(deleted) != 0 || len(trashed) != 0 {
+		t.Errorf("Expected empty trash, got %+v, %+v", deleted, trashed)
+	}
+}
+
+func testAtomic(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	a1 := addActor(t, r, "actor1")
+
+	// changes made by a failed run are rolled back
+	errFail := errors.New("fail")
+	err := r.Tx.Atomic(ctx, func(fr film.FilmRepository, ar actor.ActorRepository, rec audit.Recorder) error {
+		a, err := ar.AddActor(ctx, &actor.Actor{Name: "actor2", Sex: "female", Birthday: date(t, "1990-01-01")})
+		if err != nil {
+			return err
+		}
+		if err := rec.Record(ctx, audit.EntityActor, a.ID, audit.ActionCreate, nil, a); err != nil {
+			return err
+		}
+		f, err := fr.AddFilm(ctx, &film.Film{Name: "film1", ReleaseDate: date(t, "2000-01-12"), Rating: 5})
+		if err != nil {
+			return err
+		}
+		if err := fr.AddFilmActors(ctx, &film.FilmActors{ID: f.ID, ActorIDs: []int32{a1}}); err != nil {
+			return err
+		}
+
+		return errFail
+	})
+	if !errors.Is(err, errFail) {
+		t.Fatalf("Expected %v, got %v", errFail, err)
+	}
+	actors, _ := r.Actors.GetActors(ctx)
+	films, _ := r.Films.GetFilms(ctx, &film.Query{})
+	if len(actors) != 1 || len(films) != 0 {
+		t.Fatalf("Expected nothing but actor1, got %+v, %+v", actors, films)
+	}
+	if a, _ := r.Actors.GetActor(ctx, a1); len(a.Films) != 0 {
+		t.Errorf("Expected no films, got %v", a.Films)
+	}
+	if entries, _ := r.Audit.GetEntries(ctx, &audit.Query{Limit: 10}); len(entries) != 0 {
+		t.Errorf("Expected no audit entries, got %+v", entries)
+	}
+
+	// a successful run commits the changes, which it sees itself
+	var id int32
+	err = r.Tx.Atomic(ctx, func(fr film.FilmRepository, ar actor.ActorRepository, rec audit.Recorder) error {
+		f, err := fr.AddFilm(ctx, &film.Film{Name: "film1", ReleaseDate: date(t, "2000-01-12"), Rating: 5})
+		if err != nil {
+			return err
+		}
+		id = f.ID
+		if err := fr.AddFilmActors(ctx, &film.FilmActors{ID: f.ID, ActorIDs: []int32{a1}}); err != nil {
+			return err
+		}
+
+		f, err = fr.GetFilm(ctx, f.ID)
+		if err != nil {
+			return err
+		}
+		if exp := []string{"actor1"}; !slices.Equal(f.Actors, exp) {
+			t.Errorf("Expected %v, got %v", exp, f.Actors)
+		}
+
+		return rec.Record(ctx, audit.EntityFilm, f.ID, audit.ActionCreate, nil, f)
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	f, err := r.Films.GetFilm(ctx, id)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := []string{"actor1"}; !slices.Equal(f.Actors, exp) {
+		t.Errorf("Expected %v, got %v", exp, f.Actors)
+	}
+	entries, err := r.Audit.GetEntries(ctx, &audit.Query{Limit: 10})
+	if err != nil || len(entries) != 1 || entries[0].EntityID != id {
+		t.Errorf("Expected the entry of film %d, got %+v, %v", id, entries, err)
+	}
+
+	// a single repository runs atomically too, nested in a run or not
+	err = r.Films.Atomic(ctx, func(fr film.FilmRepository, rec audit.Recorder) error {
+		if err := fr.DeleteFilm(ctx, id, 0); err != nil {
+			return err
+		}
+		if err := rec.Record(ctx, audit.EntityFilm, id, audit.ActionDelete, f, nil); err != nil {
+			return err
+		}
+
+		return errFail
+	})
+	if !errors.Is(err, errFail) {
+		t.Fatalf("Expected %v, got %v", errFail, err)
+	}
+	if _, err := r.Films.GetFilm(ctx, id); err != nil {
+		t.Errorf("Expected film %d to be kept, got %v", id, err)
+	}
+	err = r.Tx.Atomic(ctx, func(fr film.FilmRepository, ar actor.ActorRepository, _ audit.Recorder) error {
+		return ar.Atomic(ctx, func(ar actor.ActorRepository, rec audit.Recorder) error {
+			if err := ar.DeleteActor(ctx, a1, 0); err != nil {
+				return err
+			}
+			return rec.Record(ctx, audit.EntityActor, a1, audit.ActionDelete, nil, nil)
+		})
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if _, err := r.Actors.GetActor(ctx, a1); !errors.Is(err, actor.ErrActorNotExist) {
+		t.Errorf("Expected %v, got %v", actor.ErrActorNotExist, err)
+	}
+	entries, err = r.Audit.GetEntries(ctx, &audit.Query{Limit: 10})
+	if err != nil || len(entries) != 2 || entries[0].EntityID != a1 || entries[0].Entity != audit.EntityActor {
+		t.Errorf("Expected the entries of film %d and actor %d, got %+v, %v", id, a1, entries, err)
+	}
+	if err := r.Actors.RestoreActor(ctx, a1); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	// genres given in a run are kept with their own ids
+	if _, err := r.Films.AddFilm(ctx, &film.Film{Name: "film2", ReleaseDate: date(t, "2001-01-12"), Genres: []string{"comedy"}}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	err = r.Tx.Atomic(ctx, func(fr film.FilmRepository, ar actor.ActorRepository, _ audit.Recorder) error {
+		_, err := fr.AddFilm(ctx, &film.Film{Name: "film3", ReleaseDate: date(t, "2002-01-12"), Genres: []string{"drama"}})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	facets, err := r.Films.GetFilmFacets(ctx, &film.Query{}, []string{film.FacetGenre})
+	if err != nil || len(facets) != 1 || len(facets[0].Counts) != 2 {
+		t.Fatalf("Expected two genres, got %v, %v", facets, err)
+	}
+	ids := make(map[string]int32)
+	for _, v := range facets[0].Counts {
+		ids[v.Name] = v.Value
+	}
+	if ids["drama"] == 0 || ids["drama"] == ids["comedy"] {
+		t.Fatalf("Expected distinct genre ids, got %v", ids)
+	}
+	films, err = r.Films.GetFilms(ctx, &film.Query{GenreIDs: []int32{ids["drama"]}})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := []string{"film3"}; !slices.Equal(filmNames(films), exp) {
+		t.Errorf("Expected %v, got %v", exp, filmNames(films))
+	}
+}
+
+func testExport(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	a1 := addActor(t, r, "actor1")
+	a2 := addActor(t, r, "actor2")
+	a3 := addActor(t, r, "actor3")
+	id1 := addFilm(t, r, "film1", 5, "2000-01-12", a2, a1)
+	id2 := addFilm(t, r, "film2", 9, "2001-01-12", a3)
+	id3 := addFilm(t, r, "film3", 7, "2002-01-12", a1)
+	id4 := addFilm(t, r, "deleted", 7, "2003-01-12", a1)
+	if err := r.Films.DeleteFilm(ctx, id4, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	exportFilms := func(q *film.ExportQuery) ([]int32, map[int32][]*film.ActorShort) {
+		t.Helper()
+
+		var ids []int32
+		casts := make(map[int32][]*film.ActorShort)
+		err := r.Films.ExportFilms(ctx, q, func(f *film.Film, cast []*film.ActorShort) error {
+			ids = append(ids, f.ID)
+			casts[f.ID] = cast
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+
+		return ids, casts
+	}
+
+	// films come in id order with their cast in actor id order
+	ids, casts := exportFilms(&film.ExportQuery{})
+	if exp := []int32{id1, id2, id3}; !slices.Equal(ids, exp) {
+		t.Errorf("Expected %v, got %v", exp, ids)
+	}
+	exp := []*film.ActorShort{{ID: a1, Name: "actor1"}, {ID: a2, Name: "actor2"}}
+	if !reflect.DeepEqual(casts[id1], exp) {
+		t.Errorf("Expected %+v, got %+v", exp, casts[id1])
+	}
+
+	// the cursor resumes after the last exported film
+	if ids, _ := exportFilms(&film.ExportQuery{After: id1, Limit: 1}); !slices.Equal(ids, []int32{id2}) {
+		t.Errorf("Expected %v, got %v", []int32{id2}, ids)
+	}
+	if ids, _ := exportFilms(&film.ExportQuery{Query: film.Query{Actor: "actor1"}}); !slices.Equal(ids, []int32{id1, id3}) {
+		t.Errorf("Expected %v, got %v", []int32{id1, id3}, ids)
+	}
+	if ids, _ := exportFilms(&film.ExportQuery{Query: film.Query{Film: "m2"}}); !slices.Equal(ids, []int32{id2}) {
+		t.Errorf("Expected %v, got %v", []int32{id2}, ids)
+	}
+
+	// actors in the trash are left out of the cast
+	if err := r.Actors.DeleteActor(ctx, a2, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if _, casts := exportFilms(&film.ExportQuery{}); len(casts[id1]) != 1 {
+		t.Errorf("Expected 1 actor, got %+v", casts[id1])
+	}
+
+	var actorIDs []int32
+	var films []*actor.FilmShort
+	err := r.Actors.ExportActors(ctx, &actor.ExportQuery{}, func(a *actor.Actor, fs []*actor.FilmShort) error {
+		actorIDs = append(actorIDs, a.ID)
+		if a.ID == a1 {
+			films = fs
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := []int32{a1, a3}; !slices.Equal(actorIDs, exp) {
+		t.Errorf("Expected %v, got %v", exp, actorIDs)
+	}
+	if exp := []*actor.FilmShort{{ID: id1, Name: "film1"}, {ID: id3, Name: "film3"}}; !reflect.DeepEqual(films, exp) {
+		t.Errorf("Expected %+v, got %+v", exp, films)
+	}
+
+	actorIDs = nil
+	err = r.Actors.ExportActors(ctx, &actor.ExportQuery{After: a1, Limit: 1}, func(a *actor.Actor, fs []*actor.FilmShort) error {
+		actorIDs = append(actorIDs, a.ID)
+		return nil
+	})
+	if err != nil || !slices.Equal(actorIDs, []int32{a3}) {
+		t.Errorf("Expected %v, got %v, %v", []int32{a3}, actorIDs, err)
+	}
+
+	// an error of fn stops the export
+	errStop := errors.New("stop")
+	n := 0
+	err = r.Films.ExportFilms(ctx, &film.ExportQuery{}, func(f *film.Film, cast []*film.ActorShort) error {
+		n++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || n != 1 {
+		t.Errorf("Expected %v after 1 film, got %v after %d", errStop, err, n)
+	}
+}
+
+func testIdempotency(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	u, err := r.Users.CreateUser(ctx, &user.User{Username: "editor", Passhash: "hash", IsAdmin: true})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	rec := &idempotency.Record{
+		UserID:      u.ID,
+		Key:         "key1",
+		RequestHash: "hash1",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+		LockedAt:    now,
+	}
+	if added, err := r.Idempotency.AddRecord(ctx, rec); err != nil || !added {
+		t.Fatalf("Expected record added, got %v, %v", added, err)
+	}
+	if added, err := r.Idempotency.AddRecord(ctx, rec); err != nil || added {
+		t.Fatalf("Expected duplicate key refused, got %v, %v", added, err)
+	}
+
+	got, err := r.Idempotency.GetRecord(ctx, u.ID, "key1")
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got.Completed() || got.RequestHash != "hash1" || !got.ExpiresAt.Equal(rec.ExpiresAt) || !got.LockedAt.Equal(now) {
+		t.Errorf("Expected reserved record %+v, got %+v", rec, got)
+	}
+
+	// a fresh lock is kept, a stale one is taken over once
+	retry := *rec
+	retry.LockedAt = now.Add(time.Minute)
+	if locked, err := r.Idempotency.LockRecord(ctx, &retry, now); err != nil || locked {
+		t.Errorf("Expected fresh lock kept, got %v, %v", locked, err)
+	}
+	if locked, err := r.Idempotency.LockRecord(ctx, &retry, now.Add(time.Second)); err != nil || !locked {
+		t.Errorf("Expected stale lock taken over, got %v, %v", locked, err)
+	}
+	if locked, err := r.Idempotency.LockRecord(ctx, &retry, now.Add(time.Second)); err != nil || locked {
+		t.Errorf("Expected lock taken over once, got %v, %v", locked, err)
+	}
+	got, _ = r.Idempotency.GetRecord(ctx, u.ID, "key1")
+	if !got.LockedAt.Equal(retry.LockedAt) {
+		t.Errorf("Expected lock moved to %s, got %s", retry.LockedAt, got.LockedAt)
+	}
+
+	rec.StatusCode = http.StatusOK
+	rec.Header = http.Header{"Content-Type": {"application/json; charset=utf-8"}, "Etag": {`"1"`}}
+	rec.Body = []byte(`{"id": 1}`)
+	if err := r.Idempotency.CompleteRecord(ctx, rec); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, err = r.Idempotency.GetRecord(ctx, u.ID, "key1")
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got.StatusCode != rec.StatusCode || !reflect.DeepEqual(got.Header, rec.Header) || string(got.Body) != string(rec.Body) {
+		t.Errorf("Expected completed record %+v, got %+v", rec, got)
+	}
+	if locked, err := r.Idempotency.LockRecord(ctx, &retry, now.Add(time.Hour)); err != nil || locked {
+		t.Errorf("Expected completed record not locked, got %v, %v", locked, err)
+	}
+
+	// keys are scoped to the user
+	if _, err := r.Idempotency.GetRecord(ctx, u.ID+1, "key1"); !errors.Is(err, idempotency.ErrRecordNotExist) {
+		t.Errorf("Expected %v, got %v", idempotency.ErrRecordNotExist, err)
+	}
+
+	expired := &idempotency.Record{
+		UserID:      u.ID,
+		Key:         "key2",
+		RequestHash: "hash2",
+		CreatedAt:   now.Add(-2 * time.Hour),
+		ExpiresAt:   now.Add(-time.Hour),
+		LockedAt:    now.Add(-2 * time.Hour),
+	}
+	if _, err := r.Idempotency.AddRecord(ctx, expired); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if n, err := r.Idempotency.DeleteExpired(ctx, now); err != nil || n != 1 {
+		t.Errorf("Expected 1 expired record deleted, got %d, %v", n, err)
+	}
+	if _, err := r.Idempotency.GetRecord(ctx, u.ID, "key2"); !errors.Is(err, idempotency.ErrRecordNotExist) {
+		t.Errorf("Expected %v, got %v", idempotency.ErrRecordNotExist, err)
+	}
+
+	if err := r.Idempotency.DeleteRecord(ctx, u.ID, "key1"); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if _, err := r.Idempotency.GetRecord(ctx, u.ID, "key1"); !errors.Is(err, idempotency.ErrRecordNotExist) {
+		t.Errorf("Expected %v, got %v", idempotency.ErrRecordNotExist, err)
+	}
+}
+
+func hitKeys(hits []*search.Hit) []string {
+	keys := make([]string, 0, len(hits))
+	for _, v := range hits {
+		keys = append(keys, v.Kind+":"+v.Name)
+	}
+
+	return keys
+}
+
+func testSearch(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	reeves := addActor(t, r, "Keanu Reeves")
+	matrix := addFilm(t, r, "Matrix", 9, "1999-03-31", reeves)
+	addFilm(t, r, "Speed", 7, "1994-06-10", reeves)
+	_, err := r.Films.AddFilm(ctx, &film.Film{
+		Name:        "Documentary",
+		Description: "making of the <script>alert(1)</script> matrix",
+		ReleaseDate: date(t, "2001-01-01"),
+		Rating:      5,
+	})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	hits, err := r.Search.Search(ctx, &search.Query{Text: "MATRIX", Limit: 10})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	// a name match outranks a description one
+	exp := []string{"film:Matrix", "film:Documentary"}
+	if got := hitKeys(hits); !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+	for _, v := range hits {
+		if !strings.Contains(strings.ToLower(v.Snippet), "<b>matrix</b>") {
+			t.Errorf("Expected highlighted snippet, got %q", v.Snippet)
+		}
+	}
+	// the text is escaped, only the highlight is markup
+	if len(hits) == 2 {
+		if s := hits[1].Snippet; strings.Contains(s, "<script>") || !strings.Contains(s, "&lt;script&gt;") {
+			t.Errorf("Expected escaped snippet, got %q", s)
+		}
+	}
+
+	hits, err = r.Search.Search(ctx, &search.Query{Text: "matrix", Limit: 1})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got := hitKeys(hits); !reflect.DeepEqual([]string{"film:Matrix"}, got) {
+		t.Errorf("Expected %+v, got %+v", []string{"film:Matrix"}, got)
+	}
+
+	// all the words have to match, operators are not taken from the text
+	hits, err = r.Search.Search(ctx, &search.Query{Text: `keanu "reeves" -`, Limit: 10})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got := hitKeys(hits); !reflect.DeepEqual([]string{"actor:Keanu Reeves"}, got) {
+		t.Errorf("Expected %+v, got %+v", []string{"actor:Keanu Reeves"}, got)
+	}
+
+	name := "Keanu Charles Reeves"
+	if err := r.Actors.UpdateActor(ctx, &actor.ActorUpdate{ID: reeves, Name: &name}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	hits, err = r.Search.Search(ctx, &search.Query{Text: "charles", Limit: 10})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got := hitKeys(hits); !reflect.DeepEqual([]string{"actor:" + name}, got) {
+		t.Errorf("Expected %+v, got %+v", []string{"actor:" + name}, got)
+	}
+
+	if err := r.Films.DeleteFilm(ctx, matrix, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	hits, err = r.Search.Search(ctx, &search.Query{Text: "matrix", Limit: 10})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got := hitKeys(hits); !reflect.DeepEqual([]string{"film:Documentary"}, got) {
+		t.Errorf("Expected %+v, got %+v", []string{"film:Documentary"}, got)
+	}
+}
+
+func suggest(t *testing.T, r *Repositories, kind, text string, limit int) []string {
+	t.Helper()
+
+	res, err := r.Autocomplete.Suggest(context.TODO(), &autocomplete.Query{Text: text, Kind: kind, Limit: limit})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	names := make([]string, 0, len(res))
+	for _, v := range res {
+		names = append(names, v.Name)
+	}
+
+	return names
+}
+
+func testAutocomplete(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	tarantino := addActor(t, r, "Quentin Tarantino")
+	addActor(t, r, "Quentin Dupieux")
+	reeves := addActor(t, r, "Keanu Reeves")
+	addFilm(t, r, "Pulp Fiction", 9, "1994-05-21", tarantino)
+
+	tests := []struct {
+		kind  string
+		text  string
+		limit int
+		exp   []string
+	}{
+		// prefix matches of the name or of a word, ordered by name
+		{autocomplete.KindActor, "quen", 10, []string{"Quentin Dupieux", "Quentin Tarantino"}},
+		{autocomplete.KindActor, "TAR", 10, []string{"Quentin Tarantino"}},
+		{autocomplete.KindActor, "quen", 1, []string{"Quentin Dupieux"}},
+		// typos
+		{autocomplete.KindActor, "Tarantno", 10, []string{"Quentin Tarantino"}},
+		{autocomplete.KindFilm, "pulp fction", 10, []string{"Pulp Fiction"}},
+		{autocomplete.KindFilm, "keanu", 10, []string{}},
+		{autocomplete.KindActor, "%", 10, []string{}},
+	}
+	for _, v := range tests {
+		if got := suggest(t, r, v.kind, v.text, v.limit); !reflect.DeepEqual(v.exp, got) {
+			t.Errorf("Expected %+v for %s %q, got %+v", v.exp, v.kind, v.text, got)
+		}
+	}
+
+	// changes are seen right away
+	name := "Keanu Charles Reeves"
+	if err := r.Actors.UpdateActor(ctx, &actor.ActorUpdate{ID: reeves, Name: &name}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got := suggest(t, r, autocomplete.KindActor, "charl", 10); !reflect.DeepEqual([]string{name}, got) {
+		t.Errorf("Expected %+v, got %+v", []string{name}, got)
+	}
+
+	if err := r.Actors.DeleteActor(ctx, tarantino, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got := suggest(t, r, autocomplete.KindActor, "quen", 10); !reflect.DeepEqual([]string{"Quentin Dupieux"}, got) {
+		t.Errorf("Expected %+v, got %+v", []string{"Quentin Dupieux"}, got)
+	}
+}
+
+func testGraph(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	stamp := func() string {
+		t.Helper()
+		s, err := r.Graph.Stamp(ctx)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		return s
+	}
+	// path returns the names along the shortest path, nil without one
+	path := func(from, to int32) []string {
+		t.Helper()
+		s, err := r.Graph.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		steps, ok := graph.NewNetwork(s).Path(from, to, 6)
+		if !ok {
+			return nil
+		}
+		names := make([]string, 0, len(steps))
+		for _, v := range steps {
+			names = append(names, v.Name)
+		}
+		return names
+	}
+
+	bacon := addActor(t, r, "Kevin Bacon")
+	hanks := addActor(t, r, "Tom Hanks")
+	bullock := addActor(t, r, "Sandra Bullock")
+	apollo := addFilm(t, r, "Apollo 13", 8, "1995-06-30", bacon, hanks)
+	loud := addFilm(t, r, "Extremely Loud", 7, "2011-12-25", hanks)
+
+	if exp, got := []string(nil), path(bacon, bullock); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	// binding changes the stamp and links the actors
+	before := stamp()
+	if err := r.Films.AddFilmActors(ctx, &film.FilmActors{ID: loud, ActorIDs: []int32{bullock}}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if stamp() == before {
+		t.Errorf("Expected stamp to change after binding")
+	}
+	exp := []string{"Kevin Bacon", "Apollo 13", "Tom Hanks", "Extremely Loud", "Sandra Bullock"}
+	if got := path(bacon, bullock); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	before = stamp()
+	if stamp() != before {
+		t.Errorf("Expected stamp to stay without changes")
+	}
+
+	// so do unbinding and the trash
+	if err := r.Films.DeleteFilmActors(ctx, &film.FilmActors{ID: loud, ActorIDs: []int32{bullock}}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if stamp() == before {
+		t.Errorf("Expected stamp to change after unbinding")
+	}
+	if got := path(bacon, bullock); got != nil {
+		t.Errorf("Expected no path, got %v", got)
+	}
+
+	before = stamp()
+	if err := r.Films.DeleteFilm(ctx, apollo, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if stamp() == before {
+		t.Errorf("Expected stamp to change after deleting a film")
+	}
+	if got := path(bacon, hanks); got != nil {
+		t.Errorf("Expected no path through a film in the trash, got %v", got)
+	}
+
+	before = stamp()
+	if err := r.Films.RestoreFilm(ctx, apollo); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if stamp() == before {
+		t.Errorf("Expected stamp to change after restoring a film")
+	}
+	if exp, got := []string{"Kevin Bacon", "Apollo 13", "Tom Hanks"}, path(bacon, hanks); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+}
+
+func testViews(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	u1, err := r.Users.CreateUser(ctx, &user.User{Username: "viewer1", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	u2, err := r.Users.CreateUser(ctx, &user.User{Username: "viewer2", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	f1 := addFilm(t, r, "film1", 5, "2000-01-12")
+	f2 := addFilm(t, r, "film2", 6, "2001-01-12")
+	f3 := addFilm(t, r, "film3", 7, "2002-01-12")
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	view := func(userID, filmID int32, minutes int, keep int) {
+		t.Helper()
+		v := &recommend.View{UserID: userID, FilmID: filmID, ViewedAt: now.Add(time.Duration(minutes) * time.Minute)}
+		if err := r.Views.AddView(ctx, v, keep); err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+	}
+	// viewed returns the ids of the films viewed by the user, latest first
+	viewed := func(userID int32, limit int) []int32 {
+		t.Helper()
+		views, err := r.Views.GetViews(ctx, userID, limit)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		ids := make([]int32, 0, len(views))
+		for _, v := range views {
+			if v.UserID != userID {
+				t.Errorf("Expected view of user %d, got %+v", userID, v)
+			}
+			ids = append(ids, v.FilmID)
+		}
+		return ids
+	}
+
+	view(u1.ID, f1, 1, 10)
+	view(u1.ID, f2, 2, 10)
+	view(u1.ID, f3, 3, 10)
+	view(u2.ID, f1, 4, 10)
+	if exp, got := []int32{f3, f2, f1}, viewed(u1.ID, 10); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	if exp, got := []int32{f3, f2}, viewed(u1.ID, 2); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	if exp, got := []int32{f1}, viewed(u2.ID, 10); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	// viewing again moves the film to the front
+	view(u1.ID, f1, 5, 10)
+	if exp, got := []int32{f1, f3, f2}, viewed(u1.ID, 10); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	// only the latest views are kept
+	view(u1.ID, f2, 6, 2)
+	if exp, got := []int32{f2, f1}, viewed(u1.ID, 10); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	// films in the trash are skipped
+	if err := r.Films.DeleteFilm(ctx, f2, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp, got := []int32{f1}, viewed(u1.ID, 10); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+}
+
+func testCollections(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	u1, err := r.Users.CreateUser(ctx, &user.User{Username: "collector1", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	u2, err := r.Users.CreateUser(ctx, &user.User{Username: "collector2", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	f1 := addFilm(t, r, "film1", 5, "2000-01-12")
+	f2 := addFilm(t, r, "film2", 6, "2001-01-12")
+	f3 := addFilm(t, r, "film3", 7, "2002-01-12")
+
+	add := func(userID int32, name, kind, visibility string) *collection.Collection {
+		t.Helper()
+		c, err := r.Collections.AddCollection(ctx, &collection.Collection{
+			UserID:     userID,
+			Name:       name,
+			Kind:       kind,
+			Visibility: visibility,
+			ShareToken: fmt.Sprintf("token-%d-%s", userID, name),
+		})
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		return c
+	}
+	watchlist := add(u1.ID, "Watchlist", collection.KindWatchlist, collection.VisibilityPrivate)
+	custom := add(u1.ID, "Noir", collection.KindCustom, collection.VisibilityPublic)
+	other := add(u2.ID, "Watchlist", collection.KindWatchlist, collection.VisibilityUnlisted)
+
+	// names and the watchlist and favorites kinds are unique per user
+	_, err = r.Collections.AddCollection(ctx, &collection.Collection{
+		UserID: u1.ID, Name: "Noir", Kind: collection.KindCustom,
+		Visibility: collection.VisibilityPrivate, ShareToken: "token-dup-name",
+	})
+	if !errors.Is(err, collection.ErrNameTaken) {
+		t.Errorf("Expected %+v, got %+v", collection.ErrNameTaken, err)
+	}
+	_, err = r.Collections.AddCollection(ctx, &collection.Collection{
+		UserID: u1.ID, Name: "Later", Kind: collection.KindWatchlist,
+		Visibility: collection.VisibilityPrivate, ShareToken: "token-dup-kind",
+	})
+	if !errors.Is(err, collection.ErrKindTaken) {
+		t.Errorf("Expected %+v, got %+v", collection.ErrKindTaken, err)
+	}
+	add(u1.ID, "Westerns", collection.KindCustom, collection.VisibilityPrivate)
+
+	ids := func(collections []*collection.Collection) []int32 {
+		res := make([]int32, 0, len(collections))
+		for _, v := range collections {
+			res = append(res, v.ID)
+		}
+		return res
+	}
+	own, err := r.Collections.GetCollections(ctx, &collection.Query{UserID: u2.ID})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp, got := []int32{other.ID}, ids(own); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	public, err := r.Collections.GetCollections(ctx, &collection.Query{Visibility: collection.VisibilityPublic})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp, got := []int32{custom.ID}, ids(public); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	shared, err := r.Collections.GetCollectionByToken(ctx, other.ShareToken)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if shared.ID != other.ID || shared.UserID != u2.ID || shared.Kind != collection.KindWatchlist {
+		t.Errorf("Expected %+v, got %+v", other, shared)
+	}
+	if _, err := r.Collections.GetCollectionByToken(ctx, "missing"); !errors.Is(err, collection.ErrCollectionNotExist) {
+		t.Errorf("Expected %+v, got %+v", collection.ErrCollectionNotExist, err)
+	}
+
+	name, visibility := "Noir classics", collection.VisibilityUnlisted
+	err = r.Collections.UpdateCollection(ctx, &collection.CollectionUpdate{ID: custom.ID, Name: &name, Visibility: &visibility})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	got, err := r.Collections.GetCollection(ctx, custom.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got.Name != name || got.Visibility != visibility || got.UpdatedAt.Before(custom.UpdatedAt) {
+		t.Errorf("Expected updated %+v, got %+v", custom, got)
+	}
+	taken := "Westerns"
+	err = r.Collections.UpdateCollection(ctx, &collection.CollectionUpdate{ID: custom.ID, Name: &taken})
+	if !errors.Is(err, collection.ErrNameTaken) {
+		t.Errorf("Expected %+v, got %+v", collection.ErrNameTaken, err)
+	}
+
+	put := func(filmID int32, pos int, note string) {
+		t.Helper()
+		e := &collection.Entry{CollectionID: watchlist.ID, FilmID: filmID, Position: pos, Note: note}
+		if err := r.Collections.PutEntry(ctx, e); err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+	}
+	// entries returns the film ids of the watchlist in order and checks
+	// the positions are contiguous
+	entries := func() []int32 {
+		t.Helper()
+		entries, err := r.Collections.GetEntries(ctx, watchlist.ID)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		res := make([]int32, 0, len(entries))
+		for i, v := range entries {
+			if v.Position != i+1 {
+				t.Errorf("Expected position %d, got %+v", i+1, v)
+			}
+			res = append(res, v.FilmID)
+		}
+		return res
+	}
+
+	put(f1, 0, "")
+	put(f2, 0, "")
+	put(f3, 1, "first")
+	if exp, got := []int32{f3, f1, f2}, entries(); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	// moves and note changes
+	put(f3, 3, "last")
+	if exp, got := []int32{f1, f2, f3}, entries(); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	put(f2, 1, "")
+	put(f1, 0, "kept in place")
+	if exp, got := []int32{f2, f1, f3}, entries(); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	put(f2, 10, "")
+	if exp, got := []int32{f1, f3, f2}, entries(); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	all, err := r.Collections.GetEntries(ctx, watchlist.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if e := all[0]; e.Note != "kept in place" || e.FilmName != "film1" || e.Rating != 5 || !e.Available {
+		t.Errorf("Expected entry of film1, got %+v", e)
+	}
+	if e := all[1]; e.Note != "last" {
+		t.Errorf("Expected note %q, got %+v", "last", e)
+	}
+
+	if err := r.Collections.DeleteEntry(ctx, watchlist.ID, f3); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp, got := []int32{f1, f2}, entries(); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	if err := r.Collections.DeleteEntry(ctx, watchlist.ID, f3); !errors.Is(err, collection.ErrEntryNotExist) {
+		t.Errorf("Expected %+v, got %+v", collection.ErrEntryNotExist, err)
+	}
+
+	// films in the trash stay listed but can not be added
+	if err := r.Films.DeleteFilm(ctx, f1, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	all, err = r.Collections.GetEntries(ctx, watchlist.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(all) != 2 || all[0].FilmID != f1 || all[0].Available || all[0].FilmName != "film1" {
+		t.Errorf("Expected film1 listed as unavailable, got %+v", all[0])
+	}
+	err = r.Collections.PutEntry(ctx, &collection.Entry{CollectionID: other.ID, FilmID: f1})
+	if !errors.Is(err, collection.ErrFilmNotExist) {
+		t.Errorf("Expected %+v, got %+v", collection.ErrFilmNotExist, err)
+	}
+
+	// purged films leave the lists
+	if _, err := r.Films.PurgeFilms(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp, got := []int32{f2}, entries(); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	got, err = r.Collections.GetCollection(ctx, watchlist.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got.FilmCount != 1 {
+		t.Errorf("Expected 1 film, got %+v", got)
+	}
+
+	if err := r.Collections.DeleteCollection(ctx, watchlist.ID); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if _, err := r.Collections.GetCollection(ctx, watchlist.ID); !errors.Is(err, collection.ErrCollectionNotExist) {
+		t.Errorf("Expected %+v, got %+v", collection.ErrCollectionNotExist, err)
+	}
+	if err := r.Collections.DeleteCollection(ctx, watchlist.ID); !errors.Is(err, collection.ErrCollectionNotExist) {
+		t.Errorf("Expected %+v, got %+v", collection.ErrCollectionNotExist, err)
+	}
+}
+
+func testHistory(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	u1, err := r.Users.CreateUser(ctx, &user.User{Username: "watcher1", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	u2, err := r.Users.CreateUser(ctx, &user.User{Username: "watcher2", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	a1 := addActor(t, r, "actor1")
+	a2 := addActor(t, r, "actor2")
+	f1 := addFilm(t, r, "film1", 5, "2000-01-12", a1, a2)
+	f2 := addFilm(t, r, "film2", 6, "2001-01-12", a2)
+	f3 := addFilm(t, r, "film3", 7, "2002-01-12")
+
+	rating := int32(8)
+	add := func(userID, filmID int32, watchedOn string, rewatch bool) *history.Watch {
+		t.Helper()
+		w, err := r.History.AddWatch(ctx, &history.Watch{
+			UserID:    userID,
+			FilmID:    filmID,
+			WatchedOn: date(t, watchedOn),
+			Rating:    &rating,
+			Rewatch:   rewatch,
+		})
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		return w
+	}
+	w1 := add(u1.ID, f1, "2023-12-31", false)
+	w2 := add(u1.ID, f2, "2024-02-01", false)
+	w3 := add(u1.ID, f1, "2024-03-01", true)
+	add(u2.ID, f3, "2024-03-01", false)
+	if w1.ID == 0 || w1.FilmName != "film1" || !w1.Available || w1.CreatedAt.IsZero() {
+		t.Errorf("Expected added watch of film1, got %+v", w1)
+	}
+
+	_, err = r.History.AddWatch(ctx, &history.Watch{UserID: u1.ID, FilmID: 1 << 30, WatchedOn: date(t, "2024-01-01")})
+	if !errors.Is(err, history.ErrFilmNotExist) {
+		t.Errorf("Expected %+v, got %+v", history.ErrFilmNotExist, err)
+	}
+
+	ids := func(q *history.Query) []int32 {
+		t.Helper()
+		watches, err := r.History.GetWatches(ctx, q)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		ids := make([]int32, 0, len(watches))
+		for _, v := range watches {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+	from, to := history.YearRange(2024)
+	tests := []struct {
+		name string
+		q    *history.Query
+		exp  []int32
+	}{
+		{name: "latest first", q: &history.Query{UserID: u1.ID}, exp: []int32{w3.ID, w2.ID, w1.ID}},
+		{name: "year", q: &history.Query{UserID: u1.ID, From: from, To: to}, exp: []int32{w3.ID, w2.ID}},
+		{name: "film", q: &history.Query{UserID: u1.ID, FilmID: f1}, exp: []int32{w3.ID, w1.ID}},
+		{name: "limit", q: &history.Query{UserID: u1.ID, Limit: 1}, exp: []int32{w3.ID}},
+		{name: "before", q: &history.Query{UserID: u1.ID, FilmID: f1, To: date(t, "2024-01-01")}, exp: []int32{w1.ID}},
+	}
+	for _, tt := range tests {
+		if got := ids(tt.q); !slices.Equal(tt.exp, got) {
+			t.Errorf("%s: Expected %v, got %v", tt.name, tt.exp, got)
+		}
+	}
+
+	watches, err := r.History.GetWatches(ctx, &history.Query{UserID: u1.ID, Limit: 1})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if w := watches[0]; !w.WatchedOn.Equal(date(t, "2024-03-01")) || w.Rating == nil || *w.Rating != 8 || !w.Rewatch {
+		t.Errorf("Expected rated rewatch on 2024-03-01, got %+v", w)
+	}
+
+	actors, err := r.History.GetTopActors(ctx, &history.Query{UserID: u1.ID, From: from, To: to}, 10)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	exp := []*history.ActorCount{{ID: a2, Name: "actor2", Films: 2}, {ID: a1, Name: "actor1", Films: 1}}
+	if !reflect.DeepEqual(exp, actors) {
+		t.Errorf("Expected %+v, got %+v", exp, actors)
+	}
+
+	// seen films of the user
+	seen := func(v bool) []string {
+		t.Helper()
+		films, err := r.Films.GetFilms(ctx, &film.Query{Seen: &v, SeenBy: u1.ID})
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		names := make([]string, 0, len(films))
+		for _, v := range films {
+			names = append(names, v.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+	if exp, got := []string{"film1", "film2"}, seen(true); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	if exp, got := []string{"film3"}, seen(false); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	// watches of other users do not exist for the user
+	if err := r.History.DeleteWatch(ctx, u2.ID, w2.ID); !errors.Is(err, history.ErrWatchNotExist) {
+		t.Errorf("Expected %+v, got %+v", history.ErrWatchNotExist, err)
+	}
+	if err := r.History.DeleteWatch(ctx, u1.ID, w2.ID); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.History.DeleteWatch(ctx, u1.ID, w2.ID); !errors.Is(err, history.ErrWatchNotExist) {
+		t.Errorf("Expected %+v, got %+v", history.ErrWatchNotExist, err)
+	}
+
+	// trashed films stay in the history, purged ones leave it
+	if err := r.Films.DeleteFilm(ctx, f1, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	watches, err = r.History.GetWatches(ctx, &history.Query{UserID: u1.ID})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(watches) != 2 || watches[0].Available {
+		t.Errorf("Expected 2 unavailable watches, got %+v", watches)
+	}
+	_, err = r.History.AddWatch(ctx, &history.Watch{UserID: u1.ID, FilmID: f1, WatchedOn: date(t, "2024-04-01")})
+	if !errors.Is(err, history.ErrFilmNotExist) {
+		t.Errorf("Expected %+v, got %+v", history.ErrFilmNotExist, err)
+	}
+	if _, err := r.Films.PurgeFilms(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got := ids(&history.Query{UserID: u1.ID}); len(got) != 0 {
+		t.Errorf("Expected empty history, got %v", got)
+	}
+}
+
+func testLending(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	u1, err := r.Users.CreateUser(ctx, &user.User{Username: "member1", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	u2, err := r.Users.CreateUser(ctx, &user.User{Username: "member2", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	f1 := addFilm(t, r, "film1", 5, "2000-01-12")
+	f2 := addFilm(t, r, "film2", 6, "2001-01-12")
+
+	add := func(filmID int32, barcode string) *lending.Copy {
+		t.Helper()
+		c, err := r.Lending.AddCopy(ctx, &lending.Copy{
+			FilmID:    filmID,
+			Format:    lending.FormatDVD,
+			Barcode:   barcode,
+			Condition: lending.ConditionGood,
+		})
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		return c
+	}
+	c1 := add(f1, "B-1")
+	c2 := add(f1, "B-2")
+	c3 := add(f2, "B-3")
+	if c1.ID == 0 || c1.CreatedAt.IsZero() || c1.OnLoan {
+		t.Errorf("Expected added copy, got %+v", c1)
+	}
+
+	_, err = r.Lending.AddCopy(ctx, &lending.Copy{FilmID: f2, Format: lending.FormatVHS, Barcode: "B-1", Condition: lending.ConditionPoor})
+	if !errors.Is(err, lending.ErrBarcodeTaken) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrBarcodeTaken, err)
+	}
+	_, err = r.Lending.AddCopy(ctx, &lending.Copy{FilmID: 1 << 30, Format: lending.FormatVHS, Barcode: "B-4", Condition: lending.ConditionPoor})
+	if !errors.Is(err, lending.ErrFilmNotExist) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrFilmNotExist, err)
+	}
+
+	condition := lending.ConditionFair
+	if err := r.Lending.UpdateCopy(ctx, &lending.CopyUpdate{ID: c2.ID, Condition: &condition}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.Lending.UpdateCopy(ctx, &lending.CopyUpdate{ID: c2.ID}); !errors.Is(err, lending.ErrEmptyUpdate) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrEmptyUpdate, err)
+	}
+	got, err := r.Lending.GetCopyByBarcode(ctx, "B-2")
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got.ID != c2.ID || got.Condition != lending.ConditionFair || got.Format != lending.FormatDVD {
+		t.Errorf("Expected fair copy %d, got %+v", c2.ID, got)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	loan := func(copyID, userID int32, due time.Time) *lending.Loan {
+		t.Helper()
+		l, err := r.Lending.AddLoan(ctx, &lending.Loan{CopyID: copyID, UserID: userID, CheckedOutAt: now, DueAt: due})
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		return l
+	}
+	l1 := loan(c1.ID, u1.ID, now.Add(-time.Hour))
+	l2 := loan(c3.ID, u1.ID, now.Add(time.Hour))
+	if l1.ID == 0 || l1.Barcode != "B-1" || l1.FilmID != f1 || l1.FilmName != "film1" || l1.Username != "member1" {
+		t.Errorf("Expected loan of B-1 to member1, got %+v", l1)
+	}
+
+	_, err = r.Lending.AddLoan(ctx, &lending.Loan{CopyID: c1.ID, UserID: u2.ID, CheckedOutAt: now, DueAt: now})
+	if !errors.Is(err, lending.ErrCopyOnLoan) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrCopyOnLoan, err)
+	}
+	_, err = r.Lending.AddLoan(ctx, &lending.Loan{CopyID: c2.ID, UserID: 1 << 30, CheckedOutAt: now, DueAt: now})
+	if !errors.Is(err, lending.ErrUserNotExist) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrUserNotExist, err)
+	}
+	if err := r.Lending.DeleteCopy(ctx, c1.ID); !errors.Is(err, lending.ErrCopyOnLoan) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrCopyOnLoan, err)
+	}
+
+	copies, err := r.Lending.GetCopies(ctx, f1)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(copies) != 2 || copies[0].ID != c1.ID || !copies[0].OnLoan || copies[1].OnLoan {
+		t.Errorf("Expected B-1 on loan and B-2 available, got %+v", copies)
+	}
+
+	loans := func(q *lending.LoanQuery) []int32 {
+		t.Helper()
+		loans, err := r.Lending.GetLoans(ctx, q)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		ids := make([]int32, 0, len(loans))
+		for _, v := range loans {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+	tests := []struct {
+		name string
+		q    *lending.LoanQuery
+		exp  []int32
+	}{
+		{name: "earliest due first", q: &lending.LoanQuery{}, exp: []int32{l1.ID, l2.ID}},
+		{name: "overdue", q: &lending.LoanQuery{Active: true, DueBefore: now}, exp: []int32{l1.ID}},
+		{name: "film", q: &lending.LoanQuery{UserID: u1.ID, FilmID: f2}, exp: []int32{l2.ID}},
+		{name: "user", q: &lending.LoanQuery{UserID: u2.ID}, exp: []int32{}},
+	}
+	for _, tt := range tests {
+		if got := loans(tt.q); !slices.Equal(tt.exp, got) {
+			t.Errorf("%s: Expected %v, got %v", tt.name, tt.exp, got)
+		}
+	}
+
+	if err := r.Lending.RenewLoan(ctx, l1.ID, now.Add(24*time.Hour)); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.Lending.ReturnLoan(ctx, l2.ID, now); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.Lending.ReturnLoan(ctx, l2.ID, now); !errors.Is(err, lending.ErrLoanNotExist) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrLoanNotExist, err)
+	}
+	renewed, err := r.Lending.GetLoan(ctx, l1.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if renewed.Renewals != 1 || !renewed.DueAt.Equal(now.Add(24*time.Hour)) || renewed.ReturnedAt != nil {
+		t.Errorf("Expected loan renewed once, got %+v", renewed)
+	}
+	returned, err := r.Lending.GetLoan(ctx, l2.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if returned.ReturnedAt == nil || !returned.ReturnedAt.Equal(now) {
+		t.Errorf("Expected loan returned at %s, got %+v", now, returned)
+	}
+	if exp, got := []int32{l1.ID}, loans(&lending.LoanQuery{Active: true}); !slices.Equal(exp, got) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	if _, err := r.Lending.GetLoan(ctx, 1<<30); !errors.Is(err, lending.ErrLoanNotExist) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrLoanNotExist, err)
+	}
+
+	// holds are served first come, first served
+	for _, v := range []int32{u2.ID, u1.ID} {
+		if err := r.Lending.AddHold(ctx, &lending.Hold{FilmID: f1, UserID: v}); err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+	}
+	if err := r.Lending.AddHold(ctx, &lending.Hold{FilmID: f1, UserID: u2.ID}); !errors.Is(err, lending.ErrHoldExists) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrHoldExists, err)
+	}
+	if err := r.Lending.AddHold(ctx, &lending.Hold{FilmID: f2, UserID: u1.ID}); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	holds, err := r.Lending.GetHolds(ctx, f1)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(holds) != 2 || holds[0].UserID != u2.ID || holds[0].Position != 1 || holds[1].Position != 2 ||
+		holds[0].AvailableCopies != 1 || holds[1].Username != "member1" || holds[1].FilmName != "film1" {
+		t.Errorf("Expected member2 then member1 with a copy available, got %+v", holds)
+	}
+	mine, err := r.Lending.GetUserHolds(ctx, u1.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(mine) != 2 || mine[0].FilmID != f1 || mine[0].Position != 2 || mine[1].FilmID != f2 ||
+		mine[1].Position != 1 || mine[1].AvailableCopies != 1 {
+		t.Errorf("Expected second for film1 and first for film2, got %+v", mine)
+	}
+	if err := r.Lending.DeleteHold(ctx, f1, u2.ID); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.Lending.DeleteHold(ctx, f1, u2.ID); !errors.Is(err, lending.ErrHoldNotExist) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrHoldNotExist, err)
+	}
+	if holds, err = r.Lending.GetHolds(ctx, f1); err != nil || len(holds) != 1 || holds[0].Position != 1 {
+		t.Errorf("Expected member1 first, got %+v %v", holds, err)
+	}
+
+	// returned loans go with the copy
+	if err := r.Lending.DeleteCopy(ctx, c3.ID); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if _, err := r.Lending.GetLoan(ctx, l2.ID); !errors.Is(err, lending.ErrLoanNotExist) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrLoanNotExist, err)
+	}
+
+	// copies of films in the trash are not found, purged films take their
+	// copies, loans and holds along
+	if err := r.Films.DeleteFilm(ctx, f1, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if _, err := r.Lending.GetCopy(ctx, c2.ID); !errors.Is(err, lending.ErrCopyNotExist) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrCopyNotExist, err)
+	}
+	if _, err := r.Lending.GetCopies(ctx, f1); !errors.Is(err, lending.ErrFilmNotExist) {
+		t.Errorf("Expected %+v, got %+v", lending.ErrFilmNotExist, err)
+	}
+	if _, err := r.Films.PurgeFilms(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got := loans(&lending.LoanQuery{}); len(got) != 0 {
+		t.Errorf("Expected no loans, got %v", got)
+	}
+	mine, err = r.Lending.GetUserHolds(ctx, u1.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(mine) != 1 || mine[0].FilmID != f2 {
+		t.Errorf("Expected the hold for film2 only, got %+v", mine)
+	}
+}
+
+func testFollow(t *testing.T, r *Repositories) {
+	ctx := context.TODO()
+
+	u1, err := r.Users.CreateUser(ctx, &user.User{Username: "fan1", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	u2, err := r.Users.CreateUser(ctx, &user.User{Username: "fan2", Passhash: "hash"})
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	a1 := addActor(t, r, "actor1")
+	a2 := addActor(t, r, "actor2")
+	a3 := addActor(t, r, "actor3")
+
+	for _, v := range []follow.Follow{{UserID: u1.ID, ActorID: a1}, {UserID: u1.ID, ActorID: a2}, {UserID: u2.ID, ActorID: a1}} {
+		if err := r.Follow.AddFollow(ctx, &v); err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+	}
+	// following again keeps the follow as it is
+	again := &follow.Follow{UserID: u1.ID, ActorID: a1}
+	if err := r.Follow.AddFollow(ctx, again); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.Follow.AddFollow(ctx, &follow.Follow{UserID: u1.ID, ActorID: 1 << 30}); !errors.Is(err, follow.ErrActorNotExist) {
+		t.Errorf("Expected %+v, got %+v", follow.ErrActorNotExist, err)
+	}
+	follows, err := r.Follow.GetFollows(ctx, u1.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(follows) != 2 || follows[0].ActorID != a1 || follows[0].ActorName != "actor1" || follows[1].ActorID != a2 ||
+		again.ActorName != "actor1" || !again.CreatedAt.Equal(follows[0].CreatedAt) {
+		t.Errorf("Expected actor1 and actor2 followed once, got %+v %+v", follows, again)
+	}
+
+	if err := r.Follow.SetEmail(ctx, u1.ID, "fan1@example.com"); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.Follow.SetEmail(ctx, u1.ID, "fan@example.com"); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	// a notification is recorded once per follower, actor and film
+	f1 := addFilm(t, r, "film1", 5, "2000-01-12")
+	now := time.Now().UTC().Truncate(time.Second)
+	count, err := r.Follow.AddNotifications(ctx, f1, []int32{a1, a2, a3}, now)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 notifications, got %d", count)
+	}
+	if count, err = r.Follow.AddNotifications(ctx, f1, []int32{a1}, now); err != nil || count != 0 {
+		t.Errorf("Expected no notifications recorded twice, got %d %v", count, err)
+	}
+
+	notifications, err := r.Follow.GetNotifications(ctx, u1.ID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(notifications) != 2 || notifications[0].ActorID != a2 || notifications[1].ActorID != a1 {
+		t.Fatalf("Expected notifications about actor2 and actor1, got %+v", notifications)
+	}
+	n := notifications[1]
+	if n.Username != "fan1" || n.Email != "fan@example.com" || n.FilmName != "film1" || n.ActorName != "actor1" ||
+		!n.ReleaseDate.Equal(date(t, "2000-01-12")) || n.Status != follow.StatusPending || n.SentAt != nil {
+		t.Errorf("Expected pending notification of fan1 about actor1 in film1, got %+v", n)
+	}
+
+	if _, err := r.Follow.ClaimNotifications(ctx, now.Add(-time.Second), 10); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	claimed, err := r.Follow.ClaimNotifications(ctx, now, 2)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(claimed) != 2 || claimed[0].ID >= claimed[1].ID || claimed[0].Status != follow.StatusSending {
+		t.Fatalf("Expected 2 notifications oldest first, got %+v", claimed)
+	}
+	rest, err := r.Follow.ClaimNotifications(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(rest) != 1 || rest[0].ID == claimed[0].ID || rest[0].ID == claimed[1].ID {
+		t.Errorf("Expected the notification not claimed yet, got %+v", rest)
+	}
+	if again, err := r.Follow.ClaimNotifications(ctx, now, 10); err != nil || len(again) != 0 {
+		t.Errorf("Expected nothing to claim, got %+v %v", again, err)
+	}
+
+	sent := claimed[0]
+	sent.Status, sent.Attempts, sent.SentAt = follow.StatusSent, 1, &now
+	if err := r.Follow.FinishNotification(ctx, sent); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.Follow.FinishNotification(ctx, sent); !errors.Is(err, follow.ErrNotificationNotClaimed) {
+		t.Errorf("Expected %+v, got %+v", follow.ErrNotificationNotClaimed, err)
+	}
+	retried := claimed[1]
+	retried.Status, retried.Attempts, retried.LastError = follow.StatusPending, 1, "unavailable"
+	retried.NextAttemptAt = now.Add(time.Minute)
+	if err := r.Follow.FinishNotification(ctx, retried); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if got, err := r.Follow.ClaimNotifications(ctx, now, 10); err != nil || len(got) != 0 {
+		t.Errorf("Expected the retry to wait, got %+v %v", got, err)
+	}
+	got, err := r.Follow.ClaimNotifications(ctx, now.Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(got) != 1 || got[0].ID != retried.ID || got[0].Attempts != 1 || got[0].LastError != "unavailable" {
+		t.Errorf("Expected the retried notification, got %+v", got)
+	}
+
+	// a claim left sending past the timeout is failed, later ones are kept
+	stale, err := r.Follow.FailStaleNotifications(ctx, now.Add(time.Second), follow.ErrDeliveryUnknown.Error())
+	if err != nil || stale != 1 {
+		t.Errorf("Expected 1 stale notification, got %d %v", stale, err)
+	}
+	if err := r.Follow.FinishNotification(ctx, rest[0]); !errors.Is(err, follow.ErrNotificationNotClaimed) {
+		t.Errorf("Expected %+v, got %+v", follow.ErrNotificationNotClaimed, err)
+	}
+	mine, err := r.Follow.GetNotifications(ctx, rest[0].UserID)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	i := slices.IndexFunc(mine, func(n *follow.Notification) bool { return n.ID == rest[0].ID })
+	if i == -1 || mine[i].Status != follow.StatusFailed || mine[i].LastError != follow.ErrDeliveryUnknown.Error() {
+		t.Errorf("Expected notification %d failed with unknown delivery, got %+v", rest[0].ID, mine)
+	}
+	if err := r.Follow.FinishNotification(ctx, got[0]); err != nil {
+		t.Errorf("Expected notification %d still claimed, got %v", got[0].ID, err)
+	}
+
+	all := append(notifications, func() []*follow.Notification {
+		n, err := r.Follow.GetNotifications(ctx, u2.ID)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		return n
+	}()...)
+	for _, v := range all {
+		if v.ID != sent.ID {
+			continue
+		}
+		mine, err := r.Follow.GetNotifications(ctx, v.UserID)
+		if err != nil {
+			t.Fatalf("No error expected, got %s", err.Error())
+		}
+		i := slices.IndexFunc(mine, func(n *follow.Notification) bool { return n.ID == sent.ID })
+		if i == -1 || mine[i].Status != follow.StatusSent || mine[i].SentAt == nil || !mine[i].SentAt.Equal(now) {
+			t.Errorf("Expected notification %d sent at %s, got %+v", sent.ID, now, mine)
+		}
+	}
+
+	// removing the address keeps the notifications
+	if err := r.Follow.SetEmail(ctx, u1.ID, ""); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if notifications, err = r.Follow.GetNotifications(ctx, u1.ID); err != nil || len(notifications) != 2 || notifications[0].Email != "" {
+		t.Errorf("Expected notifications without address, got %+v %v", notifications, err)
+	}
+
+	if err := r.Follow.DeleteFollow(ctx, u1.ID, a2); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if err := r.Follow.DeleteFollow(ctx, u1.ID, a2); !errors.Is(err, follow.ErrFollowNotExist) {
+		t.Errorf("Expected %+v, got %+v", follow.ErrFollowNotExist, err)
+	}
+
+	// follows of actors in the trash are not listed, purged actors take
+	// their follows and notifications along
+	if err := r.Actors.DeleteActor(ctx, a1, 0); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if follows, err = r.Follow.GetFollows(ctx, u2.ID); err != nil || len(follows) != 0 {
+		t.Errorf("Expected no follows, got %+v %v", follows, err)
+	}
+	if _, err := r.Actors.PurgeActors(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if notifications, err = r.Follow.GetNotifications(ctx, u1.ID); err != nil || len(notifications) != 1 || notifications[0].ActorID != a2 {
+		t.Errorf("Expected the notification about actor2 only, got %+v %v", notifications, err)
+	}
+}
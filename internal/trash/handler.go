@@ -0,0 +1,31 @@
+package trash
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+var _ TrashHandler = (*Handler)(nil)
+
+type Handler struct {
+	service TrashService
+}
+
+func NewHandler(ts TrashService) *Handler {
+	return &Handler{
+		service: ts,
+	}
+}
+
+func (h *Handler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetTrash(r.Context())
+	if err != nil {
+		log.Printf("ERROR: failed to get trash err=%s\n", err.Error())
+		util.InternalServerError(w, r)
+		return
+	}
+
+	util.JSON(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,149 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+var _ TrashService = (*Service)(nil)
+
+type Service struct {
+	films  film.FilmRepository
+	actors actor.ActorRepository
+}
+
+func NewService(fr film.FilmRepository, ar actor.ActorRepository) *Service {
+	return &Service{
+		films:  fr,
+		actors: ar,
+	}
+}
+
+func (s *Service) GetTrash(ctx context.Context) (*TrashResponse, error) {
+	const op = "trash.Service.GetTrash"
+
+	films, err := s.films.GetDeletedFilms(ctx)
+	if err != nil {
+		log.Printf("ERROR: failed to get deleted films from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	actors, err := s.actors.GetDeletedActors(ctx)
+	if err != nil {
+		log.Printf("ERROR: failed to get deleted actors from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := &TrashResponse{
+		Films:  make([]*film.FilmResponse, 0, len(films)),
+		Actors: make([]*actor.ActorResponse, 0, len(actors)),
+	}
+	for _, v := range films {
+		res.Films = append(res.Films, film.ToFilmResponse(v))
+	}
+	for _, v := range actors {
+		res.Actors = append(res.Actors, actor.ToActorResponse(v))
+	}
+
+	return res, nil
+}
+
+// Purge removes films and actors deleted before the given time for good.
+// Each purged record gets an audit entry in the transaction of its purge.
+func (s *Service) Purge(ctx context.Context, before time.Time) (*PurgeResult, error) {
+	const op = "trash.Service.Purge"
+
+	var res PurgeResult
+
+	err := s.films.Atomic(ctx, func(fr film.FilmRepository, rec audit.Recorder) error {
+		films, err := fr.GetDeletedFilms(ctx)
+		if err != nil {
+			log.Printf("ERROR: failed to get deleted films from repository\n")
+			return err
+		}
+
+		ids, err := fr.PurgeFilms(ctx, before)
+		if err != nil {
+			log.Printf("ERROR: failed to purge films in repository\n")
+			return err
+		}
+
+		states := make(map[int32]any, len(films))
+		for _, v := range films {
+			states[v.ID] = film.ToFilmState(v)
+		}
+		for _, id := range ids {
+			if err := rec.Record(ctx, audit.EntityFilm, id, audit.ActionPurge, states[id], nil); err != nil {
+				log.Printf("ERROR: failed to record film purge\n")
+				return err
+			}
+		}
+		res.Films = int64(len(ids))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.actors.Atomic(ctx, func(ar actor.ActorRepository, rec audit.Recorder) error {
+		actors, err := ar.GetDeletedActors(ctx)
+		if err != nil {
+			log.Printf("ERROR: failed to get deleted actors from repository\n")
+			return err
+		}
+
+		ids, err := ar.PurgeActors(ctx, before)
+		if err != nil {
+			log.Printf("ERROR: failed to purge actors in repository\n")
+			return err
+		}
+
+		states := make(map[int32]any, len(actors))
+		for _, v := range actors {
+			states[v.ID] = actor.ToActorState(v)
+		}
+		for _, id := range ids {
+			if err := rec.Record(ctx, audit.EntityActor, id, audit.ActionPurge, states[id], nil); err != nil {
+				log.Printf("ERROR: failed to record actor purge\n")
+				return err
+			}
+		}
+		res.Actors = int64(len(ids))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &res, nil
+}
+
+// RunPurge purges records kept in the trash longer than retention every
+// interval until ctx is done.
+func (s *Service) RunPurge(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		res, err := s.Purge(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("ERROR: failed to purge trash err=%s\n", err.Error())
+		} else if res.Films != 0 || res.Actors != 0 {
+			log.Printf("INFO: purged %d films and %d actors from trash\n", res.Films, res.Actors)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
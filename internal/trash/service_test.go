@@ -0,0 +1,112 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/audit"
+	"github.com/Coderovshik/film-library/internal/film"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestService_GetTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := film.NewMockFilmRepository(ctrl)
+	am := actor.NewMockActorRepository(ctrl)
+
+	s := NewService(fm, am)
+
+	deletedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	films := []*film.Film{
+		{ID: 1, Name: "film1", Actors: []string{"actor1"}, DeletedAt: &deletedAt},
+	}
+	fm.EXPECT().GetDeletedFilms(gomock.Any()).Return(films, nil).Times(1)
+	am.EXPECT().GetDeletedActors(gomock.Any()).Return([]*actor.Actor{}, nil).Times(1)
+
+	exp := &TrashResponse{
+		Films:  []*film.FilmResponse{film.ToFilmResponse(films[0])},
+		Actors: []*actor.ActorResponse{},
+	}
+
+	res, err := s.GetTrash(context.TODO())
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+	if res.Films[0].DeletedAt != "2024-03-01T12:00:00Z" {
+		t.Errorf("Expected deletedAt %s, got %s", "2024-03-01T12:00:00Z", res.Films[0].DeletedAt)
+	}
+}
+
+func TestService_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fm := film.NewMockFilmRepository(ctrl)
+	am := actor.NewMockActorRepository(ctrl)
+	rec := audit.NewMockRecorder(ctrl)
+
+	fm.EXPECT().
+		Atomic(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(fr film.FilmRepository, rec audit.Recorder) error) error {
+			return fn(fm, rec)
+		}).AnyTimes()
+	am.EXPECT().
+		Atomic(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ar actor.ActorRepository, rec audit.Recorder) error) error {
+			return fn(am, rec)
+		}).AnyTimes()
+
+	s := NewService(fm, am)
+
+	// every purged record is audited with its last state
+	before := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	films := []*film.Film{{ID: 1, Name: "film1"}, {ID: 2, Name: "film2"}}
+	actors := []*actor.Actor{{ID: 3, Name: "actor3"}}
+	gomock.InOrder(
+		fm.EXPECT().GetDeletedFilms(gomock.Any()).Return(films, nil).Times(1),
+		fm.EXPECT().PurgeFilms(gomock.Any(), before).Return([]int32{1, 2}, nil).Times(1),
+		rec.EXPECT().Record(gomock.Any(), audit.EntityFilm, int32(1), audit.ActionPurge, film.ToFilmState(films[0]), nil).Return(nil).Times(1),
+		rec.EXPECT().Record(gomock.Any(), audit.EntityFilm, int32(2), audit.ActionPurge, film.ToFilmState(films[1]), nil).Return(nil).Times(1),
+		am.EXPECT().GetDeletedActors(gomock.Any()).Return(actors, nil).Times(1),
+		am.EXPECT().PurgeActors(gomock.Any(), before).Return([]int32{3}, nil).Times(1),
+		rec.EXPECT().Record(gomock.Any(), audit.EntityActor, int32(3), audit.ActionPurge, actor.ToActorState(actors[0]), nil).Return(nil).Times(1),
+	)
+
+	res, err := s.Purge(context.TODO(), before)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if exp := (&PurgeResult{Films: 2, Actors: 1}); *exp != *res {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+
+	// failed film purge leaves actors alone
+	purgeErr := errors.New("connection refused")
+	gomock.InOrder(
+		fm.EXPECT().GetDeletedFilms(gomock.Any()).Return(films, nil).Times(1),
+		fm.EXPECT().PurgeFilms(gomock.Any(), before).Return(nil, purgeErr).Times(1),
+	)
+
+	_, err = s.Purge(context.TODO(), before)
+	if !errors.Is(err, purgeErr) {
+		t.Errorf("Expected %v, got %v", purgeErr, err)
+	}
+
+	// failed audit entry fails the purge
+	auditErr := errors.New("audit log unavailable")
+	gomock.InOrder(
+		fm.EXPECT().GetDeletedFilms(gomock.Any()).Return(films, nil).Times(1),
+		fm.EXPECT().PurgeFilms(gomock.Any(), before).Return([]int32{1}, nil).Times(1),
+		rec.EXPECT().Record(gomock.Any(), audit.EntityFilm, int32(1), audit.ActionPurge, gomock.Any(), nil).Return(auditErr).Times(1),
+	)
+
+	_, err = s.Purge(context.TODO(), before)
+	if !errors.Is(err, auditErr) {
+		t.Errorf("Expected %v, got %v", auditErr, err)
+	}
+}
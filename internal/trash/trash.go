@@ -0,0 +1,30 @@
+package trash
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Coderovshik/film-library/internal/actor"
+	"github.com/Coderovshik/film-library/internal/film"
+)
+
+type TrashService interface {
+	GetTrash(ctx context.Context) (*TrashResponse, error)
+	Purge(ctx context.Context, before time.Time) (*PurgeResult, error)
+}
+
+type TrashHandler interface {
+	GetTrash(w http.ResponseWriter, r *http.Request)
+}
+
+// TrashResponse lists deleted films and actors, most recently deleted first.
+type TrashResponse struct {
+	Films  []*film.FilmResponse   `json:"films"`
+	Actors []*actor.ActorResponse `json:"actors"`
+}
+
+type PurgeResult struct {
+	Films  int64
+	Actors int64
+}
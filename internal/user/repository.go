@@ -8,7 +8,6 @@ import (
 	"log"
 
 	"github.com/Coderovshik/film-library/internal/db"
-	"github.com/lib/pq"
 )
 
 var (
@@ -16,40 +15,35 @@ var (
 	ErrUserNotExist = errors.New("user does not exist")
 )
 
+var _ UserRepository = (*Repository)(nil)
+
 type Repository struct {
-	db db.DBTX
+	db      db.DBTX
+	dialect db.Dialect
 }
 
-func NewRepository(db db.DBTX) *Repository {
+func NewRepository(db db.DBTX, d db.Dialect) *Repository {
 	return &Repository{
-		db: db,
+		db:      db,
+		dialect: d,
 	}
 }
 
 func (r *Repository) CreateUser(ctx context.Context, user *User) (*User, error) {
 	const op = "user.Repository.CreateUser"
 
-	const query = "INSERT INTO users(user_name, passhash, is_admin) VALUES ($1, $2, $3) RETURNING user_id"
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		log.Printf("ERROR: failed to prepare query\n")
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-	defer stmt.Close()
-
-	err = stmt.QueryRowContext(ctx, user.Username, user.Passhash, user.IsAdmin).Scan(&user.ID)
+	const query = "INSERT INTO users(user_name, passhash, is_admin) VALUES ($1, $2, $3)"
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, "user_id", user.Username, user.Passhash, user.IsAdmin)
 	if err != nil {
-		var pgErr *pq.Error
-		if errors.As(err, &pgErr) {
-			if pgErr.Code.Name() == "unique_violation" {
-				log.Printf("ERROR: user %s already exists\n", user.Username)
-				return nil, fmt.Errorf("%s: %w", op, ErrUserExist)
-			}
+		if r.dialect.IsUniqueViolation(err) {
+			log.Printf("ERROR: user %s already exists\n", user.Username)
+			return nil, fmt.Errorf("%s: %w", op, ErrUserExist)
 		}
 
 		log.Printf("ERROR: failed to execute query\n")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	user.ID = int32(id)
 
 	return user, nil
 }
@@ -79,3 +73,47 @@ func (r *Repository) GetUserByUsername(ctx context.Context, username string) (*U
 
 	return &u, nil
 }
+
+func (r *Repository) UpdateUser(ctx context.Context, user *User) error {
+	const op = "user.Repository.UpdateUser"
+
+	const query = "UPDATE users SET passhash = $1, is_admin = $2 WHERE user_id = $3"
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: failed to prepare query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, user.Passhash, user.IsAdmin, user.ID)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: failed to retrieve amount of rows affected by query\n")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if count == 0 {
+		log.Printf("ERROR: zero rows affected by update\n")
+		return fmt.Errorf("%s: %w", op, ErrUserNotExist)
+	}
+
+	return nil
+}
+
+func (r *Repository) HasAdmin(ctx context.Context) (bool, error) {
+	const op = "user.Repository.HasAdmin"
+
+	const query = "SELECT EXISTS (SELECT 1 FROM users WHERE is_admin)"
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query).Scan(&exists)
+	if err != nil {
+		log.Printf("ERROR: failed to execute query\n")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists, nil
+}
@@ -2,6 +2,8 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"log"
@@ -13,8 +15,11 @@ import (
 
 var (
 	ErrPasswordIncorrect = errors.New("password is incorrect")
+	ErrAdminExist        = errors.New("admin already exists")
 )
 
+const generatedPasswordBytes = 18
+
 type Service struct {
 	repo       UserRepository
 	signingKey string
@@ -97,3 +102,92 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest) (*LoginResponse,
 		AccessToken: ss,
 	}, nil
 }
+
+func generatePassword() (string, error) {
+	b := make([]byte, generatedPasswordBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateAdmin bootstraps an admin account. It refuses to run when
+// an admin already exists, unless forced.
+func (s *Service) CreateAdmin(ctx context.Context, req *CreateAdminRequest) (*CreateAdminResponse, error) {
+	const op = "user.Service.CreateAdmin"
+
+	vErr := ValidateCreateAdminRequest(req)
+	if vErr != nil {
+		log.Printf("ERROR: failed request validation")
+		return nil, fmt.Errorf("%s: %w", op, vErr)
+	}
+
+	if !req.Force {
+		exists, err := s.repo.HasAdmin(ctx)
+		if err != nil {
+			log.Printf("ERROR: failed to check admin existence\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if exists {
+			log.Printf("ERROR: admin already exists\n")
+			return nil, fmt.Errorf("%s: %w", op, ErrAdminExist)
+		}
+	}
+
+	res := &CreateAdminResponse{
+		Username: req.Username,
+	}
+
+	password := req.Password
+	if len(password) == 0 {
+		var err error
+		password, err = generatePassword()
+		if err != nil {
+			log.Printf("ERROR: failed to generate password\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res.GeneratedPassword = password
+	}
+
+	passhash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("ERROR: failed to generate password hash\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	u := &User{
+		Username: req.Username,
+		Passhash: string(passhash),
+		IsAdmin:  true,
+	}
+
+	existing, err := s.repo.GetUserByUsername(ctx, req.Username)
+	switch {
+	case err == nil:
+		if !req.Force {
+			log.Printf("ERROR: user %s already exists\n", req.Username)
+			return nil, fmt.Errorf("%s: %w", op, ErrUserExist)
+		}
+
+		u.ID = existing.ID
+		err = s.repo.UpdateUser(ctx, u)
+		if err != nil {
+			log.Printf("ERROR: failed to update user record in repository\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res.Updated = true
+	case errors.Is(err, ErrUserNotExist):
+		u, err = s.repo.CreateUser(ctx, u)
+		if err != nil {
+			log.Printf("ERROR: failed to create user record in repository\n")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	default:
+		log.Printf("ERROR: failed to get user record from repository\n")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	res.ID = int(u.ID)
+
+	return res, nil
+}
@@ -194,3 +194,94 @@ func TestService_Login(t *testing.T) {
 		t.Fatalf("Expected %s, got %s", vErr.Error(), err.Error())
 	}
 }
+
+func TestService_CreateAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := NewMockUserRepository(ctrl)
+
+	cfg := &config.Config{
+		SigningKey: "key",
+	}
+	s := NewService(m, cfg)
+
+	// valid request
+	uIn := &User{
+		Username: "admin",
+		Passhash: "long-enough-password",
+		IsAdmin:  true,
+	}
+	uOut := &User{
+		ID:       1,
+		Username: "admin",
+		IsAdmin:  true,
+	}
+	gomock.InOrder(
+		m.EXPECT().HasAdmin(gomock.Any()).Return(false, nil).Times(1),
+		m.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq("admin")).Return(nil, ErrUserNotExist).Times(1),
+		m.EXPECT().CreateUser(gomock.Any(), UserMatcher(uIn)).Return(uOut, nil).Times(1),
+	)
+
+	req := &CreateAdminRequest{
+		Username: "admin",
+		Password: "long-enough-password",
+	}
+
+	res, err := s.CreateAdmin(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if res.ID != 1 || res.Username != "admin" || len(res.GeneratedPassword) != 0 || res.Updated {
+		t.Errorf("Unexpected response %+v", res)
+	}
+
+	// admin already exists
+	m.EXPECT().HasAdmin(gomock.Any()).Return(true, nil).Times(1)
+
+	_, err = s.CreateAdmin(context.TODO(), req)
+	if !errors.Is(err, ErrAdminExist) {
+		t.Fatalf("Expected %s, got %s", ErrAdminExist.Error(), err.Error())
+	}
+
+	// forced with generated password resets existing user
+	var updated *User
+	existing := &User{
+		ID:       2,
+		Username: "admin",
+	}
+	gomock.InOrder(
+		m.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq("admin")).Return(existing, nil).Times(1),
+		m.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *User) error {
+			updated = u
+			return nil
+		}).Times(1),
+	)
+
+	req = &CreateAdminRequest{
+		Username: "admin",
+		Force:    true,
+	}
+
+	res, err = s.CreateAdmin(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+	if len(res.GeneratedPassword) < minAdminPasswordLength || !res.Updated || res.ID != 2 {
+		t.Errorf("Unexpected response %+v", res)
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(updated.Passhash), []byte(res.GeneratedPassword))
+	if updated.ID != 2 || !updated.IsAdmin || err != nil {
+		t.Errorf("Unexpected user update %+v", updated)
+	}
+
+	// short password
+	req = &CreateAdminRequest{
+		Username: "admin",
+		Password: "short",
+	}
+
+	_, err = s.CreateAdmin(context.TODO(), req)
+	vErr := &util.ValidationError{}
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Expected %s, got %s", vErr.Error(), err.Error())
+	}
+}
@@ -15,6 +15,8 @@ type User struct {
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *User) (*User, error)
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	UpdateUser(ctx context.Context, user *User) error
+	HasAdmin(ctx context.Context) (bool, error)
 }
 
 type UserService interface {
@@ -46,3 +48,20 @@ type LoginRequest struct {
 type LoginResponse struct {
 	AccessToken string
 }
+
+type CreateAdminRequest struct {
+	Username string
+	// Password is generated when empty.
+	Password string
+	// Force allows creating an admin when one already exists and turns
+	// an existing user with the same name into an admin with the new password.
+	Force bool
+}
+
+type CreateAdminResponse struct {
+	ID       int
+	Username string
+	// GeneratedPassword is set only when the password was generated.
+	GeneratedPassword string
+	Updated           bool
+}
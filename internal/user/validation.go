@@ -1,6 +1,12 @@
 package user
 
-import "github.com/Coderovshik/film-library/internal/util"
+import (
+	"fmt"
+
+	"github.com/Coderovshik/film-library/internal/util"
+)
+
+const minAdminPasswordLength = 12
 
 func ValidateCreateUserReuqest(req *CreateUserRequest) *util.ValidationError {
 	ve := &util.ValidationError{}
@@ -37,3 +43,21 @@ func ValidateLoginReuqest(req *LoginRequest) *util.ValidationError {
 
 	return ve
 }
+
+func ValidateCreateAdminRequest(req *CreateAdminRequest) *util.ValidationError {
+	ve := &util.ValidationError{}
+
+	if len(req.Username) == 0 {
+		ve.AddViolation("username of length 0")
+	}
+
+	if len(req.Password) != 0 && len(req.Password) < minAdminPasswordLength {
+		ve.AddViolation(fmt.Sprintf("admin password shorter than %d symbols", minAdminPasswordLength))
+	}
+
+	if ve.NoViolations() {
+		return nil
+	}
+
+	return ve
+}
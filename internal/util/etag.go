@@ -0,0 +1,72 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ETag returns a strong entity tag for the representation v of a record
+// with the given row version. The hash covers data of related records,
+// e.g. actor names of a film, which do not bump the row version.
+func ETag(version int32, v any) string {
+	b, _ := json.Marshal(v)
+	sum := sha256.Sum256(b)
+
+	return fmt.Sprintf(`"%d-%x"`, version, sum[:8])
+}
+
+// MatchETag reports whether an If-Match or If-None-Match header value
+// matches etag. If-Match requires strong comparison, If-None-Match weak.
+func MatchETag(header, etag string, weak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "W/") {
+			if !weak {
+				continue
+			}
+			v = v[2:]
+		}
+
+		if v == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequireIfMatch returns the If-Match header of a modifying request and
+// writes 428 Precondition Required when it is missing.
+func RequireIfMatch(w http.ResponseWriter, r *http.Request) (string, bool) {
+	ifMatch := r.Header.Get("if-match")
+	if len(ifMatch) == 0 {
+		log.Printf("ERROR: missing If-Match header\n")
+		PreconditionRequired(w, r)
+		return "", false
+	}
+
+	return ifMatch, true
+}
+
+// CheckNotModified sets the ETag header and writes 304 Not Modified
+// when If-None-Match shows the client has the current representation.
+func CheckNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("etag", etag)
+
+	ifNoneMatch := r.Header.Get("if-none-match")
+	if len(ifNoneMatch) == 0 || !MatchETag(ifNoneMatch, etag, true) {
+		return false
+	}
+	NotModified(w, r)
+
+	return true
+}
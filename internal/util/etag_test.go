@@ -0,0 +1,29 @@
+package util
+
+import "testing"
+
+func TestMatchETag(t *testing.T) {
+	etag := ETag(2, map[string]string{"name": "film"})
+	if etag == ETag(3, map[string]string{"name": "film"}) || etag == ETag(2, map[string]string{"name": "other"}) {
+		t.Fatalf("Expected etag to depend on version and representation, got %s", etag)
+	}
+
+	tests := []struct {
+		header string
+		weak   bool
+		exp    bool
+	}{
+		{header: etag, exp: true},
+		{header: "*", exp: true},
+		{header: `"1-0000000000000000", ` + etag, exp: true},
+		{header: `"1-0000000000000000"`, exp: false},
+		{header: "W/" + etag, exp: false},
+		{header: "W/" + etag, weak: true, exp: true},
+	}
+
+	for _, v := range tests {
+		if got := MatchETag(v.header, etag, v.weak); got != v.exp {
+			t.Errorf("Expected %t for %s (weak %t), got %t", v.exp, v.header, v.weak, got)
+		}
+	}
+}
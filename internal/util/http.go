@@ -5,12 +5,18 @@ import (
 	"errors"
 	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
+const ContentTypeMergePatch = "application/merge-patch+json"
+
 const (
 	ErrorTypeValidation = "Validation"
 	ErrorTypeConflict   = "Conflict"
+	ErrorTypeNotFound   = "NotFound"
 )
 
 type ErrorMessage struct {
@@ -42,37 +48,113 @@ func BadRequest(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusBadRequest)
 }
 
+// JSON writes obj as JSON whatever the Accept header says, Render
+// negotiates the media type.
 func JSON(w http.ResponseWriter, r *http.Request, statusCode int, obj any) {
+	w.Header().Set("content-type", ContentType(MediaTypeJSON))
 	w.WriteHeader(statusCode)
-	w.Header().Set("content-type", "application/json")
 	jsonBytes, _ := json.Marshal(obj)
 	w.Write(jsonBytes)
 }
 
+// Text writes a plain text message, headers go before the status code.
+func Text(w http.ResponseWriter, r *http.Request, statusCode int, msg string) {
+	w.Header().Set("content-type", ContentType("text/plain"))
+	w.WriteHeader(statusCode)
+	w.Write([]byte(msg))
+}
+
+func NotModified(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotModified)
+}
+
+func PreconditionFailed(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusPreconditionFailed)
+}
+
+// PreconditionRequired is returned for modifications without If-Match.
+func PreconditionRequired(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusPreconditionRequired)
+}
+
+func NotAcceptable(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotAcceptable)
+}
+
+func UnsupportedMediaType(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusUnsupportedMediaType)
+}
+
+func ServiceUnavailable(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+// Query parses the query of the request as URL.Query does, except that
+// semicolons are kept in the values rather than dropping their pairs.
+func Query(r *http.Request) url.Values {
+	v := make(url.Values)
+	for _, pair := range strings.Split(r.URL.RawQuery, "&") {
+		if len(pair) == 0 {
+			continue
+		}
+
+		key, value, _ := strings.Cut(pair, "=")
+		key, kErr := url.QueryUnescape(key)
+		value, vErr := url.QueryUnescape(value)
+		if kErr != nil || vErr != nil {
+			continue
+		}
+		v[key] = append(v[key], value)
+	}
+
+	return v
+}
+
 func BindJSON(w http.ResponseWriter, r *http.Request, object any) bool {
-	if err := json.NewDecoder(r.Body).Decode(object); err != nil {
+	return bindJSON(w, r, json.NewDecoder(r.Body), object)
+}
+
+// BindMergePatch decodes a JSON Merge Patch document. Unlike BindJSON it
+// rejects other media types and unknown fields, a misspelled field would
+// otherwise be silently ignored.
+func BindMergePatch(w http.ResponseWriter, r *http.Request, object any) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("content-type"))
+	if err != nil || mt != ContentTypeMergePatch {
+		log.Printf("ERROR: unsupported media type %q\n", r.Header.Get("content-type"))
+		w.Header().Set("accept-patch", ContentTypeMergePatch)
+		UnsupportedMediaType(w, r)
+		return false
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	return bindJSON(w, r, dec, object)
+}
+
+func bindJSON(w http.ResponseWriter, r *http.Request, dec *json.Decoder, object any) bool {
+	if err := dec.Decode(object); err != nil {
 		log.Printf("ERROR: failed to decode request body err=%s\n", err.Error())
 
 		var sErr *json.SyntaxError
 		if errors.As(err, &sErr) {
-			BadRequest(w, r)
-			w.Header().Set("content-type", "text/plain")
-			w.Write([]byte("invalid json"))
+			Text(w, r, http.StatusBadRequest, "invalid json")
 			return false
 		}
 
 		if errors.Is(err, io.EOF) {
-			BadRequest(w, r)
-			w.Header().Set("content-type", "text/plain")
-			w.Write([]byte("empty request"))
+			Text(w, r, http.StatusBadRequest, "empty request")
 			return false
 		}
 
 		var utErr *json.UnmarshalTypeError
 		if errors.As(err, &utErr) {
-			BadRequest(w, r)
-			w.Header().Set("content-type", "text/plain")
-			w.Write([]byte("incorrect request typing"))
+			Text(w, r, http.StatusBadRequest, "incorrect request typing")
+			return false
+		}
+
+		if strings.HasPrefix(err.Error(), "json: unknown field") {
+			Text(w, r, http.StatusBadRequest, strings.TrimPrefix(err.Error(), "json: "))
 			return false
 		}
 
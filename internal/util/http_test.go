@@ -2,6 +2,8 @@ package util
 
 import (
 	"net/http"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -90,3 +92,16 @@ func TestBindJSON(t *testing.T) {
 		t.Errorf("Expected %+v, got %d: %+v", expReq, w.statusCode, req)
 	}
 }
+
+func TestQuery(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodGet, "/films?sort=rating,desc;name,asc&actorId=1&actorId=2&film=Kill%20Bill&bad=%zz&&", nil)
+
+	exp := url.Values{
+		"sort":    {"rating,desc;name,asc"},
+		"actorId": {"1", "2"},
+		"film":    {"Kill Bill"},
+	}
+	if res := Query(r); !reflect.DeepEqual(exp, res) {
+		t.Errorf("Expected %+v, got %+v", exp, res)
+	}
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"errors"
+	"strconv"
 )
 
 var (
@@ -31,3 +32,12 @@ func AreEqual[T comparable](a, b []T) bool {
 	}
 	return true
 }
+
+// FormatID formats an optional id, nil gives an empty string.
+func FormatID(id *int32) string {
+	if id == nil {
+		return ""
+	}
+
+	return strconv.Itoa(int(*id))
+}
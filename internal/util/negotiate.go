@@ -0,0 +1,72 @@
+package util
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the offered media type the Accept header prefers.
+// Offers are given in the order of the server preference, which breaks
+// ties; an empty header accepts the first offer. The second result is
+// false if none of the offers is acceptable.
+func Negotiate(accept string, offers ...string) (string, bool) {
+	if len(strings.TrimSpace(accept)) == 0 {
+		return offers[0], true
+	}
+
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+
+	var ranges []mediaRange
+	for _, v := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(v)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mt, q: q})
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		// the most specific matching range decides
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			s := matchMediaRange(r.mediaType, offer)
+			if s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best, bestQ > 0
+}
+
+// matchMediaRange returns -1 if the range does not match the media type,
+// otherwise the higher the more specific the range is.
+func matchMediaRange(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") &&
+		strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+
+	return -1
+}
@@ -0,0 +1,30 @@
+package util
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "application/x-ndjson", "text/csv"}
+
+	tests := []struct {
+		accept string
+		exp    string
+		ok     bool
+	}{
+		{accept: "", exp: "application/json", ok: true},
+		{accept: "*/*", exp: "application/json", ok: true},
+		{accept: "text/csv", exp: "text/csv", ok: true},
+		{accept: "text/*", exp: "text/csv", ok: true},
+		{accept: "application/json;q=0.5, text/csv", exp: "text/csv", ok: true},
+		{accept: "application/*;q=0.9, application/x-ndjson", exp: "application/x-ndjson", ok: true},
+		{accept: "*/*;q=0.1, application/json;q=0", exp: "application/x-ndjson", ok: true},
+		{accept: "text/html, application/xml", ok: false},
+		{accept: "text/csv;q=0", ok: false},
+	}
+
+	for _, v := range tests {
+		got, ok := Negotiate(v.accept, offers...)
+		if got != v.exp || ok != v.ok {
+			t.Errorf("Expected %q, %t for %q, got %q, %t", v.exp, v.ok, v.accept, got, ok)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// PatchField is a member of a JSON Merge Patch (RFC 7396) document. It tells
+// a field missing from the document apart from one explicitly set to null.
+type PatchField[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (f *PatchField[T]) UnmarshalJSON(b []byte) error {
+	f.Set = true
+	if bytes.Equal(b, []byte("null")) {
+		f.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(b, &f.Value)
+}
+
+// Ptr returns nil when the field is absent and a pointer to the value otherwise.
+func (f *PatchField[T]) Ptr() *T {
+	if !f.Set {
+		return nil
+	}
+
+	return &f.Value
+}
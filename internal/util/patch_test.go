@@ -0,0 +1,58 @@
+package util
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type mockPatch struct {
+	Name  PatchField[string] `json:"name"`
+	Count PatchField[int]    `json:"count"`
+	Note  PatchField[string] `json:"note"`
+}
+
+func TestPatchField(t *testing.T) {
+	var p mockPatch
+	if err := json.Unmarshal([]byte(`{"name": "new", "count": null}`), &p); err != nil {
+		t.Fatalf("No error expected, got %s", err.Error())
+	}
+
+	exp := mockPatch{
+		Name:  PatchField[string]{Set: true, Value: "new"},
+		Count: PatchField[int]{Set: true, Null: true},
+	}
+	if p != exp {
+		t.Errorf("Expected %+v, got %+v", exp, p)
+	}
+
+	if p.Note.Ptr() != nil || *p.Name.Ptr() != "new" {
+		t.Errorf("Expected absent note and set name, got %+v", p)
+	}
+}
+
+func TestBindMergePatch(t *testing.T) {
+	w := &MockResponseWriter{}
+	r, _ := http.NewRequest("", "", strings.NewReader(`{"name": "new"}`))
+	r.Header.Set("content-type", "application/merge-patch+json; charset=utf-8")
+
+	var p mockPatch
+	if ok := BindMergePatch(w, r, &p); !ok || p.Name.Value != "new" {
+		t.Errorf("Expected bound patch, got %d: %+v", w.statusCode, p)
+	}
+
+	w = &MockResponseWriter{}
+	r, _ = http.NewRequest("", "", strings.NewReader(`{"name": "new"}`))
+	r.Header.Set("content-type", "application/json")
+	if ok := BindMergePatch(w, r, &p); ok || w.statusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected %d, got %d", http.StatusUnsupportedMediaType, w.statusCode)
+	}
+
+	w = &MockResponseWriter{}
+	r, _ = http.NewRequest("", "", strings.NewReader(`{"nmae": "new"}`))
+	r.Header.Set("content-type", ContentTypeMergePatch)
+	if ok := BindMergePatch(w, r, &p); ok || w.statusCode != http.StatusBadRequest {
+		t.Errorf("Expected %d, got %d", http.StatusBadRequest, w.statusCode)
+	}
+}
@@ -42,3 +42,9 @@ func (qo *QueryableObject) Values() []any {
 func (qo *QueryableObject) Len() int {
 	return len(qo.keys)
 }
+
+// EscapeLike escapes the wildcards of s for a LIKE pattern, SQLite reads
+// the escapes only with ESCAPE '\'.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -28,3 +28,11 @@ func TestQueryableObject_Args(t *testing.T) {
 		t.Errorf("Expected %s, got %s", expRes, res)
 	}
 }
+
+func TestEscapeLike(t *testing.T) {
+	expRes := `50\% off\_sale \\o/`
+	res := EscapeLike(`50% off_sale \o/`)
+	if res != expRes {
+		t.Errorf("Expected %s, got %s", expRes, res)
+	}
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"log"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeXML  = "application/xml"
+	MediaTypeYAML = "application/yaml"
+	MediaTypeCSV  = "text/csv"
+)
+
+var ErrNotTable = errors.New("response is not a table")
+
+// Table is implemented by responses that can be rendered as CSV.
+type Table interface {
+	CSVHeader() []string
+	CSVRecords() [][]string
+}
+
+type encoder func(obj any) ([]byte, error)
+
+// renderers map media types to their encoders in the order of preference,
+// aliases are answered with the media type asked for.
+var renderers = []struct {
+	mediaType string
+	encode    encoder
+}{
+	{MediaTypeJSON, json.Marshal},
+	{MediaTypeXML, encodeXML},
+	{"text/xml", encodeXML},
+	{MediaTypeYAML, yaml.Marshal},
+	{"application/x-yaml", yaml.Marshal},
+	{"text/yaml", yaml.Marshal},
+	{MediaTypeCSV, encodeCSV},
+}
+
+// ContentType adds the charset to a textual media type.
+func ContentType(mediaType string) string {
+	return mediaType + "; charset=utf-8"
+}
+
+// Render writes obj in the media type the Accept header prefers: JSON,
+// XML, YAML or, for a Table, CSV. If none of them is acceptable it
+// responds with 406 Not Acceptable.
+func Render(w http.ResponseWriter, r *http.Request, statusCode int, obj any) {
+	_, isTable := obj.(Table)
+
+	offers := make([]string, 0, len(renderers))
+	encoders := make(map[string]encoder, len(renderers))
+	for _, v := range renderers {
+		if v.mediaType == MediaTypeCSV && !isTable {
+			continue
+		}
+		offers = append(offers, v.mediaType)
+		encoders[v.mediaType] = v.encode
+	}
+
+	w.Header().Add("vary", "Accept")
+
+	mt, ok := Negotiate(r.Header.Get("accept"), offers...)
+	if !ok {
+		log.Printf("ERROR: no acceptable media type for %q\n", r.Header.Get("accept"))
+		NotAcceptable(w, r)
+		return
+	}
+
+	b, err := encoders[mt](obj)
+	if err != nil {
+		log.Printf("ERROR: failed to encode response as %s err=%s\n", mt, err.Error())
+		InternalServerError(w, r)
+		return
+	}
+
+	w.Header().Set("content-type", ContentType(mt))
+	w.WriteHeader(statusCode)
+	w.Write(b)
+}
+
+func encodeXML(obj any) ([]byte, error) {
+	b, err := xml.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
+
+func encodeCSV(obj any) ([]byte, error) {
+	t, ok := obj.(Table)
+	if !ok {
+		return nil, ErrNotTable
+	}
+
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write(t.CSVHeader())
+	cw.WriteAll(t.CSVRecords())
+
+	return buf.Bytes(), cw.Error()
+}
@@ -0,0 +1,86 @@
+package util
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockItem struct {
+	ID   int    `json:"id" xml:"id" yaml:"id"`
+	Name string `json:"name" xml:"name" yaml:"name"`
+}
+
+type mockList []*mockItem
+
+func (l mockList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Items []*mockItem `xml:"item"`
+	}{l}, xml.StartElement{Name: xml.Name{Local: "items"}})
+}
+
+func (l mockList) CSVHeader() []string {
+	return []string{"id", "name"}
+}
+
+func (l mockList) CSVRecords() [][]string {
+	return [][]string{{"1", "one"}, {"2", "two, three"}}
+}
+
+func TestRender(t *testing.T) {
+	list := mockList{{1, "one"}, {2, "two, three"}}
+
+	tests := []struct {
+		accept      string
+		obj         any
+		status      int
+		contentType string
+		body        string
+	}{
+		{"", list, http.StatusOK, "application/json; charset=utf-8",
+			`[{"id":1,"name":"one"},{"id":2,"name":"two, three"}]`},
+		{"application/xml", list, http.StatusOK, "application/xml; charset=utf-8",
+			xml.Header + `<items><item><id>1</id><name>one</name></item><item><id>2</id><name>two, three</name></item></items>`},
+		{"text/xml", list, http.StatusOK, "text/xml; charset=utf-8",
+			xml.Header + `<items><item><id>1</id><name>one</name></item><item><id>2</id><name>two, three</name></item></items>`},
+		{"application/yaml", list, http.StatusOK, "application/yaml; charset=utf-8",
+			"- id: 1\n  name: one\n- id: 2\n  name: two, three\n"},
+		{"text/csv;q=0.9, application/json;q=0.5", list, http.StatusOK, "text/csv; charset=utf-8",
+			"id,name\n1,one\n2,\"two, three\"\n"},
+		{"text/csv", []*mockItem(list), http.StatusNotAcceptable, "", ""},
+		{"text/html", list, http.StatusNotAcceptable, "", ""},
+	}
+
+	for _, v := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("accept", v.accept)
+
+		Render(w, r, http.StatusOK, v.obj)
+
+		if w.Code != v.status {
+			t.Errorf("Accept %q: expected status %d, got %d", v.accept, v.status, w.Code)
+		}
+		if ct := w.Header().Get("content-type"); ct != v.contentType {
+			t.Errorf("Accept %q: expected content type %q, got %q", v.accept, v.contentType, ct)
+		}
+		if w.Body.String() != v.body {
+			t.Errorf("Accept %q: expected %q, got %q", v.accept, v.body, w.Body.String())
+		}
+		if w.Header().Get("vary") != "Accept" {
+			t.Errorf("Accept %q: expected vary header, got %q", v.accept, w.Header().Get("vary"))
+		}
+	}
+}
+
+func TestJSON_ContentType(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	JSON(w, r, http.StatusCreated, &ErrorMessage{ErrorType: ErrorTypeValidation, Body: "body"})
+
+	if w.Code != http.StatusCreated || w.Header().Get("content-type") != "application/json; charset=utf-8" {
+		t.Errorf("Expected %d with JSON content type, got %d %q", http.StatusCreated, w.Code, w.Header().Get("content-type"))
+	}
+}
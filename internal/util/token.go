@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"time"
@@ -12,7 +13,7 @@ var (
 	ErrUnknownClaimsType = errors.New("unknown claims type, cannot proceed")
 )
 
-var JWTContextKey struct{}
+type userClaimsKey struct{}
 
 type UserClaims struct {
 	ID       int    `json:"id"`
@@ -57,6 +58,29 @@ func ParseUserClaims(ss string, key string) (*UserClaims, error) {
 	}
 }
 
+// WithUserClaims returns a copy of ctx carrying the authenticated user.
+func WithUserClaims(ctx context.Context, uc *UserClaims) context.Context {
+	return context.WithValue(ctx, userClaimsKey{}, uc)
+}
+
+func UserClaimsFromContext(ctx context.Context) (*UserClaims, bool) {
+	uc, ok := ctx.Value(userClaimsKey{}).(*UserClaims)
+	return uc, ok
+}
+
+// AuthorFromContext returns the id of the authenticated user to record
+// as the author of a change, nil for changes made outside of a request
+// such as seeding.
+func AuthorFromContext(ctx context.Context) *int32 {
+	uc, ok := UserClaimsFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	id := int32(uc.ID)
+	return &id
+}
+
 func SetJWTCookie(w http.ResponseWriter, token string) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "jwt",
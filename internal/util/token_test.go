@@ -1,6 +1,9 @@
 package util
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 const (
 	secret = "mock_secret"
@@ -22,3 +25,14 @@ func TestParseUserClaims(t *testing.T) {
 		t.Errorf("Expected %+v, got %+v", uc, parsedUC)
 	}
 }
+
+func TestAuthorFromContext(t *testing.T) {
+	if id := AuthorFromContext(context.TODO()); id != nil {
+		t.Errorf("Expected no author, got %d", *id)
+	}
+
+	ctx := WithUserClaims(context.TODO(), NewUserClaims(7, "user", false))
+	if id := AuthorFromContext(ctx); id == nil || *id != 7 {
+		t.Errorf("Expected author %d, got %v", 7, id)
+	}
+}